@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -16,16 +17,31 @@ import (
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
-	"microservice-mvp/pkg/configs"
 	_ "microservice-mvp/docs" // 匯入生成的 Swagger 文件
+	"microservice-mvp/internal/audit"
+	"microservice-mvp/internal/consumer"
 	"microservice-mvp/internal/controller"
 	"microservice-mvp/internal/middleware"
 	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/outbox"
 	"microservice-mvp/internal/repository"
 	"microservice-mvp/internal/service"
+	"microservice-mvp/internal/webhook"
+	"microservice-mvp/pkg/buildinfo"
+	"microservice-mvp/pkg/configs"
 	"microservice-mvp/pkg/database"
+	"microservice-mvp/pkg/diagnostics"
+	"microservice-mvp/pkg/events"
+	"microservice-mvp/pkg/health"
 	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+	_ "microservice-mvp/pkg/messaging/kafka"
+	_ "microservice-mvp/pkg/messaging/nats"
+	_ "microservice-mvp/pkg/messaging/rocketmq"
+	"microservice-mvp/pkg/metrics"
+	"microservice-mvp/pkg/ratelimit"
 	"microservice-mvp/pkg/redis"
+	"microservice-mvp/pkg/tracing"
 )
 
 // @title Microservice MVP API (範本)
@@ -35,16 +51,22 @@ import (
 // @license.name Apache 2.0
 // @host localhost:8080
 // @BasePath /
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name X-Admin-Token
 func main() {
-	// 1. 載入配置
-	cfg, err := configs.LoadConfig("./configs/config.yaml")
+	// 1. 載入配置並驗證，驗證失敗時在建立任何連線之前結束
+	configPath := flag.String("config", "", "配置檔路徑，未指定時使用 "+configs.EnvConfigPath+" 環境變數或 "+configs.DefaultPath)
+	flag.Parse()
+	cfgPath := configs.ResolvePath(*configPath)
+	cfg, err := configs.LoadConfig(cfgPath)
 	if err != nil {
 		fmt.Printf("載入配置失敗: %v\n", err)
 		os.Exit(1)
 	}
 
 	// 2. 初始化日誌
-	zapLogger, err := logger.NewLogger(cfg.Logger.Level, cfg.Logger.Encoding)
+	zapLogger, err := logger.New(cfg.Logger)
 	if err != nil {
 		fmt.Printf("初始化日誌失敗: %v\n", err)
 		os.Exit(1)
@@ -54,10 +76,36 @@ func main() {
 		_ = logger.Logger.Sync()
 	}()
 
-	logger.Logger.Info("應用程式啟動中...", zap.String("persistence_mode", cfg.Persistence.Type))
+	build := buildinfo.Get()
+	logger.Logger.Info("應用程式啟動中...",
+		zap.String("persistence_mode", cfg.Persistence.Type),
+		zap.String("config", cfgPath),
+		zap.String("profile", os.Getenv(configs.EnvProfile)),
+		zap.String("version", build.Version),
+		zap.String("commit", build.Commit),
+		zap.String("build_time", build.BuildTime),
+	)
+
+	// 初始化分散式追蹤，最後才關閉以送出其他組件關閉過程中產生的 span
+	shutdownTracing, err := tracing.Init(cfg.Tracing)
+	if err != nil {
+		logger.Logger.Fatal("初始化分散式追蹤失敗", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Logger.Warn("關閉分散式追蹤失敗", zap.Error(err))
+		}
+	}()
 
 	// 3. 初始化持久化層 (Repository)
 	var playerRepo repository.PlayerRepository
+	var outboxRepo repository.OutboxRepository
+	var deadLetterRepo repository.DeadLetterRepository
+	var processedStore repository.ProcessedMessageStore
+	var webhookRepo repository.WebhookRepository
+	var auditRepo repository.AuditLogRepository
 	var sqlDB *gorm.DB
 	var redisClient *goRedis.Client
 
@@ -69,14 +117,14 @@ func main() {
 			logger.Logger.Fatal("初始化 TiDB 失敗", zap.Error(err))
 		}
 		sqlDB = dbClient
-		sqlDBGeneric, _ := sqlDB.DB()
 		defer func() {
-			_ = sqlDBGeneric.Close()
+			_ = database.Close()
 			logger.Logger.Info("資料庫連線已關閉")
 		}()
 
 		// 自動遷移 (Auto-migrate)
-		err = dbClient.AutoMigrate(&model.Player{})
+		err = dbClient.AutoMigrate(&model.Player{}, &model.OutboxEvent{}, &model.DeadLetter{}, &model.ProcessedMessage{},
+			&model.WebhookEndpoint{}, &model.WebhookDelivery{}, &model.AuditLog{})
 		if err != nil {
 			logger.Logger.Fatal("資料庫自動遷移失敗", zap.Error(err))
 		}
@@ -92,38 +140,195 @@ func main() {
 		}()
 
 		playerRepo = repository.NewPlayerRepositoryMySQL(sqlDB, redisClient)
+		outboxRepo = repository.NewOutboxRepositoryMySQL(sqlDB)
+		deadLetterRepo = repository.NewDeadLetterRepositoryMySQL(sqlDB)
+		webhookRepo = repository.NewWebhookRepositoryMySQL(sqlDB)
+		auditRepo = repository.NewAuditLogRepositoryMySQL(sqlDB)
+		switch cfg.Consumer.Dedupe.Store {
+		case "redis":
+			retention := time.Duration(cfg.Consumer.Dedupe.RetentionHours) * time.Hour
+			processedStore = repository.NewProcessedMessageStoreRedis(redisClient, retention)
+		case "db", "":
+			processedStore = repository.NewProcessedMessageStoreMySQL(sqlDB)
+		default:
+			logger.Logger.Fatal("配置中定義了無效的去重儲存類型", zap.String("store", cfg.Consumer.Dedupe.Store))
+		}
 
 	case "memory":
-		logger.Logger.Info("使用 In-Memory 儲存模式。重啟後資料將會遺失。 সন")
-		playerRepo = repository.NewPlayerRepositoryMemory()
+		memStore, err := repository.OpenMemoryStore(cfg.Persistence.Memory)
+		if err != nil {
+			logger.Logger.Fatal("初始化 In-Memory 儲存失敗", zap.Error(err))
+		}
+		defer func() {
+			if err := memStore.Close(); err != nil {
+				logger.Logger.Error("關閉 In-Memory 儲存失敗", zap.Error(err))
+				return
+			}
+			logger.Logger.Info("In-Memory 儲存已關閉")
+		}()
+
+		if cfg.Persistence.Memory.DataDir == "" {
+			logger.Logger.Info("使用 In-Memory 儲存模式。重啟後資料將會遺失。")
+		} else {
+			logger.Logger.Info("使用 In-Memory 儲存模式，資料將落地至本機目錄", zap.String("data_dir", cfg.Persistence.Memory.DataDir))
+		}
+		playerRepo = memStore.Players()
+		outboxRepo = memStore.Outbox()
+		deadLetterRepo = memStore.DeadLetters()
+		processedStore = memStore.ProcessedMessages()
+		webhookRepo = memStore.Webhooks()
+		health.Register(health.NewChecker("memory_store", func(ctx context.Context) health.Result {
+			if err := memStore.Err(); err != nil {
+				return health.Result{Status: health.StatusDown, Details: err.Error()}
+			}
+			return health.Result{Status: health.StatusUp, Details: "In-Memory 持久化已啟用"}
+		}), health.Options{Critical: true})
+
+		if cfg.Audit.Enabled {
+			// audit.file 已於載入配置時驗證
+			auditLog, err := repository.OpenAuditLogJSONL(cfg.Audit.File)
+			if err != nil {
+				logger.Logger.Fatal("開啟稽核紀錄檔失敗", zap.Error(err))
+			}
+			defer func() {
+				_ = auditLog.Close()
+			}()
+			auditRepo = auditLog
+		}
 
 	default:
 		logger.Logger.Fatal("配置中定義了無效的持久化類型", zap.String("type", cfg.Persistence.Type))
 	}
 
+	// 稽核紀錄 (登入、註冊與管理操作)，未啟用時 audit.Record 不做任何事
+	if cfg.Audit.Enabled {
+		audit.SetDefault(audit.NewRecorder(auditRepo))
+		logger.Logger.Info("稽核紀錄已啟用", zap.String("persistence_mode", cfg.Persistence.Type))
+	}
+
+	// 初始化訊息佇列 Producer (messaging.driver: stub 或 inproc 時不需要外部 Broker)
+	if _, err := messaging.InitProducer(cfg.Messaging); err != nil {
+		logger.Logger.Fatal("初始化訊息佇列 Producer 失敗", zap.Error(err))
+	}
+	defer messaging.GracefulShutdown()
+
+	// 啟動 outbox relay，將與業務資料同一交易寫入的事件發送到訊息佇列
+	// 於訊息佇列與儲存層關閉前停止 (defer 依相反順序執行)
+	if cfg.Outbox.Enabled {
+		relay := outbox.NewRelay(outboxRepo, cfg.Outbox, nil)
+		relay.Start()
+		defer relay.Stop()
+	}
+
+	// 啟動 Consumer：依 Topic/標籤路由到 handler，重試失敗的訊息寫入死信
+	// 未在配置中指定訂閱時，依已註冊的 handler 自動訂閱
+	if cfg.Consumer.Enabled {
+		msgRouter := consumer.NewRouter(cfg.Messaging.ConsumerGroup, cfg.Consumer, deadLetterRepo, nil)
+		msgRouter.Use(consumer.Tracing(), consumer.Logging(), consumer.Metrics(), consumer.Recovery())
+		if cfg.Consumer.Dedupe.Enabled {
+			// Dedupe 位於最內層，handler 的 DB 寫入與處理紀錄同一交易提交
+			msgRouter.Use(consumer.Dedupe(cfg.Messaging.ConsumerGroup, processedStore))
+			cleaner := consumer.NewDedupeCleaner(processedStore, cfg.Consumer.Dedupe)
+			cleaner.Start()
+			defer cleaner.Stop()
+		}
+		if err := msgRouter.HandleEvent(events.TypePlayerLoggedIn, service.HandlePlayerLoggedIn); err != nil {
+			logger.Logger.Fatal("註冊訊息 handler 失敗", zap.Error(err))
+		}
+
+		consumerCfg := cfg.Messaging
+		if len(consumerCfg.Subscriptions) == 0 {
+			consumerCfg.Subscriptions = msgRouter.Subscriptions()
+		}
+		if _, err := messaging.InitConsumer(consumerCfg, msgRouter.Dispatch); err != nil {
+			logger.Logger.Fatal("初始化訊息佇列 Consumer 失敗", zap.Error(err))
+		}
+
+		// webhook 以獨立的 Consumer Group 消費同一批領域事件，與其他 handler 互不影響
+		if cfg.Webhook.Enabled {
+			webhookGroup := cfg.Webhook.ConsumerGroup
+			if webhookGroup == "" {
+				webhookGroup = cfg.Messaging.ConsumerGroup + "_WEBHOOK"
+			}
+			webhookRouter := consumer.NewRouter(webhookGroup, cfg.Consumer, deadLetterRepo, nil)
+			webhookRouter.Use(consumer.Tracing(), consumer.Logging(), consumer.Metrics(), consumer.Recovery())
+			if cfg.Consumer.Dedupe.Enabled {
+				webhookRouter.Use(consumer.Dedupe(webhookGroup, processedStore))
+			}
+			fanout := webhook.NewFanout(webhookRepo)
+			for _, topic := range cfg.Webhook.Topics {
+				webhookRouter.Handle(topic, consumer.AnyTag, fanout.Handle)
+			}
+
+			webhookCfg := cfg.Messaging
+			webhookCfg.ConsumerGroup = webhookGroup
+			webhookCfg.Subscriptions = webhookRouter.Subscriptions()
+			webhookConsumer, err := messaging.NewConsumer(webhookCfg, webhookRouter.Dispatch)
+			if err != nil {
+				logger.Logger.Fatal("初始化 webhook Consumer 失敗", zap.Error(err))
+			}
+			defer func() {
+				_ = webhookConsumer.Shutdown()
+			}()
+
+			dispatcher := webhook.NewDispatcher(webhookRepo, cfg.Webhook, nil)
+			dispatcher.Start()
+			defer dispatcher.Stop()
+		}
+	}
+
 	// 4. 初始化服務層 (Services)
-	authService := service.NewAuthService(playerRepo)
+	authService := service.NewAuthService(playerRepo, outboxRepo)
 	playerService := service.NewPlayerService(playerRepo)
+	deadLetterService := service.NewDeadLetterService(deadLetterRepo, nil)
+	webhookService := service.NewWebhookService(webhookRepo)
 
 	// 5. 初始化控制器 (Controllers)
-	// 注意: HealthCheckController 的邏輯需要感知已啟用的組件。
-	// 在此範本中，我們保持簡單，若組件未啟用則傳遞 nil。
-	healthCheckController := controller.NewHealthCheckController(cfg) 
+	// 健康檢查讀取 health.Default() 的快取結果，已初始化的組件 (TiDB、Redis、訊息佇列) 已自行註冊檢查
+	healthCheckController := controller.NewHealthCheckController(health.Default())
 	authController := controller.NewAuthController(authService)
 	playerController := controller.NewPlayerController(playerService)
+	deadLetterController := controller.NewDeadLetterController(deadLetterService)
+	webhookController := controller.NewWebhookController(webhookService)
+	logLevelController := controller.NewLogLevelController()
+	auditController := controller.NewAuditController(service.NewAuditService(auditRepo))
 
 	// 6. 設定 Gin 引擎與路由
 	gin.SetMode(cfg.Server.Mode)
 	router := gin.New()
+	// 只信任設定的反向代理，否則任何用戶端都能以偽造的 X-Forwarded-For 繞過以 IP 區分的速率限制
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Logger.Fatal("設定可信任的反向代理失敗", zap.Error(err))
+	}
 
 	// 全域中間件 (Middleware)
 	router.Use(middleware.Recovery())
 	router.Use(middleware.TraceID())
+	router.Use(middleware.AuditContext())
+	router.Use(middleware.ReadYourWrites())
 	router.Use(middleware.LoggerMiddleware(cfg.Server))
+	if cfg.Logger.Capture.Enabled {
+		router.Use(middleware.BodyCapture(cfg.Logger.Capture)) // 除錯用，body 經遮蔽後寫入請求日誌
+	}
+	router.Use(middleware.ErrorHandler())
+	if cfg.RateLimit.Enabled {
+		// 位於 ErrorHandler 之內，被拒絕的請求同樣由 ErrorHandler 回應並寫入存取日誌
+		router.Use(middleware.RateLimit(cfg.RateLimit, newRateLimitStore(cfg, redisClient)))
+	}
 
 	// 註冊路由
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	router.GET("/health", healthCheckController.Check)
+	router.GET("/livez", healthCheckController.Live)
+	router.GET("/readyz", healthCheckController.Ready)
+	router.GET("/startupz", healthCheckController.Startup)
+	if cfg.Metrics.Enabled {
+		metricsPath := cfg.Metrics.Path
+		if metricsPath == "" {
+			metricsPath = "/metrics"
+		}
+		router.GET(metricsPath, gin.WrapH(metrics.Handler())) // Prometheus 指標
+	}
 
 	v1 := router.Group("/api/v1")
 	{
@@ -131,6 +336,35 @@ func main() {
 		v1.GET("/players/:id", playerController.GetPlayerInfo)
 	}
 
+	// 管理 API 僅在設定權杖時註冊
+	if cfg.Admin.Token != "" {
+		admin := router.Group("/admin", middleware.AdminAuth(cfg.Admin.Token), middleware.AuditAdmin())
+		{
+			admin.GET("/dlq", deadLetterController.List)
+			admin.DELETE("/dlq", deadLetterController.Purge)
+			admin.GET("/dlq/:id", deadLetterController.Get)
+			admin.DELETE("/dlq/:id", deadLetterController.Delete)
+			admin.POST("/dlq/:id/replay", deadLetterController.Replay)
+
+			admin.GET("/webhooks", webhookController.ListEndpoints)
+			admin.POST("/webhooks", webhookController.CreateEndpoint)
+			admin.GET("/webhooks/deliveries", webhookController.ListDeliveries)
+			admin.GET("/webhooks/deliveries/:id", webhookController.GetDelivery)
+			admin.POST("/webhooks/deliveries/:id/retry", webhookController.RetryDelivery)
+			admin.GET("/webhooks/:id", webhookController.GetEndpoint)
+			admin.PATCH("/webhooks/:id", webhookController.UpdateEndpoint)
+			admin.DELETE("/webhooks/:id", webhookController.DeleteEndpoint)
+
+			admin.GET("/log-level", logLevelController.Get)
+			admin.PUT("/log-level", logLevelController.Update)
+
+			if cfg.Audit.Enabled {
+				admin.GET("/audit", auditController.List)
+				admin.GET("/audit/verify", auditController.Verify)
+			}
+		}
+	}
+
 	// 7. 啟動伺服器
 	serverAddr := fmt.Sprintf(":%d", cfg.Server.Port)
 	srv := &http.Server{
@@ -144,19 +378,77 @@ func main() {
 		}
 	}()
 
+	// 遷移、儲存層載入與各背景工作皆已於上方完成；先完成一輪健康檢查，/readyz 才有結果可回報
+	health.Default().Start(cfg.HealthCheck)
+	defer health.Default().Stop()
+	healthCheckController.MarkStarted()
 	logger.Logger.Info(fmt.Sprintf("伺服器運行於 %s", serverAddr))
 
+	// 診斷伺服器 (pprof、expvar 等) 於獨立的埠監聽，不經過對外的 Gin 路由
+	var adminSrv *http.Server
+	if cfg.Server.Admin.Enabled {
+		adminSrv = diagnostics.NewServer(cfg)
+		go func() {
+			if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Logger.Error("診斷伺服器啟動失敗", zap.Error(err))
+			}
+		}()
+		logger.Logger.Info(fmt.Sprintf("診斷伺服器運行於 %s", adminSrv.Addr))
+	}
+
 	// 8. 優雅關閉 (Graceful Shutdown)
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Logger.Info("正在關閉伺服器...")
 
+	// 先讓 /readyz 失敗，等負載平衡移除此實例後才停止接受連線
+	healthCheckController.MarkShuttingDown()
+	if delay := time.Duration(cfg.Server.ShutdownDelaySeconds) * time.Second; delay > 0 {
+		logger.Logger.Info("等待負載平衡移除實例", zap.Duration("delay", delay))
+		time.Sleep(delay)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Logger.Fatal("伺服器強制關閉", zap.Error(err))
 	}
+	// 主伺服器關閉後才關閉診斷伺服器；進行中的 profile 可能超過關閉期限，逾時後直接中斷連線
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(ctx); err != nil {
+			logger.Logger.Warn("診斷伺服器強制關閉", zap.Error(err))
+			_ = adminSrv.Close()
+		}
+	}
 
 	logger.Logger.Info("伺服器已退出")
 }
+
+// newRateLimitStore 依 rate_limit.store 建立速率限制的儲存，未設定時 mysql 模式使用 Redis (多個實例共用額度)，memory 模式使用記憶體
+func newRateLimitStore(cfg *configs.Config, redisClient *goRedis.Client) ratelimit.Store {
+	store := cfg.RateLimit.Store
+	if store == "" {
+		store = "memory"
+		if cfg.Persistence.Type == "mysql" {
+			store = "redis"
+		}
+	}
+
+	switch store {
+	case "redis":
+		if redisClient == nil {
+			logger.Logger.Fatal("速率限制使用 Redis 儲存時須使用 mysql 持久化模式")
+		}
+		prefix := cfg.RateLimit.KeyPrefix
+		if prefix == "" {
+			prefix = "ratelimit:"
+		}
+		return ratelimit.NewRedisStore(redisClient, prefix)
+	case "memory":
+		return ratelimit.NewMemoryStore()
+	default:
+		logger.Logger.Fatal("配置中定義了無效的速率限制儲存類型", zap.String("store", store))
+		return nil
+	}
+}
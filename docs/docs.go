@@ -20,6 +20,1141 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/audit": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "依 ID 遞增順序列出稽核紀錄，可依動作、行為者、對象與時間區間篩選，以 after_id 分頁",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "查詢稽核紀錄",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "動作，例如 auth.login_failed、admin.request",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "行為者 ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "對象 ID",
+                        "name": "target_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "起始時間 (RFC3339，含)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "結束時間 (RFC3339，不含)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分頁游標，只回傳 ID 大於此值的紀錄",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每頁筆數 (預設 100，上限 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功查詢稽核紀錄",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/microservice-mvp_internal_model.AuditLog"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit/verify": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "從第一筆開始驗證雜湊鏈，回報第一筆遭修改、刪除或插入的紀錄；可保存回傳的 last_hash，之後比對以偵測尾端被截斷",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "驗證稽核紀錄",
+                "responses": {
+                    "200": {
+                        "description": "驗證完成 (valid 表示雜湊鏈是否完整)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.AuditVerifyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dlq": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "依 ID 遞增順序列出死信，可依原始 Topic 篩選，以 after_id 分頁",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "查詢死信",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "原始 Topic",
+                        "name": "topic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分頁游標，只回傳 ID 大於此值的死信",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每頁筆數 (預設 50，上限 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功查詢死信",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/microservice-mvp_internal_model.DeadLetterResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "清除指定原始 Topic 的死信，未指定 Topic 則清除全部",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "清除死信",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "原始 Topic",
+                        "name": "topic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "清除成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.PurgeDeadLettersResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dlq/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "根據 ID 取得死信內容，無法以 UTF-8 呈現的內容以 base64 編碼",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "取得死信",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "死信 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功取得死信",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.DeadLetterResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "404": {
+                        "description": "死信不存在",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "刪除死信",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "死信 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "刪除成功",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "404": {
+                        "description": "死信不存在",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dlq/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "以原本的 Topic、標籤、Keys 與屬性重新投遞死信，成功後刪除該死信",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "重新投遞死信",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "死信 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "重新投遞成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.ReplayDeadLetterResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "404": {
+                        "description": "死信不存在",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/log-level": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "回傳目前的全域日誌級別與各具名 logger (例如 gorm、consumer、outbox) 的級別覆寫",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "查詢日誌級別",
+                "responses": {
+                    "200": {
+                        "description": "成功查詢日誌級別",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.LogLevelResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "不需重啟即可調整日誌級別。未指定 logger 時調整全域級別；指定 logger 時只調整該 logger 及其子 logger，level 為空則移除其覆寫",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "調整日誌級別",
+                "parameters": [
+                    {
+                        "description": "日誌級別",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_internal_model.LogLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功調整日誌級別",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.LogLevelResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "列出 webhook 端點",
+                "responses": {
+                    "200": {
+                        "description": "成功列出端點",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/microservice-mvp_internal_model.WebhookEndpointResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "註冊接收領域事件的端點。事件類型可為完整類型、\"*\" 或前綴 (例如 \"wallet.*\")；\n未提供簽章金鑰時由伺服器產生，金鑰只會在此回應中出現一次",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "註冊 webhook 端點",
+                "parameters": [
+                    {
+                        "description": "端點設定",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_internal_model.CreateWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "註冊成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.CreateWebhookEndpointResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "依 ID 遞增順序列出投遞紀錄，可依端點與狀態篩選，以 after_id 分頁",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "查詢 webhook 投遞紀錄",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "端點 ID",
+                        "name": "endpoint_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "狀態",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "分頁游標，只回傳 ID 大於此值的紀錄",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每頁筆數 (預設 50，上限 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功查詢投遞紀錄",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/microservice-mvp_internal_model.WebhookDelivery"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/deliveries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "取得投遞紀錄與投遞的事件內容",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "取得 webhook 投遞紀錄",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "投遞紀錄 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功取得投遞紀錄",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.WebhookDeliveryResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "404": {
+                        "description": "投遞紀錄不存在",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/deliveries/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "將投遞紀錄排入立即重新投遞並重新開始重試排程；端點停用期間不會投遞",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "重新投遞 webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "投遞紀錄 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已排入重新投遞",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.WebhookDelivery"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "404": {
+                        "description": "投遞紀錄不存在",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "取得 webhook 端點",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "端點 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功取得端點",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.WebhookEndpointResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "404": {
+                        "description": "端點不存在",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "刪除端點及其所有投遞紀錄",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "刪除 webhook 端點",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "端點 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "刪除成功",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "404": {
+                        "description": "端點不存在",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "只更新有提供的欄位；將 enabled 設為 true 可重新啟用被自動停用的端點，並將連續失敗次數歸零",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "更新 webhook 端點",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "端點 ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "要更新的欄位",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_internal_model.UpdateWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/microservice-mvp_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/microservice-mvp_internal_model.WebhookEndpointResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "請求參數錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "管理權杖無效",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
+                        }
+                    },
+                    "404": {
+                        "description": "端點不存在",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/login": {
             "post": {
                 "description": "驗證玩家憑證並返回認證 Token",
@@ -74,6 +1209,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError401"
                         }
+                    },
+                    "429": {
+                        "description": "請求過於頻繁",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError429"
+                        }
+                    },
+                    "500": {
+                        "description": "內部伺服器錯誤",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError500"
+                        }
                     }
                 }
             }
@@ -128,6 +1275,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError404"
                         }
                     },
+                    "429": {
+                        "description": "請求過於頻繁",
+                        "schema": {
+                            "$ref": "#/definitions/microservice-mvp_pkg_response.HTTPError429"
+                        }
+                    },
                     "500": {
                         "description": "內部伺服器錯誤",
                         "schema": {
@@ -137,90 +1290,431 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
-            "get": {
-                "description": "檢查服務運行狀態、系統指標及依賴組件健康狀況",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "System"
-                ],
-                "summary": "健康檢查",
-                "responses": {
-                    "200": {
-                        "description": "服務正常",
-                        "schema": {
-                            "$ref": "#/definitions/internal_controller.HealthCheckResponse"
-                        }
-                    },
-                    "503": {
-                        "description": "服務降級或異常",
-                        "schema": {
-                            "$ref": "#/definitions/internal_controller.HealthCheckResponse"
-                        }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "internal_controller.ComponentStatus": {
+        "/health": {
+            "get": {
+                "description": "回報服務運行狀態、系統指標及依賴組件最近一次背景檢查的結果；關鍵組件 DOWN 時為 DOWN (503)，延遲偏高或非關鍵組件異常時為 DEGRADED (200)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "健康檢查",
+                "responses": {
+                    "200": {
+                        "description": "服務正常或降級",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller.HealthCheckResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "服務異常",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller.HealthCheckResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "行程仍在運行即回傳 200，不檢查任何依賴，避免依賴異常時 Kubernetes 重啟健康的 Pod",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "存活探針",
+                "responses": {
+                    "200": {
+                        "description": "行程存活",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller.ProbeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "依最近一次背景檢查的結果，任一關鍵組件 DOWN 時回傳 503；延遲偏高與非關鍵組件異常不視為失敗。啟動完成前與關閉期間一律回傳 503",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "就緒探針",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "略過的檢查項目 (例如 tidb、redis、messaging)，可重複或以逗號分隔",
+                        "name": "exclude",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "可接受流量",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller.ProbeResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "未就緒",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller.ProbeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/startupz": {
+            "get": {
+                "description": "資料庫遷移與預熱完成後回傳 200，完成前回傳 503",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "啟動探針",
+                "responses": {
+                    "200": {
+                        "description": "啟動完成",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller.ProbeResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "啟動中",
+                        "schema": {
+                            "$ref": "#/definitions/internal_controller.ProbeResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "internal_controller.ComponentStatus": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "critical": {
+                    "description": "關鍵組件異常時整體為 DOWN，非關鍵組件異常時為 DEGRADED",
+                    "type": "boolean"
+                },
+                "details": {
+                    "description": "額外資訊 (例如: 項目數量, DB 統計)",
+                    "type": "string"
+                },
+                "latency": {
+                    "type": "string",
+                    "example": "5ms"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "UP"
+                }
+            }
+        },
+        "internal_controller.HealthCheckResponse": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/internal_controller.ComponentStatus"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "UP"
+                },
+                "system": {
+                    "$ref": "#/definitions/internal_controller.SystemMetrics"
+                },
+                "uptime": {
+                    "type": "string",
+                    "example": "1h2m3s"
+                }
+            }
+        },
+        "internal_controller.ProbeResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/internal_controller.ComponentStatus"
+                    }
+                },
+                "excluded": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "UP"
+                }
+            }
+        },
+        "internal_controller.SystemMetrics": {
+            "type": "object",
+            "properties": {
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.23.0"
+                },
+                "goroutines": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "memory_usage": {
+                    "type": "string",
+                    "example": "5 MB"
+                }
+            }
+        },
+        "microservice-mvp_internal_model.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "actor_type": {
+                    "type": "string"
+                },
+                "after": {
+                    "description": "異動後的值 (JSON)",
+                    "type": "string"
+                },
+                "before": {
+                    "description": "異動前的值 (JSON)",
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "prev_hash": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "type": "string"
+                },
+                "trace_id": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "microservice-mvp_internal_model.AuditVerifyResponse": {
+            "type": "object",
+            "properties": {
+                "broken_at_id": {
+                    "description": "第一筆驗證失敗的紀錄 ID",
+                    "type": "integer"
+                },
+                "checked": {
+                    "description": "已驗證的紀錄筆數",
+                    "type": "integer"
+                },
+                "last_hash": {
+                    "description": "最後一筆通過驗證的紀錄雜湊，可另行保存以偵測截斷",
+                    "type": "string"
+                },
+                "last_id": {
+                    "description": "最後一筆通過驗證的紀錄 ID",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "microservice-mvp_internal_model.CreateWebhookEndpointRequest": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "event_types": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "留空則由伺服器產生",
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "microservice-mvp_internal_model.CreateWebhookEndpointResponse": {
             "type": "object",
             "properties": {
-                "details": {
-                    "description": "額外資訊 (例如: 項目數量, DB 統計)",
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "latency": {
-                    "type": "string",
-                    "example": "5ms"
+                "description": {
+                    "type": "string"
                 },
-                "message": {
+                "disabled_at": {
                     "type": "string"
                 },
-                "status": {
-                    "type": "string",
-                    "example": "UP"
+                "disabled_reason": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "internal_controller.HealthCheckResponse": {
+        "microservice-mvp_internal_model.DeadLetterResponse": {
             "type": "object",
             "properties": {
-                "components": {
+                "body": {
+                    "type": "string"
+                },
+                "body_encoding": {
+                    "description": "utf8 或 base64 (例如 protobuf 編碼的內容)",
+                    "type": "string"
+                },
+                "consumer_group": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "keys": {
+                    "type": "string"
+                },
+                "msg_id": {
+                    "type": "string"
+                },
+                "properties": {
                     "type": "object",
                     "additionalProperties": {
-                        "$ref": "#/definitions/internal_controller.ComponentStatus"
+                        "type": "string"
                     }
                 },
-                "status": {
-                    "type": "string",
-                    "example": "UP"
+                "reason": {
+                    "type": "string"
                 },
-                "system": {
-                    "$ref": "#/definitions/internal_controller.SystemMetrics"
+                "reconsume_times": {
+                    "type": "integer"
                 },
-                "uptime": {
-                    "type": "string",
-                    "example": "1h2m3s"
+                "tag": {
+                    "type": "string"
+                },
+                "topic": {
+                    "type": "string"
                 }
             }
         },
-        "internal_controller.SystemMetrics": {
+        "microservice-mvp_internal_model.LogLevelRequest": {
             "type": "object",
             "properties": {
-                "go_version": {
+                "level": {
                     "type": "string",
-                    "example": "go1.23.0"
-                },
-                "goroutines": {
-                    "type": "integer",
-                    "example": 12
+                    "example": "debug"
                 },
-                "memory_usage": {
+                "logger": {
                     "type": "string",
-                    "example": "5 MB"
+                    "example": "gorm"
+                }
+            }
+        },
+        "microservice-mvp_internal_model.LogLevelResponse": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "example": "info"
+                },
+                "loggers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -265,6 +1759,187 @@ const docTemplate = `{
                 }
             }
         },
+        "microservice-mvp_internal_model.PurgeDeadLettersResponse": {
+            "type": "object",
+            "properties": {
+                "purged": {
+                    "type": "integer"
+                }
+            }
+        },
+        "microservice-mvp_internal_model.ReplayDeadLetterResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "已重新投遞並刪除的死信 ID",
+                    "type": "integer"
+                },
+                "msg_id": {
+                    "description": "重新投遞後的新訊息 ID",
+                    "type": "string"
+                },
+                "topic": {
+                    "description": "重新投遞的 Topic",
+                    "type": "string"
+                }
+            }
+        },
+        "microservice-mvp_internal_model.UpdateWebhookEndpointRequest": {
+            "type": "object",
+            "required": [
+                "event_types"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "enabled": {
+                    "description": "重新啟用時會將連續失敗次數歸零",
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "microservice-mvp_internal_model.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "endpoint_id": {
+                    "type": "integer"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_duration_ms": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "microservice-mvp_internal_model.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "endpoint_id": {
+                    "type": "integer"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_duration_ms": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "microservice-mvp_internal_model.WebhookEndpointResponse": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "disabled_reason": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "microservice-mvp_pkg_response.HTTPError400": {
             "type": "object",
             "properties": {
@@ -272,6 +1947,10 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 400
                 },
+                "error_code": {
+                    "type": "string",
+                    "example": "VALIDATION_FAILED"
+                },
                 "message": {
                     "type": "string",
                     "example": "請求參數錯誤"
@@ -285,9 +1964,13 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 401
                 },
+                "error_code": {
+                    "type": "string",
+                    "example": "INVALID_CREDENTIALS"
+                },
                 "message": {
                     "type": "string",
-                    "example": "未經授權"
+                    "example": "憑證無效"
                 }
             }
         },
@@ -298,9 +1981,30 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 404
                 },
+                "error_code": {
+                    "type": "string",
+                    "example": "PLAYER_NOT_FOUND"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "玩家不存在"
+                }
+            }
+        },
+        "microservice-mvp_pkg_response.HTTPError429": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 429
+                },
+                "error_code": {
+                    "type": "string",
+                    "example": "RATE_LIMITED"
+                },
                 "message": {
                     "type": "string",
-                    "example": "找不到資源"
+                    "example": "請求過於頻繁，請稍後再試"
                 }
             }
         },
@@ -311,6 +2015,10 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 500
                 },
+                "error_code": {
+                    "type": "string",
+                    "example": "INTERNAL"
+                },
                 "message": {
                     "type": "string",
                     "example": "內部伺服器錯誤"
@@ -325,12 +2033,24 @@ const docTemplate = `{
                     "example": 200
                 },
                 "data": {},
+                "error_code": {
+                    "description": "機器可讀的錯誤碼，僅錯誤回應時出現",
+                    "type": "string",
+                    "example": ""
+                },
                 "message": {
                     "type": "string",
                     "example": "success"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "type": "apiKey",
+            "name": "X-Admin-Token",
+            "in": "header"
+        }
     }
 }`
 
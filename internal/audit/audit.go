@@ -0,0 +1,144 @@
+// Package audit 記錄安全與金流相關操作 (登入、密碼與角色變更、餘額異動、管理操作) 的稽核紀錄。
+// 稽核紀錄與一般應用程式日誌分開保存：mysql 模式寫入 audit_logs 資料表，memory 模式寫入 JSONL 檔，
+// 每筆紀錄以雜湊鏈接在前一筆之後，可透過管理 API 驗證是否遭竄改。
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/logger"
+)
+
+// Actor 是執行操作的主體
+type Actor struct {
+	Type string // model.AuditActorPlayer、model.AuditActorAdmin 等
+	ID   string
+}
+
+// Target 是操作的對象
+type Target struct {
+	Type string // 例如 player、webhook_endpoint
+	ID   string
+}
+
+// Entry 是一筆待記錄的稽核事件，IP、User-Agent 與 trace ID 由上下文補上
+type Entry struct {
+	Action string
+	Result string // 空字串視為 model.AuditResultSuccess
+	Actor  Actor  // 未指定時使用上下文中的行為者 (見 WithActor)，都沒有則為 anonymous
+	Target Target
+	Before any // 異動前的值，以 JSON 保存；依 logger.redact 遮蔽敏感欄位
+	After  any // 異動後的值，以 JSON 保存；依 logger.redact 遮蔽敏感欄位
+	Reason string
+}
+
+// Client 是發出請求的用戶端資訊
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+type actorKey struct{}
+
+// WithClient 回傳帶有用戶端資訊的上下文，HTTP 請求由 middleware.AuditContext 設定
+func WithClient(ctx context.Context, c Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// ClientFromContext 取出上下文中的用戶端資訊
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	c, ok := ctx.Value(clientKey{}).(Client)
+	return c, ok
+}
+
+// WithActor 回傳帶有行為者的上下文，之後未指定 Actor 的稽核事件都歸屬於此行為者
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// Recorder 將稽核事件寫入 AuditLogRepository
+type Recorder struct {
+	repo repository.AuditLogRepository
+	now  func() time.Time
+}
+
+// NewRecorder 建立一個新的 Recorder
+func NewRecorder(repo repository.AuditLogRepository) *Recorder {
+	return &Recorder{repo: repo, now: time.Now}
+}
+
+// Record 補上上下文資訊後寫入一筆稽核紀錄
+func (r *Recorder) Record(ctx context.Context, e Entry) error {
+	entry := &model.AuditLog{
+		OccurredAt: r.now(),
+		Action:     e.Action,
+		Result:     e.Result,
+		ActorType:  e.Actor.Type,
+		ActorID:    e.Actor.ID,
+		TargetType: e.Target.Type,
+		TargetID:   e.Target.ID,
+		Before:     encodeValue(e.Before),
+		After:      encodeValue(e.After),
+		Reason:     e.Reason,
+		TraceID:    logger.TraceIDFromContext(ctx),
+	}
+	if entry.Result == "" {
+		entry.Result = model.AuditResultSuccess
+	}
+	if entry.ActorType == "" {
+		if a, ok := ctx.Value(actorKey{}).(Actor); ok {
+			entry.ActorType, entry.ActorID = a.Type, a.ID
+		} else {
+			entry.ActorType = model.AuditActorAnonymous
+		}
+	}
+	if c, ok := ClientFromContext(ctx); ok {
+		entry.IP, entry.UserAgent = c.IP, c.UserAgent
+	}
+	return r.repo.Append(ctx, entry)
+}
+
+// encodeValue 將值序列化為 JSON 並遮蔽敏感欄位，nil 時回傳空字串
+func encodeValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return logger.RedactPayload(b)
+}
+
+// defaultRecorder 是 Record 使用的全域 Recorder，未設定時不記錄
+var defaultRecorder atomic.Pointer[Recorder]
+
+// SetDefault 設定全域 Recorder，nil 代表停用稽核
+func SetDefault(r *Recorder) {
+	defaultRecorder.Store(r)
+}
+
+// Record 以全域 Recorder 寫入一筆稽核紀錄
+// 寫入失敗只記錄錯誤日誌，不影響呼叫端的業務流程
+func Record(ctx context.Context, e Entry) {
+	r := defaultRecorder.Load()
+	if r == nil {
+		return
+	}
+	if err := r.Record(ctx, e); err != nil {
+		logger.FromContext(ctx).Named("audit").Error("寫入稽核紀錄失敗",
+			zap.Error(err),
+			zap.String("action", e.Action),
+			zap.String("targetID", e.Target.ID),
+		)
+	}
+}
@@ -0,0 +1,72 @@
+package audit_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/audit"
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestRecorder_Record(t *testing.T) {
+	_, err := logger.New(configs.LoggerConfig{Stdout: true, Redact: configs.RedactConfig{Enabled: true}})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = logger.NewLogger("info", "console") })
+
+	repo, err := repository.OpenAuditLogJSONL(filepath.Join(t.TempDir(), "audit.jsonl"))
+	require.NoError(t, err)
+	defer repo.Close()
+	recorder := audit.NewRecorder(repo)
+
+	ctx := logger.WithTraceID(context.Background(), "trace-123")
+	ctx = audit.WithClient(ctx, audit.Client{IP: "203.0.113.9", UserAgent: "curl/8.0"})
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		entry      audit.Entry
+		wantActor  audit.Actor
+		wantResult string
+		wantAfter  string
+	}{
+		{
+			name:       "未指定行為者時為 anonymous",
+			ctx:        ctx,
+			entry:      audit.Entry{Action: model.AuditActionLoginFailed, Result: model.AuditResultFailure},
+			wantActor:  audit.Actor{Type: model.AuditActorAnonymous},
+			wantResult: model.AuditResultFailure,
+		},
+		{
+			name:       "沿用上下文中的行為者並遮蔽敏感欄位",
+			ctx:        audit.WithActor(ctx, audit.Actor{Type: model.AuditActorAdmin, ID: "admin"}),
+			entry:      audit.Entry{Action: model.AuditActionPasswordChange, After: map[string]any{"password": "n3w", "username": "bob"}},
+			wantActor:  audit.Actor{Type: model.AuditActorAdmin, ID: "admin"},
+			wantResult: model.AuditResultSuccess,
+			wantAfter:  `{"password":"***","username":"bob"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, recorder.Record(tt.ctx, tt.entry))
+
+			logs, err := repo.List(ctx, repository.AuditLogFilter{Action: tt.entry.Action})
+			require.NoError(t, err)
+			require.Len(t, logs, 1)
+			got := logs[0]
+			assert.Equal(t, tt.wantActor, audit.Actor{Type: got.ActorType, ID: got.ActorID})
+			assert.Equal(t, tt.wantAfter, got.After)
+			assert.Equal(t, tt.wantResult, got.Result)
+			assert.Equal(t, "203.0.113.9", got.IP)
+			assert.Equal(t, "curl/8.0", got.UserAgent)
+			assert.Equal(t, "trace-123", got.TraceID)
+		})
+	}
+}
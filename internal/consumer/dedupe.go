@@ -0,0 +1,112 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+// Dedupe 以 (Consumer Group, 訊息 ID) 去除重複投遞的訊息，已處理過的訊息直接確認不再呼叫 handler
+// 應放在 middleware 鏈的最內層：使用 DB 儲存時，handler 以 database.WithContext(ctx) 進行的寫入
+// 會與處理紀錄在同一個交易中提交，handler 失敗時一併回滾，訊息重試時會再次處理
+func Dedupe(group string, store repository.ProcessedMessageStore) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *messaging.Delivery) error {
+			if msg.MsgID == "" {
+				return next(ctx, msg)
+			}
+
+			err := store.Process(ctx, group, msg.MsgID, func(ctx context.Context) error {
+				return next(ctx, msg)
+			})
+			if errors.Is(err, repository.ErrAlreadyProcessed) {
+				duplicatesTotal.Add(1)
+				logger.FromContext(ctx).Named("consumer").Info("略過已處理過的重複訊息",
+					zap.String("topic", msg.Topic), zap.String("msgID", msg.MsgID), zap.String("group", group))
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// DedupeCleaner 是定期清除過期處理紀錄的背景工作
+type DedupeCleaner struct {
+	store     repository.ProcessedMessageStore
+	retention time.Duration
+	interval  time.Duration
+
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewDedupeCleaner 建立一個新的 DedupeCleaner
+func NewDedupeCleaner(store repository.ProcessedMessageStore, cfg configs.DedupeConfig) *DedupeCleaner {
+	c := &DedupeCleaner{
+		store:     store,
+		retention: time.Duration(cfg.RetentionHours) * time.Hour,
+		interval:  time.Duration(cfg.CleanupIntervalMinutes) * time.Minute,
+		stop:      make(chan struct{}),
+	}
+	if c.retention <= 0 {
+		c.retention = 72 * time.Hour
+	}
+	if c.interval <= 0 {
+		c.interval = 10 * time.Minute
+	}
+	return c
+}
+
+// Start 啟動背景清除
+func (c *DedupeCleaner) Start() {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+			if _, err := c.RunOnce(context.Background()); err != nil {
+				logger.Named("consumer").Error("清除過期處理紀錄失敗", zap.Error(err))
+			}
+		}
+	}()
+	logger.Named("consumer").Info("處理紀錄清除工作已啟動",
+		zap.Duration("retention", c.retention),
+		zap.Duration("interval", c.interval),
+	)
+}
+
+// Stop 停止背景清除並等待進行中的清除完成
+func (c *DedupeCleaner) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		c.wg.Wait()
+		logger.Named("consumer").Info("處理紀錄清除工作已停止")
+	})
+}
+
+// RunOnce 清除超過保留期限的處理紀錄，回傳刪除筆數
+func (c *DedupeCleaner) RunOnce(ctx context.Context) (int64, error) {
+	n, err := c.store.PurgeProcessedBefore(ctx, time.Now().Add(-c.retention))
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		logger.Named("consumer").Info("已清除過期處理紀錄", zap.Int64("purged", n))
+	}
+	return n, nil
+}
@@ -0,0 +1,57 @@
+package consumer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"microservice-mvp/internal/consumer"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+func TestDedupe_RedeliveryHasSingleEffect(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	store := repository.NewMemoryStore().ProcessedMessages()
+
+	r := consumer.NewRouter(testGroup, configs.ConsumerConfig{MaxRetries: 3}, nil, nil)
+	r.Use(consumer.Recovery(), consumer.Dedupe(testGroup, store))
+
+	credits := 0
+	failNext := true
+	r.Handle("bets", "bet.settled", func(ctx context.Context, msg *messaging.Delivery) error {
+		if failNext {
+			failNext = false
+			return errors.New("錢包服務暫時無法使用")
+		}
+		credits++
+		return nil
+	})
+
+	msg := &messaging.Delivery{Message: messaging.Message{Topic: "bets", Tag: "bet.settled"}, MsgID: "msg-1"}
+
+	tests := []struct {
+		name        string
+		wantRetry   bool
+		wantCredits int
+	}{
+		{name: "FirstAttemptFails", wantRetry: true, wantCredits: 0},
+		{name: "RetrySucceeds", wantCredits: 1},
+		{name: "DuplicateIsAcked", wantCredits: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.Dispatch(context.Background(), msg)
+			_, retry := messaging.RetryDelay(err)
+			assert.Equal(t, tt.wantRetry, retry)
+			if !tt.wantRetry {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCredits, credits)
+		})
+	}
+}
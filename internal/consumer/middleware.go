@@ -0,0 +1,128 @@
+package consumer
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+	promMetrics "microservice-mvp/pkg/metrics" // 別名以避免與 expvar 的 metrics 衝突
+	"microservice-mvp/pkg/tracing"
+)
+
+// metrics 透過 expvar 暴露消費統計，handled 與 failed 以 "<topic>/<tag>" 分組
+var (
+	metrics           = expvar.NewMap("consumer")
+	handledByRoute    = new(expvar.Map)
+	failedByRoute     = new(expvar.Map)
+	panicsTotal       = new(expvar.Int)
+	retriedTotal      = new(expvar.Int)
+	deadLetteredTotal = new(expvar.Int)
+	duplicatesTotal   = new(expvar.Int)
+)
+
+func init() {
+	metrics.Set("handled_total", handledByRoute)
+	metrics.Set("failed_total", failedByRoute)
+	metrics.Set("panics_total", panicsTotal)
+	metrics.Set("retried_total", retriedTotal)
+	metrics.Set("dead_lettered_total", deadLetteredTotal)
+	metrics.Set("duplicates_total", duplicatesTotal)
+}
+
+// Tracing 從訊息的 traceparent/tracestate 屬性接續發送端的 trace，為每次處理建立 consumer span
+// 應放在最外層，讓其他 middleware 的日誌帶有 span 的 trace_id 與 span_id
+func Tracing() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *messaging.Delivery) error {
+			ctx = messaging.ExtractTraceContext(ctx, &msg.Message)
+			ctx, span := tracing.Tracer().Start(ctx, msg.Topic+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					semconv.MessagingOperationDeliver,
+					semconv.MessagingDestinationName(msg.Topic),
+					semconv.MessagingMessageID(msg.MsgID),
+					semconv.MessagingMessageBodySize(len(msg.Body)),
+				),
+			)
+			defer span.End()
+
+			err := next(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// Logging 將訊息的 TRACE_ID 屬性注入上下文，並記錄處理結果與耗時
+func Logging() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *messaging.Delivery) error {
+			ctx = logger.WithTraceID(ctx, msg.Property(messaging.PropertyTraceID))
+			log := logger.FromContext(ctx).Named("consumer").With(
+				zap.String("topic", msg.Topic),
+				zap.String("tag", msg.Tag),
+				zap.String("msgID", msg.MsgID),
+				zap.Int("reconsumeTimes", msg.ReconsumeTimes),
+			)
+
+			start := time.Now()
+			err := next(ctx, msg)
+			if err != nil {
+				log.Warn("訊息處理失敗", zap.Error(err), zap.Duration("latency", time.Since(start)))
+				return err
+			}
+			log.Info("訊息處理完成", zap.Duration("latency", time.Since(start)))
+			return nil
+		}
+	}
+}
+
+// Recovery 捕獲 handler 的 panic 並轉為錯誤，訊息依一般失敗流程重試
+func Recovery() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *messaging.Delivery) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					panicsTotal.Add(1)
+					logger.FromContext(ctx).Named("consumer").Error("捕獲訊息處理 Panic",
+						zap.Any("error", r),
+						zap.String("msgID", msg.MsgID),
+						zap.String("stack", string(debug.Stack())),
+					)
+					err = fmt.Errorf("訊息處理 panic: %v", r)
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Metrics 依 Topic 與標籤累計處理成功與失敗次數 (expvar)，並記錄 Prometheus 消費指標與處理時間
+func Metrics() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *messaging.Delivery) error {
+			route := msg.Topic + "/" + msg.Tag
+			start := time.Now()
+			err := next(ctx, msg)
+			promMetrics.ObserveMQConsume(msg.Topic, msg.Tag, time.Since(start), err)
+			if err != nil {
+				failedByRoute.Add(route, 1)
+				return err
+			}
+			handledByRoute.Add(route, 1)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Package consumer 是訊息消費框架：依 Topic 與標籤把訊息路由到 handler，
+// 以 middleware 包裝日誌、panic 復原與指標，失敗時以指數退避重試，
+// 超過重試上限或判定為無法處理 (poison) 的訊息則寫入死信儲存並轉送死信 Topic。
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/events"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+const (
+	// AnyTag 代表符合任何標籤的路由
+	AnyTag = "*"
+	// PropertyDeadLetterReason 記錄訊息轉入死信 Topic 的原因
+	PropertyDeadLetterReason = "DLQ_REASON"
+)
+
+// Handler 處理一則訊息，回傳錯誤代表需要重試 (以 Permanent 包裝則直接轉入死信)
+type Handler func(ctx context.Context, msg *messaging.Delivery) error
+
+// Middleware 包裝 Handler，例如日誌、panic 復原與指標
+type Middleware func(next Handler) Handler
+
+// EventHandler 處理一則已解碼的領域事件
+type EventHandler func(ctx context.Context, env *events.Envelope) error
+
+// Publisher 將訊息同步發送到訊息佇列，預設為 messaging.Send
+type Publisher func(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error)
+
+// permanentError 標記重試也無法成功的錯誤 (poison message)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 將錯誤標記為不可重試，訊息會直接轉入死信
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 判斷錯誤是否已被標記為不可重試
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// Router 依 Topic 與標籤分派訊息，並負責重試排程與死信處理
+// 所有 Use 與 Handle 呼叫須在 Consumer 啟動前完成，且 Use 須在 Handle 之前
+type Router struct {
+	group       string
+	dlqTopic    string
+	maxRetries  int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+
+	deadLetters repository.DeadLetterRepository
+	publish     Publisher
+
+	middlewares []Middleware
+	routes      map[string]map[string]Handler // topic -> tag -> 已套用 middleware 的 handler
+}
+
+// NewRouter 建立一個新的 Router
+// deadLetters 為 nil 時死信只會轉送到死信 Topic；publish 為 nil 時使用 messaging.Send
+func NewRouter(group string, cfg configs.ConsumerConfig, deadLetters repository.DeadLetterRepository, publish Publisher) *Router {
+	if publish == nil {
+		publish = messaging.Send
+	}
+	r := &Router{
+		group:       group,
+		dlqTopic:    cfg.DLQTopic,
+		maxRetries:  cfg.MaxRetries,
+		backoffBase: time.Duration(cfg.BackoffBaseMs) * time.Millisecond,
+		backoffMax:  time.Duration(cfg.BackoffMaxMs) * time.Millisecond,
+		deadLetters: deadLetters,
+		publish:     publish,
+		routes:      make(map[string]map[string]Handler),
+	}
+	if r.dlqTopic == "" {
+		r.dlqTopic = messaging.DLQTopicPrefix + group
+	}
+	if r.maxRetries < 0 {
+		r.maxRetries = 0
+	}
+	if r.backoffBase <= 0 {
+		r.backoffBase = time.Second
+	}
+	if r.backoffMax <= 0 {
+		r.backoffMax = 10 * time.Minute
+	}
+	return r
+}
+
+// DLQTopic 回傳死信 Topic
+func (r *Router) DLQTopic() string {
+	return r.dlqTopic
+}
+
+// Use 加入 middleware，先加入的位於最外層
+// middleware 在 Handle 時組合進 handler，已註冊路由後再呼叫 Use 會 panic，避免部分路由漏掉 middleware
+func (r *Router) Use(mw ...Middleware) {
+	if len(r.routes) > 0 {
+		panic("consumer: Use 須在 Handle 之前呼叫")
+	}
+	r.middlewares = append(r.middlewares, mw...)
+}
+
+// Handle 註冊 Topic 與標籤的 handler，tag 為空字串或 AnyTag 代表該 Topic 的所有標籤
+// 同時符合特定標籤與 AnyTag 時，以特定標籤優先
+func (r *Router) Handle(topic, tag string, h Handler) {
+	if tag == "" {
+		tag = AnyTag
+	}
+	if r.routes[topic] == nil {
+		r.routes[topic] = make(map[string]Handler)
+	}
+	// 在註冊時組合 middleware 鏈，Dispatch 不必為每則訊息重新組合
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+	r.routes[topic][tag] = h
+}
+
+// HandleEvent 註冊領域事件的 handler，Topic 與標籤取自 events.Default 的事件目錄
+// 無法解碼的訊息視為 poison message，直接轉入死信
+func (r *Router) HandleEvent(eventType string, h EventHandler) error {
+	topic, err := events.Default.Topic(eventType)
+	if err != nil {
+		return err
+	}
+	r.Handle(topic, eventType, func(ctx context.Context, msg *messaging.Delivery) error {
+		env, err := events.Default.DecodeMessage(msg)
+		if err != nil {
+			return Permanent(fmt.Errorf("解碼事件失敗: %w", err))
+		}
+		return h(ctx, env)
+	})
+	return nil
+}
+
+// Subscriptions 回傳已註冊路由對應的訂閱設定，可直接交給 messaging.InitConsumer
+func (r *Router) Subscriptions() []configs.SubscriptionConfig {
+	subs := make([]configs.SubscriptionConfig, 0, len(r.routes))
+	for topic, tags := range r.routes {
+		expr := make([]string, 0, len(tags))
+		for tag := range tags {
+			if tag == AnyTag {
+				expr = []string{AnyTag}
+				break
+			}
+			expr = append(expr, tag)
+		}
+		sort.Strings(expr)
+		subs = append(subs, configs.SubscriptionConfig{Topic: topic, Tags: strings.Join(expr, " || ")})
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Topic < subs[j].Topic })
+	return subs
+}
+
+// Dispatch 實作 messaging.Handler
+// 需要重試時回傳以 messaging.RetryAfter 包裝的錯誤，由 Broker 依退避時間重新投遞；
+// 已處理或已轉入死信時回傳 nil；死信也無法保存時回傳錯誤，訊息交由 Broker 依預設策略重投
+func (r *Router) Dispatch(ctx context.Context, msg *messaging.Delivery) error {
+	h := r.route(msg.Topic, msg.Tag)
+	if h == nil {
+		logger.FromContext(ctx).Named("consumer").Warn("訊息沒有對應的 handler，直接確認",
+			zap.String("topic", msg.Topic), zap.String("tag", msg.Tag), zap.String("msgID", msg.MsgID))
+		return nil
+	}
+
+	err := h(ctx, msg)
+	if err == nil {
+		return nil
+	}
+
+	if !IsPermanent(err) && msg.ReconsumeTimes < r.maxRetries {
+		retriedTotal.Add(1)
+		backoff := r.backoff(msg.ReconsumeTimes)
+		logger.FromContext(ctx).Named("consumer").Warn("訊息處理失敗，稍後重試",
+			zap.Error(err),
+			zap.String("topic", msg.Topic),
+			zap.String("msgID", msg.MsgID),
+			zap.Int("reconsumeTimes", msg.ReconsumeTimes),
+			zap.Duration("backoff", backoff),
+		)
+		return messaging.RetryAfter(backoff, err)
+	}
+
+	if err := r.deadLetter(ctx, msg, err); err != nil {
+		logger.FromContext(ctx).Named("consumer").Error("保存死信失敗", zap.Error(err), zap.String("msgID", msg.MsgID))
+		return err
+	}
+	return nil
+}
+
+// route 查詢訊息對應的 handler
+func (r *Router) route(topic, tag string) Handler {
+	tags := r.routes[topic]
+	if h, ok := tags[tag]; ok {
+		return h
+	}
+	return tags[AnyTag]
+}
+
+// backoff 回傳第 n 次失敗 (從 0 起算) 後的退避時間 base·2^n，不超過上限
+// RocketMQ 會再進位到最接近的延遲等級
+func (r *Router) backoff(n int) time.Duration {
+	if n < 32 {
+		if d := r.backoffBase << n; d > 0 && d < r.backoffMax {
+			return d
+		}
+	}
+	return r.backoffMax
+}
+
+// deadLetter 將訊息寫入死信儲存並轉送到死信 Topic
+// 有死信儲存時以儲存為準，轉送失敗只記錄日誌；沒有死信儲存時轉送失敗即回傳錯誤
+func (r *Router) deadLetter(ctx context.Context, msg *messaging.Delivery, cause error) error {
+	log := logger.FromContext(ctx).Named("consumer")
+
+	if r.deadLetters != nil {
+		props, err := json.Marshal(msg.Properties)
+		if err != nil {
+			return fmt.Errorf("序列化訊息屬性失敗: %w", err)
+		}
+		d := &model.DeadLetter{
+			ConsumerGroup:  r.group,
+			Topic:          msg.Topic,
+			Tag:            msg.Tag,
+			Keys:           msg.KeysString(),
+			MsgID:          msg.MsgID,
+			Body:           msg.Body,
+			Properties:     string(props),
+			ReconsumeTimes: int32(msg.ReconsumeTimes),
+			Reason:         cause.Error(),
+		}
+		if err := r.deadLetters.SaveDeadLetter(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	dlq := msg.Message.Clone()
+	dlq.Topic = r.dlqTopic
+	dlq.Delay = 0
+	dlq.WithProperty(messaging.PropertyOriginTopic, msg.Topic)
+	dlq.WithProperty(messaging.PropertyOriginMsgID, msg.MsgID)
+	dlq.WithProperty(PropertyDeadLetterReason, cause.Error())
+	if _, err := r.publish(ctx, dlq); err != nil {
+		if r.deadLetters == nil {
+			return fmt.Errorf("轉送死信 Topic 失敗: %w", err)
+		}
+		log.Error("轉送死信 Topic 失敗，死信已保存", zap.Error(err), zap.String("dlqTopic", r.dlqTopic))
+	}
+
+	deadLetteredTotal.Add(1)
+	log.Warn("訊息轉入死信",
+		zap.Error(cause),
+		zap.String("topic", msg.Topic),
+		zap.String("tag", msg.Tag),
+		zap.String("msgID", msg.MsgID),
+		zap.Int("reconsumeTimes", msg.ReconsumeTimes),
+		zap.Bool("permanent", IsPermanent(cause)),
+		zap.String("dlqTopic", r.dlqTopic),
+	)
+	return nil
+}
@@ -0,0 +1,275 @@
+package consumer_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/consumer"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/events"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+const testGroup = "test-group"
+
+// harness 以行程內 Broker 端到端地執行 Router
+type harness struct {
+	broker      *messaging.InProcBroker
+	router      *consumer.Router
+	deadLetters repository.DeadLetterRepository
+	dlq         chan *messaging.Delivery
+}
+
+func newHarness(t *testing.T, maxRetries int) *harness {
+	t.Helper()
+	_, _ = logger.NewLogger("info", "console")
+
+	b := messaging.NewInProcBroker(messaging.InProcOptions{
+		MaxReconsumeTimes: maxRetries + 3, // Broker 層的上限須大於 Router 的重試上限
+		DelayLevels:       []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 15 * time.Millisecond},
+	})
+	t.Cleanup(b.Close)
+
+	h := &harness{
+		broker:      b,
+		deadLetters: repository.NewMemoryStore().DeadLetters(),
+		dlq:         make(chan *messaging.Delivery, 16),
+	}
+	publish := func(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error) {
+		return b.Producer().Send(ctx, msg)
+	}
+	h.router = consumer.NewRouter(testGroup, configs.ConsumerConfig{MaxRetries: maxRetries, BackoffBaseMs: 1000}, h.deadLetters, publish)
+	h.router.Use(consumer.Logging(), consumer.Metrics(), consumer.Recovery())
+
+	dlqConsumer := b.NewConsumer("dlq-watcher")
+	require.NoError(t, dlqConsumer.Subscribe(h.router.DLQTopic(), "", func(ctx context.Context, d *messaging.Delivery) error {
+		h.dlq <- d
+		return nil
+	}))
+	require.NoError(t, dlqConsumer.Start())
+	return h
+}
+
+// start 依 Router 的路由訂閱並啟動 Consumer，須在註冊 handler 之後呼叫
+func (h *harness) start(t *testing.T) {
+	t.Helper()
+	c := h.broker.NewConsumer(testGroup)
+	for _, sub := range h.router.Subscriptions() {
+		require.NoError(t, c.Subscribe(sub.Topic, sub.Tags, h.router.Dispatch))
+	}
+	require.NoError(t, c.Start())
+}
+
+func (h *harness) send(t *testing.T, topic, tag string, body []byte) {
+	t.Helper()
+	msg := messaging.NewMessage(topic, tag, body, []string{"key-1"})
+	msg.WithProperty(messaging.PropertyTraceID, "trace-abc")
+	_, err := h.broker.Producer().Send(context.Background(), msg)
+	require.NoError(t, err)
+}
+
+func (h *harness) waitDLQ(t *testing.T) *messaging.Delivery {
+	t.Helper()
+	select {
+	case m := <-h.dlq:
+		return m
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待死信逾時")
+		return nil
+	}
+}
+
+func TestRouter_RetriesThenSucceeds(t *testing.T) {
+	h := newHarness(t, 3)
+	var calls atomic.Int32
+	done := make(chan string, 1)
+	h.router.Handle("orders", "created", func(ctx context.Context, msg *messaging.Delivery) error {
+		if calls.Add(1) < 3 {
+			return errors.New("下游暫時無法使用")
+		}
+		done <- logger.TraceIDFromContext(ctx)
+		return nil
+	})
+	h.start(t)
+
+	h.send(t, "orders", "created", []byte("order-1"))
+	select {
+	case traceID := <-done:
+		assert.Equal(t, "trace-abc", traceID, "Logging middleware 應注入訊息的 TRACE_ID")
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待重試成功逾時")
+	}
+	assert.EqualValues(t, 3, calls.Load())
+
+	letters, err := h.deadLetters.ListDeadLetters(context.Background(), repository.DeadLetterFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+}
+
+func TestRouter_DeadLettersAfterMaxRetries(t *testing.T) {
+	h := newHarness(t, 2)
+	var calls atomic.Int32
+	h.router.Handle("orders", "", func(ctx context.Context, msg *messaging.Delivery) error {
+		calls.Add(1)
+		return errors.New("永遠失敗")
+	})
+	h.start(t)
+
+	h.send(t, "orders", "created", []byte("order-2"))
+	dlq := h.waitDLQ(t)
+	assert.EqualValues(t, 3, calls.Load(), "首次消費加上 2 次重試")
+	assert.Equal(t, "orders", dlq.Property(messaging.PropertyOriginTopic))
+	assert.Equal(t, "永遠失敗", dlq.Property(consumer.PropertyDeadLetterReason))
+	assert.Equal(t, "order-2", string(dlq.Body))
+
+	letters, err := h.deadLetters.ListDeadLetters(context.Background(), repository.DeadLetterFilter{Topic: "orders"})
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, testGroup, letters[0].ConsumerGroup)
+	assert.Equal(t, "created", letters[0].Tag)
+	assert.Equal(t, "key-1", letters[0].Keys)
+	assert.EqualValues(t, 2, letters[0].ReconsumeTimes)
+	assert.Equal(t, "永遠失敗", letters[0].Reason)
+}
+
+func TestRouter_PoisonMessageSkipsRetries(t *testing.T) {
+	h := newHarness(t, 5)
+	var calls atomic.Int32
+	require.NoError(t, h.router.HandleEvent(events.TypePlayerRegistered, func(ctx context.Context, env *events.Envelope) error {
+		calls.Add(1)
+		return nil
+	}))
+	h.start(t)
+
+	topic, err := events.Default.Topic(events.TypePlayerRegistered)
+	require.NoError(t, err)
+	h.send(t, topic, events.TypePlayerRegistered, []byte("not-an-envelope"))
+
+	dlq := h.waitDLQ(t)
+	assert.Contains(t, dlq.Property(consumer.PropertyDeadLetterReason), "解碼事件失敗")
+	assert.Zero(t, calls.Load(), "無法解碼的訊息不應呼叫 handler")
+
+	letters, err := h.deadLetters.ListDeadLetters(context.Background(), repository.DeadLetterFilter{})
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Zero(t, letters[0].ReconsumeTimes)
+}
+
+func TestRouter_PanicIsRecoveredAndRetried(t *testing.T) {
+	h := newHarness(t, 1)
+	var calls atomic.Int32
+	h.router.Handle("orders", "created", func(ctx context.Context, msg *messaging.Delivery) error {
+		calls.Add(1)
+		panic("nil map")
+	})
+	h.start(t)
+
+	h.send(t, "orders", "created", []byte("order-3"))
+	dlq := h.waitDLQ(t)
+	assert.Contains(t, dlq.Property(consumer.PropertyDeadLetterReason), "nil map")
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestRouter_RoutingAndSubscriptions(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	r := consumer.NewRouter(testGroup, configs.ConsumerConfig{}, nil, func(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error) {
+		return &messaging.SendResult{}, nil
+	})
+
+	var got []string
+	record := func(name string) consumer.Handler {
+		return func(ctx context.Context, msg *messaging.Delivery) error {
+			got = append(got, name)
+			return nil
+		}
+	}
+	r.Handle("bets", "placed", record("placed"))
+	r.Handle("bets", "settled", record("settled"))
+	r.Handle("wallet", "", record("wallet"))
+	r.Handle("wallet", "credited", record("credited"))
+
+	assert.Equal(t, []configs.SubscriptionConfig{
+		{Topic: "bets", Tags: "placed || settled"},
+		{Topic: "wallet", Tags: "*"},
+	}, r.Subscriptions())
+
+	for _, m := range []struct{ topic, tag string }{
+		{"bets", "settled"}, {"wallet", "debited"}, {"wallet", "credited"}, {"bets", "cancelled"},
+	} {
+		msg := &messaging.Delivery{Message: messaging.Message{Topic: m.topic, Tag: m.tag}}
+		require.NoError(t, r.Dispatch(context.Background(), msg))
+	}
+	assert.Equal(t, []string{"settled", "wallet", "credited"}, got, "沒有對應 handler 的訊息直接確認")
+}
+
+func TestRouter_ComposesMiddlewareOnce(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	r := consumer.NewRouter(testGroup, configs.ConsumerConfig{}, nil, nil)
+
+	var composed, calls int
+	r.Use(func(next consumer.Handler) consumer.Handler {
+		composed++
+		return func(ctx context.Context, msg *messaging.Delivery) error {
+			calls++
+			return next(ctx, msg)
+		}
+	})
+	r.Handle("bets", "", func(ctx context.Context, msg *messaging.Delivery) error { return nil })
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, r.Dispatch(context.Background(), &messaging.Delivery{Message: messaging.Message{Topic: "bets"}}))
+	}
+	assert.Equal(t, 1, composed, "middleware 鏈應只在 Handle 時組合一次")
+	assert.Equal(t, 3, calls)
+
+	assert.Panics(t, func() { r.Use(consumer.Recovery()) }, "註冊路由後不可再加入 middleware")
+}
+
+func TestRouter_Backoff(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	r := consumer.NewRouter(testGroup, configs.ConsumerConfig{MaxRetries: 100, BackoffBaseMs: 1000, BackoffMaxMs: 600000}, nil, nil)
+	r.Handle("orders", "", func(ctx context.Context, msg *messaging.Delivery) error {
+		return errors.New("失敗")
+	})
+
+	tests := []struct {
+		reconsumeTimes int
+		wantBackoff    time.Duration
+	}{
+		{reconsumeTimes: 0, wantBackoff: time.Second},
+		{reconsumeTimes: 1, wantBackoff: 2 * time.Second},
+		{reconsumeTimes: 3, wantBackoff: 8 * time.Second},
+		{reconsumeTimes: 5, wantBackoff: 32 * time.Second},
+		{reconsumeTimes: 40, wantBackoff: 10 * time.Minute}, // 上限 10m
+	}
+	for _, tt := range tests {
+		msg := &messaging.Delivery{Message: messaging.Message{Topic: "orders"}, ReconsumeTimes: tt.reconsumeTimes}
+		err := r.Dispatch(context.Background(), msg)
+		delay, ok := messaging.RetryDelay(err)
+		require.True(t, ok, "reconsumeTimes=%d 應要求重試", tt.reconsumeTimes)
+		assert.Equal(t, tt.wantBackoff, delay, "reconsumeTimes=%d", tt.reconsumeTimes)
+	}
+}
+
+func TestTracing_ContinuesProducerTrace(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+
+	var traceID string
+	handler := consumer.Tracing()(consumer.Logging()(func(ctx context.Context, msg *messaging.Delivery) error {
+		traceID = logger.TraceIDFromContext(ctx)
+		return nil
+	}))
+
+	msg := messaging.NewMessage("orders", "created", []byte("order-1"), nil).
+		WithProperty(messaging.PropertyTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, handler(context.Background(), &messaging.Delivery{Message: *msg, MsgID: "msg-1"}))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID, "應接續 traceparent 屬性中的 trace")
+}
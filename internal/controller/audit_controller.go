@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/internal/service"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/response"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditController 處理稽核紀錄的查詢與驗證請求
+type AuditController struct {
+	auditService service.AuditService
+}
+
+// NewAuditController 建立一個新的 AuditController
+func NewAuditController(auditService service.AuditService) *AuditController {
+	return &AuditController{auditService: auditService}
+}
+
+// List 處理查詢稽核紀錄的請求
+// @Summary 查詢稽核紀錄
+// @Description 依 ID 遞增順序列出稽核紀錄，可依動作、行為者、對象與時間區間篩選，以 after_id 分頁
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param action query string false "動作，例如 auth.login_failed、admin.request"
+// @Param actor_id query string false "行為者 ID"
+// @Param target_id query string false "對象 ID"
+// @Param since query string false "起始時間 (RFC3339，含)"
+// @Param until query string false "結束時間 (RFC3339，不含)"
+// @Param after_id query int false "分頁游標，只回傳 ID 大於此值的紀錄"
+// @Param limit query int false "每頁筆數 (預設 100，上限 1000)"
+// @Success 200 {object} response.Response{data=[]model.AuditLog} "成功查詢稽核紀錄"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/audit [get]
+func (ctrl *AuditController) List(c *gin.Context) {
+	filter := repository.AuditLogFilter{
+		Action:   c.Query("action"),
+		ActorID:  c.Query("actor_id"),
+		TargetID: c.Query("target_id"),
+		Limit:    defaultAuditLimit,
+	}
+
+	if s := c.Query("since"); s != "" {
+		since, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			_ = c.Error(apperrors.Validation("INVALID_SINCE", "起始時間須為 RFC3339 格式"))
+			return
+		}
+		filter.Since = since
+	}
+	if s := c.Query("until"); s != "" {
+		until, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			_ = c.Error(apperrors.Validation("INVALID_UNTIL", "結束時間須為 RFC3339 格式"))
+			return
+		}
+		filter.Until = until
+	}
+	if s := c.Query("after_id"); s != "" {
+		afterID, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			_ = c.Error(apperrors.Validation("INVALID_AFTER_ID", "無效的分頁游標"))
+			return
+		}
+		filter.AfterID = afterID
+	}
+	if s := c.Query("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxAuditLimit {
+			_ = c.Error(apperrors.Validation("INVALID_LIMIT", "每頁筆數須介於 1 到 1000"))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	var resp []model.AuditLog
+	resp, err := ctrl.auditService.List(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// Verify 處理驗證稽核紀錄雜湊鏈的請求
+// @Summary 驗證稽核紀錄
+// @Description 從第一筆開始驗證雜湊鏈，回報第一筆遭修改、刪除或插入的紀錄；可保存回傳的 last_hash，之後比對以偵測尾端被截斷
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} response.Response{data=model.AuditVerifyResponse} "驗證完成 (valid 表示雜湊鏈是否完整)"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/audit/verify [get]
+func (ctrl *AuditController) Verify(c *gin.Context) {
+	var resp *model.AuditVerifyResponse
+	resp, err := ctrl.auditService.Verify(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
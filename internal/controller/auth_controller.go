@@ -3,9 +3,9 @@ package controller
 import (
 	"microservice-mvp/internal/model"
 	"microservice-mvp/internal/service"
+	"microservice-mvp/pkg/apperrors"
 	"microservice-mvp/pkg/logger"
 	"microservice-mvp/pkg/response"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -31,6 +31,8 @@ func NewAuthController(authService service.AuthService) *AuthController {
 // @Success 200 {object} response.Response{data=model.LoginResponse} "登入成功"
 // @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
 // @Failure 401 {object} response.HTTPError401 "認證失敗"
+// @Failure 429 {object} response.HTTPError429 "請求過於頻繁"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
 // @Router /api/v1/login [post]
 func (ctrl *AuthController) Login(c *gin.Context) {
 	log := logger.FromContext(c.Request.Context())
@@ -38,16 +40,16 @@ func (ctrl *AuthController) Login(c *gin.Context) {
 	var req model.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Warn("無效的登入請求", zap.Error(err))
-		response.Fail(c, http.StatusBadRequest, err)
+		_ = c.Error(apperrors.Validation("INVALID_LOGIN_REQUEST", "請求參數錯誤").WithCause(err))
 		return
 	}
 
 	resp, err := ctrl.authService.Login(c.Request.Context(), &req)
 	if err != nil {
-		log.Error("認證服務登入失敗", zap.Error(err))
-		response.Fail(c, http.StatusUnauthorized, err)
+		log.Warn("認證服務登入失敗", zap.Error(err))
+		_ = c.Error(err)
 		return
 	}
 
 	response.OK(c, resp)
-}
\ No newline at end of file
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/internal/service"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/response"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+// DeadLetterController 處理死信管理請求
+type DeadLetterController struct {
+	deadLetterService service.DeadLetterService
+}
+
+// NewDeadLetterController 建立一個新的 DeadLetterController
+func NewDeadLetterController(deadLetterService service.DeadLetterService) *DeadLetterController {
+	return &DeadLetterController{deadLetterService: deadLetterService}
+}
+
+// List 處理查詢死信的請求
+// @Summary 查詢死信
+// @Description 依 ID 遞增順序列出死信，可依原始 Topic 篩選，以 after_id 分頁
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param topic query string false "原始 Topic"
+// @Param after_id query int false "分頁游標，只回傳 ID 大於此值的死信"
+// @Param limit query int false "每頁筆數 (預設 50，上限 500)"
+// @Success 200 {object} response.Response{data=[]model.DeadLetterResponse} "成功查詢死信"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/dlq [get]
+func (ctrl *DeadLetterController) List(c *gin.Context) {
+	filter := repository.DeadLetterFilter{Topic: c.Query("topic"), Limit: defaultDeadLetterLimit}
+
+	if s := c.Query("after_id"); s != "" {
+		afterID, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			_ = c.Error(apperrors.Validation("INVALID_AFTER_ID", "無效的分頁游標"))
+			return
+		}
+		filter.AfterID = afterID
+	}
+	if s := c.Query("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxDeadLetterLimit {
+			_ = c.Error(apperrors.Validation("INVALID_LIMIT", "每頁筆數須介於 1 到 500"))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	var resp []model.DeadLetterResponse
+	resp, err := ctrl.deadLetterService.List(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// Get 處理取得單筆死信的請求
+// @Summary 取得死信
+// @Description 根據 ID 取得死信內容，無法以 UTF-8 呈現的內容以 base64 編碼
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "死信 ID"
+// @Success 200 {object} response.Response{data=model.DeadLetterResponse} "成功取得死信"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 404 {object} response.HTTPError404 "死信不存在"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/dlq/{id} [get]
+func (ctrl *DeadLetterController) Get(c *gin.Context) {
+	id, ok := deadLetterID(c)
+	if !ok {
+		return
+	}
+
+	var resp *model.DeadLetterResponse
+	resp, err := ctrl.deadLetterService.Get(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// Replay 處理重新投遞死信的請求
+// @Summary 重新投遞死信
+// @Description 以原本的 Topic、標籤、Keys 與屬性重新投遞死信，成功後刪除該死信
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "死信 ID"
+// @Success 200 {object} response.Response{data=model.ReplayDeadLetterResponse} "重新投遞成功"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 404 {object} response.HTTPError404 "死信不存在"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/dlq/{id}/replay [post]
+func (ctrl *DeadLetterController) Replay(c *gin.Context) {
+	id, ok := deadLetterID(c)
+	if !ok {
+		return
+	}
+
+	var resp *model.ReplayDeadLetterResponse
+	resp, err := ctrl.deadLetterService.Replay(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// Delete 處理刪除單筆死信的請求
+// @Summary 刪除死信
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "死信 ID"
+// @Success 200 {object} response.Response "刪除成功"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 404 {object} response.HTTPError404 "死信不存在"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/dlq/{id} [delete]
+func (ctrl *DeadLetterController) Delete(c *gin.Context) {
+	id, ok := deadLetterID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.deadLetterService.Delete(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// Purge 處理清除死信的請求
+// @Summary 清除死信
+// @Description 清除指定原始 Topic 的死信，未指定 Topic 則清除全部
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param topic query string false "原始 Topic"
+// @Success 200 {object} response.Response{data=model.PurgeDeadLettersResponse} "清除成功"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/dlq [delete]
+func (ctrl *DeadLetterController) Purge(c *gin.Context) {
+	var resp *model.PurgeDeadLettersResponse
+	resp, err := ctrl.deadLetterService.Purge(c.Request.Context(), c.Query("topic"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// deadLetterID 解析路徑中的死信 ID，失敗時已寫入驗證錯誤
+func deadLetterID(c *gin.Context) (uint64, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Warn("無效的死信 ID 格式", zap.Error(err), zap.String("id", idStr))
+		_ = c.Error(apperrors.Validation("INVALID_DEAD_LETTER_ID", "無效的死信 ID 格式"))
+		return 0, false
+	}
+	return id, true
+}
@@ -1,20 +1,16 @@
 package controller
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"runtime"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 
-	"microservice-mvp/pkg/configs"
-	"microservice-mvp/pkg/database"
-	"microservice-mvp/pkg/logger"
-	"microservice-mvp/pkg/redis"
-	"microservice-mvp/pkg/rocketmq"
+	"microservice-mvp/pkg/health"
 )
 
 // HealthCheckResponse 定義健康檢查 API 的回應結構
@@ -32,44 +28,134 @@ type SystemMetrics struct {
 	GoVersion  string `json:"go_version" example:"go1.23.0"`
 }
 
-// ComponentStatus 定義個別組件的狀態
+// ComponentStatus 定義個別組件的狀態 (最近一次背景檢查的結果)
 type ComponentStatus struct {
-	Status  string `json:"status" example:"UP"`
-	Latency string `json:"latency,omitempty" example:"5ms"`
-	Details string `json:"details,omitempty"` // 額外資訊 (例如: 項目數量, DB 統計)
-	Message string `json:"message,omitempty"`
+	Status    string    `json:"status" example:"UP"`
+	Critical  bool      `json:"critical"` // 關鍵組件異常時整體為 DOWN，非關鍵組件異常時為 DEGRADED
+	Latency   string    `json:"latency,omitempty" example:"5ms"`
+	Details   string    `json:"details,omitempty"` // 額外資訊 (例如: 項目數量, DB 統計)
+	Message   string    `json:"message,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
 }
 
-// HealthCheckController 處理健康檢查請求
+// ProbeResponse 定義 /livez、/readyz 與 /startupz 探針的回應結構
+type ProbeResponse struct {
+	Status   string                     `json:"status" example:"UP"`
+	Message  string                     `json:"message,omitempty"`
+	Checks   map[string]ComponentStatus `json:"checks,omitempty"`
+	Excluded []string                   `json:"excluded,omitempty"`
+}
+
+// HealthCheckController 處理健康檢查請求，依賴組件的狀態讀取自 health.Registry 的快取，不會在請求中探測依賴
+// /health 為包含系統指標與延遲的詳細報告，供人工排查；Kubernetes 探針應使用 /livez、/readyz 與 /startupz
 type HealthCheckController struct {
-	cfg       *configs.Config
-	startTime time.Time
+	registry     *health.Registry
+	startTime    time.Time
+	started      atomic.Bool // 遷移與預熱完成，開始接受流量
+	shuttingDown atomic.Bool // 收到關閉訊號，等待負載平衡移除此實例
 }
 
 // NewHealthCheckController 建立一個新的 HealthCheckController
-func NewHealthCheckController(cfg *configs.Config) *HealthCheckController {
+func NewHealthCheckController(registry *health.Registry) *HealthCheckController {
 	return &HealthCheckController{
-		cfg:       cfg,
+		registry:  registry,
 		startTime: time.Now(),
 	}
 }
 
+// MarkStarted 標記啟動 (遷移與預熱) 已完成，之後 /startupz 回傳成功，/readyz 開始回報依賴狀態
+func (ctrl *HealthCheckController) MarkStarted() {
+	ctrl.started.Store(true)
+}
+
+// MarkShuttingDown 標記服務正在關閉，之後 /readyz 一律回傳失敗，應在 srv.Shutdown 之前呼叫
+func (ctrl *HealthCheckController) MarkShuttingDown() {
+	ctrl.shuttingDown.Store(true)
+}
+
+// Live 處理 GET /livez 請求
+// @Summary 存活探針
+// @Description 行程仍在運行即回傳 200，不檢查任何依賴，避免依賴異常時 Kubernetes 重啟健康的 Pod
+// @Tags System
+// @Produce json
+// @Success 200 {object} ProbeResponse "行程存活"
+// @Router /livez [get]
+func (ctrl *HealthCheckController) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, ProbeResponse{Status: "UP"})
+}
+
+// Startup 處理 GET /startupz 請求
+// @Summary 啟動探針
+// @Description 資料庫遷移與預熱完成後回傳 200，完成前回傳 503
+// @Tags System
+// @Produce json
+// @Success 200 {object} ProbeResponse "啟動完成"
+// @Failure 503 {object} ProbeResponse "啟動中"
+// @Router /startupz [get]
+func (ctrl *HealthCheckController) Startup(c *gin.Context) {
+	if !ctrl.started.Load() {
+		c.JSON(http.StatusServiceUnavailable, ProbeResponse{Status: "DOWN", Message: "服務啟動中"})
+		return
+	}
+	c.JSON(http.StatusOK, ProbeResponse{Status: "UP"})
+}
+
+// Ready 處理 GET /readyz 請求
+// @Summary 就緒探針
+// @Description 依最近一次背景檢查的結果，任一關鍵組件 DOWN 時回傳 503；延遲偏高與非關鍵組件異常不視為失敗。啟動完成前與關閉期間一律回傳 503
+// @Tags System
+// @Produce json
+// @Param exclude query []string false "略過的檢查項目 (例如 tidb、redis、messaging)，可重複或以逗號分隔" collectionFormat(multi)
+// @Success 200 {object} ProbeResponse "可接受流量"
+// @Failure 503 {object} ProbeResponse "未就緒"
+// @Router /readyz [get]
+func (ctrl *HealthCheckController) Ready(c *gin.Context) {
+	switch {
+	case ctrl.shuttingDown.Load():
+		c.JSON(http.StatusServiceUnavailable, ProbeResponse{Status: "DOWN", Message: "服務正在關閉"})
+		return
+	case !ctrl.started.Load():
+		c.JSON(http.StatusServiceUnavailable, ProbeResponse{Status: "DOWN", Message: "服務啟動中"})
+		return
+	}
+
+	excluded := make(map[string]bool)
+	for _, v := range c.QueryArray("exclude") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				excluded[name] = true
+			}
+		}
+	}
+
+	resp := ProbeResponse{Status: "UP", Checks: make(map[string]ComponentStatus)}
+	for _, check := range ctrl.registry.Report().Checks {
+		if excluded[check.Name] {
+			resp.Excluded = append(resp.Excluded, check.Name)
+			continue
+		}
+		resp.Checks[check.Name] = componentStatus(check)
+		if check.Critical && check.Status != health.StatusUp && check.Status != health.StatusDegraded {
+			resp.Status = "DOWN"
+		}
+	}
+
+	httpStatus := http.StatusOK
+	if resp.Status != "UP" {
+		httpStatus = http.StatusServiceUnavailable
+	}
+	c.JSON(httpStatus, resp)
+}
+
 // Check 處理 GET /health 請求
 // @Summary 健康檢查
-// @Description 檢查服務運行狀態、系統指標及依賴組件健康狀況
+// @Description 回報服務運行狀態、系統指標及依賴組件最近一次背景檢查的結果；關鍵組件 DOWN 時為 DOWN (503)，延遲偏高或非關鍵組件異常時為 DEGRADED (200)
 // @Tags System
 // @Produce json
-// @Success 200 {object} HealthCheckResponse "服務正常"
-// @Failure 503 {object} HealthCheckResponse "服務降級或異常"
+// @Success 200 {object} HealthCheckResponse "服務正常或降級"
+// @Failure 503 {object} HealthCheckResponse "服務異常"
 // @Router /health [get]
 func (ctrl *HealthCheckController) Check(c *gin.Context) {
-	ctx := c.Request.Context()
-	log := logger.FromContext(ctx)
-
-	overallStatus := "UP"
-	components := make(map[string]ComponentStatus)
-
-	// 1. 系統指標 (始終相關)
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 	systemMetrics := SystemMetrics{
@@ -78,99 +164,35 @@ func (ctrl *HealthCheckController) Check(c *gin.Context) {
 		GoVersion:  runtime.Version(),
 	}
 
-	// 2. 持久化檢查 (基於配置)
-	if ctrl.cfg.Persistence.Type == "memory" {
-		// Memory 模式檢查
-		components["memory_store"] = ComponentStatus{
-			Status:  "UP",
-			Details: "In-Memory 持久化已啟用",
-		}
-	} else {
-		// MySQL 模式檢查
-		dbStatus := ctrl.checkTiDB(ctx, log)
-		components["tidb"] = dbStatus
-		if dbStatus.Status != "UP" {
-			overallStatus = "DEGRADED"
-		}
-
-		// Redis 檢查
-		redisStatus := ctrl.checkRedis(ctx, log)
-		components["redis"] = redisStatus
-		if redisStatus.Status != "UP" {
-			overallStatus = "DEGRADED"
-		}
-	}
-
-	// 3. RocketMQ 檢查 (僅當實際初始化時)
-	if rocketmq.ProducerClient != nil && rocketmq.ProducerClient.Started() {
-		mqStatus := ComponentStatus{Status: "UP"}
-		components["rocketmq"] = mqStatus
+	report := ctrl.registry.Report()
+	components := make(map[string]ComponentStatus, len(report.Checks))
+	for _, check := range report.Checks {
+		components[check.Name] = componentStatus(check)
 	}
 
 	httpStatus := http.StatusOK
-	if overallStatus != "UP" {
+	if report.Status == health.StatusDown {
 		httpStatus = http.StatusServiceUnavailable
 	}
 
 	c.JSON(httpStatus, HealthCheckResponse{
-		Status:     overallStatus,
+		Status:     string(report.Status),
 		Uptime:     time.Since(ctrl.startTime).String(),
 		System:     systemMetrics,
 		Components: components,
 	})
 }
 
-func (ctrl *HealthCheckController) checkTiDB(ctx context.Context, log *zap.Logger) ComponentStatus {
-	start := time.Now()
-	gormDB := database.GetDB()
-	if gormDB == nil {
-		return ComponentStatus{Status: "DOWN", Message: "資料庫客戶端未初始化"}
-	}
-
-	sqlDB, err := gormDB.DB()
-	if err != nil {
-		log.Error("無法獲取 TiDB 底層 DB 連線以進行健康檢查", zap.Error(err))
-		return ComponentStatus{Status: "DOWN", Message: "無法獲取 DB 連線池"}
-	}
-	err = sqlDB.PingContext(ctx)
-	latency := time.Since(start)
-	status := "UP"
-	message := ""
-
-	if err != nil {
-		status = "DOWN"
-		message = fmt.Sprintf("Ping 失敗: %v", err)
-		log.Error("TiDB 健康檢查失敗", zap.Error(err))
-	} else if latency > time.Duration(ctrl.cfg.HealthCheck.LatencyThreshold)*time.Millisecond {
-		status = "DEGRADED"
-		message = fmt.Sprintf("高延遲: %s > %dms", latency, ctrl.cfg.HealthCheck.LatencyThreshold)
-		log.Warn("TiDB 健康檢查偵測到高延遲", zap.Duration("latency", latency), zap.Int("threshold", ctrl.cfg.HealthCheck.LatencyThreshold))
-	}
-
-	return ComponentStatus{Status: status, Latency: latency.String(), Message: message}
-}
-
-func (ctrl *HealthCheckController) checkRedis(ctx context.Context, log *zap.Logger) ComponentStatus {
-	start := time.Now()
-	rdb := redis.GetClient()
-	if rdb == nil {
-		return ComponentStatus{Status: "DOWN", Message: "Redis 客戶端未初始化"}
+func componentStatus(r health.CheckResult) ComponentStatus {
+	status := ComponentStatus{
+		Status:    string(r.Status),
+		Critical:  r.Critical,
+		Details:   r.Details,
+		Message:   r.Message,
+		CheckedAt: r.CheckedAt,
 	}
-
-	err := rdb.Ping(ctx).Err()
-	latency := time.Since(start)
-	status := "UP"
-	message := ""
-
-	if err != nil {
-		status = "DOWN"
-		message = fmt.Sprintf("Ping 失敗: %v", err)
-		log.Error("Redis 健康檢查失敗", zap.Error(err))
-	} else if latency > time.Duration(ctrl.cfg.HealthCheck.LatencyThreshold)*time.Millisecond {
-		status = "DEGRADED"
-		message = fmt.Sprintf("高延遲: %s > %dms", latency, ctrl.cfg.HealthCheck.LatencyThreshold)
-		log.Warn("Redis 健康檢查偵測到高延遲", zap.Duration("latency", latency), zap.Int("threshold", ctrl.cfg.HealthCheck.LatencyThreshold))
+	if !r.CheckedAt.IsZero() {
+		status.Latency = r.Latency.String()
 	}
-
-	return ComponentStatus{Status: status, Latency: latency.String(), Message: message}
+	return status
 }
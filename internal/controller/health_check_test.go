@@ -0,0 +1,124 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/controller"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/health"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestHealthCheckController_Probes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, err := logger.NewLogger("info", "console")
+	require.NoError(t, err)
+
+	var mqUp atomic.Bool
+	registry := health.NewRegistry()
+	registry.Register(health.PingChecker("messaging", func(ctx context.Context) error {
+		if !mqUp.Load() {
+			return assert.AnError
+		}
+		return nil
+	}), health.Options{Critical: true, Interval: 10 * time.Millisecond})
+	registry.Register(health.NewChecker("redis", func(ctx context.Context) health.Result {
+		return health.Result{Status: health.StatusDown}
+	}), health.Options{Critical: false})
+
+	ctrl := controller.NewHealthCheckController(registry)
+	router := gin.New()
+	router.GET("/health", ctrl.Check)
+	router.GET("/livez", ctrl.Live)
+	router.GET("/readyz", ctrl.Ready)
+	router.GET("/startupz", ctrl.Startup)
+
+	probe := func(path string) (int, controller.ProbeResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var resp controller.ProbeResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return w.Code, resp
+	}
+
+	steps := []struct {
+		name   string
+		setup  func()
+		path   string
+		status int
+		check  func(t *testing.T, resp controller.ProbeResponse)
+	}{
+		{name: "啟動前存活", path: "/livez", status: http.StatusOK},
+		{name: "啟動前未完成啟動", path: "/startupz", status: http.StatusServiceUnavailable},
+		{name: "啟動前未就緒", path: "/readyz", status: http.StatusServiceUnavailable},
+		{
+			name: "啟動完成",
+			setup: func() {
+				registry.Start(configs.HealthCheckConfig{IntervalSeconds: 60})
+				ctrl.MarkStarted()
+			},
+			path: "/startupz", status: http.StatusOK,
+		},
+		{
+			name: "關鍵組件異常", path: "/readyz", status: http.StatusServiceUnavailable,
+			check: func(t *testing.T, resp controller.ProbeResponse) {
+				assert.Equal(t, "DOWN", resp.Checks["messaging"].Status)
+				assert.True(t, resp.Checks["messaging"].Critical)
+			},
+		},
+		{
+			name: "略過失敗的檢查，非關鍵組件異常不影響就緒", path: "/readyz?exclude=messaging", status: http.StatusOK,
+			check: func(t *testing.T, resp controller.ProbeResponse) {
+				assert.Equal(t, []string{"messaging"}, resp.Excluded)
+				assert.Equal(t, "DOWN", resp.Checks["redis"].Status)
+			},
+		},
+		{
+			name: "背景檢查更新後就緒",
+			setup: func() {
+				mqUp.Store(true)
+				require.Eventually(t, func() bool {
+					code, _ := probe("/readyz")
+					return code == http.StatusOK
+				}, time.Second, 10*time.Millisecond)
+			},
+			path: "/readyz", status: http.StatusOK,
+		},
+		{
+			name: "非關鍵組件異常時整體降級", path: "/health", status: http.StatusOK,
+			check: func(t *testing.T, resp controller.ProbeResponse) {
+				assert.Equal(t, "DEGRADED", resp.Status)
+			},
+		},
+		{
+			name: "關閉期間不就緒", setup: ctrl.MarkShuttingDown, path: "/readyz", status: http.StatusServiceUnavailable,
+			check: func(t *testing.T, resp controller.ProbeResponse) {
+				assert.Equal(t, "服務正在關閉", resp.Message)
+			},
+		},
+		{name: "關閉期間仍存活", path: "/livez", status: http.StatusOK},
+	}
+	t.Cleanup(registry.Stop)
+
+	// 各步驟依序改變狀態，不使用 t.Run 以免單獨執行時狀態不完整
+	for _, step := range steps {
+		if step.setup != nil {
+			step.setup()
+		}
+		status, resp := probe(step.path)
+		assert.Equal(t, step.status, status, step.name)
+		if step.check != nil {
+			step.check(t, resp)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/response"
+)
+
+// LogLevelController 處理執行期日誌級別的查詢與調整
+type LogLevelController struct{}
+
+// NewLogLevelController 建立一個新的 LogLevelController
+func NewLogLevelController() *LogLevelController {
+	return &LogLevelController{}
+}
+
+// Get 處理查詢日誌級別的請求
+// @Summary 查詢日誌級別
+// @Description 回傳目前的全域日誌級別與各具名 logger (例如 gorm、consumer、outbox) 的級別覆寫
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} response.Response{data=model.LogLevelResponse} "成功查詢日誌級別"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Router /admin/log-level [get]
+func (ctrl *LogLevelController) Get(c *gin.Context) {
+	response.OK(c, currentLogLevels())
+}
+
+// Update 處理調整日誌級別的請求
+// @Summary 調整日誌級別
+// @Description 不需重啟即可調整日誌級別。未指定 logger 時調整全域級別；指定 logger 時只調整該 logger 及其子 logger，level 為空則移除其覆寫
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body model.LogLevelRequest true "日誌級別"
+// @Success 200 {object} response.Response{data=model.LogLevelResponse} "成功調整日誌級別"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Router /admin/log-level [put]
+func (ctrl *LogLevelController) Update(c *gin.Context) {
+	var req model.LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperrors.Validation("INVALID_LOG_LEVEL_REQUEST", "請求參數錯誤").WithCause(err))
+		return
+	}
+
+	if req.Logger != "" && req.Level == "" {
+		logger.ResetLevel(req.Logger)
+	} else if err := logger.SetLevel(req.Logger, req.Level); err != nil {
+		_ = c.Error(apperrors.Validation("INVALID_LOG_LEVEL", "無效的日誌級別，可用值：debug, info, warn, error, dpanic, panic, fatal").WithCause(err))
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).Info("日誌級別已調整",
+		zap.String("logger", req.Logger),
+		zap.String("level", req.Level),
+	)
+	response.OK(c, currentLogLevels())
+}
+
+func currentLogLevels() model.LogLevelResponse {
+	level, loggers := logger.Levels()
+	return model.LogLevelResponse{Level: level, Loggers: loggers}
+}
@@ -3,9 +3,9 @@ package controller
 import (
 	"microservice-mvp/internal/model"
 	"microservice-mvp/internal/service"
+	"microservice-mvp/pkg/apperrors"
 	"microservice-mvp/pkg/logger"
 	"microservice-mvp/pkg/response"
-	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -31,6 +31,7 @@ func NewPlayerController(playerService service.PlayerService) *PlayerController
 // @Success 200 {object} response.Response{data=model.PlayerInfoResponse} "成功取得玩家資料"
 // @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
 // @Failure 404 {object} response.HTTPError404 "玩家不存在"
+// @Failure 429 {object} response.HTTPError429 "請求過於頻繁"
 // @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
 // @Router /api/v1/players/{id} [get]
 func (ctrl *PlayerController) GetPlayerInfo(c *gin.Context) {
@@ -40,21 +41,17 @@ func (ctrl *PlayerController) GetPlayerInfo(c *gin.Context) {
 	playerID, err := strconv.ParseUint(playerIDStr, 10, 32)
 	if err != nil {
 		log.Warn("無效的玩家 ID 格式", zap.Error(err), zap.String("playerIDStr", playerIDStr))
-		response.FailWithMessage(c, http.StatusBadRequest, "無效的玩家 ID 格式")
+		_ = c.Error(apperrors.Validation("INVALID_PLAYER_ID", "無效的玩家 ID 格式"))
 		return
 	}
 
 	var resp *model.PlayerInfoResponse
 	resp, err = ctrl.playerService.GetPlayerInfo(c.Request.Context(), uint(playerID))
 	if err != nil {
-		log.Error("玩家服務取得資訊失敗", zap.Error(err), zap.Uint("playerID", uint(playerID)))
-		if err.Error() == "玩家不存在" { // 注意: 這裡依賴 Error string，如果 service 層錯誤訊息也翻譯了，這裡要對應修改
-			response.FailWithMessage(c, http.StatusNotFound, err.Error())
-		} else {
-			response.FailWithMessage(c, http.StatusInternalServerError, "取得玩家資訊失敗")
-		}
+		log.Warn("玩家服務取得資訊失敗", zap.Error(err), zap.Uint("playerID", uint(playerID)))
+		_ = c.Error(err) // 由 middleware.ErrorHandler 依錯誤分類對應 404 或 500
 		return
 	}
 
 	response.OK(c, resp)
-}
\ No newline at end of file
+}
@@ -0,0 +1,295 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/internal/service"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/response"
+)
+
+const (
+	defaultWebhookDeliveryLimit = 50
+	maxWebhookDeliveryLimit     = 500
+)
+
+// WebhookController 處理 webhook 端點與投遞紀錄的管理請求
+type WebhookController struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookController 建立一個新的 WebhookController
+func NewWebhookController(webhookService service.WebhookService) *WebhookController {
+	return &WebhookController{webhookService: webhookService}
+}
+
+// CreateEndpoint 處理註冊 webhook 端點的請求
+// @Summary 註冊 webhook 端點
+// @Description 註冊接收領域事件的端點。事件類型可為完整類型、"*" 或前綴 (例如 "wallet.*")；
+// @Description 未提供簽章金鑰時由伺服器產生，金鑰只會在此回應中出現一次
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param request body model.CreateWebhookEndpointRequest true "端點設定"
+// @Success 200 {object} response.Response{data=model.CreateWebhookEndpointResponse} "註冊成功"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/webhooks [post]
+func (ctrl *WebhookController) CreateEndpoint(c *gin.Context) {
+	var req model.CreateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Warn("無效的 webhook 端點請求", zap.Error(err))
+		_ = c.Error(apperrors.Validation("INVALID_WEBHOOK_REQUEST", "請求參數錯誤").WithCause(err))
+		return
+	}
+
+	var resp *model.CreateWebhookEndpointResponse
+	resp, err := ctrl.webhookService.CreateEndpoint(c.Request.Context(), &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// ListEndpoints 處理列出 webhook 端點的請求
+// @Summary 列出 webhook 端點
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} response.Response{data=[]model.WebhookEndpointResponse} "成功列出端點"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/webhooks [get]
+func (ctrl *WebhookController) ListEndpoints(c *gin.Context) {
+	var resp []model.WebhookEndpointResponse
+	resp, err := ctrl.webhookService.ListEndpoints(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// GetEndpoint 處理取得 webhook 端點的請求
+// @Summary 取得 webhook 端點
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "端點 ID"
+// @Success 200 {object} response.Response{data=model.WebhookEndpointResponse} "成功取得端點"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 404 {object} response.HTTPError404 "端點不存在"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/webhooks/{id} [get]
+func (ctrl *WebhookController) GetEndpoint(c *gin.Context) {
+	id, ok := pathID(c, "INVALID_WEBHOOK_ID", "無效的 webhook 端點 ID 格式")
+	if !ok {
+		return
+	}
+
+	var resp *model.WebhookEndpointResponse
+	resp, err := ctrl.webhookService.GetEndpoint(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// UpdateEndpoint 處理更新 webhook 端點的請求
+// @Summary 更新 webhook 端點
+// @Description 只更新有提供的欄位；將 enabled 設為 true 可重新啟用被自動停用的端點，並將連續失敗次數歸零
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "端點 ID"
+// @Param request body model.UpdateWebhookEndpointRequest true "要更新的欄位"
+// @Success 200 {object} response.Response{data=model.WebhookEndpointResponse} "更新成功"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 404 {object} response.HTTPError404 "端點不存在"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/webhooks/{id} [patch]
+func (ctrl *WebhookController) UpdateEndpoint(c *gin.Context) {
+	id, ok := pathID(c, "INVALID_WEBHOOK_ID", "無效的 webhook 端點 ID 格式")
+	if !ok {
+		return
+	}
+	var req model.UpdateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.FromContext(c.Request.Context()).Warn("無效的 webhook 端點請求", zap.Error(err))
+		_ = c.Error(apperrors.Validation("INVALID_WEBHOOK_REQUEST", "請求參數錯誤").WithCause(err))
+		return
+	}
+
+	var resp *model.WebhookEndpointResponse
+	resp, err := ctrl.webhookService.UpdateEndpoint(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// DeleteEndpoint 處理刪除 webhook 端點的請求
+// @Summary 刪除 webhook 端點
+// @Description 刪除端點及其所有投遞紀錄
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "端點 ID"
+// @Success 200 {object} response.Response "刪除成功"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 404 {object} response.HTTPError404 "端點不存在"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/webhooks/{id} [delete]
+func (ctrl *WebhookController) DeleteEndpoint(c *gin.Context) {
+	id, ok := pathID(c, "INVALID_WEBHOOK_ID", "無效的 webhook 端點 ID 格式")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.webhookService.DeleteEndpoint(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, nil)
+}
+
+// ListDeliveries 處理查詢投遞紀錄的請求
+// @Summary 查詢 webhook 投遞紀錄
+// @Description 依 ID 遞增順序列出投遞紀錄，可依端點與狀態篩選，以 after_id 分頁
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param endpoint_id query int false "端點 ID"
+// @Param status query string false "狀態" Enums(pending, succeeded, failed)
+// @Param after_id query int false "分頁游標，只回傳 ID 大於此值的紀錄"
+// @Param limit query int false "每頁筆數 (預設 50，上限 500)"
+// @Success 200 {object} response.Response{data=[]model.WebhookDelivery} "成功查詢投遞紀錄"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/webhooks/deliveries [get]
+func (ctrl *WebhookController) ListDeliveries(c *gin.Context) {
+	filter := repository.WebhookDeliveryFilter{Status: c.Query("status"), Limit: defaultWebhookDeliveryLimit}
+
+	switch filter.Status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliverySucceeded, model.WebhookDeliveryFailed:
+	default:
+		_ = c.Error(apperrors.Validation("INVALID_STATUS", "無效的投遞狀態"))
+		return
+	}
+	if s := c.Query("endpoint_id"); s != "" {
+		endpointID, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			_ = c.Error(apperrors.Validation("INVALID_WEBHOOK_ID", "無效的 webhook 端點 ID 格式"))
+			return
+		}
+		filter.EndpointID = endpointID
+	}
+	if s := c.Query("after_id"); s != "" {
+		afterID, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			_ = c.Error(apperrors.Validation("INVALID_AFTER_ID", "無效的分頁游標"))
+			return
+		}
+		filter.AfterID = afterID
+	}
+	if s := c.Query("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxWebhookDeliveryLimit {
+			_ = c.Error(apperrors.Validation("INVALID_LIMIT", "每頁筆數須介於 1 到 500"))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	var resp []model.WebhookDelivery
+	resp, err := ctrl.webhookService.ListDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// GetDelivery 處理取得單筆投遞紀錄的請求
+// @Summary 取得 webhook 投遞紀錄
+// @Description 取得投遞紀錄與投遞的事件內容
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "投遞紀錄 ID"
+// @Success 200 {object} response.Response{data=model.WebhookDeliveryResponse} "成功取得投遞紀錄"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 404 {object} response.HTTPError404 "投遞紀錄不存在"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/webhooks/deliveries/{id} [get]
+func (ctrl *WebhookController) GetDelivery(c *gin.Context) {
+	id, ok := pathID(c, "INVALID_WEBHOOK_DELIVERY_ID", "無效的投遞紀錄 ID 格式")
+	if !ok {
+		return
+	}
+
+	var resp *model.WebhookDeliveryResponse
+	resp, err := ctrl.webhookService.GetDelivery(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// RetryDelivery 處理重新投遞的請求
+// @Summary 重新投遞 webhook
+// @Description 將投遞紀錄排入立即重新投遞並重新開始重試排程；端點停用期間不會投遞
+// @Tags Admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "投遞紀錄 ID"
+// @Success 200 {object} response.Response{data=model.WebhookDelivery} "已排入重新投遞"
+// @Failure 400 {object} response.HTTPError400 "請求參數錯誤"
+// @Failure 401 {object} response.HTTPError401 "管理權杖無效"
+// @Failure 404 {object} response.HTTPError404 "投遞紀錄不存在"
+// @Failure 500 {object} response.HTTPError500 "內部伺服器錯誤"
+// @Router /admin/webhooks/deliveries/{id}/retry [post]
+func (ctrl *WebhookController) RetryDelivery(c *gin.Context) {
+	id, ok := pathID(c, "INVALID_WEBHOOK_DELIVERY_ID", "無效的投遞紀錄 ID 格式")
+	if !ok {
+		return
+	}
+
+	var resp *model.WebhookDelivery
+	resp, err := ctrl.webhookService.RetryDelivery(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	response.OK(c, resp)
+}
+
+// pathID 解析路徑中的 ID，失敗時已寫入驗證錯誤
+func pathID(c *gin.Context, code, message string) (uint64, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Warn(message, zap.Error(err), zap.String("id", idStr))
+		_ = c.Error(apperrors.Validation(code, message))
+		return 0, false
+	}
+	return id, true
+}
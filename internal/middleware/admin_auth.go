@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/audit"
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/logger"
+)
+
+// HeaderXAdminToken 是管理 API 權杖的替代標頭，未使用 Authorization: Bearer 時可改用此標頭
+const HeaderXAdminToken = "X-Admin-Token"
+
+// ErrInvalidAdminToken 代表管理 API 權杖缺失或錯誤
+var ErrInvalidAdminToken = apperrors.Unauthorized("INVALID_ADMIN_TOKEN", "管理權杖無效")
+
+// AdminAuth 驗證管理 API 的存取權杖，以固定時間比較避免時序攻擊
+func AdminAuth(token string) gin.HandlerFunc {
+	expected := []byte(token)
+	return func(c *gin.Context) {
+		got := c.GetHeader(HeaderXAdminToken)
+		if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			got = strings.TrimPrefix(auth, "Bearer ")
+		}
+
+		if len(expected) == 0 || subtle.ConstantTimeCompare([]byte(got), expected) != 1 {
+			logger.FromContext(c.Request.Context()).Warn("管理 API 權杖無效",
+				zap.String("path", c.Request.URL.Path), zap.String("ip", c.ClientIP()))
+			audit.Record(c.Request.Context(), audit.Entry{
+				Action: model.AuditActionAdminAuthFail,
+				Result: model.AuditResultFailure,
+				Target: audit.Target{Type: "route", ID: c.Request.Method + " " + c.Request.URL.Path},
+			})
+			_ = c.Error(ErrInvalidAdminToken)
+			c.Abort()
+			return
+		}
+		// 管理權杖目前只有一組，之後的稽核紀錄皆歸屬於 admin
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{Type: model.AuditActorAdmin, ID: "admin"}))
+		c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice-mvp/internal/audit"
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/logger"
+)
+
+// maxAuditedBodyBytes 是管理請求 body 寫入稽核紀錄的大小上限，超過時只記錄大小
+const maxAuditedBodyBytes = 64 << 10
+
+// AuditContext 將用戶端 IP 與 User-Agent 放入請求上下文，供稽核紀錄使用
+func AuditContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := audit.WithClient(c.Request.Context(), audit.Client{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// AuditAdmin 為每個異動的管理請求 (GET、HEAD、OPTIONS 以外) 寫入一筆稽核紀錄
+// 需註冊在 AdminAuth 之後；請求 body 經 logger.redact 遮蔽後記錄於 after
+func AuditAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			head, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditedBodyBytes+1))
+			if err == nil {
+				c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), c.Request.Body), Closer: c.Request.Body}
+				body = head
+			}
+		}
+
+		c.Next()
+
+		// 錯誤回應由外層的 ErrorHandler 於稍後寫出，此時依錯誤分類推算狀態碼
+		status := c.Writer.Status()
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			status = apperrors.HTTPStatus(apperrors.From(c.Errors.Last().Err).Kind)
+		}
+		after := map[string]any{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"status": status,
+		}
+		if len(body) > maxAuditedBodyBytes {
+			after["request_bytes"] = len(body)
+		} else if len(body) > 0 {
+			// JSON body 以原始結構保存，方便查詢時閱讀
+			redacted := logger.RedactPayload(body)
+			if json.Valid([]byte(redacted)) {
+				after["request"] = json.RawMessage(redacted)
+			} else {
+				after["request"] = redacted
+			}
+		}
+
+		entry := audit.Entry{
+			Action: model.AuditActionAdmin,
+			Target: audit.Target{Type: c.FullPath(), ID: c.Param("id")},
+			After:  after,
+		}
+		if status >= http.StatusBadRequest {
+			entry.Result = model.AuditResultFailure
+			entry.Reason = c.Errors.ByType(gin.ErrorTypePrivate).String()
+		}
+		audit.Record(c.Request.Context(), entry)
+	}
+}
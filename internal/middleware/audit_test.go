@@ -0,0 +1,94 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/audit"
+	"microservice-mvp/internal/middleware"
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestAuditAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, err := logger.New(configs.LoggerConfig{Stdout: true, Redact: configs.RedactConfig{Enabled: true}})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = logger.NewLogger("info", "console") })
+
+	repo, err := repository.OpenAuditLogJSONL(filepath.Join(t.TempDir(), "audit.jsonl"))
+	require.NoError(t, err)
+	defer repo.Close()
+	audit.SetDefault(audit.NewRecorder(repo))
+	t.Cleanup(func() { audit.SetDefault(nil) })
+
+	router := gin.New()
+	router.Use(middleware.AuditContext(), middleware.ErrorHandler())
+	admin := router.Group("/admin", middleware.AdminAuth("s3cret"), middleware.AuditAdmin())
+	admin.GET("/things", func(c *gin.Context) { c.Status(http.StatusOK) })
+	admin.PATCH("/things/:id", func(c *gin.Context) {
+		if c.Param("id") == "404" {
+			_ = c.Error(apperrors.NotFound("THING_NOT_FOUND", "不存在"))
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantAction string
+		wantResult string
+		wantTarget string
+	}{
+		{name: "查詢不記錄", method: http.MethodGet, path: "/admin/things", token: "s3cret"},
+		{name: "異動成功", method: http.MethodPatch, path: "/admin/things/7", token: "s3cret",
+			wantAction: model.AuditActionAdmin, wantResult: model.AuditResultSuccess, wantTarget: "7"},
+		{name: "異動失敗以錯誤分類判斷結果", method: http.MethodPatch, path: "/admin/things/404", token: "s3cret",
+			wantAction: model.AuditActionAdmin, wantResult: model.AuditResultFailure, wantTarget: "404"},
+		{name: "權杖錯誤", method: http.MethodGet, path: "/admin/things", token: "wrong",
+			wantAction: model.AuditActionAdminAuthFail, wantResult: model.AuditResultFailure, wantTarget: "GET /admin/things"},
+	}
+
+	var lastID uint64
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"enabled":false,"secret":"whsec_x"}`))
+			req.Header.Set(middleware.HeaderXAdminToken, tt.token)
+			req.Header.Set("User-Agent", "admin-cli/1.0")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			logs, err := repo.List(context.Background(), repository.AuditLogFilter{AfterID: lastID})
+			require.NoError(t, err)
+			if tt.wantAction == "" {
+				assert.Empty(t, logs)
+				return
+			}
+			require.Len(t, logs, 1)
+			got := logs[0]
+			lastID = got.ID
+			assert.Equal(t, tt.wantAction, got.Action)
+			assert.Equal(t, tt.wantResult, got.Result)
+			assert.Equal(t, tt.wantTarget, got.TargetID)
+			assert.Equal(t, "admin-cli/1.0", got.UserAgent)
+			if tt.wantAction == model.AuditActionAdmin {
+				assert.Equal(t, model.AuditActorAdmin, got.ActorType)
+				assert.Contains(t, got.After, `"request":{"enabled":false,"secret":"***"}`)
+				assert.NotContains(t, got.After, "whsec_x")
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+const (
+	// capturedRequestBodyKey 與 capturedResponseBodyKey 是 BodyCapture 將遮蔽後的 body 存入 gin.Context 的鍵，由 LoggerMiddleware 寫入請求日誌
+	capturedRequestBodyKey  = "middleware.requestBody"
+	capturedResponseBodyKey = "middleware.responseBody"
+
+	defaultCaptureMaxBytes = 4096
+)
+
+// defaultCaptureContentTypes 是未設定 content_types 時擷取的 Content-Type
+var defaultCaptureContentTypes = []string{"application/json", "application/x-www-form-urlencoded"}
+
+// BodyCapture 是除錯用的 Gin 中間件，擷取請求與回應的 body，經 logger 的遮蔽規則處理後交由 LoggerMiddleware 記錄
+// 需註冊在 LoggerMiddleware 之後；超過 max_bytes 的 body 只記錄大小，避免截斷的 JSON 無法遮蔽而洩漏敏感資料
+func BodyCapture(cfg configs.BodyCaptureConfig) gin.HandlerFunc {
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultCaptureMaxBytes
+	}
+	contentTypes := cfg.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCaptureContentTypes
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Body != nil && matchContentType(c.ContentType(), contentTypes) {
+			head, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(maxBytes)+1))
+			if err == nil {
+				// 將已讀取的部分接回原 body，handler 仍可讀到完整內容
+				c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), c.Request.Body), Closer: c.Request.Body}
+				c.Set(capturedRequestBodyKey, redactBody(c.ContentType(), head, maxBytes))
+			}
+		}
+
+		w := &captureWriter{ResponseWriter: c.Writer, limit: maxBytes + 1}
+		c.Writer = w
+
+		c.Next()
+
+		if w.buf.Len() > 0 {
+			contentType := strings.TrimSpace(strings.Split(w.Header().Get("Content-Type"), ";")[0])
+			if matchContentType(contentType, contentTypes) {
+				c.Set(capturedResponseBodyKey, redactBody(contentType, w.buf.Bytes(), maxBytes))
+			}
+		}
+	}
+}
+
+// redactBody 依 Content-Type 遮蔽 body，表單以查詢字串規則處理，其他內容以 JSON/信用卡號規則處理
+func redactBody(contentType string, body []byte, maxBytes int) string {
+	if len(body) > maxBytes {
+		return fmt.Sprintf("<超過 %d 位元組，未記錄>", maxBytes)
+	}
+	if contentType == "application/x-www-form-urlencoded" {
+		return logger.RedactQuery(string(body))
+	}
+	return logger.RedactPayload(body)
+}
+
+func matchContentType(contentType string, prefixes []string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, p := range prefixes {
+		if strings.HasPrefix(contentType, strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// captureWriter 在寫出回應的同時保留前 limit 個位元組
+type captureWriter struct {
+	gin.ResponseWriter
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *captureWriter) capture(b []byte) {
+	if remaining := w.limit - w.buf.Len(); remaining > 0 {
+		if len(b) > remaining {
+			b = b[:remaining]
+		}
+		w.buf.Write(b)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/response"
+)
+
+// ErrorHandler 是集中處理錯誤回應的 Gin 中間件
+// Controller 只需呼叫 c.Error(err) 並 return，由此中間件依 apperrors 的分類決定 HTTP 狀態碼與錯誤碼
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperrors.From(c.Errors.Last().Err)
+		status := apperrors.HTTPStatus(appErr.Kind)
+		code := appErr.Code
+		if code == "" {
+			code = appErr.Kind.DefaultCode()
+		}
+
+		if appErr.Kind == apperrors.KindRateLimited && appErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
+
+		// 只回傳可給客戶端看的 Message；底層原因 (例如 binding 的驗證細節或資料庫錯誤) 由 LoggerMiddleware 記錄在日誌中
+		response.FailWithCode(c, status, code, appErr.Message)
+	}
+}
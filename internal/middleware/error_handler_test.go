@@ -0,0 +1,104 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/middleware"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/response"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name              string
+		err               error
+		expectedStatus    int
+		expectedErrorCode string
+		expectedMessage   string
+		expectedRetry     string
+	}{
+		{
+			name:              "NotFound",
+			err:               apperrors.NotFound("PLAYER_NOT_FOUND", "玩家不存在"),
+			expectedStatus:    http.StatusNotFound,
+			expectedErrorCode: "PLAYER_NOT_FOUND",
+			expectedMessage:   "玩家不存在",
+		},
+		{
+			name:              "WrappedConflict",
+			err:               fmt.Errorf("建立玩家失敗: %w", apperrors.Conflict("USERNAME_TAKEN", "使用者名稱已存在")),
+			expectedStatus:    http.StatusConflict,
+			expectedErrorCode: "USERNAME_TAKEN",
+			expectedMessage:   "使用者名稱已存在",
+		},
+		{
+			name:              "Validation",
+			err:               apperrors.Validation("", "請求參數錯誤"),
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apperrors.CodeValidation,
+			expectedMessage:   "請求參數錯誤",
+		},
+		{
+			name:              "ValidationHidesCause",
+			err:               apperrors.Validation("INVALID_LOGIN_REQUEST", "請求參數錯誤").WithCause(errors.New("Key: 'LoginRequest.Username' Error:Field validation for 'Username' failed on the 'required' tag")),
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: "INVALID_LOGIN_REQUEST",
+			expectedMessage:   "請求參數錯誤",
+		},
+		{
+			name:              "RateLimited",
+			err:               apperrors.RateLimited("TOO_MANY_LOGINS", "請求過於頻繁", 1500*time.Millisecond),
+			expectedStatus:    http.StatusTooManyRequests,
+			expectedErrorCode: "TOO_MANY_LOGINS",
+			expectedMessage:   "請求過於頻繁",
+			expectedRetry:     "2",
+		},
+		{
+			name:              "InternalHidesCause",
+			err:               apperrors.Internal("PLAYER_LOOKUP_FAILED", "檢索玩家資訊失敗", errors.New("dial tcp: connection refused")),
+			expectedStatus:    http.StatusInternalServerError,
+			expectedErrorCode: "PLAYER_LOOKUP_FAILED",
+			expectedMessage:   "檢索玩家資訊失敗",
+		},
+		{
+			name:              "PlainError",
+			err:               errors.New("boom"),
+			expectedStatus:    http.StatusInternalServerError,
+			expectedErrorCode: apperrors.CodeInternal,
+			expectedMessage:   "內部伺服器錯誤",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(middleware.ErrorHandler())
+			router.GET("/", func(c *gin.Context) {
+				_ = c.Error(tt.err)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			var body response.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedStatus, body.Code)
+			assert.Equal(t, tt.expectedErrorCode, body.ErrorCode)
+			assert.Equal(t, tt.expectedMessage, body.Message)
+			assert.Equal(t, tt.expectedRetry, w.Header().Get("Retry-After"))
+		})
+	}
+}
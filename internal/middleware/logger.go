@@ -5,12 +5,33 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"microservice-mvp/pkg/apperrors"
 	"microservice-mvp/pkg/configs"
 	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/metrics"
 )
 
-// LoggerMiddleware 是 Gin 的中間件，用於記錄 HTTP 請求並處理延遲警告
+// accessLoggerName 是存取日誌的具名 logger，可透過 logger.loggers 或 /admin/log-level 單獨調整級別
+const accessLoggerName = "access"
+
+// LoggerMiddleware 是 Gin 的中間件，於請求完成時寫入一筆存取日誌並處理延遲警告
+// cfg.AccessLog 可略過特定路由或提高其最低級別，請求出錯 (5xx 或 handler 回報錯誤) 時一律記錄
 func LoggerMiddleware(cfg configs.ServerConfig) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(cfg.AccessLog.Skip))
+	for _, p := range cfg.AccessLog.Skip {
+		skip[p] = struct{}{}
+	}
+	routeLevels := make(map[string]zapcore.Level, len(cfg.AccessLog.Routes))
+	for _, r := range cfg.AccessLog.Routes {
+		var lvl zapcore.Level
+		if err := lvl.UnmarshalText([]byte(r.Level)); err != nil {
+			logger.Logger.Warn("無效的存取日誌級別，忽略此路由設定", zap.String("path", r.Path), zap.String("level", r.Level))
+			continue
+		}
+		routeLevels[r.Path] = lvl
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -19,18 +40,56 @@ func LoggerMiddleware(cfg configs.ServerConfig) gin.HandlerFunc {
 		// 處理請求
 		c.Next()
 
-		// 從上下文中獲取帶有 TraceID 的 Logger
-		log := logger.FromContext(c.Request.Context())
-
 		// 請求處理完成後記錄詳細資訊
 		latency := time.Since(start)
 		clientIP := c.ClientIP()
 		method := c.Request.Method
 		statusCode := c.Writer.Status()
 		errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String()
+		route := c.FullPath()
+
+		// 以路由樣板 (例如 /api/v1/players/:id) 作為標籤，避免路徑參數產生大量時間序列
+		metrics.ObserveHTTPRequest(method, route, statusCode, latency)
+
+		// 記錄請求上下文錯誤（例如：客戶端斷線、逾時）
+		ctxErr := c.Request.Context().Err()
+
+		// 只有伺服器端錯誤記為 Error (會附上堆疊)；驗證失敗、找不到、未授權與限流等用戶端錯誤記為 Warn，
+		// 仍受略過清單與路由最低級別控制，避免大量被拒絕的請求淹沒錯誤日誌
+		level, msg := zapcore.InfoLevel, "請求完成"
+		switch {
+		case statusCode >= 500 || ctxErr != nil:
+			level, msg = zapcore.ErrorLevel, "請求失敗"
+		case errorMessage != "" && apperrors.From(c.Errors.Last().Err).Kind == apperrors.KindInternal:
+			level, msg = zapcore.ErrorLevel, "請求失敗"
+		case errorMessage != "" || statusCode >= 400:
+			level, msg = zapcore.WarnLevel, "用戶端請求錯誤"
+		case latency > time.Duration(cfg.SlowThreshold)*time.Millisecond:
+			level, msg = zapcore.WarnLevel, "慢請求"
+		}
+
+		// 略過的路由與最低級別只套用於未出錯的請求
+		key := route
+		if key == "" {
+			key = path
+		}
+		if level < zapcore.ErrorLevel {
+			if _, ok := skip[key]; ok {
+				return
+			}
+			if min, ok := routeLevels[key]; ok && level < min {
+				return
+			}
+		}
+
+		// 從上下文中獲取帶有 TraceID 的 Logger
+		ce := logger.FromContext(c.Request.Context()).Named(accessLoggerName).Check(level, msg)
+		if ce == nil {
+			return
+		}
 
 		if raw != "" {
-			path = path + "?" + raw
+			path = path + "?" + logger.RedactQuery(raw) // 查詢字串可能帶有權杖等敏感參數
 		}
 
 		fields := []zap.Field{
@@ -39,24 +98,25 @@ func LoggerMiddleware(cfg configs.ServerConfig) gin.HandlerFunc {
 			zap.String("clientIP", clientIP),
 			zap.String("method", method),
 			zap.String("path", path),
+			zap.String("route", route),
+			zap.String("requestID", c.Writer.Header().Get(HeaderXRequestID)),
+			zap.String("userAgent", c.Request.UserAgent()),
+			zap.Int("responseSize", c.Writer.Size()),
 		}
-
-		// 記錄請求上下文錯誤（例如：客戶端斷線、逾時）
-		select {
-		case <-c.Request.Context().Done():
-			err := c.Request.Context().Err()
-			log.Error("請求上下文意外結束", zap.Error(err), zap.Any("context_error", err.Error()))
-			fields = append(fields, zap.Error(err))
-		default:
-			// 無上下文錯誤
-		}
-
 		if errorMessage != "" {
-			log.Error(errorMessage, fields...)
-		} else if latency > time.Duration(cfg.SlowThreshold)*time.Millisecond {
-			log.Warn("慢請求", fields...)
-		} else {
-			log.Info("請求完成", fields...)
+			fields = append(fields, zap.String("errors", errorMessage))
+		}
+		if ctxErr != nil {
+			fields = append(fields, zap.Error(ctxErr))
 		}
+		// BodyCapture 啟用時附上已遮蔽的請求/回應 body
+		if body := c.GetString(capturedRequestBodyKey); body != "" {
+			fields = append(fields, zap.String("requestBody", body))
+		}
+		if body := c.GetString(capturedResponseBodyKey); body != "" {
+			fields = append(fields, zap.String("responseBody", body))
+		}
+
+		ce.Write(fields...)
 	}
-}
\ No newline at end of file
+}
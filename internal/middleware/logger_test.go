@@ -0,0 +1,180 @@
+package middleware_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/middleware"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/metrics"
+)
+
+func TestLoggerMiddleware_RecordsRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, _ = logger.NewLogger("info", "console")
+
+	router := gin.New()
+	router.Use(middleware.LoggerMiddleware(configs.ServerConfig{SlowThreshold: 500}))
+	router.GET("/metrics-test/players/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/metrics-test/players/1", "/metrics-test/players/2", "/metrics-test/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `mvp_http_requests_total{method="GET",route="/metrics-test/players/:id",status="204"} 2`, "路徑參數應歸入同一個路由樣板")
+	assert.NotContains(t, string(body), "/metrics-test/missing", "未匹配的路徑不應成為標籤")
+}
+
+func TestLoggerMiddleware_RedactsQueryAndCapturedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	readLog := useFileLogger(t, configs.LoggerConfig{
+		Level:  "info",
+		Redact: configs.RedactConfig{Enabled: true, Fields: []string{"password", "token"}},
+	})
+
+	router := gin.New()
+	router.Use(middleware.LoggerMiddleware(configs.ServerConfig{SlowThreshold: 500}))
+	router.Use(middleware.BodyCapture(configs.BodyCaptureConfig{Enabled: true, MaxBytes: 256}))
+	var received string
+	router.POST("/capture-test", func(c *gin.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		received = string(b)
+		c.JSON(http.StatusOK, gin.H{"token": "issued-token", "user": "alice"})
+	})
+
+	reqBody := `{"username":"alice","password":"p@ss"}`
+	req := httptest.NewRequest(http.MethodPost, "/capture-test?token=query-token&page=1", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, reqBody, received, "擷取 body 後 handler 仍應讀到完整內容")
+
+	out := readLog()
+	assert.Contains(t, out, "token=***&page=1")
+	assert.Contains(t, out, "requestBody")
+	assert.Contains(t, out, "responseBody")
+	assert.NotContains(t, out, "query-token")
+	assert.NotContains(t, out, "p@ss")
+	assert.NotContains(t, out, "issued-token")
+}
+
+func TestLoggerMiddleware_AccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	readLog := useFileLogger(t, configs.LoggerConfig{Level: "info"})
+
+	router := gin.New()
+	router.Use(middleware.LoggerMiddleware(configs.ServerConfig{
+		SlowThreshold: 500,
+		AccessLog: configs.AccessLogConfig{
+			Skip: []string{"/access-test/health"},
+			Routes: []configs.AccessLogRoute{
+				{Path: "/access-test/players/:id", Level: "warn"},
+				{Path: "/access-test/login", Level: "error"},
+			},
+		},
+	}))
+	router.GET("/access-test/health", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.Status(http.StatusServiceUnavailable)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	router.GET("/access-test/players/:id", func(c *gin.Context) {
+		if c.Param("id") == "boom" {
+			_ = c.Error(errors.New("查詢玩家失敗"))
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		if c.Param("id") == "missing" {
+			_ = c.Error(apperrors.NotFound("PLAYER_NOT_FOUND", "玩家不存在"))
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	router.GET("/access-test/bets", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/access-test/login", func(c *gin.Context) {
+		_ = c.Error(apperrors.RateLimited("", "請求過於頻繁", time.Second))
+		c.Status(http.StatusTooManyRequests)
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		logged  bool
+		message string
+	}{
+		{name: "略過的路由", path: "/access-test/health", logged: false},
+		{name: "略過的路由出錯時仍記錄", path: "/access-test/health?fail=1", logged: true, message: "請求失敗"},
+		{name: "低於路由最低級別", path: "/access-test/players/7", logged: false},
+		{name: "錯誤不受路由最低級別限制", path: "/access-test/players/boom", logged: true, message: "查詢玩家失敗"},
+		{name: "用戶端錯誤為 Warn 並附上錯誤訊息", path: "/access-test/players/missing", logged: true, message: `"level":"warn"`},
+		{name: "用戶端錯誤仍受路由最低級別限制", path: "/access-test/login", logged: false},
+		{name: "一般路由", path: "/access-test/bets", logged: true, message: "請求完成"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(readLog())
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			written := readLog()[before:]
+
+			if !tt.logged {
+				assert.Empty(t, written)
+				return
+			}
+			lines := strings.Split(strings.TrimSpace(written), "\n")
+			require.Len(t, lines, 1, "每個請求只應寫入一筆存取日誌")
+			assert.Contains(t, lines[0], tt.message)
+			assert.Contains(t, lines[0], `"logger":"access"`)
+		})
+	}
+
+	t.Run("以具名 logger 調整存取日誌級別", func(t *testing.T) {
+		require.NoError(t, logger.SetLevel("access", "warn"))
+		defer logger.ResetLevel("access")
+
+		before := readLog()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/access-test/bets", nil))
+		assert.Equal(t, before, readLog())
+	})
+}
+
+// useFileLogger 將全域 logger 改為寫入暫存檔，測試結束後恢復為標準輸出，回傳讀取日誌內容的函式
+func useFileLogger(t *testing.T, cfg configs.LoggerConfig) func() string {
+	t.Helper()
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	cfg.File = configs.LogFileConfig{Enabled: true, Path: logFile}
+	_, err := logger.New(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = logger.NewLogger("info", "console") })
+
+	return func() string {
+		_ = logger.Logger.Sync()
+		b, err := os.ReadFile(logFile)
+		if os.IsNotExist(err) {
+			return ""
+		}
+		require.NoError(t, err)
+		return string(b)
+	}
+}
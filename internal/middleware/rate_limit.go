@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/metrics"
+	"microservice-mvp/pkg/ratelimit"
+)
+
+// 速率限制的回應標頭 (IETF draft-ietf-httpapi-ratelimit-headers)，被拒絕時另由 ErrorHandler 回傳 Retry-After
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// defaultAPIKeyHeader 是未設定 rate_limit.api_key_header 時讀取 API 金鑰的標頭
+const defaultAPIKeyHeader = "X-API-Key"
+
+// 區分用戶端的方式
+const (
+	rateLimitKeyIP     = "ip"
+	rateLimitKeyAPIKey = "api_key"
+)
+
+// rateLimitPolicy 是解析後的速率限制策略
+type rateLimitPolicy struct {
+	name   string
+	key    string
+	rule   ratelimit.Rule
+	routes []rateLimitRoute
+}
+
+// rateLimitRoute 是策略套用的路由，method 為空字串時比對所有方法
+type rateLimitRoute struct {
+	method string
+	path   string
+	prefix bool
+}
+
+func (p *rateLimitPolicy) matches(method, route string) bool {
+	for _, r := range p.routes {
+		if r.method != "" && r.method != method {
+			continue
+		}
+		if r.path == route || (r.prefix && strings.HasPrefix(route, r.path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimit 依 cfg.Policies 限制請求速率，每個請求套用所有路由相符的策略，任一策略超過時回傳 429 與 Retry-After
+// 回應帶有剩餘額度最少的策略的 RateLimit-* 標頭；Store 異常時放行請求 (fail open)，避免 Redis 故障導致 API 無法使用
+func RateLimit(cfg configs.RateLimitConfig, store ratelimit.Store) gin.HandlerFunc {
+	apiKeyHeader := cfg.APIKeyHeader
+	if apiKeyHeader == "" {
+		apiKeyHeader = defaultAPIKeyHeader
+	}
+	policies := parseRateLimitPolicies(cfg.Policies)
+	apiKeys := make(map[[sha256.Size]byte]struct{}, len(cfg.APIKeys))
+	for _, k := range cfg.APIKeys {
+		apiKeys[sha256.Sum256([]byte(k))] = struct{}{}
+	}
+	identify := func(c *gin.Context, key string) string {
+		return rateLimitIdentity(c, key, apiKeyHeader, apiKeys)
+	}
+
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		var shown *ratelimit.Result
+		var shownPolicy *rateLimitPolicy
+		for i := range policies {
+			p := &policies[i]
+			if !p.matches(method, route) {
+				continue
+			}
+
+			res, err := store.Take(c.Request.Context(), p.name+":"+identify(c, p.key), p.rule)
+			if err != nil {
+				logger.FromContext(c.Request.Context()).Warn("速率限制檢查失敗，放行請求", zap.String("policy", p.name), zap.Error(err))
+				metrics.ObserveRateLimit(p.name, "error")
+				continue
+			}
+			if !res.Allowed {
+				metrics.ObserveRateLimit(p.name, "limited")
+				setRateLimitHeaders(c, p, res)
+				_ = c.Error(apperrors.RateLimited(apperrors.CodeRateLimited, "請求過於頻繁，請稍後再試", res.RetryAfter))
+				c.Abort()
+				return
+			}
+			metrics.ObserveRateLimit(p.name, "allowed")
+			if shown == nil || res.Remaining < shown.Remaining {
+				shown, shownPolicy = &res, p
+			}
+		}
+
+		if shown != nil {
+			setRateLimitHeaders(c, shownPolicy, *shown)
+		}
+		c.Next()
+	}
+}
+
+// parseRateLimitPolicies 解析配置中的策略，無效的策略記錄警告後忽略
+func parseRateLimitPolicies(cfgs []configs.RateLimitPolicy) []rateLimitPolicy {
+	policies := make([]rateLimitPolicy, 0, len(cfgs))
+	for _, pc := range cfgs {
+		rule := ratelimit.Rule{
+			Algorithm: ratelimit.Algorithm(pc.Algorithm),
+			Limit:     pc.Limit,
+			Window:    time.Duration(pc.WindowSeconds) * time.Second,
+			Burst:     pc.Burst,
+		}
+		if err := rule.Validate(); err != nil {
+			logger.Logger.Warn("無效的速率限制策略，忽略此策略", zap.String("policy", pc.Name), zap.Error(err))
+			continue
+		}
+		switch pc.Key {
+		case rateLimitKeyIP, rateLimitKeyAPIKey:
+		default:
+			logger.Logger.Warn("無效的速率限制鍵，忽略此策略", zap.String("policy", pc.Name), zap.String("key", pc.Key))
+			continue
+		}
+		if pc.Name == "" {
+			logger.Logger.Warn("速率限制策略未設定名稱，忽略此策略", zap.Strings("routes", pc.Routes))
+			continue
+		}
+
+		p := rateLimitPolicy{name: pc.Name, key: pc.Key, rule: rule}
+		for _, r := range pc.Routes {
+			var route rateLimitRoute
+			route.path = strings.TrimSpace(r)
+			if method, path, ok := strings.Cut(route.path, " "); ok {
+				route.method, route.path = strings.ToUpper(method), strings.TrimSpace(path)
+			}
+			if strings.HasSuffix(route.path, "*") {
+				route.path, route.prefix = strings.TrimSuffix(route.path, "*"), true
+			}
+			p.routes = append(p.routes, route)
+		}
+		policies = append(policies, p)
+	}
+	return policies
+}
+
+// rateLimitIdentity 回傳區分用戶端的鍵，無法取得已核發的 API 金鑰時以 IP 代替
+// 未核發的金鑰不可取得獨立的額度，否則用戶端每次帶不同的標頭值即可繞過限制
+func rateLimitIdentity(c *gin.Context, key, apiKeyHeader string, apiKeys map[[sha256.Size]byte]struct{}) string {
+	if key == rateLimitKeyAPIKey {
+		if k := c.GetHeader(apiKeyHeader); k != "" {
+			// 以雜湊比對與保存，避免金鑰寫入 Redis 鍵
+			sum := sha256.Sum256([]byte(k))
+			if _, ok := apiKeys[sum]; ok {
+				return "key:" + hex.EncodeToString(sum[:16])
+			}
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func setRateLimitHeaders(c *gin.Context, p *rateLimitPolicy, res ratelimit.Result) {
+	window := int(p.rule.Window.Seconds())
+	policy := strconv.Itoa(p.rule.Limit) + ";w=" + strconv.Itoa(window)
+	if p.rule.Algorithm == ratelimit.TokenBucket {
+		policy += ";burst=" + strconv.Itoa(res.Limit)
+	}
+	c.Header(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+	c.Header(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+	c.Header(HeaderRateLimitReset, strconv.Itoa(int(math.Ceil(res.Reset.Seconds()))))
+	c.Header(HeaderRateLimitPolicy, policy)
+}
@@ -0,0 +1,157 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/middleware"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/ratelimit"
+	"microservice-mvp/pkg/response"
+)
+
+// failingStore 模擬 Redis 異常的儲存
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, rule ratelimit.Rule) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func newRateLimitRouter(t *testing.T, cfg configs.RateLimitConfig, store ratelimit.Store) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	_, err := logger.NewLogger("info", "console")
+	require.NoError(t, err)
+
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(nil))
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.RateLimit(cfg, store))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/api/v1/login", ok)
+	router.GET("/api/v1/players/:id", ok)
+	router.GET("/health", ok)
+	return router
+}
+
+func TestRateLimit(t *testing.T) {
+	cfg := configs.RateLimitConfig{Policies: []configs.RateLimitPolicy{
+		{Name: "auth", Routes: []string{"POST /api/v1/login"}, Key: "ip", Algorithm: "sliding_window", Limit: 2, WindowSeconds: 60},
+		{Name: "players", Routes: []string{"/api/v1/players/*"}, Key: "ip", Algorithm: "token_bucket", Limit: 60, WindowSeconds: 60, Burst: 1},
+		{Name: "invalid", Routes: []string{"/health"}, Key: "ip", Algorithm: "leaky_bucket", Limit: 1, WindowSeconds: 1},
+		{Name: "player", Routes: []string{"/health"}, Key: "player", Algorithm: "sliding_window", Limit: 1, WindowSeconds: 1},
+	}}
+	router := newRateLimitRouter(t, cfg, ratelimit.NewMemoryStore())
+
+	do := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("回應帶有 RateLimit 標頭", func(t *testing.T) {
+		w := do(http.MethodPost, "/api/v1/login", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get(middleware.HeaderRateLimitLimit))
+		assert.Equal(t, "1", w.Header().Get(middleware.HeaderRateLimitRemaining))
+		assert.Equal(t, "60", w.Header().Get(middleware.HeaderRateLimitReset))
+		assert.Equal(t, "2;w=60", w.Header().Get(middleware.HeaderRateLimitPolicy))
+	})
+
+	t.Run("超過額度回傳 429 與 Retry-After", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/v1/login", nil).Code)
+
+		w := do(http.MethodPost, "/api/v1/login", nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get(middleware.HeaderRateLimitRemaining))
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+		var body response.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, apperrors.CodeRateLimited, body.ErrorCode)
+	})
+
+	t.Run("token bucket 容量用盡後拒絕", func(t *testing.T) {
+		w := do(http.MethodGet, "/api/v1/players/1", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "60;w=60;burst=1", w.Header().Get(middleware.HeaderRateLimitPolicy))
+		assert.Equal(t, http.StatusTooManyRequests, do(http.MethodGet, "/api/v1/players/2", nil).Code)
+	})
+
+	t.Run("未相符或無效的策略不限制", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			w := do(http.MethodGet, "/health", nil)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get(middleware.HeaderRateLimitLimit))
+		}
+	})
+}
+
+func TestRateLimit_APIKey(t *testing.T) {
+	cfg := configs.RateLimitConfig{APIKeys: []string{"key-a", "key-b"}, Policies: []configs.RateLimitPolicy{
+		{Name: "api", Routes: []string{"GET /api/v1/players/:id"}, Key: "api_key", Algorithm: "sliding_window", Limit: 1, WindowSeconds: 60},
+	}}
+	router := newRateLimitRouter(t, cfg, ratelimit.NewMemoryStore())
+
+	codes := func(key string) []int {
+		var out []int
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/players/1", nil)
+			req.Header.Set("X-API-Key", key)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			out = append(out, w.Code)
+		}
+		return out
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes("key-a"))
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes("key-b"))
+	// 未核發的金鑰以 IP 計算，更換標頭值無法取得新的額度
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes("forged-1"))
+	assert.Equal(t, []int{http.StatusTooManyRequests, http.StatusTooManyRequests}, codes("forged-2"))
+}
+
+func TestRateLimit_IgnoresForgedForwardedFor(t *testing.T) {
+	cfg := configs.RateLimitConfig{Policies: []configs.RateLimitPolicy{
+		{Name: "auth", Routes: []string{"POST /api/v1/login"}, Key: "ip", Algorithm: "sliding_window", Limit: 1, WindowSeconds: 60},
+	}}
+	router := newRateLimitRouter(t, cfg, ratelimit.NewMemoryStore())
+
+	var codes []int
+	for _, ip := range []string{"1.1.1.1", "2.2.2.2"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/login", nil)
+		req.Header.Set("X-Forwarded-For", ip)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes, "未信任代理時以連線來源計算")
+}
+
+func TestRateLimit_StoreErrorFailsOpen(t *testing.T) {
+	cfg := configs.RateLimitConfig{Policies: []configs.RateLimitPolicy{
+		{Name: "auth", Routes: []string{"POST /api/v1/login"}, Key: "ip", Algorithm: "sliding_window", Limit: 1, WindowSeconds: 60},
+	}}
+	router := newRateLimitRouter(t, cfg, failingStore{})
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/login", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
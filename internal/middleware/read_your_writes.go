@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"microservice-mvp/pkg/database"
+)
+
+// ReadYourWrites 為每個請求建立讀寫分離的 session
+// 請求中一旦寫入主庫，後續的讀取都會固定走主庫，避免讀到尚未同步到副本的舊資料
+func ReadYourWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(database.WithSession(c.Request.Context()))
+		c.Next()
+	}
+}
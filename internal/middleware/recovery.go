@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"microservice-mvp/pkg/apperrors"
 	"microservice-mvp/pkg/logger"
 	"microservice-mvp/pkg/response"
 	"net/http"
@@ -20,10 +21,10 @@ func Recovery() gin.HandlerFunc {
 					zap.Any("error", err),
 					zap.String("stack", string(debug.Stack())),
 				)
-				response.FailWithMessage(c, http.StatusInternalServerError, "內部伺服器錯誤")
+				response.FailWithCode(c, http.StatusInternalServerError, apperrors.CodeInternal, "內部伺服器錯誤")
 				c.Abort()
 			}
 		}()
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -2,24 +2,44 @@ package middleware
 
 import (
 	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"go.uber.org/zap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	// HeaderXRequestID 是請求 ID 的標頭名稱
 	HeaderXRequestID = "X-Request-ID"
-	// HeaderXTraceID 是追蹤 ID 的標頭名稱，對應 logger.TraceIDKey
+	// HeaderXTraceID 是回應中的追蹤 ID 標頭名稱，值為 W3C trace ID，對應 logger.TraceIDKey
 	HeaderXTraceID = "X-Trace-ID"
 )
 
-// TraceID 是一個 Gin 中間件，用於將唯一的追蹤 ID 注入請求上下文和回應標頭中
+// TraceID 是一個 Gin 中間件，從 W3C traceparent/tracestate 標頭接續上游的 trace 並建立 server span，
+// 將 trace ID 注入請求上下文和回應標頭中
+// 未啟用追蹤且請求沒有 traceparent 時，沿用 X-Trace-ID 標頭或產生 UUID 作為追蹤 ID
 func TraceID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		traceID := c.GetHeader(HeaderXTraceID)
-		if traceID == "" {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracing.Tracer().Start(ctx, spanName(c),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		traceID := ""
+		if sc := span.SpanContext(); sc.IsValid() {
+			traceID = sc.TraceID().String()
+		} else if traceID = c.GetHeader(HeaderXTraceID); traceID == "" {
 			traceID = uuid.New().String()
 		}
 
@@ -33,19 +53,28 @@ func TraceID() gin.HandlerFunc {
 			requestID = uuid.New().String()
 		}
 		c.Writer.Header().Set(HeaderXRequestID, requestID)
-		
-		// 將 traceID 注入上下文以供日誌使用
-		ctx := c.Request.Context()
+
+		// 將 span 與 traceID 注入上下文以供日誌使用，請求日誌於完成時由 LoggerMiddleware 統一寫入一筆
 		ctx = logger.WithTraceID(ctx, traceID)
 		c.Request = c.Request.WithContext(ctx)
 
-		// 記錄帶有 trace ID 的初始請求
-		logger.FromContext(c.Request.Context()).Info("收到請求",
-			zap.String("method", c.Request.Method),
-			zap.String("path", c.Request.URL.Path),
-			zap.String("ip", c.ClientIP()),
-		)
-
 		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if route := c.FullPath(); route != "" {
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		if status >= 500 {
+			span.SetStatus(codes.Error, c.Errors.ByType(gin.ErrorTypePrivate).String())
+		}
+	}
+}
+
+// spanName 以方法與路由樣板命名 span，未匹配任何路由時只使用方法，避免以原始路徑產生大量名稱
+func spanName(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return c.Request.Method + " " + route
 	}
-}
\ No newline at end of file
+	return c.Request.Method
+}
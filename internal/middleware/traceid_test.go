@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"microservice-mvp/internal/middleware"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestTraceID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, _ = logger.NewLogger("info", "console")
+
+	exp := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	var seen string
+	router := gin.New()
+	router.Use(middleware.TraceID())
+	router.GET("/players/:id", func(c *gin.Context) {
+		seen = logger.TraceIDFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("ContinuesUpstreamTraceParent", func(t *testing.T) {
+		exp.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/players/7", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rec.Header().Get(middleware.HeaderXTraceID))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", seen)
+
+		spans := exp.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "GET /players/:id", spans[0].Name)
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+		assert.Contains(t, spans[0].Attributes, attribute.String("http.route", "/players/:id"))
+		assert.Contains(t, spans[0].Attributes, attribute.Int("http.response.status_code", http.StatusOK))
+	})
+
+	t.Run("StartsNewTrace", func(t *testing.T) {
+		exp.Reset()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/players/7", nil))
+
+		spans := exp.GetSpans()
+		require.Len(t, spans, 1)
+		assert.False(t, spans[0].Parent.IsValid())
+		assert.Equal(t, spans[0].SpanContext.TraceID().String(), rec.Header().Get(middleware.HeaderXTraceID))
+	})
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// 稽核動作
+const (
+	AuditActionLogin          = "auth.login"
+	AuditActionLoginFailed    = "auth.login_failed"
+	AuditActionRegister       = "auth.register"
+	AuditActionPasswordChange = "player.password_change"
+	AuditActionBalanceChange  = "player.balance_change"
+	AuditActionRoleChange     = "player.role_change"
+	AuditActionAdmin          = "admin.request"     // 管理 API 的異動請求
+	AuditActionAdminAuthFail  = "admin.auth_failed" // 管理 API 權杖驗證失敗
+)
+
+// 稽核結果
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// 稽核的行為者類型
+const (
+	AuditActorPlayer    = "player"
+	AuditActorAdmin     = "admin"
+	AuditActorAnonymous = "anonymous"
+	AuditActorSystem    = "system"
+)
+
+// AuditTargetPlayer 是以玩家為對象的稽核目標類型
+const AuditTargetPlayer = "player"
+
+// AuditLog 是一筆只可附加的稽核紀錄
+// ID 從 1 起連續遞增，Hash 為包含前一筆 Hash (PrevHash) 在內的內容雜湊，任何一筆被修改或刪除都會使之後的鏈驗證失敗
+type AuditLog struct {
+	ID         uint64    `gorm:"primarykey;autoIncrement:false" json:"id"`
+	OccurredAt time.Time `gorm:"type:datetime(3);not null;index" json:"occurred_at"`
+	Action     string    `gorm:"type:varchar(64);not null;index" json:"action"`
+	Result     string    `gorm:"type:varchar(16);not null" json:"result"`
+	ActorType  string    `gorm:"type:varchar(32);not null" json:"actor_type"`
+	ActorID    string    `gorm:"type:varchar(128);index" json:"actor_id"`
+	TargetType string    `gorm:"type:varchar(64)" json:"target_type"`
+	TargetID   string    `gorm:"type:varchar(255);index" json:"target_id"`
+	Before     string    `gorm:"type:text" json:"before,omitempty"` // 異動前的值 (JSON)
+	After      string    `gorm:"type:text" json:"after,omitempty"`  // 異動後的值 (JSON)
+	Reason     string    `gorm:"type:varchar(1024)" json:"reason,omitempty"`
+	IP         string    `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent  string    `gorm:"type:varchar(512)" json:"user_agent"`
+	TraceID    string    `gorm:"type:varchar(64);index" json:"trace_id"`
+	PrevHash   string    `gorm:"type:char(64)" json:"prev_hash"`
+	Hash       string    `gorm:"type:char(64);not null" json:"hash"`
+}
+
+// TableName 指定 AuditLog 的資料表名稱
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// ComputeHash 計算紀錄的鏈雜湊：SHA-256(除 Hash 以外所有欄位的 JSON)，時間以 UTC 毫秒精度表示以配合 DB 的 datetime(3)
+func (a *AuditLog) ComputeHash() string {
+	view := struct {
+		ID         string `json:"id"`
+		OccurredAt string `json:"occurred_at"`
+		Action     string `json:"action"`
+		Result     string `json:"result"`
+		ActorType  string `json:"actor_type"`
+		ActorID    string `json:"actor_id"`
+		TargetType string `json:"target_type"`
+		TargetID   string `json:"target_id"`
+		Before     string `json:"before"`
+		After      string `json:"after"`
+		Reason     string `json:"reason"`
+		IP         string `json:"ip"`
+		UserAgent  string `json:"user_agent"`
+		TraceID    string `json:"trace_id"`
+		PrevHash   string `json:"prev_hash"`
+	}{
+		ID:         strconv.FormatUint(a.ID, 10),
+		OccurredAt: a.OccurredAt.UTC().Truncate(time.Millisecond).Format("2006-01-02T15:04:05.000Z"),
+		Action:     a.Action,
+		Result:     a.Result,
+		ActorType:  a.ActorType,
+		ActorID:    a.ActorID,
+		TargetType: a.TargetType,
+		TargetID:   a.TargetID,
+		Before:     a.Before,
+		After:      a.After,
+		Reason:     a.Reason,
+		IP:         a.IP,
+		UserAgent:  a.UserAgent,
+		TraceID:    a.TraceID,
+		PrevHash:   a.PrevHash,
+	}
+	b, _ := json.Marshal(view) // 只含字串欄位，不會失敗
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Chain 將紀錄接在 prev 之後：設定 ID、PrevHash 並計算 Hash，prev 為 nil 代表第一筆
+func (a *AuditLog) Chain(prev *AuditLog) {
+	a.ID, a.PrevHash = 1, ""
+	if prev != nil {
+		a.ID, a.PrevHash = prev.ID+1, prev.Hash
+	}
+	a.OccurredAt = a.OccurredAt.UTC().Truncate(time.Millisecond)
+	a.Hash = a.ComputeHash()
+}
+
+// AuditVerifyResponse 代表稽核鏈的驗證結果
+type AuditVerifyResponse struct {
+	Valid      bool   `json:"valid"`
+	Checked    int    `json:"checked"`                // 已驗證的紀錄筆數
+	LastID     uint64 `json:"last_id"`                // 最後一筆通過驗證的紀錄 ID
+	LastHash   string `json:"last_hash"`              // 最後一筆通過驗證的紀錄雜湊，可另行保存以偵測截斷
+	BrokenAtID uint64 `json:"broken_at_id,omitempty"` // 第一筆驗證失敗的紀錄 ID
+	Reason     string `json:"reason,omitempty"`
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+)
+
+// DeadLetter 代表一則超過重試上限或無法處理 (poison) 的訊息
+// 保留原始 Topic、標籤、屬性與內容，供管理 API 檢視、重新投遞或清除
+type DeadLetter struct {
+	ID             uint64    `gorm:"primarykey" json:"id"`
+	ConsumerGroup  string    `gorm:"type:varchar(255)" json:"consumer_group"`
+	Topic          string    `gorm:"type:varchar(255);not null;index" json:"topic"` // 原始 Topic
+	Tag            string    `gorm:"type:varchar(128)" json:"tag"`
+	Keys           string    `gorm:"type:varchar(512)" json:"keys"`
+	MsgID          string    `gorm:"type:varchar(128);index" json:"msg_id"`
+	Body           []byte    `gorm:"type:mediumblob" json:"body"`
+	Properties     string    `gorm:"type:text" json:"properties"` // 原始訊息屬性的 JSON
+	ReconsumeTimes int32     `json:"reconsume_times"`
+	Reason         string    `gorm:"type:varchar(1024)" json:"reason"` // 最後一次處理失敗的原因
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName 指定 DeadLetter 的資料表名稱
+func (DeadLetter) TableName() string {
+	return "dead_letters"
+}
+
+// DeadLetterResponse 代表管理 API 回傳的死信內容，Body 以字串呈現方便檢視
+type DeadLetterResponse struct {
+	ID             uint64            `json:"id"`
+	ConsumerGroup  string            `json:"consumer_group"`
+	Topic          string            `json:"topic"`
+	Tag            string            `json:"tag"`
+	Keys           string            `json:"keys"`
+	MsgID          string            `json:"msg_id"`
+	Body           string            `json:"body"`
+	BodyEncoding   string            `json:"body_encoding"` // utf8 或 base64 (例如 protobuf 編碼的內容)
+	Properties     map[string]string `json:"properties"`
+	ReconsumeTimes int32             `json:"reconsume_times"`
+	Reason         string            `json:"reason"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// PurgeDeadLettersResponse 代表清除死信的結果
+type PurgeDeadLettersResponse struct {
+	Purged int64 `json:"purged"`
+}
+
+// ReplayDeadLetterResponse 代表重新投遞死信的結果
+type ReplayDeadLetterResponse struct {
+	ID    uint64 `json:"id"`     // 已重新投遞並刪除的死信 ID
+	Topic string `json:"topic"`  // 重新投遞的 Topic
+	MsgID string `json:"msg_id"` // 重新投遞後的新訊息 ID
+}
+
+// ToDeadLetterResponse 將 DeadLetter 模型轉換為 DeadLetterResponse
+func (d *DeadLetter) ToDeadLetterResponse() DeadLetterResponse {
+	resp := DeadLetterResponse{
+		ID:             d.ID,
+		ConsumerGroup:  d.ConsumerGroup,
+		Topic:          d.Topic,
+		Tag:            d.Tag,
+		Keys:           d.Keys,
+		MsgID:          d.MsgID,
+		Body:           string(d.Body),
+		BodyEncoding:   "utf8",
+		ReconsumeTimes: d.ReconsumeTimes,
+		Reason:         d.Reason,
+		CreatedAt:      d.CreatedAt,
+	}
+	if !utf8.Valid(d.Body) {
+		resp.Body = base64.StdEncoding.EncodeToString(d.Body)
+		resp.BodyEncoding = "base64"
+	}
+	_ = json.Unmarshal([]byte(d.Properties), &resp.Properties)
+	return resp
+}
@@ -0,0 +1,14 @@
+package model
+
+// LogLevelRequest 代表調整日誌級別的請求
+// Logger 為空時調整全域級別；指定 Logger 且 Level 為空時移除該 logger 的級別覆寫
+type LogLevelRequest struct {
+	Logger string `json:"logger" example:"gorm"`
+	Level  string `json:"level" example:"debug"`
+}
+
+// LogLevelResponse 代表目前的全域日誌級別與各具名 logger 的級別覆寫
+type LogLevelResponse struct {
+	Level   string            `json:"level" example:"info"`
+	Loggers map[string]string `json:"loggers"`
+}
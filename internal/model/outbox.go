@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+const (
+	// OutboxStatusPending 代表事件尚未成功發送
+	OutboxStatusPending = "pending"
+	// OutboxStatusSent 代表事件已發送到訊息佇列
+	OutboxStatusSent = "sent"
+	// OutboxStatusFailed 代表事件超過最大嘗試次數，需人工處理
+	OutboxStatusFailed = "failed"
+)
+
+// OutboxEvent 代表一筆待發送的領域事件 (Transactional Outbox)
+// 事件與業務資料在同一個交易中寫入，再由 outbox.Relay 非同步發送到訊息佇列
+type OutboxEvent struct {
+	ID            uint64     `gorm:"primarykey" json:"id"`
+	Topic         string     `gorm:"type:varchar(255);not null" json:"topic"`
+	Tag           string     `gorm:"type:varchar(128)" json:"tag"`
+	Key           string     `gorm:"type:varchar(255)" json:"key"`                   // 訊息索引鍵，同時作為 sharding key 以保證同一聚合的順序
+	Payload       []byte     `gorm:"type:mediumblob" json:"payload"`                 // 序列化後的事件內容
+	ContentType   string     `gorm:"type:varchar(64)" json:"content_type"`           // Payload 的編碼方式，發送時寫入訊息屬性
+	TraceID       string     `gorm:"type:varchar(64)" json:"trace_id"`               // 產生事件的請求追蹤 ID
+	TraceParent   string     `gorm:"type:varchar(64)" json:"trace_parent,omitempty"` // 產生事件的 span (W3C traceparent)，發送時作為 producer span 的父 span
+	Status        string     `gorm:"type:varchar(16);not null;index:idx_outbox_due,priority:1" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:varchar(1024)" json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `gorm:"index:idx_outbox_due,priority:2" json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	// ClaimedBy 與 ClaimedUntil 是 relay 的認領 (lease)：認領期間其他實例不會取得此事件，逾期後可再被認領
+	ClaimedBy    string     `gorm:"type:varchar(64);index" json:"-"`
+	ClaimedUntil *time.Time `json:"-"`
+}
+
+// TableName 指定 OutboxEvent 的資料表名稱
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
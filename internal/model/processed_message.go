@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// ProcessedMessage 記錄 Consumer Group 已處理過的訊息，用於去除 at-least-once 投遞造成的重複訊息
+type ProcessedMessage struct {
+	ConsumerGroup string    `gorm:"primaryKey;type:varchar(255)" json:"consumer_group"`
+	MsgID         string    `gorm:"primaryKey;type:varchar(128)" json:"msg_id"`
+	ProcessedAt   time.Time `gorm:"not null;index" json:"processed_at"` // 超過保留期限的紀錄由背景工作清除
+}
+
+// TableName 指定 ProcessedMessage 的資料表名稱
+func (ProcessedMessage) TableName() string {
+	return "processed_messages"
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// WebhookDelivery 的狀態
+const (
+	WebhookDeliveryPending   = "pending"   // 等待投遞或等待重試
+	WebhookDeliverySucceeded = "succeeded" // 對方回應 2xx
+	WebhookDeliveryFailed    = "failed"    // 重試排程用盡後放棄
+)
+
+// WebhookEndpoint 代表合作夥伴註冊的 webhook 端點
+type WebhookEndpoint struct {
+	ID          uint64 `gorm:"primarykey" json:"id"`
+	URL         string `gorm:"type:varchar(2048);not null" json:"url"`
+	Description string `gorm:"type:varchar(255)" json:"description"`
+	Secret      string `gorm:"type:varchar(255);not null" json:"-"` // HMAC-SHA256 簽章金鑰，不回傳給客戶端
+	// EventTypes 是以逗號分隔的事件類型過濾條件，支援 "*" 與前綴萬用字元 (例如 "wallet.*")
+	EventTypes          string     `gorm:"type:varchar(1024);not null" json:"event_types"`
+	Enabled             bool       `gorm:"not null" json:"enabled"`
+	ConsecutiveFailures int        `gorm:"not null;default:0" json:"consecutive_failures"` // 連續投遞失敗次數，成功時歸零
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `gorm:"type:varchar(255)" json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// TableName 指定 WebhookEndpoint 的資料表名稱
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// WebhookDelivery 代表一個事件對一個端點的投遞，同時作為可查詢的投遞紀錄
+type WebhookDelivery struct {
+	ID             uint64     `gorm:"primarykey" json:"id"`
+	EndpointID     uint64     `gorm:"not null;index" json:"endpoint_id"`
+	EventID        string     `gorm:"type:varchar(64);not null" json:"event_id"`
+	EventType      string     `gorm:"type:varchar(128);not null" json:"event_type"`
+	Payload        []byte     `gorm:"type:mediumblob" json:"-"` // 事件信封的 JSON
+	Status         string     `gorm:"type:varchar(16);not null;index:idx_webhook_due,priority:1" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"not null;index:idx_webhook_due,priority:2" json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `gorm:"type:varchar(1024)" json:"last_error,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// ClaimedBy 與 ClaimedUntil 是 dispatcher 的認領 (lease)：認領期間其他實例不會取得此紀錄，逾期後可再被認領
+	ClaimedBy    string     `gorm:"type:varchar(64);index" json:"-"`
+	ClaimedUntil *time.Time `json:"-"`
+}
+
+// TableName 指定 WebhookDelivery 的資料表名稱
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// CreateWebhookEndpointRequest 代表註冊 webhook 端點的請求
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2048"`
+	Description string   `json:"description" binding:"max=255"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1,dive,required,max=128"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=255"` // 留空則由伺服器產生
+}
+
+// UpdateWebhookEndpointRequest 代表更新 webhook 端點的請求，未提供的欄位維持不變
+type UpdateWebhookEndpointRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,url,max=2048"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	EventTypes  []string `json:"event_types" binding:"omitempty,min=1,dive,required,max=128"`
+	Enabled     *bool    `json:"enabled"` // 重新啟用時會將連續失敗次數歸零
+}
+
+// WebhookEndpointResponse 代表回傳的 webhook 端點
+type WebhookEndpointResponse struct {
+	ID                  uint64     `json:"id"`
+	URL                 string     `json:"url"`
+	Description         string     `json:"description"`
+	EventTypes          []string   `json:"event_types"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// CreateWebhookEndpointResponse 代表註冊 webhook 端點的結果，簽章金鑰只在此時回傳一次
+type CreateWebhookEndpointResponse struct {
+	WebhookEndpointResponse
+	Secret string `json:"secret"`
+}
+
+// WebhookDeliveryResponse 代表回傳的單筆投遞紀錄，包含投遞的事件內容
+type WebhookDeliveryResponse struct {
+	WebhookDelivery
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+// EventTypeList 回傳事件類型過濾條件的列表
+func (e *WebhookEndpoint) EventTypeList() []string {
+	var types []string
+	for _, t := range strings.Split(e.EventTypes, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// ToWebhookEndpointResponse 將 WebhookEndpoint 模型轉換為 WebhookEndpointResponse
+func (e *WebhookEndpoint) ToWebhookEndpointResponse() WebhookEndpointResponse {
+	return WebhookEndpointResponse{
+		ID:                  e.ID,
+		URL:                 e.URL,
+		Description:         e.Description,
+		EventTypes:          e.EventTypeList(),
+		Enabled:             e.Enabled,
+		ConsecutiveFailures: e.ConsecutiveFailures,
+		DisabledAt:          e.DisabledAt,
+		DisabledReason:      e.DisabledReason,
+		CreatedAt:           e.CreatedAt,
+		UpdatedAt:           e.UpdatedAt,
+	}
+}
+
+// ToWebhookDeliveryResponse 將 WebhookDelivery 模型轉換為 WebhookDeliveryResponse
+func (d *WebhookDelivery) ToWebhookDeliveryResponse() WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{WebhookDelivery: *d, Payload: json.RawMessage(d.Payload)}
+}
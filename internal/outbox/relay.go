@@ -0,0 +1,249 @@
+// Package outbox 實作 Transactional Outbox 的 relay：
+// 輪詢 outbox 中到期的事件，透過 pkg/messaging 發送，成功後標記為已發送，失敗則以指數退避重試。
+// 發送語意為 at-least-once，Consumer 需自行處理重複訊息。
+package outbox
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+	"microservice-mvp/pkg/metrics"
+	"microservice-mvp/pkg/tracing"
+)
+
+// vars 透過 expvar 暴露 relay 的發送統計與積壓 (lag) 指標；積壓指標同時輸出於 Prometheus 的 /metrics
+var (
+	vars                   = expvar.NewMap("outbox")
+	publishedTotal         = new(expvar.Int)
+	publishFailuresTotal   = new(expvar.Int)
+	givenUpTotal           = new(expvar.Int)
+	pendingEvents          = new(expvar.Int)
+	failedEvents           = new(expvar.Int)
+	oldestPendingAgeSecond = new(expvar.Float)
+	lastPollUnix           = new(expvar.Int)
+)
+
+func init() {
+	vars.Set("published_total", publishedTotal)
+	vars.Set("publish_failures_total", publishFailuresTotal)
+	vars.Set("given_up_total", givenUpTotal)
+	vars.Set("pending", pendingEvents)
+	vars.Set("failed", failedEvents)
+	vars.Set("oldest_pending_age_seconds", oldestPendingAgeSecond)
+	vars.Set("last_poll_unix", lastPollUnix)
+}
+
+// Publisher 將訊息同步發送到訊息佇列，預設為 messaging.Send
+type Publisher func(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error)
+
+// Relay 是將 outbox 事件發送到訊息佇列的背景工作
+type Relay struct {
+	repo    repository.OutboxRepository
+	publish Publisher
+
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	lease       time.Duration
+
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewRelay 建立一個新的 Relay，publish 為 nil 時使用 messaging.Send
+func NewRelay(repo repository.OutboxRepository, cfg configs.OutboxConfig, publish Publisher) *Relay {
+	if publish == nil {
+		publish = messaging.Send
+	}
+	r := &Relay{
+		repo:        repo,
+		publish:     publish,
+		interval:    time.Duration(cfg.PollIntervalMs) * time.Millisecond,
+		batchSize:   cfg.BatchSize,
+		maxAttempts: cfg.MaxAttempts,
+		backoffBase: time.Duration(cfg.BackoffBaseMs) * time.Millisecond,
+		backoffMax:  time.Duration(cfg.BackoffMaxMs) * time.Millisecond,
+		lease:       time.Duration(cfg.LeaseSeconds) * time.Second,
+		stop:        make(chan struct{}),
+	}
+	if r.interval <= 0 {
+		r.interval = 500 * time.Millisecond
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = 100
+	}
+	if r.backoffBase <= 0 {
+		r.backoffBase = time.Second
+	}
+	if r.backoffMax < r.backoffBase {
+		r.backoffMax = time.Minute
+	}
+	if r.lease <= 0 {
+		r.lease = 30 * time.Second
+	}
+	return r
+}
+
+// Start 啟動背景輪詢
+func (r *Relay) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := r.RunOnce(context.Background()); err != nil {
+				logger.Named("outbox").Error("outbox relay 輪詢失敗", zap.Error(err))
+			}
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	logger.Named("outbox").Info("outbox relay 已啟動",
+		zap.Duration("interval", r.interval),
+		zap.Int("batch_size", r.batchSize),
+		zap.Int("max_attempts", r.maxAttempts),
+	)
+}
+
+// Stop 停止背景輪詢並等待進行中的批次完成
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		r.wg.Wait()
+		logger.Named("outbox").Info("outbox relay 已停止")
+	})
+}
+
+// RunOnce 認領並發送一批到期的事件，更新積壓指標，回傳成功發送的事件數
+// 同一批次中某個 Key 的事件發送失敗後，該 Key 之後的事件釋放認領留待下次輪詢，避免順序顛倒；
+// 多個實例同時執行時，同一 Key 的事件可能由不同實例認領，只在同一批次內保證順序
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	lastPollUnix.Set(now.Unix())
+
+	events, err := r.repo.ClaimDue(ctx, now, r.lease, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	blocked := make(map[string]bool)
+	var skipped []uint64
+	for _, ev := range events {
+		if ev.Key != "" && blocked[ev.Key] {
+			skipped = append(skipped, ev.ID)
+			continue
+		}
+		if r.publishOne(ctx, ev) {
+			published++
+		} else if ev.Key != "" {
+			blocked[ev.Key] = true
+		}
+	}
+	if err := r.repo.Release(ctx, skipped); err != nil {
+		// 認領逾期後仍會被再次認領，只是延後發送
+		logger.Named("outbox").Warn("釋放 outbox 事件的認領失敗", zap.Error(err))
+	}
+
+	r.updateLag(ctx)
+	return published, nil
+}
+
+// publishOne 發送單一事件並更新其狀態，回傳是否發送成功
+func (r *Relay) publishOne(ctx context.Context, ev *model.OutboxEvent) bool {
+	// 以產生事件的 span 作為父 span，讓發送與後續消費接續原請求的 trace
+	ctx = tracing.ContextWithTraceParent(ctx, ev.TraceParent)
+	ctx = logger.WithTraceID(ctx, ev.TraceID)
+	log := logger.FromContext(ctx).Named("outbox")
+
+	_, err := r.publish(ctx, buildMessage(ev))
+	if err == nil {
+		publishedTotal.Add(1)
+		if err := r.repo.MarkSent(ctx, ev.ID, time.Now()); err != nil {
+			// 訊息已發送但狀態未更新，下次輪詢會重送一次 (at-least-once)
+			log.Error("標記 outbox 事件已發送失敗", zap.Error(err), zap.Uint64("eventID", ev.ID))
+		}
+		return true
+	}
+
+	publishFailuresTotal.Add(1)
+	attempts := ev.Attempts + 1
+	giveUp := r.maxAttempts > 0 && attempts >= r.maxAttempts
+	next := time.Now().Add(r.backoff(attempts))
+	if giveUp {
+		givenUpTotal.Add(1)
+		log.Error("outbox 事件超過最大發送次數，已放棄",
+			zap.Error(err), zap.Uint64("eventID", ev.ID), zap.String("topic", ev.Topic), zap.Int("attempts", attempts))
+	} else {
+		log.Warn("outbox 事件發送失敗，稍後重試",
+			zap.Error(err), zap.Uint64("eventID", ev.ID), zap.String("topic", ev.Topic),
+			zap.Int("attempts", attempts), zap.Time("nextAttemptAt", next))
+	}
+	if err := r.repo.MarkFailed(ctx, ev.ID, next, err.Error(), giveUp); err != nil {
+		log.Error("記錄 outbox 事件發送失敗", zap.Error(err), zap.Uint64("eventID", ev.ID))
+	}
+	return false
+}
+
+// backoff 回傳第 attempts 次失敗後的等待時間：base * 2^(attempts-1)，上限為 backoffMax
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.backoffBase
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= r.backoffMax {
+			return r.backoffMax
+		}
+	}
+	return d
+}
+
+func (r *Relay) updateLag(ctx context.Context) {
+	stats, err := r.repo.Stats(ctx)
+	if err != nil {
+		logger.Named("outbox").Warn("取得 outbox 積壓統計失敗", zap.Error(err))
+		return
+	}
+	var oldestAge time.Duration
+	if stats.OldestPendingAt != nil {
+		oldestAge = time.Since(*stats.OldestPendingAt)
+	}
+	pendingEvents.Set(stats.Pending)
+	failedEvents.Set(stats.Failed)
+	oldestPendingAgeSecond.Set(oldestAge.Seconds())
+	metrics.SetOutboxLag(stats.Pending, stats.Failed, oldestAge)
+}
+
+// buildMessage 將 outbox 事件轉換為訊息，Key 同時作為索引鍵與 sharding key
+func buildMessage(ev *model.OutboxEvent) *messaging.Message {
+	var keys []string
+	if ev.Key != "" {
+		keys = []string{ev.Key}
+	}
+	msg := messaging.NewMessage(ev.Topic, ev.Tag, ev.Payload, keys)
+	if ev.Key != "" {
+		msg.WithShardingKey(ev.Key)
+	}
+	if ev.TraceID != "" {
+		msg.WithProperty(messaging.PropertyTraceID, ev.TraceID)
+	}
+	if ev.ContentType != "" {
+		msg.WithProperty(messaging.PropertyContentType, ev.ContentType)
+	}
+	return msg
+}
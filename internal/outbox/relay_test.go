@@ -0,0 +1,114 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/outbox"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+// fakePublisher 記錄發送的訊息，並可讓指定 Key 的訊息發送失敗
+type fakePublisher struct {
+	mu     sync.Mutex
+	sent   []*messaging.Message
+	failOn map[string]bool
+}
+
+func (p *fakePublisher) publish(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failOn[msg.KeysString()] {
+		return nil, errors.New("broker unavailable")
+	}
+	p.sent = append(p.sent, msg)
+	return &messaging.SendResult{MsgID: "msg-1"}, nil
+}
+
+func seedEvents(t *testing.T, store *repository.MemoryStore, ctx context.Context, keys ...string) {
+	t.Helper()
+	for i, key := range keys {
+		player := &model.Player{Username: key + string(rune('a'+i)), Password: "secret"}
+		require.NoError(t, store.Players().CreatePlayerWithEvents(ctx, player, func(p *model.Player) ([]*model.OutboxEvent, error) {
+			ev, err := repository.NewOutboxEvent(ctx, "player_events", "registered", key, map[string]uint{"player_id": p.ID})
+			return []*model.OutboxEvent{ev}, err
+		}))
+	}
+}
+
+func TestRelay_PublishesAndMarksSent(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := logger.WithTraceID(context.Background(), "trace-abc")
+
+	store := repository.NewMemoryStore()
+	seedEvents(t, store, ctx, "1", "2")
+
+	pub := &fakePublisher{}
+	relay := outbox.NewRelay(store.Outbox(), configs.OutboxConfig{}, pub.publish)
+
+	n, err := relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	require.Len(t, pub.sent, 2)
+	msg := pub.sent[0]
+	assert.Equal(t, "player_events", msg.Topic)
+	assert.Equal(t, "registered", msg.Tag)
+	assert.Equal(t, "1", msg.ShardingKey)
+	assert.Equal(t, "trace-abc", msg.Property(messaging.PropertyTraceID))
+
+	stats, err := store.Outbox().Stats(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, stats.Pending)
+}
+
+func TestRelay_RetryWithBackoff(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+
+	store := repository.NewMemoryStore()
+	// Key "1" 有兩筆事件：第一筆失敗後，第二筆不可越過它先發送
+	seedEvents(t, store, ctx, "1", "1", "2")
+
+	pub := &fakePublisher{failOn: map[string]bool{"1": true}}
+	relay := outbox.NewRelay(store.Outbox(), configs.OutboxConfig{BackoffBaseMs: 50, BackoffMaxMs: 1000, MaxAttempts: 2}, pub.publish)
+
+	n, err := relay.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "只有 Key 2 的事件應發送成功")
+
+	// 租期為 0，認領不影響之後的輪詢
+	due, err := store.Outbox().ClaimDue(ctx, time.Now(), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1, "失敗的事件在退避期間不應被取出，被跳過的事件應已釋放認領")
+	assert.Equal(t, "1", due[0].Key)
+	assert.Zero(t, due[0].Attempts)
+
+	// 退避時間過後重試，第二次失敗即超過 MaxAttempts
+	time.Sleep(60 * time.Millisecond)
+	_, err = relay.RunOnce(ctx)
+	require.NoError(t, err)
+
+	stats, err := store.Outbox().Stats(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, stats.Failed)
+	assert.EqualValues(t, 1, stats.Pending)
+
+	// Broker 恢復後剩餘事件可正常發送
+	pub.mu.Lock()
+	pub.failOn = nil
+	pub.mu.Unlock()
+	n, err = relay.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"microservice-mvp/internal/model"
+)
+
+// AuditLogFilter 是查詢稽核紀錄的條件，空值代表不限制
+type AuditLogFilter struct {
+	Action   string
+	ActorID  string
+	TargetID string
+	Since    time.Time // 只回傳發生時間不早於此時間的紀錄
+	Until    time.Time // 只回傳發生時間早於此時間的紀錄
+	AfterID  uint64    // 分頁游標：只回傳 ID 大於此值的紀錄
+	Limit    int
+}
+
+// AuditLogRepository 定義只可附加的稽核紀錄儲存操作，刻意不提供修改與刪除
+type AuditLogRepository interface {
+	// Append 將紀錄接在鏈的最後 (設定 ID、PrevHash 與 Hash，見 model.AuditLog.Chain) 後寫入
+	// 多個實例同時寫入時，實作須保證鏈不分岔
+	Append(ctx context.Context, e *model.AuditLog) error
+	// List 依 ID 遞增順序回傳符合條件的紀錄
+	List(ctx context.Context, filter AuditLogFilter) ([]*model.AuditLog, error)
+}
+
+// maxAuditUserAgentLen 對應 audit_logs.user_agent 欄位長度
+const maxAuditUserAgentLen = 512
+
+// truncateAuditLog 截斷超過欄位長度的內容，須在 Chain 之前呼叫，讓雜湊涵蓋實際保存的內容
+func truncateAuditLog(e *model.AuditLog) {
+	e.Reason = truncateError(e.Reason)
+	if len(e.UserAgent) > maxAuditUserAgentLen {
+		e.UserAgent = strings.ToValidUTF8(e.UserAgent[:maxAuditUserAgentLen], "")
+	}
+}
+
+// matchAuditLog 回傳紀錄是否符合條件 (不含分頁)，供非 SQL 的實作使用
+func matchAuditLog(e *model.AuditLog, filter AuditLogFilter) bool {
+	switch {
+	case e.ID <= filter.AfterID:
+		return false
+	case filter.Action != "" && e.Action != filter.Action:
+		return false
+	case filter.ActorID != "" && e.ActorID != filter.ActorID:
+		return false
+	case filter.TargetID != "" && e.TargetID != filter.TargetID:
+		return false
+	case !filter.Since.IsZero() && e.OccurredAt.Before(filter.Since):
+		return false
+	case !filter.Until.IsZero() && !e.OccurredAt.Before(filter.Until):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/logger"
+)
+
+// AuditLogJSONL 以 JSONL 檔 (每行一筆紀錄) 實作 AuditLogRepository，供 memory 模式使用
+// 每筆寫入後立即 fsync；開啟時載入既有紀錄以接續雜湊鏈並提供查詢
+type AuditLogJSONL struct {
+	mu      sync.Mutex
+	file    *os.File
+	entries []*model.AuditLog
+}
+
+// OpenAuditLogJSONL 開啟 (或建立) 稽核紀錄檔
+// 若最後一行不完整 (例如寫入途中當機)，會截斷該行並繼續；中間行損毀則視為錯誤
+func OpenAuditLogJSONL(path string) (*AuditLogJSONL, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("建立稽核紀錄目錄失敗: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("開啟稽核紀錄檔失敗: %w", err)
+	}
+
+	r := &AuditLogJSONL{file: f}
+	if err := r.load(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *AuditLogJSONL) load() error {
+	reader := bufio.NewReader(r.file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(bytes.TrimSpace(line)) > 0 {
+				logger.Logger.Warn("稽核紀錄檔結尾不完整，已截斷",
+					zap.Int64("offset", offset), zap.Int("bytes", len(line)))
+				if err := r.file.Truncate(offset); err != nil {
+					return fmt.Errorf("截斷稽核紀錄檔失敗: %w", err)
+				}
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("讀取稽核紀錄檔失敗: %w", err)
+		}
+
+		var e model.AuditLog
+		if err := json.Unmarshal(line, &e); err != nil {
+			return fmt.Errorf("稽核紀錄檔於位移 %d 損毀: %w", offset, err)
+		}
+		r.entries = append(r.entries, &e)
+		offset += int64(len(line))
+	}
+
+	if _, err := r.file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("定位稽核紀錄檔失敗: %w", err)
+	}
+	return nil
+}
+
+// Append 將紀錄接在鏈的最後後寫入並 fsync
+func (r *AuditLogJSONL) Append(ctx context.Context, e *model.AuditLog) error {
+	truncateAuditLog(e)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var prev *model.AuditLog
+	if n := len(r.entries); n > 0 {
+		prev = r.entries[n-1]
+	}
+	e.Chain(prev)
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("序列化稽核紀錄失敗: %w", err)
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("寫入稽核紀錄失敗: %w", err)
+	}
+	if err := r.file.Sync(); err != nil {
+		return fmt.Errorf("fsync 稽核紀錄失敗: %w", err)
+	}
+
+	stored := *e
+	r.entries = append(r.entries, &stored)
+	return nil
+}
+
+// List 查詢稽核紀錄
+func (r *AuditLogJSONL) List(ctx context.Context, filter AuditLogFilter) ([]*model.AuditLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []*model.AuditLog
+	for _, e := range r.entries {
+		if !matchAuditLog(e, filter) {
+			continue
+		}
+		copied := *e
+		result = append(result, &copied)
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Close 關閉稽核紀錄檔
+func (r *AuditLogJSONL) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
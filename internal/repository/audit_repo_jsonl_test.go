@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestAuditLogJSONL_ChainsAcrossRestart(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+
+	repo, err := repository.OpenAuditLogJSONL(path)
+	require.NoError(t, err)
+	for _, action := range []string{model.AuditActionLogin, model.AuditActionLoginFailed, model.AuditActionLogin} {
+		require.NoError(t, repo.Append(ctx, &model.AuditLog{Action: action, ActorType: model.AuditActorPlayer, ActorID: "7"}))
+	}
+	require.NoError(t, repo.Close())
+
+	// 模擬寫入途中當機留下的不完整行
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":4,"action":"auth.lo`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reopened, err := repository.OpenAuditLogJSONL(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	next := &model.AuditLog{Action: model.AuditActionRegister, ActorType: model.AuditActorPlayer, ActorID: "8"}
+	require.NoError(t, reopened.Append(ctx, next))
+
+	all, err := reopened.List(ctx, repository.AuditLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.EqualValues(t, 4, next.ID)
+	for i, e := range all {
+		assert.EqualValues(t, i+1, e.ID)
+		assert.Equal(t, e.ComputeHash(), e.Hash)
+		if i > 0 {
+			assert.Equal(t, all[i-1].Hash, e.PrevHash, "每筆紀錄應接在前一筆之後")
+		}
+	}
+
+	failed, err := reopened.List(ctx, repository.AuditLogFilter{Action: model.AuditActionLogin, AfterID: 1})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.EqualValues(t, 3, failed[0].ID)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/database"
+)
+
+// maxAuditAppendAttempts 是多個實例同時寫入、ID 衝突時重新接鏈的次數上限
+const maxAuditAppendAttempts = 10
+
+// auditLogRepositoryMySQL 使用 GORM 實作 AuditLogRepository
+// ID 由應用程式指定為前一筆加一，並以主鍵唯一性保證多個實例同時寫入時鏈不分岔：
+// 衝突的一方讀取新的最後一筆後重新接鏈
+type auditLogRepositoryMySQL struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepositoryMySQL 建立一個新的 auditLogRepositoryMySQL
+func NewAuditLogRepositoryMySQL(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepositoryMySQL{db: db}
+}
+
+// Append 將紀錄接在鏈的最後後寫入
+func (r *auditLogRepositoryMySQL) Append(ctx context.Context, e *model.AuditLog) error {
+	truncateAuditLog(e)
+	for attempt := 0; attempt < maxAuditAppendAttempts; attempt++ {
+		// 最後一筆一律從主庫讀取，唯讀副本的延遲會造成不必要的衝突
+		var last []*model.AuditLog
+		if err := database.WithContext(ctx).Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+			return fmt.Errorf("取得最後一筆稽核紀錄失敗: %w", err)
+		}
+		var prev *model.AuditLog
+		if len(last) > 0 {
+			prev = last[0]
+		}
+		e.Chain(prev)
+
+		err := database.WithContext(ctx).Create(e).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("寫入稽核紀錄失敗: %w", err)
+		}
+	}
+	return fmt.Errorf("寫入稽核紀錄失敗: 重試 %d 次後仍與其他寫入衝突", maxAuditAppendAttempts)
+}
+
+// List 查詢稽核紀錄
+func (r *auditLogRepositoryMySQL) List(ctx context.Context, filter AuditLogFilter) ([]*model.AuditLog, error) {
+	q := database.ReadContext(ctx).Where("id > ?", filter.AfterID)
+	if filter.Action != "" {
+		q = q.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != "" {
+		q = q.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetID != "" {
+		q = q.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.Since.IsZero() {
+		q = q.Where("occurred_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		q = q.Where("occurred_at < ?", filter.Until)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+
+	var logs []*model.AuditLog
+	if err := q.Order("id").Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("查詢稽核紀錄失敗: %w", err)
+	}
+	return logs, nil
+}
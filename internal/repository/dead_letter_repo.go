@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/apperrors"
+)
+
+// ErrDeadLetterNotFound 代表找不到死信，屬於 apperrors.ErrNotFound 分類
+var ErrDeadLetterNotFound = apperrors.NotFound("DEAD_LETTER_NOT_FOUND", "死信不存在")
+
+// DeadLetterFilter 是查詢死信的條件
+type DeadLetterFilter struct {
+	Topic   string // 原始 Topic，空字串代表全部
+	AfterID uint64 // 分頁游標：只回傳 ID 大於此值的死信
+	Limit   int
+}
+
+// DeadLetterRepository 定義死信的儲存操作
+type DeadLetterRepository interface {
+	SaveDeadLetter(ctx context.Context, d *model.DeadLetter) error
+	// ListDeadLetters 依 ID 遞增順序回傳符合條件的死信
+	ListDeadLetters(ctx context.Context, filter DeadLetterFilter) ([]*model.DeadLetter, error)
+	// GetDeadLetter 找不到時回傳 ErrDeadLetterNotFound
+	GetDeadLetter(ctx context.Context, id uint64) (*model.DeadLetter, error)
+	// DeleteDeadLetter 找不到時回傳 ErrDeadLetterNotFound
+	DeleteDeadLetter(ctx context.Context, id uint64) error
+	// PurgeDeadLetters 刪除指定 Topic (空字串代表全部) 的死信並回傳刪除筆數
+	PurgeDeadLetters(ctx context.Context, topic string) (int64, error)
+}
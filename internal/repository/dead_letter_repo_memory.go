@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"microservice-mvp/internal/model"
+)
+
+// deadLetterRepositoryMemory 使用 MemoryStore 實作 DeadLetterRepository
+type deadLetterRepositoryMemory struct {
+	store *MemoryStore
+}
+
+// SaveDeadLetter 儲存一則死信並回填 ID
+func (r *deadLetterRepositoryMemory) SaveDeadLetter(ctx context.Context, d *model.DeadLetter) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp := *d
+	cp.ID = s.nextDeadLetterID
+	cp.Reason = truncateError(cp.Reason)
+	if cp.CreatedAt.IsZero() {
+		cp.CreatedAt = time.Now()
+	}
+
+	if s.journal != nil {
+		rec := journalRecord{
+			Op:               opPutDeadLetter,
+			DeadLetter:       &cp,
+			NextID:           s.nextID,
+			NextOutboxID:     s.nextOutboxID,
+			NextDeadLetterID: cp.ID + 1,
+		}
+		if err := s.journal.append(rec); err != nil {
+			return fmt.Errorf("儲存死信失敗: %w", err)
+		}
+	}
+	s.applyPutDeadLetter(&cp)
+	d.ID = cp.ID
+	d.CreatedAt = cp.CreatedAt
+	return nil
+}
+
+// ListDeadLetters 依 ID 遞增順序查詢死信副本
+func (r *deadLetterRepositoryMemory) ListDeadLetters(ctx context.Context, filter DeadLetterFilter) ([]*model.DeadLetter, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var letters []*model.DeadLetter
+	for _, d := range s.deadLetters {
+		if d.ID <= filter.AfterID || (filter.Topic != "" && d.Topic != filter.Topic) {
+			continue
+		}
+		cp := *d
+		letters = append(letters, &cp)
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].ID < letters[j].ID })
+	if filter.Limit > 0 && len(letters) > filter.Limit {
+		letters = letters[:filter.Limit]
+	}
+	return letters, nil
+}
+
+// GetDeadLetter 根據 ID 取得死信副本
+func (r *deadLetterRepositoryMemory) GetDeadLetter(ctx context.Context, id uint64) (*model.DeadLetter, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	d, ok := s.deadLetters[id]
+	if !ok {
+		return nil, ErrDeadLetterNotFound
+	}
+	cp := *d
+	return &cp, nil
+}
+
+// DeleteDeadLetter 刪除一則死信
+func (r *deadLetterRepositoryMemory) DeleteDeadLetter(ctx context.Context, id uint64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.deadLetters[id]; !ok {
+		return ErrDeadLetterNotFound
+	}
+	if err := s.persistDeleteDeadLetters([]uint64{id}); err != nil {
+		return fmt.Errorf("刪除死信失敗: %w", err)
+	}
+	delete(s.deadLetters, id)
+	return nil
+}
+
+// PurgeDeadLetters 清除指定 Topic (空字串代表全部) 的死信
+func (r *deadLetterRepositoryMemory) PurgeDeadLetters(ctx context.Context, topic string) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []uint64
+	for id, d := range s.deadLetters {
+		if topic == "" || d.Topic == topic {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if err := s.persistDeleteDeadLetters(ids); err != nil {
+		return 0, fmt.Errorf("清除死信失敗: %w", err)
+	}
+	for _, id := range ids {
+		delete(s.deadLetters, id)
+	}
+	return int64(len(ids)), nil
+}
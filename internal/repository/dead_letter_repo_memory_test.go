@@ -0,0 +1,65 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestDeadLetterRepositoryMemory_PersistsAcrossRestart(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	cfg := configs.MemoryStoreConfig{DataDir: t.TempDir(), FsyncPolicy: repository.FsyncAlways}
+
+	store, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	repo := store.DeadLetters()
+	for _, topic := range []string{"orders", "bets", "orders"} {
+		require.NoError(t, repo.SaveDeadLetter(ctx, &model.DeadLetter{Topic: topic, Body: []byte{0xff, 0x00}, Reason: "boom"}))
+	}
+
+	letters, err := repo.ListDeadLetters(ctx, repository.DeadLetterFilter{Topic: "orders", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.EqualValues(t, 1, letters[0].ID)
+	assert.Equal(t, "base64", letters[0].ToDeadLetterResponse().BodyEncoding)
+
+	require.NoError(t, repo.DeleteDeadLetter(ctx, 1))
+	assert.ErrorIs(t, repo.DeleteDeadLetter(ctx, 1), repository.ErrDeadLetterNotFound)
+
+	// 不經快照直接重播 append log
+	reopened, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	repo = reopened.DeadLetters()
+
+	letters, err = repo.ListDeadLetters(ctx, repository.DeadLetterFilter{})
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.EqualValues(t, []uint64{2, 3}, []uint64{letters[0].ID, letters[1].ID})
+
+	purged, err := repo.PurgeDeadLetters(ctx, "orders")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, purged)
+	require.NoError(t, reopened.Close())
+
+	// 經快照還原，ID 計數器不會倒退
+	reopened, err = repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	defer reopened.Close()
+	repo = reopened.DeadLetters()
+
+	d := &model.DeadLetter{Topic: "orders"}
+	require.NoError(t, repo.SaveDeadLetter(ctx, d))
+	assert.EqualValues(t, 4, d.ID)
+	letters, err = repo.ListDeadLetters(ctx, repository.DeadLetterFilter{AfterID: 2})
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, d.ID, letters[0].ID)
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/database"
+)
+
+// deadLetterRepositoryMySQL 使用 GORM 實作 DeadLetterRepository
+type deadLetterRepositoryMySQL struct {
+	db *gorm.DB
+}
+
+// NewDeadLetterRepositoryMySQL 建立一個新的 deadLetterRepositoryMySQL
+func NewDeadLetterRepositoryMySQL(db *gorm.DB) DeadLetterRepository {
+	return &deadLetterRepositoryMySQL{db: db}
+}
+
+// SaveDeadLetter 儲存一則死信
+func (r *deadLetterRepositoryMySQL) SaveDeadLetter(ctx context.Context, d *model.DeadLetter) error {
+	d.Reason = truncateError(d.Reason)
+	if err := database.WithContext(ctx).Create(d).Error; err != nil {
+		return fmt.Errorf("儲存死信失敗: %w", err)
+	}
+	return nil
+}
+
+// ListDeadLetters 查詢死信
+func (r *deadLetterRepositoryMySQL) ListDeadLetters(ctx context.Context, filter DeadLetterFilter) ([]*model.DeadLetter, error) {
+	q := database.ReadContext(ctx).Where("id > ?", filter.AfterID)
+	if filter.Topic != "" {
+		q = q.Where("topic = ?", filter.Topic)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+
+	var letters []*model.DeadLetter
+	if err := q.Order("id").Find(&letters).Error; err != nil {
+		return nil, fmt.Errorf("查詢死信失敗: %w", err)
+	}
+	return letters, nil
+}
+
+// GetDeadLetter 根據 ID 取得死信
+func (r *deadLetterRepositoryMySQL) GetDeadLetter(ctx context.Context, id uint64) (*model.DeadLetter, error) {
+	var d model.DeadLetter
+	if err := database.ReadContext(ctx).First(&d, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, fmt.Errorf("取得死信失敗: %w", err)
+	}
+	return &d, nil
+}
+
+// DeleteDeadLetter 刪除一則死信
+func (r *deadLetterRepositoryMySQL) DeleteDeadLetter(ctx context.Context, id uint64) error {
+	result := database.WithContext(ctx).Delete(&model.DeadLetter{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("刪除死信失敗: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// PurgeDeadLetters 清除死信
+func (r *deadLetterRepositoryMySQL) PurgeDeadLetters(ctx context.Context, topic string) (int64, error) {
+	q := database.WithContext(ctx)
+	if topic != "" {
+		q = q.Where("topic = ?", topic)
+	} else {
+		q = q.Where("1 = 1") // GORM 預設禁止不帶條件的刪除
+	}
+	result := q.Delete(&model.DeadLetter{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("清除死信失敗: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,417 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/logger"
+)
+
+const (
+	journalFileName  = "journal.log"
+	snapshotFileName = "snapshot.json"
+	snapshotVersion  = 1
+
+	// FsyncAlways 代表每筆寫入後立即 fsync
+	FsyncAlways = "always"
+	// FsyncInterval 代表由背景工作定期 fsync
+	FsyncInterval = "interval"
+	// FsyncNever 代表不主動 fsync，交由作業系統決定何時寫回磁碟
+	FsyncNever = "never"
+)
+
+const (
+	opPutPlayer    = "put_player"
+	opPutOutbox    = "put_outbox"
+	opDeleteOutbox = "delete_outbox"
+
+	opPutDeadLetter     = "put_dead_letter"
+	opDeleteDeadLetters = "delete_dead_letters"
+
+	opPutProcessed    = "put_processed"
+	opDeleteProcessed = "delete_processed"
+
+	opPutWebhook            = "put_webhook"
+	opDeleteWebhookEndpoint = "delete_webhook_endpoint"
+)
+
+// persistedPlayer 是玩家的落地格式
+// model.Player 的 JSON 標籤會隱藏密碼，因此不能直接序列化
+type persistedPlayer struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password"`
+	Balance   float64   `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func toPersistedPlayer(p *model.Player) persistedPlayer {
+	return persistedPlayer{
+		ID:        p.ID,
+		Username:  p.Username,
+		Password:  p.Password,
+		Balance:   p.Balance,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func (p persistedPlayer) toModel() *model.Player {
+	return &model.Player{
+		ID:        p.ID,
+		Username:  p.Username,
+		Password:  p.Password,
+		Balance:   p.Balance,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// persistedWebhookEndpoint 與 persistedWebhookDelivery 是 webhook 的落地格式
+// 模型的 JSON 標籤會隱藏簽章金鑰與事件內容，因此以同名欄位覆蓋
+type persistedWebhookEndpoint struct {
+	model.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+type persistedWebhookDelivery struct {
+	model.WebhookDelivery
+	Payload []byte `json:"payload"`
+}
+
+func toPersistedWebhookEndpoint(e *model.WebhookEndpoint) persistedWebhookEndpoint {
+	return persistedWebhookEndpoint{WebhookEndpoint: *e, Secret: e.Secret}
+}
+
+func (p persistedWebhookEndpoint) toModel() *model.WebhookEndpoint {
+	e := p.WebhookEndpoint
+	e.Secret = p.Secret
+	return &e
+}
+
+func toPersistedWebhookDelivery(d *model.WebhookDelivery) persistedWebhookDelivery {
+	return persistedWebhookDelivery{WebhookDelivery: *d, Payload: d.Payload}
+}
+
+func (p persistedWebhookDelivery) toModel() *model.WebhookDelivery {
+	d := p.WebhookDelivery
+	d.Payload = p.Payload
+	return &d
+}
+
+// journalRecord 是 append log 中的一筆操作
+// NextID 與 NextOutboxID 記錄套用此操作後的 ID 計數器，重播時可精確還原
+// 玩家與同時產生的 outbox 事件寫在同一筆記錄中，重播時不會只還原其中一半
+type journalRecord struct {
+	Op           string              `json:"op"`
+	Player       *persistedPlayer    `json:"player,omitempty"`
+	Outbox       []model.OutboxEvent `json:"outbox,omitempty"`
+	OutboxID     uint64              `json:"outbox_id,omitempty"`
+	NextID       uint                `json:"next_id"`
+	NextOutboxID uint64              `json:"next_outbox_id,omitempty"`
+
+	DeadLetter       *model.DeadLetter `json:"dead_letter,omitempty"`
+	DeadLetterIDs    []uint64          `json:"dead_letter_ids,omitempty"`
+	NextDeadLetterID uint64            `json:"next_dead_letter_id,omitempty"`
+
+	Processed []model.ProcessedMessage `json:"processed,omitempty"`
+
+	// 端點與其投遞紀錄寫在同一筆記錄中，投遞結果與連續失敗次數一起還原
+	WebhookEndpoint       *persistedWebhookEndpoint  `json:"webhook_endpoint,omitempty"`
+	WebhookEndpointID     uint64                     `json:"webhook_endpoint_id,omitempty"`
+	WebhookDeliveries     []persistedWebhookDelivery `json:"webhook_deliveries,omitempty"`
+	NextWebhookEndpointID uint64                     `json:"next_webhook_endpoint_id,omitempty"`
+	NextWebhookDeliveryID uint64                     `json:"next_webhook_delivery_id,omitempty"`
+}
+
+// memorySnapshot 是壓縮後的完整狀態
+type memorySnapshot struct {
+	Version int               `json:"version"`
+	TakenAt time.Time         `json:"taken_at"`
+	NextID  uint              `json:"next_id"`
+	Players []persistedPlayer `json:"players"`
+
+	NextOutboxID uint64              `json:"next_outbox_id,omitempty"`
+	Outbox       []model.OutboxEvent `json:"outbox,omitempty"` // 尚未發送 (或已放棄) 的 outbox 事件
+
+	NextDeadLetterID uint64             `json:"next_dead_letter_id,omitempty"`
+	DeadLetters      []model.DeadLetter `json:"dead_letters,omitempty"`
+
+	Processed []model.ProcessedMessage `json:"processed,omitempty"` // Consumer 去重用的處理紀錄
+
+	NextWebhookEndpointID uint64                     `json:"next_webhook_endpoint_id,omitempty"`
+	NextWebhookDeliveryID uint64                     `json:"next_webhook_delivery_id,omitempty"`
+	WebhookEndpoints      []persistedWebhookEndpoint `json:"webhook_endpoints,omitempty"`
+	WebhookDeliveries     []persistedWebhookDelivery `json:"webhook_deliveries,omitempty"`
+}
+
+// memoryJournal 管理落地目錄中的 append log 與快照檔
+type memoryJournal struct {
+	dir    string
+	policy string
+
+	mu     sync.Mutex
+	file   *os.File
+	dirty  bool  // 是否有尚未 fsync 的寫入
+	ops    int   // 上次快照後的操作筆數
+	failed error // 寫入失敗且無法復原時的錯誤，非 nil 時拒絕後續寫入，直到快照成功重寫落地資料
+}
+
+func openMemoryJournal(dir, policy string) (*memoryJournal, error) {
+	switch policy {
+	case "":
+		policy = FsyncInterval
+	case FsyncAlways, FsyncInterval, FsyncNever:
+	default:
+		return nil, fmt.Errorf("無效的 fsync 策略: %s", policy)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("建立落地目錄失敗: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, journalFileName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("開啟 append log 失敗: %w", err)
+	}
+	return &memoryJournal{dir: dir, policy: policy, file: f}, nil
+}
+
+// loadSnapshot 讀取快照檔，若不存在則回傳 nil
+func (j *memoryJournal) loadSnapshot() (*memorySnapshot, error) {
+	data, err := os.ReadFile(filepath.Join(j.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("讀取快照失敗: %w", err)
+	}
+
+	var snap memorySnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("解析快照失敗: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("不支援的快照版本: %d", snap.Version)
+	}
+	return &snap, nil
+}
+
+// replay 依序讀取 append log 並呼叫 apply
+// 若最後一行不完整 (例如寫入途中當機)，會截斷該行並繼續；中間行損毀則視為錯誤
+func (j *memoryJournal) replay(apply func(rec journalRecord)) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("定位 append log 失敗: %w", err)
+	}
+
+	reader := bufio.NewReader(j.file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(bytes.TrimSpace(line)) > 0 {
+				logger.Logger.Warn("append log 結尾不完整，已截斷",
+					zap.Int64("offset", offset), zap.Int("bytes", len(line)))
+				if err := j.file.Truncate(offset); err != nil {
+					return fmt.Errorf("截斷 append log 失敗: %w", err)
+				}
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("讀取 append log 失敗: %w", err)
+		}
+
+		var rec journalRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("append log 於位移 %d 損毀: %w", offset, err)
+		}
+		apply(rec)
+		offset += int64(len(line))
+		j.ops++
+	}
+
+	if _, err := j.file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("定位 append log 失敗: %w", err)
+	}
+	return nil
+}
+
+// append 寫入一筆操作，呼叫端須持有 MemoryStore 的寫鎖以確保順序
+func (j *memoryJournal) append(rec journalRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("序列化 append log 記錄失敗: %w", err)
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.failed != nil {
+		return fmt.Errorf("append log 已停止寫入: %w", j.failed)
+	}
+	offset, err := j.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("定位 append log 失敗: %w", err)
+	}
+	if _, err := j.file.Write(line); err != nil {
+		// 部分寫入會留下不完整的一行，之後的記錄接在其後會讓重播失敗，因此退回寫入前的位移
+		j.rollback(offset, err)
+		return fmt.Errorf("寫入 append log 失敗: %w", err)
+	}
+	j.ops++
+	if j.policy == FsyncAlways {
+		if err := j.file.Sync(); err != nil {
+			// fsync 失敗後無法確定資料是否已落地，呼叫端不會套用這筆操作，因此同樣退回
+			// 作業系統可能已丟棄尚未寫回的頁面，之後的 fsync 也不可信，一律停止寫入
+			j.ops--
+			j.rollback(offset, err)
+			j.failed = fmt.Errorf("fsync append log 失敗: %w", err)
+			return j.failed
+		}
+		return nil
+	}
+	j.dirty = true
+	return nil
+}
+
+// rollback 將 append log 截斷回 offset，失敗時停止後續寫入；呼叫端須持有 j.mu
+func (j *memoryJournal) rollback(offset int64, cause error) {
+	err := j.file.Truncate(offset)
+	if err == nil {
+		_, err = j.file.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		j.failed = fmt.Errorf("寫入 append log 失敗 (%v) 且無法截斷不完整的記錄: %w", cause, err)
+		logger.Logger.Error("append log 無法復原，停止寫入", zap.Error(j.failed), zap.Int64("offset", offset))
+	}
+}
+
+// err 回傳停止寫入的原因，正常時為 nil
+func (j *memoryJournal) err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.failed
+}
+
+// sync 將尚未落地的寫入 fsync 到磁碟
+func (j *memoryJournal) sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if !j.dirty {
+		return nil
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("fsync append log 失敗: %w", err)
+	}
+	j.dirty = false
+	return nil
+}
+
+// pendingOps 回傳上次快照後的操作筆數
+func (j *memoryJournal) pendingOps() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.ops
+}
+
+// writeSnapshot 原子地寫入快照 (暫存檔 + rename)，成功後截斷 append log
+// 呼叫端須持有 MemoryStore 的寫鎖，避免快照與截斷之間有新的寫入
+// 若在 rename 與截斷之間當機，重播時會重複套用舊操作；由於 put 操作具冪等性，結果不變
+func (j *memoryJournal) writeSnapshot(snap *memorySnapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("序列化快照失敗: %w", err)
+	}
+
+	tmpPath := filepath.Join(j.dir, snapshotFileName+".tmp")
+	if err := writeFileSync(tmpPath, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, filepath.Join(j.dir, snapshotFileName)); err != nil {
+		return fmt.Errorf("替換快照檔失敗: %w", err)
+	}
+	if err := syncDir(j.dir); err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("截斷 append log 失敗: %w", err)
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("定位 append log 失敗: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("fsync append log 失敗: %w", err)
+	}
+	// 快照已包含所有成功的操作，append log 也已清空，可恢復寫入
+	j.failed = nil
+	j.dirty = false
+	j.ops = 0
+	return nil
+}
+
+func (j *memoryJournal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.file.Sync(); err != nil {
+		_ = j.file.Close()
+		return fmt.Errorf("fsync append log 失敗: %w", err)
+	}
+	return j.file.Close()
+}
+
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("建立快照暫存檔失敗: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("寫入快照暫存檔失敗: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("fsync 快照暫存檔失敗: %w", err)
+	}
+	return f.Close()
+}
+
+// syncDir 對目錄 fsync，確保 rename 本身已落地
+// Windows 不支援對目錄 fsync (rename 由檔案系統自行保證)，因此略過
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("開啟落地目錄失敗: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("fsync 落地目錄失敗: %w", err)
+	}
+	return nil
+}
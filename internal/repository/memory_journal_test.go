@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/pkg/logger"
+)
+
+func TestMemoryJournal_AppendFailureStopsWrites(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+
+	dir := t.TempDir()
+	j, err := openMemoryJournal(dir, FsyncAlways)
+	require.NoError(t, err)
+	defer j.close()
+
+	require.NoError(t, j.append(journalRecord{Op: opPutPlayer, NextID: 2}))
+
+	// 以唯讀檔案模擬寫入失敗，且無法截斷回寫入前的位移
+	good := j.file
+	readOnly, err := os.Open(filepath.Join(dir, journalFileName))
+	require.NoError(t, err)
+	defer readOnly.Close()
+	_, err = readOnly.Seek(0, io.SeekEnd)
+	require.NoError(t, err)
+	j.file = readOnly
+
+	require.Error(t, j.append(journalRecord{Op: opPutPlayer, NextID: 3}))
+	require.Error(t, j.err(), "無法復原時應停止寫入")
+
+	j.file = good
+	err = j.append(journalRecord{Op: opPutPlayer, NextID: 4})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "已停止寫入")
+
+	// 快照重寫落地資料後恢復寫入
+	require.NoError(t, j.writeSnapshot(&memorySnapshot{Version: snapshotVersion, NextID: 2}))
+	require.NoError(t, j.err())
+	require.NoError(t, j.append(journalRecord{Op: opPutPlayer, NextID: 5}))
+
+	var replayed []uint
+	require.NoError(t, j.replay(func(rec journalRecord) { replayed = append(replayed, rec.NextID) }))
+	assert.Equal(t, []uint{5}, replayed)
+}
@@ -3,21 +3,30 @@ package mocks
 import (
 	"context"
 	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
 
 	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
 // MockPlayerRepository is a mock implementation of repository.PlayerRepository
+// Its results are scripted by each test, so it is not run against repositorytest.RunPlayerRepositorySuite
 type MockPlayerRepository struct {
 	mock.Mock
 }
 
+var _ repository.PlayerRepository = (*MockPlayerRepository)(nil)
+
 func (m *MockPlayerRepository) CreatePlayer(ctx context.Context, player *model.Player) error {
 	args := m.Called(ctx, player)
 	return args.Error(0)
 }
 
+func (m *MockPlayerRepository) CreatePlayerWithEvents(ctx context.Context, player *model.Player, events repository.OutboxEventsFunc) error {
+	args := m.Called(ctx, player, events)
+	return args.Error(0)
+}
+
 func (m *MockPlayerRepository) GetPlayerByUsername(ctx context.Context, username string) (*model.Player, error) {
 	args := m.Called(ctx, username)
 	if args.Get(0) == nil {
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/events"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/tracing"
+)
+
+// OutboxEventsFunc 在業務資料寫入後 (已取得自動產生的 ID) 建立要一併寫入 outbox 的事件
+// 回傳錯誤時整筆寫入會被取消
+type OutboxEventsFunc func(player *model.Player) ([]*model.OutboxEvent, error)
+
+// OutboxStats 是 outbox 積壓狀況的統計，供 lag 指標使用
+type OutboxStats struct {
+	Pending         int64      // 尚未發送的事件數
+	Failed          int64      // 超過最大嘗試次數的事件數
+	OldestPendingAt *time.Time // 最舊的未發送事件建立時間，沒有積壓時為 nil
+}
+
+// OutboxRepository 定義 outbox 事件的寫入、讀取與狀態更新
+// 伴隨業務資料的事件由業務 Repository 在同一交易 (或同一臨界區) 中寫入，見 PlayerRepository.CreatePlayerWithEvents
+type OutboxRepository interface {
+	// Enqueue 寫入沒有伴隨業務資料變更的事件 (例如登入)，全部寫入或全部不寫入，成功後事件會帶上產生的 ID
+	Enqueue(ctx context.Context, events []*model.OutboxEvent) error
+	// ClaimDue 依建立順序認領 NextAttemptAt <= now 且未被認領 (或認領已逾期) 的待發送事件，認領於 lease 後逾期
+	// 多個實例同時執行 relay 時，同一事件在認領期間只會由一個實例發送
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.OutboxEvent, error)
+	// Release 釋放未處理的認領，讓事件可在下次輪詢時再被認領
+	Release(ctx context.Context, ids []uint64) error
+	// MarkSent 將事件標記為已發送並釋放認領
+	MarkSent(ctx context.Context, id uint64, sentAt time.Time) error
+	// MarkFailed 記錄一次發送失敗並釋放認領；giveUp 為 true 時事件轉為 failed，不再重試
+	MarkFailed(ctx context.Context, id uint64, nextAttemptAt time.Time, lastErr string, giveUp bool) error
+	// Stats 回傳目前的積壓統計
+	Stats(ctx context.Context) (OutboxStats, error)
+}
+
+// NewOutboxEvent 建立一筆待發送的事件，payload 以 JSON 序列化，並帶上上下文中的 trace ID 與 span
+func NewOutboxEvent(ctx context.Context, topic, tag, key string, payload any) (*model.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化 outbox 事件失敗: %w", err)
+	}
+	now := time.Now()
+	return &model.OutboxEvent{
+		Topic:         topic,
+		Tag:           tag,
+		Key:           key,
+		Payload:       data,
+		ContentType:   events.ContentTypeJSON,
+		TraceID:       logger.TraceIDFromContext(ctx),
+		TraceParent:   tracing.TraceParent(ctx),
+		Status:        model.OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+// NewEnvelopeOutboxEvent 將領域事件信封編碼後建立 outbox 事件
+// Topic 取自事件目錄，標籤為事件類型，Key 為聚合 ID，並帶上上下文中的 span
+func NewEnvelopeOutboxEvent(ctx context.Context, registry *events.Registry, env *events.Envelope, codec events.Codec) (*model.OutboxEvent, error) {
+	topic, err := registry.Topic(env.Type)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := codec.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("編碼 outbox 事件失敗: %w", err)
+	}
+	now := time.Now()
+	return &model.OutboxEvent{
+		Topic:         topic,
+		Tag:           env.Type,
+		Key:           env.AggregateID,
+		Payload:       payload,
+		ContentType:   codec.ContentType(),
+		TraceID:       env.TraceID,
+		TraceParent:   tracing.TraceParent(ctx),
+		Status:        model.OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+// truncateError 限制錯誤訊息長度以符合 last_error 欄位
+func truncateError(msg string) string {
+	const maxLen = 1024
+	if len(msg) <= maxLen {
+		return msg
+	}
+	return strings.ToValidUTF8(msg[:maxLen], "")
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"microservice-mvp/internal/model"
+)
+
+// outboxRepositoryMemory 使用 MemoryStore 實作 OutboxRepository
+// 記憶體模式不保留已發送的事件：MarkSent 會直接移除該事件，避免記憶體與快照無限成長
+type outboxRepositoryMemory struct {
+	store *MemoryStore
+}
+
+// Enqueue 在同一筆 append log 記錄中寫入事件
+func (r *outboxRepositoryMemory) Enqueue(ctx context.Context, events []*model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	evs := make([]model.OutboxEvent, len(events))
+	for i, ev := range events {
+		evs[i] = *ev
+		evs[i].ID = s.nextOutboxID + uint64(i)
+	}
+	next := s.nextOutboxID + uint64(len(evs))
+
+	// 先寫入 append log，成功後才更新記憶體狀態
+	if s.journal != nil {
+		rec := journalRecord{Op: opPutOutbox, Outbox: evs, NextID: s.nextID, NextOutboxID: next}
+		if err := s.journal.append(rec); err != nil {
+			return fmt.Errorf("寫入 outbox 事件失敗: %w", err)
+		}
+	}
+	s.nextOutboxID = next
+	for i := range evs {
+		s.applyPutOutbox(&evs[i])
+		events[i].ID = evs[i].ID
+	}
+	return nil
+}
+
+// ClaimDue 依 ID 順序認領到期的待發送事件並回傳副本
+// 認領只保存在記憶體中，不寫入 append log 與快照，重啟後所有事件都可再被認領
+func (r *outboxRepositoryMemory) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.OutboxEvent, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*model.OutboxEvent
+	for _, ev := range s.outbox {
+		if ev.Status == model.OutboxStatusPending && !ev.NextAttemptAt.After(now) &&
+			(ev.ClaimedUntil == nil || !ev.ClaimedUntil.After(now)) {
+			due = append(due, ev)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	until := now.Add(lease)
+	claimed := make([]*model.OutboxEvent, 0, len(due))
+	for _, ev := range due {
+		updated := *ev
+		updated.ClaimedUntil = &until
+		s.outbox[ev.ID] = &updated
+		cp := updated
+		claimed = append(claimed, &cp)
+	}
+	return claimed, nil
+}
+
+// Release 釋放未處理的認領
+func (r *outboxRepositoryMemory) Release(ctx context.Context, ids []uint64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		if ev, ok := s.outbox[id]; ok {
+			updated := *ev
+			updated.ClaimedUntil = nil
+			s.outbox[id] = &updated
+		}
+	}
+	return nil
+}
+
+// MarkSent 移除已發送的事件
+func (r *outboxRepositoryMemory) MarkSent(ctx context.Context, id uint64, sentAt time.Time) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.outbox[id]; !ok {
+		return nil
+	}
+	if s.journal != nil {
+		if err := s.journal.append(journalRecord{Op: opDeleteOutbox, OutboxID: id, NextID: s.nextID, NextOutboxID: s.nextOutboxID}); err != nil {
+			return fmt.Errorf("標記 outbox 事件已發送失敗: %w", err)
+		}
+	}
+	delete(s.outbox, id)
+	return nil
+}
+
+// MarkFailed 記錄一次發送失敗並排定下次嘗試時間
+func (r *outboxRepositoryMemory) MarkFailed(ctx context.Context, id uint64, nextAttemptAt time.Time, lastErr string, giveUp bool) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ev, ok := s.outbox[id]
+	if !ok {
+		return nil
+	}
+	updated := *ev
+	updated.Attempts++
+	updated.NextAttemptAt = nextAttemptAt
+	updated.LastError = truncateError(lastErr)
+	updated.ClaimedUntil = nil
+	if giveUp {
+		updated.Status = model.OutboxStatusFailed
+	}
+
+	if s.journal != nil {
+		rec := journalRecord{Op: opPutOutbox, Outbox: []model.OutboxEvent{updated}, NextID: s.nextID, NextOutboxID: s.nextOutboxID}
+		if err := s.journal.append(rec); err != nil {
+			return fmt.Errorf("記錄 outbox 事件發送失敗: %w", err)
+		}
+	}
+	s.outbox[id] = &updated
+	return nil
+}
+
+// Stats 回傳目前的積壓統計
+func (r *outboxRepositoryMemory) Stats(ctx context.Context) (OutboxStats, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stats OutboxStats
+	for _, ev := range s.outbox {
+		switch ev.Status {
+		case model.OutboxStatusPending:
+			stats.Pending++
+			if stats.OldestPendingAt == nil || ev.CreatedAt.Before(*stats.OldestPendingAt) {
+				created := ev.CreatedAt
+				stats.OldestPendingAt = &created
+			}
+		case model.OutboxStatusFailed:
+			stats.Failed++
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,193 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func createPlayerWithEvent(t *testing.T, ctx context.Context, repo repository.PlayerRepository, username string) *model.Player {
+	t.Helper()
+	player := &model.Player{Username: username, Password: "secret"}
+	require.NoError(t, repo.CreatePlayerWithEvents(ctx, player, func(p *model.Player) ([]*model.OutboxEvent, error) {
+		ev, err := repository.NewOutboxEvent(ctx, "player_events", "registered", p.Username, map[string]uint{"player_id": p.ID})
+		return []*model.OutboxEvent{ev}, err
+	}))
+	return player
+}
+
+func TestOutboxRepositoryMemory_Lifecycle(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := logger.WithTraceID(context.Background(), "trace-123")
+
+	store := repository.NewMemoryStore()
+	outbox := store.Outbox()
+	createPlayerWithEvent(t, ctx, store.Players(), "alice")
+	createPlayerWithEvent(t, ctx, store.Players(), "bob")
+
+	due, err := outbox.ClaimDue(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Less(t, due[0].ID, due[1].ID, "事件應依建立順序取出")
+	assert.Equal(t, "trace-123", due[0].TraceID)
+	assert.JSONEq(t, `{"player_id":1}`, string(due[0].Payload))
+
+	stats, err := outbox.Stats(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, stats.Pending)
+	require.NotNil(t, stats.OldestPendingAt)
+
+	// 發送成功的事件不再出現
+	require.NoError(t, outbox.MarkSent(ctx, due[0].ID, time.Now()))
+	// 發送失敗的事件在退避時間到之前不會被取出
+	require.NoError(t, outbox.MarkFailed(ctx, due[1].ID, time.Now().Add(time.Hour), "broker down", false))
+
+	due, err = outbox.ClaimDue(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = outbox.ClaimDue(ctx, time.Now().Add(2*time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, "broker down", due[0].LastError)
+
+	require.NoError(t, outbox.MarkFailed(ctx, due[0].ID, time.Now(), "broker down", true))
+	stats, err = outbox.Stats(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, stats.Pending)
+	assert.EqualValues(t, 1, stats.Failed)
+	assert.Nil(t, stats.OldestPendingAt)
+}
+
+func TestOutboxRepositoryMemory_Enqueue(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	cfg := configs.MemoryStoreConfig{DataDir: t.TempDir(), FsyncPolicy: repository.FsyncAlways}
+
+	store, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	createPlayerWithEvent(t, ctx, store.Players(), "alice")
+
+	var evs []*model.OutboxEvent
+	for _, key := range []string{"1", "2"} {
+		ev, err := repository.NewOutboxEvent(ctx, "player_events", "logged_in", key, map[string]string{"player_id": key})
+		require.NoError(t, err)
+		evs = append(evs, ev)
+	}
+	require.NoError(t, store.Outbox().Enqueue(ctx, evs))
+	assert.Equal(t, uint64(2), evs[0].ID, "ID 應接續 CreatePlayerWithEvents 寫入的事件")
+	assert.Equal(t, uint64(3), evs[1].ID)
+	require.NoError(t, store.Close())
+
+	// 重啟後事件與 ID 計數器都應還原
+	reopened, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	due, err := reopened.Outbox().ClaimDue(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 3)
+	assert.Equal(t, "logged_in", due[1].Tag)
+	assert.Equal(t, "2", due[2].Key)
+
+	next, err := repository.NewOutboxEvent(ctx, "player_events", "logged_in", "3", nil)
+	require.NoError(t, err)
+	require.NoError(t, reopened.Outbox().Enqueue(ctx, []*model.OutboxEvent{next}))
+	assert.Equal(t, uint64(4), next.ID)
+}
+
+func TestOutboxRepositoryMemory_ClaimDue(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+
+	store := repository.NewMemoryStore()
+	outbox := store.Outbox()
+	for _, name := range []string{"alice", "bob", "carol"} {
+		createPlayerWithEvent(t, ctx, store.Players(), name)
+	}
+
+	// 兩個 relay 實例的認領不重疊
+	now := time.Now()
+	first, err := outbox.ClaimDue(ctx, now, time.Minute, 2)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	second, err := outbox.ClaimDue(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.Greater(t, second[0].ID, first[1].ID)
+
+	none, err := outbox.ClaimDue(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	// 釋放的事件立即可再被認領
+	require.NoError(t, outbox.Release(ctx, []uint64{first[1].ID}))
+	released, err := outbox.ClaimDue(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, released, 1)
+	assert.Equal(t, first[1].ID, released[0].ID)
+
+	// 認領逾期 (例如實例崩潰) 後由其他實例接手
+	expired, err := outbox.ClaimDue(ctx, now.Add(time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, expired, 3)
+}
+
+func TestOutboxRepositoryMemory_Persistence(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+
+	for _, graceful := range []bool{true, false} {
+		name := "CrashReplayLog"
+		if graceful {
+			name = "GracefulRestart"
+		}
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := configs.MemoryStoreConfig{DataDir: t.TempDir(), FsyncPolicy: repository.FsyncAlways}
+
+			store, err := repository.OpenMemoryStore(cfg)
+			require.NoError(t, err)
+			createPlayerWithEvent(t, ctx, store.Players(), "alice")
+			createPlayerWithEvent(t, ctx, store.Players(), "bob")
+
+			due, err := store.Outbox().ClaimDue(ctx, time.Now(), time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, due, 2)
+			require.NoError(t, store.Outbox().MarkSent(ctx, due[0].ID, time.Now()))
+			require.NoError(t, store.Outbox().MarkFailed(ctx, due[1].ID, time.Now(), "broker down", false))
+
+			if graceful {
+				require.NoError(t, store.Close())
+			} else {
+				t.Cleanup(func() { _ = store.Close() })
+			}
+
+			reopened, err := repository.OpenMemoryStore(cfg)
+			require.NoError(t, err)
+			defer reopened.Close()
+
+			remaining, err := reopened.Outbox().ClaimDue(ctx, time.Now(), time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, remaining, 1)
+			assert.Equal(t, due[1].ID, remaining[0].ID)
+			assert.Equal(t, 1, remaining[0].Attempts)
+
+			// outbox ID 必須延續
+			createPlayerWithEvent(t, ctx, reopened.Players(), "carol")
+			// 認領逾期後可再次認領
+			all, err := reopened.Outbox().ClaimDue(ctx, time.Now().Add(time.Minute), time.Minute, 10)
+			require.NoError(t, err)
+			require.Len(t, all, 2)
+			assert.Greater(t, all[1].ID, due[1].ID)
+		})
+	}
+}
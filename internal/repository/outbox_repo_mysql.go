@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/database"
+)
+
+// outboxRepositoryMySQL 使用 GORM 實作 OutboxRepository
+// outbox 的讀取一律走主庫，避免副本延遲造成重複發送或積壓統計失真
+type outboxRepositoryMySQL struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepositoryMySQL 建立一個新的 outboxRepositoryMySQL
+func NewOutboxRepositoryMySQL(db *gorm.DB) OutboxRepository {
+	return &outboxRepositoryMySQL{db: db}
+}
+
+// Enqueue 以單一 INSERT 寫入事件
+func (r *outboxRepositoryMySQL) Enqueue(ctx context.Context, events []*model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := database.WithContext(ctx).Create(&events).Error; err != nil {
+		return fmt.Errorf("寫入 outbox 事件失敗: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue 以單一 UPDATE 認領到期的待發送事件，再讀取本次認領的事件
+// UPDATE 會鎖定並重新檢查符合條件的資料列，兩個實例同時認領時不會取得相同的事件
+func (r *outboxRepositoryMySQL) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.OutboxEvent, error) {
+	token := uuid.NewString()
+	err := database.WithContext(ctx).Model(&model.OutboxEvent{}).
+		Where("status = ? AND next_attempt_at <= ?", model.OutboxStatusPending, now).
+		Where("claimed_until IS NULL OR claimed_until <= ?", now).
+		Order("id").
+		Limit(limit).
+		Updates(map[string]any{"claimed_by": token, "claimed_until": now.Add(lease)}).Error
+	if err != nil {
+		return nil, fmt.Errorf("認領待發送的 outbox 事件失敗: %w", err)
+	}
+
+	var events []*model.OutboxEvent
+	err = database.WithContext(ctx).
+		Where("claimed_by = ?", token).
+		Order("id").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("讀取已認領的 outbox 事件失敗: %w", err)
+	}
+	return events, nil
+}
+
+// Release 釋放未處理的認領
+func (r *outboxRepositoryMySQL) Release(ctx context.Context, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := database.WithContext(ctx).Model(&model.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]any{"claimed_by": "", "claimed_until": nil}).Error
+	if err != nil {
+		return fmt.Errorf("釋放 outbox 事件的認領失敗: %w", err)
+	}
+	return nil
+}
+
+// MarkSent 將事件標記為已發送
+func (r *outboxRepositoryMySQL) MarkSent(ctx context.Context, id uint64, sentAt time.Time) error {
+	err := database.WithContext(ctx).Model(&model.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"status": model.OutboxStatusSent, "sent_at": sentAt, "last_error": "", "claimed_by": "", "claimed_until": nil}).Error
+	if err != nil {
+		return fmt.Errorf("標記 outbox 事件已發送失敗: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed 記錄一次發送失敗並排定下次嘗試時間
+func (r *outboxRepositoryMySQL) MarkFailed(ctx context.Context, id uint64, nextAttemptAt time.Time, lastErr string, giveUp bool) error {
+	status := model.OutboxStatusPending
+	if giveUp {
+		status = model.OutboxStatusFailed
+	}
+	err := database.WithContext(ctx).Model(&model.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":          status,
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      truncateError(lastErr),
+			"claimed_by":      "",
+			"claimed_until":   nil,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("記錄 outbox 事件發送失敗: %w", err)
+	}
+	return nil
+}
+
+// Stats 回傳目前的積壓統計
+func (r *outboxRepositoryMySQL) Stats(ctx context.Context) (OutboxStats, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+		Oldest *time.Time
+	}
+	err := database.WithContext(ctx).Model(&model.OutboxEvent{}).
+		Select("status, COUNT(*) AS count, MIN(created_at) AS oldest").
+		Where("status IN ?", []string{model.OutboxStatusPending, model.OutboxStatusFailed}).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return OutboxStats{}, fmt.Errorf("統計 outbox 積壓失敗: %w", err)
+	}
+
+	var stats OutboxStats
+	for _, row := range rows {
+		switch row.Status {
+		case model.OutboxStatusPending:
+			stats.Pending = row.Count
+			stats.OldestPendingAt = row.Oldest
+		case model.OutboxStatusFailed:
+			stats.Failed = row.Count
+		}
+	}
+	return stats, nil
+}
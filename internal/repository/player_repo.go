@@ -2,13 +2,27 @@ package repository
 
 import (
 	"context"
+
 	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/apperrors"
+)
+
+var (
+	// ErrDuplicateUsername 代表使用者名稱已被其他玩家使用，所有實作都必須回傳可被 errors.Is 判斷的此錯誤
+	// 它同時屬於 apperrors.ErrConflict 分類
+	ErrDuplicateUsername = apperrors.Conflict("USERNAME_TAKEN", "使用者名稱已存在")
+	// ErrPlayerNotFound 代表找不到玩家，屬於 apperrors.ErrNotFound 分類
+	// 查詢找不到玩家時，實作可回傳 (nil, nil) 或可被 errors.Is 判斷的此錯誤
+	ErrPlayerNotFound = apperrors.NotFound("PLAYER_NOT_FOUND", "玩家不存在")
 )
 
 // PlayerRepository 定義玩家資料操作的介面
 // 此介面允許切換不同的儲存實作（例如 MySQL, In-Memory）
 type PlayerRepository interface {
 	CreatePlayer(ctx context.Context, player *model.Player) error
+	// CreatePlayerWithEvents 建立玩家並在同一交易中寫入 events 產生的 outbox 事件，兩者同時成功或同時失敗
+	CreatePlayerWithEvents(ctx context.Context, player *model.Player, events OutboxEventsFunc) error
+	// GetPlayerByUsername 找不到時回傳 nil, nil；名稱的比對規則依實作而定 (記憶體為 NFKC_Casefold，MySQL 為欄位的 collation)
 	GetPlayerByUsername(ctx context.Context, username string) (*model.Player, error)
 	GetPlayerByID(ctx context.Context, id uint) (*model.Player, error)
-}
\ No newline at end of file
+}
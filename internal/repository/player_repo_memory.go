@@ -3,73 +3,530 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"go.uber.org/zap"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+
 	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
 )
 
+// MemoryStore 是 In-Memory 模式的資料儲存
+// 可選擇將每筆寫入追加到本機 append log，並定期壓縮成快照，重啟時重播以還原資料
+type MemoryStore struct {
+	mu        sync.RWMutex
+	players   map[uint]*model.Player
+	usernames map[string]uint // 正規化後的使用者名稱 -> 玩家 ID，與 players 在同一把鎖下維護
+	nextID    uint
+
+	outbox       map[uint64]*model.OutboxEvent // 尚未發送 (或已放棄) 的 outbox 事件，與玩家資料在同一把鎖下寫入
+	nextOutboxID uint64
+
+	deadLetters      map[uint64]*model.DeadLetter // 超過重試上限或無法處理的訊息
+	nextDeadLetterID uint64
+
+	processed  map[string]model.ProcessedMessage // (consumer group, 訊息 ID) -> 處理紀錄
+	processing map[string]struct{}               // 處理中的訊息，不落地
+
+	webhookEndpoints      map[uint64]*model.WebhookEndpoint
+	webhookDeliveries     map[uint64]*model.WebhookDelivery
+	nextWebhookEndpointID uint64
+	nextWebhookDeliveryID uint64
+
+	journal *memoryJournal // nil 代表不落地
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewMemoryStore 建立一個不落地的 MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		players:   make(map[uint]*model.Player),
+		usernames: make(map[string]uint),
+		nextID:    1,
+
+		outbox:       make(map[uint64]*model.OutboxEvent),
+		nextOutboxID: 1,
+
+		deadLetters:      make(map[uint64]*model.DeadLetter),
+		nextDeadLetterID: 1,
+
+		processed:  make(map[string]model.ProcessedMessage),
+		processing: make(map[string]struct{}),
+
+		webhookEndpoints:      make(map[uint64]*model.WebhookEndpoint),
+		webhookDeliveries:     make(map[uint64]*model.WebhookDelivery),
+		nextWebhookEndpointID: 1,
+		nextWebhookDeliveryID: 1,
+	}
+}
+
+// OpenMemoryStore 根據配置建立 MemoryStore
+// 若設定了 DataDir，會先載入快照並重播 append log，再啟動背景 fsync 與快照工作
+func OpenMemoryStore(cfg configs.MemoryStoreConfig) (*MemoryStore, error) {
+	s := NewMemoryStore()
+	if cfg.DataDir == "" {
+		return s, nil
+	}
+
+	journal, err := openMemoryJournal(cfg.DataDir, cfg.FsyncPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := journal.loadSnapshot()
+	if err != nil {
+		_ = journal.close()
+		return nil, err
+	}
+	if snap != nil {
+		for _, p := range snap.Players {
+			s.applyPut(p.toModel())
+		}
+		if snap.NextID > s.nextID {
+			s.nextID = snap.NextID
+		}
+		for i := range snap.Outbox {
+			s.applyPutOutbox(&snap.Outbox[i])
+		}
+		if snap.NextOutboxID > s.nextOutboxID {
+			s.nextOutboxID = snap.NextOutboxID
+		}
+		for i := range snap.DeadLetters {
+			s.applyPutDeadLetter(&snap.DeadLetters[i])
+		}
+		if snap.NextDeadLetterID > s.nextDeadLetterID {
+			s.nextDeadLetterID = snap.NextDeadLetterID
+		}
+		for _, p := range snap.Processed {
+			s.processed[processedKey(p.ConsumerGroup, p.MsgID)] = p
+		}
+		for _, e := range snap.WebhookEndpoints {
+			s.applyPutWebhookEndpoint(e.toModel())
+		}
+		for _, d := range snap.WebhookDeliveries {
+			s.applyPutWebhookDelivery(d.toModel())
+		}
+		if snap.NextWebhookEndpointID > s.nextWebhookEndpointID {
+			s.nextWebhookEndpointID = snap.NextWebhookEndpointID
+		}
+		if snap.NextWebhookDeliveryID > s.nextWebhookDeliveryID {
+			s.nextWebhookDeliveryID = snap.NextWebhookDeliveryID
+		}
+	}
+
+	if err := journal.replay(s.applyRecord); err != nil {
+		_ = journal.close()
+		return nil, err
+	}
+	if err := s.verifyUsernames(); err != nil {
+		_ = journal.close()
+		return nil, err
+	}
+
+	s.journal = journal
+	s.stop = make(chan struct{})
+	s.startBackground(cfg)
+
+	logger.Logger.Info("In-Memory 儲存已從落地目錄還原",
+		zap.String("data_dir", cfg.DataDir),
+		zap.String("fsync_policy", journal.policy),
+		zap.Int("players", len(s.players)),
+		zap.Uint("next_id", s.nextID),
+		zap.Int("outbox_events", len(s.outbox)),
+		zap.Int("dead_letters", len(s.deadLetters)),
+		zap.Int("processed_messages", len(s.processed)),
+		zap.Int("webhook_endpoints", len(s.webhookEndpoints)),
+		zap.Int("webhook_deliveries", len(s.webhookDeliveries)),
+	)
+	return s, nil
+}
+
+// Players 回傳以此 MemoryStore 為後端的 PlayerRepository
+func (s *MemoryStore) Players() PlayerRepository {
+	return &playerRepositoryMemory{store: s}
+}
+
+// Outbox 回傳以此 MemoryStore 為後端的 OutboxRepository
+func (s *MemoryStore) Outbox() OutboxRepository {
+	return &outboxRepositoryMemory{store: s}
+}
+
+// DeadLetters 回傳以此 MemoryStore 為後端的 DeadLetterRepository
+func (s *MemoryStore) DeadLetters() DeadLetterRepository {
+	return &deadLetterRepositoryMemory{store: s}
+}
+
+// ProcessedMessages 回傳以此 MemoryStore 為後端的 ProcessedMessageStore
+func (s *MemoryStore) ProcessedMessages() ProcessedMessageStore {
+	return &processedMessageStoreMemory{store: s}
+}
+
+// Webhooks 回傳以此 MemoryStore 為後端的 WebhookRepository
+func (s *MemoryStore) Webhooks() WebhookRepository {
+	return &webhookRepositoryMemory{store: s}
+}
+
+// Err 回傳 append log 停止寫入的原因，未落地或正常時為 nil
+// 停止寫入期間所有異動都會失敗，下一次快照成功後恢復
+func (s *MemoryStore) Err() error {
+	if s.journal == nil {
+		return nil
+	}
+	return s.journal.err()
+}
+
+// Close 停止背景工作，寫入最後一次快照並關閉 append log
+func (s *MemoryStore) Close() error {
+	if s.journal == nil {
+		return nil
+	}
+	close(s.stop)
+	s.wg.Wait()
+
+	if err := s.Snapshot(); err != nil {
+		_ = s.journal.close()
+		return err
+	}
+	return s.journal.close()
+}
+
+// Snapshot 將目前狀態壓縮成快照並截斷 append log
+// 快照期間會持有寫鎖，適合小型的展示與測試環境
+func (s *MemoryStore) Snapshot() error {
+	if s.journal == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.journal.pendingOps() == 0 {
+		return nil
+	}
+
+	snap := &memorySnapshot{
+		Version: snapshotVersion,
+		TakenAt: time.Now(),
+		NextID:  s.nextID,
+		Players: make([]persistedPlayer, 0, len(s.players)),
+	}
+	for _, p := range s.players {
+		snap.Players = append(snap.Players, toPersistedPlayer(p))
+	}
+	snap.NextOutboxID = s.nextOutboxID
+	for _, ev := range s.outbox {
+		snap.Outbox = append(snap.Outbox, *ev)
+	}
+	snap.NextDeadLetterID = s.nextDeadLetterID
+	for _, d := range s.deadLetters {
+		snap.DeadLetters = append(snap.DeadLetters, *d)
+	}
+	for _, p := range s.processed {
+		snap.Processed = append(snap.Processed, p)
+	}
+	snap.NextWebhookEndpointID = s.nextWebhookEndpointID
+	snap.NextWebhookDeliveryID = s.nextWebhookDeliveryID
+	for _, e := range s.webhookEndpoints {
+		snap.WebhookEndpoints = append(snap.WebhookEndpoints, toPersistedWebhookEndpoint(e))
+	}
+	for _, d := range s.webhookDeliveries {
+		snap.WebhookDeliveries = append(snap.WebhookDeliveries, toPersistedWebhookDelivery(d))
+	}
+	return s.journal.writeSnapshot(snap)
+}
+
+func (s *MemoryStore) startBackground(cfg configs.MemoryStoreConfig) {
+	if s.journal.policy == FsyncInterval {
+		interval := time.Duration(cfg.FsyncIntervalMs) * time.Millisecond
+		if interval <= 0 {
+			interval = time.Second
+		}
+		s.runEvery(interval, func() {
+			if err := s.journal.sync(); err != nil {
+				logger.Logger.Error("定期 fsync 失敗", zap.Error(err))
+			}
+		})
+	}
+
+	if cfg.SnapshotIntervalSeconds > 0 {
+		s.runEvery(time.Duration(cfg.SnapshotIntervalSeconds)*time.Second, func() {
+			if err := s.Snapshot(); err != nil {
+				logger.Logger.Error("寫入 In-Memory 快照失敗", zap.Error(err))
+			}
+		})
+	}
+}
+
+func (s *MemoryStore) runEvery(interval time.Duration, fn func()) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+}
+
+// applyRecord 在重播時套用一筆 append log 記錄
+func (s *MemoryStore) applyRecord(rec journalRecord) {
+	switch rec.Op {
+	case opPutPlayer:
+		if rec.Player != nil {
+			s.applyPut(rec.Player.toModel())
+		}
+		for i := range rec.Outbox {
+			s.applyPutOutbox(&rec.Outbox[i])
+		}
+	case opPutOutbox:
+		for i := range rec.Outbox {
+			s.applyPutOutbox(&rec.Outbox[i])
+		}
+	case opDeleteOutbox:
+		delete(s.outbox, rec.OutboxID)
+	case opPutDeadLetter:
+		if rec.DeadLetter != nil {
+			s.applyPutDeadLetter(rec.DeadLetter)
+		}
+	case opDeleteDeadLetters:
+		for _, id := range rec.DeadLetterIDs {
+			delete(s.deadLetters, id)
+		}
+	case opPutProcessed:
+		for _, p := range rec.Processed {
+			s.processed[processedKey(p.ConsumerGroup, p.MsgID)] = p
+		}
+	case opDeleteProcessed:
+		for _, p := range rec.Processed {
+			delete(s.processed, processedKey(p.ConsumerGroup, p.MsgID))
+		}
+	case opPutWebhook:
+		if rec.WebhookEndpoint != nil {
+			s.applyPutWebhookEndpoint(rec.WebhookEndpoint.toModel())
+		}
+		for _, d := range rec.WebhookDeliveries {
+			s.applyPutWebhookDelivery(d.toModel())
+		}
+	case opDeleteWebhookEndpoint:
+		s.applyDeleteWebhookEndpoint(rec.WebhookEndpointID)
+	}
+	if rec.NextID > s.nextID {
+		s.nextID = rec.NextID
+	}
+	if rec.NextOutboxID > s.nextOutboxID {
+		s.nextOutboxID = rec.NextOutboxID
+	}
+	if rec.NextDeadLetterID > s.nextDeadLetterID {
+		s.nextDeadLetterID = rec.NextDeadLetterID
+	}
+	if rec.NextWebhookEndpointID > s.nextWebhookEndpointID {
+		s.nextWebhookEndpointID = rec.NextWebhookEndpointID
+	}
+	if rec.NextWebhookDeliveryID > s.nextWebhookDeliveryID {
+		s.nextWebhookDeliveryID = rec.NextWebhookDeliveryID
+	}
+}
+
+// applyPut 寫入或覆蓋一位玩家並同步更新使用者名稱索引，呼叫端須持有寫鎖 (或處於初始化階段)
+// 索引只移除仍指向此玩家的舊鍵；重播時若有名稱正規化後重複的玩家，由 verifyUsernames 回報
+func (s *MemoryStore) applyPut(p *model.Player) {
+	if old, ok := s.players[p.ID]; ok {
+		if key := normalizeUsername(old.Username); s.usernames[key] == p.ID {
+			delete(s.usernames, key)
+		}
+	}
+	s.players[p.ID] = p
+	s.usernames[normalizeUsername(p.Username)] = p.ID
+	if p.ID >= s.nextID {
+		s.nextID = p.ID + 1
+	}
+}
+
+// verifyUsernames 確認還原後沒有正規化後相同的使用者名稱
+// 寫入路徑會拒絕重複的名稱，但較早版本 (或人工修改) 的落地資料可能以大小寫或全形/半形區分，
+// 此時索引只能指向其中一位，另一位將無法以名稱登入，因此拒絕啟動並列出衝突的玩家，由維運人員更名後再啟動
+func (s *MemoryStore) verifyUsernames() error {
+	ids := make([]uint, 0, len(s.players))
+	for id := range s.players {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	seen := make(map[string]*model.Player, len(ids))
+	var conflicts []string
+	for _, id := range ids {
+		p := s.players[id]
+		key := normalizeUsername(p.Username)
+		if first, ok := seen[key]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("ID %d %q 與 ID %d %q", first.ID, first.Username, p.ID, p.Username))
+			continue
+		}
+		seen[key] = p
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("落地資料中有正規化後相同的使用者名稱，請更名後再啟動: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// applyPutOutbox 寫入或覆蓋一筆 outbox 事件，呼叫端須持有寫鎖 (或處於初始化階段)
+func (s *MemoryStore) applyPutOutbox(ev *model.OutboxEvent) {
+	cp := *ev
+	s.outbox[cp.ID] = &cp
+	if cp.ID >= s.nextOutboxID {
+		s.nextOutboxID = cp.ID + 1
+	}
+}
+
+// applyPutDeadLetter 寫入一筆死信，呼叫端須持有寫鎖 (或處於初始化階段)
+func (s *MemoryStore) applyPutDeadLetter(d *model.DeadLetter) {
+	cp := *d
+	s.deadLetters[cp.ID] = &cp
+	if cp.ID >= s.nextDeadLetterID {
+		s.nextDeadLetterID = cp.ID + 1
+	}
+}
+
+// persistDeleteDeadLetters 將死信的刪除寫入 append log，呼叫端須持有寫鎖
+func (s *MemoryStore) persistDeleteDeadLetters(ids []uint64) error {
+	if s.journal == nil {
+		return nil
+	}
+	return s.journal.append(journalRecord{
+		Op:               opDeleteDeadLetters,
+		DeadLetterIDs:    ids,
+		NextID:           s.nextID,
+		NextOutboxID:     s.nextOutboxID,
+		NextDeadLetterID: s.nextDeadLetterID,
+	})
+}
+
+// persistPut 將玩家與同時產生的 outbox 事件寫入同一筆 append log 記錄，呼叫端須持有寫鎖
+func (s *MemoryStore) persistPut(p *model.Player, events []*model.OutboxEvent) error {
+	if s.journal == nil {
+		return nil
+	}
+	pp := toPersistedPlayer(p)
+	rec := journalRecord{Op: opPutPlayer, Player: &pp, NextID: s.nextID, NextOutboxID: s.nextOutboxID}
+	for _, ev := range events {
+		rec.Outbox = append(rec.Outbox, *ev)
+	}
+	return s.journal.append(rec)
+}
+
 // playerRepositoryMemory 使用記憶體中的 map 實作 PlayerRepository
 type playerRepositoryMemory struct {
-	mu      sync.RWMutex
-	players map[uint]*model.Player
-	nextID  uint
+	store *MemoryStore
 }
 
 // NewPlayerRepositoryMemory 建立一個新的 playerRepositoryMemory
 func NewPlayerRepositoryMemory() PlayerRepository {
-	return &playerRepositoryMemory{
-		players: make(map[uint]*model.Player),
-		nextID:  1,
-	}
+	return NewMemoryStore().Players()
 }
 
 // CreatePlayer 在記憶體中建立一個新玩家
 func (r *playerRepositoryMemory) CreatePlayer(ctx context.Context, player *model.Player) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	return r.CreatePlayerWithEvents(ctx, player, nil)
+}
+
+// CreatePlayerWithEvents 在同一個臨界區中建立玩家並寫入 outbox 事件
+func (r *playerRepositoryMemory) CreatePlayerWithEvents(ctx context.Context, player *model.Player, events OutboxEventsFunc) error {
+	s := r.store
+	key := normalizeUsername(player.Username) // 在鎖外完成正規化，縮短持鎖時間
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	// 檢查使用者名稱是否唯一
-	for _, p := range r.players {
-		if p.Username == player.Username {
-			return fmt.Errorf("使用者名稱已存在") // 簡單模擬唯一性約束
+	if _, exists := s.usernames[key]; exists {
+		return ErrDuplicateUsername // 模擬資料庫的唯一性約束
+	}
+
+	now := time.Now()
+	p := *player
+	p.ID = s.nextID
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	var evs []*model.OutboxEvent
+	if events != nil {
+		var err error
+		if evs, err = events(&p); err != nil {
+			return fmt.Errorf("建立玩家失敗: %w", err)
 		}
 	}
+	for i, ev := range evs {
+		ev.ID = s.nextOutboxID + uint64(i)
+	}
 
-	player.ID = r.nextID
-	r.nextID++
-	player.CreatedAt = time.Now()
-	player.UpdatedAt = time.Now()
+	// 先寫入 append log，成功後才更新記憶體狀態，避免落地失敗時兩者不一致
+	s.nextID++
+	s.nextOutboxID += uint64(len(evs))
+	if err := s.persistPut(&p, evs); err != nil {
+		s.nextID--
+		s.nextOutboxID -= uint64(len(evs))
+		for _, ev := range evs {
+			ev.ID = 0
+		}
+		logger.FromContext(ctx).Error("寫入 append log 失敗", zap.Error(err), zap.String("username", player.Username))
+		return fmt.Errorf("建立玩家失敗: %w", err)
+	}
 
 	// 儲存副本
-	p := *player
-	r.players[player.ID] = &p
-	
+	s.applyPut(&p)
+	for _, ev := range evs {
+		s.applyPutOutbox(ev)
+	}
+	player.ID = p.ID
+	player.CreatedAt = p.CreatedAt
+	player.UpdatedAt = p.UpdatedAt
+
 	return nil
 }
 
 // GetPlayerByUsername 從記憶體中根據使用者名稱檢索玩家
 func (r *playerRepositoryMemory) GetPlayerByUsername(ctx context.Context, username string) (*model.Player, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	s := r.store
+	key := normalizeUsername(username)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	for _, p := range r.players {
-		if p.Username == username {
-			copy := *p
-			return &copy, nil
-		}
+	if id, ok := s.usernames[key]; ok {
+		copy := *s.players[id]
+		return &copy, nil
 	}
 	return nil, nil // 找不到
 }
 
 // GetPlayerByID 從記憶體中根據 ID 檢索玩家
 func (r *playerRepositoryMemory) GetPlayerByID(ctx context.Context, id uint) (*model.Player, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if p, ok := r.players[id]; ok {
+	if p, ok := s.players[id]; ok {
 		copy := *p
 		return &copy, nil
 	}
 	return nil, nil // 找不到
-}
\ No newline at end of file
+}
+
+// normalizeUsername 將使用者名稱正規化為索引鍵
+// 採用 Unicode NFKC_Casefold 策略 (NFKC -> case folding -> NFKC)，
+// 因此 "Alice"、"ALICE" 與全形的 "Ａｌｉｃｅ" 視為同一個使用者名稱
+// 注意 MySQL/TiDB 實作不使用此規則，唯一性與查詢取決於 players.username 欄位的 collation (見 playerRepositoryMySQL.GetPlayerByUsername)
+func normalizeUsername(username string) string {
+	return norm.NFKC.String(cases.Fold().String(norm.NFKC.String(username)))
+}
@@ -0,0 +1,226 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/internal/repository/repositorytest"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestPlayerRepositoryMemory_Conformance(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+
+	t.Run("Volatile", func(t *testing.T) {
+		repositorytest.RunPlayerRepositorySuite(t, func(t *testing.T) repository.PlayerRepository {
+			return repository.NewPlayerRepositoryMemory()
+		})
+	})
+
+	t.Run("Persistent", func(t *testing.T) {
+		repositorytest.RunPlayerRepositorySuite(t, func(t *testing.T) repository.PlayerRepository {
+			store, err := repository.OpenMemoryStore(configs.MemoryStoreConfig{
+				DataDir:     t.TempDir(),
+				FsyncPolicy: repository.FsyncNever,
+			})
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = store.Close() })
+			return store.Players()
+		})
+	})
+}
+
+func TestMemoryStore_Persistence(t *testing.T) {
+	// Setup logger
+	_, _ = logger.NewLogger("info", "console")
+
+	tests := []struct {
+		name   string
+		policy string
+		// reopen 模擬重啟的方式：true 代表正常關閉 (寫入快照)，false 代表未關閉即重開 (僅靠 append log)
+		closeBeforeReopen bool
+	}{
+		{name: "GracefulRestart", policy: repository.FsyncInterval, closeBeforeReopen: true},
+		{name: "CrashReplayLog", policy: repository.FsyncAlways, closeBeforeReopen: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := configs.MemoryStoreConfig{DataDir: t.TempDir(), FsyncPolicy: tt.policy}
+
+			store, err := repository.OpenMemoryStore(cfg)
+			require.NoError(t, err)
+			repo := store.Players()
+
+			alice := &model.Player{Username: "alice", Password: "secret", Balance: 10}
+			bob := &model.Player{Username: "bob", Password: "secret"}
+			require.NoError(t, repo.CreatePlayer(ctx, alice))
+			require.NoError(t, repo.CreatePlayer(ctx, bob))
+
+			if tt.closeBeforeReopen {
+				require.NoError(t, store.Close())
+			} else {
+				t.Cleanup(func() { _ = store.Close() })
+			}
+
+			reopened, err := repository.OpenMemoryStore(cfg)
+			require.NoError(t, err)
+			defer reopened.Close()
+			repo = reopened.Players()
+
+			got, err := repo.GetPlayerByUsername(ctx, "alice")
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, alice.ID, got.ID)
+			assert.Equal(t, "secret", got.Password)
+			assert.Equal(t, 10.0, got.Balance)
+
+			// nextID 必須延續，而不是從 1 重新開始
+			carol := &model.Player{Username: "carol", Password: "secret"}
+			require.NoError(t, repo.CreatePlayer(ctx, carol))
+			assert.Equal(t, bob.ID+1, carol.ID)
+		})
+	}
+}
+
+func TestMemoryStore_TruncatedJournalTail(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+
+	ctx := context.Background()
+	cfg := configs.MemoryStoreConfig{DataDir: t.TempDir(), FsyncPolicy: repository.FsyncAlways}
+
+	store, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	require.NoError(t, store.Players().CreatePlayer(ctx, &model.Player{Username: "alice", Password: "secret"}))
+
+	// 模擬寫入途中當機：append log 結尾留下不完整的一行
+	f, err := os.OpenFile(filepath.Join(cfg.DataDir, "journal.log"), os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"put_player","player":{"id":2,"user`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reopened, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	got, err := reopened.Players().GetPlayerByID(ctx, 1)
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+
+	got, err = reopened.Players().GetPlayerByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestMemoryStore_RejectsCollidingUsernamesOnReplay(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+
+	ctx := context.Background()
+	cfg := configs.MemoryStoreConfig{DataDir: t.TempDir(), FsyncPolicy: repository.FsyncAlways}
+
+	store, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	require.NoError(t, store.Players().CreatePlayer(ctx, &model.Player{Username: "alice", Password: "secret"}))
+	require.NoError(t, store.Close())
+
+	// 模擬較早版本寫入、只以大小寫區分的使用者名稱
+	f, err := os.OpenFile(filepath.Join(cfg.DataDir, "journal.log"), os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"put_player","player":{"id":2,"username":"Alice","password":"secret"},"next_id":3}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = repository.OpenMemoryStore(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `ID 1 "alice" 與 ID 2 "Alice"`)
+}
+
+func TestPlayerRepositoryMemory_UsernameNormalization(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+
+	ctx := context.Background()
+	repo := repository.NewPlayerRepositoryMemory()
+	require.NoError(t, repo.CreatePlayer(ctx, &model.Player{Username: "Alice", Password: "secret"}))
+
+	tests := []struct {
+		name     string
+		username string
+	}{
+		{name: "SameCase", username: "Alice"},
+		{name: "UpperCase", username: "ALICE"},
+		{name: "FullWidth", username: "Ａｌｉｃｅ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.GetPlayerByUsername(ctx, tt.username)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, "Alice", got.Username) // 保留原始大小寫
+
+			err = repo.CreatePlayer(ctx, &model.Player{Username: tt.username, Password: "secret"})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func BenchmarkPlayerRepositoryMemory_GetPlayerByUsername(b *testing.B) {
+	_, _ = logger.NewLogger("error", "console")
+
+	for _, size := range []int{1_000, 100_000, 1_000_000} {
+		b.Run(fmt.Sprintf("players=%d", size), func(b *testing.B) {
+			ctx := context.Background()
+			repo := seedPlayers(b, size)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				username := fmt.Sprintf("player-%d", i%size)
+				if p, _ := repo.GetPlayerByUsername(ctx, username); p == nil {
+					b.Fatalf("找不到玩家 %s", username)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPlayerRepositoryMemory_CreatePlayer(b *testing.B) {
+	_, _ = logger.NewLogger("error", "console")
+
+	for _, size := range []int{1_000, 100_000, 1_000_000} {
+		b.Run(fmt.Sprintf("players=%d", size), func(b *testing.B) {
+			ctx := context.Background()
+			repo := seedPlayers(b, size)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p := &model.Player{Username: fmt.Sprintf("new-player-%d", i), Password: "secret"}
+				if err := repo.CreatePlayer(ctx, p); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func seedPlayers(b *testing.B, size int) repository.PlayerRepository {
+	b.Helper()
+	ctx := context.Background()
+	repo := repository.NewPlayerRepositoryMemory()
+	for i := 0; i < size; i++ {
+		if err := repo.CreatePlayer(ctx, &model.Player{Username: fmt.Sprintf("player-%d", i), Password: "secret"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return repo
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,8 +12,11 @@ import (
 
 	goRedis "github.com/redis/go-redis/v9"
 	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/breaker"
 	"microservice-mvp/pkg/database"
 	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/metrics"
+	"microservice-mvp/pkg/redis"
 )
 
 // playerRepositoryMySQL 使用 GORM 和 Redis 實作 PlayerRepository
@@ -28,20 +32,49 @@ func NewPlayerRepositoryMySQL(db *gorm.DB, rdb *goRedis.Client) PlayerRepository
 
 // CreatePlayer 在資料庫中建立一個新玩家
 func (r *playerRepositoryMySQL) CreatePlayer(ctx context.Context, player *model.Player) error {
+	return r.CreatePlayerWithEvents(ctx, player, nil)
+}
+
+// CreatePlayerWithEvents 在同一個資料庫交易中建立玩家並寫入 outbox 事件
+func (r *playerRepositoryMySQL) CreatePlayerWithEvents(ctx context.Context, player *model.Player, events OutboxEventsFunc) error {
 	log := logger.FromContext(ctx)
-	if err := database.WithContext(ctx).Create(player).Error; err != nil {
+	var eventCount int
+	err := database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(player).Error; err != nil {
+			return err
+		}
+		if events == nil {
+			return nil
+		}
+		evs, err := events(player)
+		if err != nil {
+			return err
+		}
+		if len(evs) == 0 {
+			return nil
+		}
+		eventCount = len(evs)
+		return tx.Create(&evs).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			log.Warn("使用者名稱已存在", zap.String("username", player.Username))
+			return fmt.Errorf("建立玩家失敗: %w", ErrDuplicateUsername)
+		}
 		log.Error("建立玩家失敗", zap.Error(err), zap.String("username", player.Username))
 		return fmt.Errorf("建立玩家失敗: %w", err)
 	}
-	log.Info("玩家建立成功", zap.Uint("playerID", player.ID), zap.String("username", player.Username))
+	log.Info("玩家建立成功", zap.Uint("playerID", player.ID), zap.String("username", player.Username), zap.Int("outboxEvents", eventCount))
 	return nil
 }
 
 // GetPlayerByUsername 根據使用者名稱檢索玩家
+// 比對方式與唯一索引都取決於 players.username 的 collation，而非記憶體實作的 normalizeUsername：
+// TiDB 預設的 utf8mb4_bin 區分大小寫 ("Alice" 與 "alice" 是不同玩家)，_ci collation 不區分大小寫，但全形/半形等規則與 NFKC_Casefold 不完全相同
 func (r *playerRepositoryMySQL) GetPlayerByUsername(ctx context.Context, username string) (*model.Player, error) {
 	log := logger.FromContext(ctx)
 	var player model.Player
-	if err := database.WithContext(ctx).Where("username = ?", username).First(&player).Error; err != nil {
+	if err := database.ReadContext(ctx).Where("username = ?", username).First(&player).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil // 找不到玩家
 		}
@@ -57,21 +90,26 @@ func (r *playerRepositoryMySQL) GetPlayerByID(ctx context.Context, id uint) (*mo
 	cacheKey := fmt.Sprintf("player:%d", id)
 	var player model.Player
 
-	// 嘗試從 Redis 快取獲取
-	if r.rdb != nil {
+	// Redis 斷路器開啟時略過快取，避免每個請求都等 Redis 逾時後才回退至資料庫
+	useCache := r.rdb != nil && !redis.CircuitOpen()
+
+	// 嘗試從 Redis 快取獲取 (同一請求已寫入時略過快取，確保讀到自己的寫入)
+	if useCache && !database.PinnedToPrimary(ctx) {
 		val, err := r.rdb.Get(ctx, cacheKey).Bytes()
 		if err == nil {
 			if err := json.Unmarshal(val, &player); err == nil {
+				metrics.ObserveCache("player", true)
 				log.Debug("從 Redis 快取獲取玩家資料", zap.Uint("playerID", id))
 				return &player, nil
 			}
-		} else if err != goRedis.Nil {
+		} else if err != goRedis.Nil && !errors.Is(err, breaker.ErrOpen) {
 			log.Warn("從 Redis 快取獲取玩家失敗", zap.Error(err), zap.Uint("playerID", id))
 		}
+		metrics.ObserveCache("player", false)
 	}
 
 	// 如果快取中沒有或反序列化失敗，則從資料庫獲取
-	if err := database.WithContext(ctx).First(&player, id).Error; err != nil {
+	if err := database.ReadContext(ctx).First(&player, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil // 找不到玩家
 		}
@@ -80,7 +118,7 @@ func (r *playerRepositoryMySQL) GetPlayerByID(ctx context.Context, id uint) (*mo
 	}
 
 	// 儲存到 Redis 快取
-	if r.rdb != nil {
+	if useCache {
 		playerBytes, err := json.Marshal(player)
 		if err == nil {
 			r.rdb.Set(ctx, cacheKey, playerBytes, 5*time.Minute)
@@ -88,4 +126,4 @@ func (r *playerRepositoryMySQL) GetPlayerByID(ctx context.Context, id uint) (*mo
 	}
 
 	return &player, nil
-}
\ No newline at end of file
+}
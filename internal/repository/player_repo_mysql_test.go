@@ -0,0 +1,36 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/internal/repository/repositorytest"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/database"
+	"microservice-mvp/pkg/logger"
+)
+
+// TestPlayerRepositoryMySQL_Conformance 需要真實的 MySQL/TiDB，
+// 設定 TEST_MYSQL_DSN (例如 docker-compose 啟動的 user:pass@tcp(127.0.0.1:4000)/test_db?parseTime=True) 後才會執行
+func TestPlayerRepositoryMySQL_Conformance(t *testing.T) {
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("未設定 TEST_MYSQL_DSN，略過 MySQL 一致性測試")
+	}
+	_, _ = logger.NewLogger("info", "console")
+
+	db, err := database.InitTiDB(configs.DatabaseConfig{DSN: dsn, MaxOpenConns: 20, MaxIdleConns: 5})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Player{}, &model.OutboxEvent{}))
+
+	repositorytest.RunPlayerRepositorySuite(t, func(t *testing.T) repository.PlayerRepository {
+		// CreateWithEvents 會寫入 outbox_events，兩張表都需清空，避免子測試互相影響
+		require.NoError(t, db.Exec("TRUNCATE TABLE players").Error)
+		require.NoError(t, db.Exec("TRUNCATE TABLE outbox_events").Error)
+		return repository.NewPlayerRepositoryMySQL(db, nil)
+	})
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrAlreadyProcessed 代表訊息已被同一個 Consumer Group 處理過
+	ErrAlreadyProcessed = errors.New("訊息已處理過")
+	// ErrProcessingInProgress 代表同一則訊息正由其他 Consumer 處理中，應稍後重試
+	ErrProcessingInProgress = errors.New("訊息處理中")
+)
+
+// ProcessedMessageStore 記錄已處理的訊息，讓 Consumer 對重複投遞的訊息只產生一次效果
+type ProcessedMessageStore interface {
+	// Process 標記 (group, msgID) 為已處理並執行 fn
+	// 已處理過時不執行 fn 並回傳 ErrAlreadyProcessed；fn 回傳錯誤時撤銷標記，訊息可再次處理
+	Process(ctx context.Context, group, msgID string, fn func(ctx context.Context) error) error
+	// PurgeProcessedBefore 刪除 before 之前的處理紀錄並回傳刪除筆數
+	PurgeProcessedBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"microservice-mvp/internal/model"
+)
+
+// processedMessageStoreMemory 使用 MemoryStore 實作 ProcessedMessageStore
+// fn 在鎖外執行 (fn 可能寫入同一個 MemoryStore)，因此處理紀錄與 fn 的寫入不是原子的：
+// fn 成功但寫入 append log 失敗時會回傳錯誤，重試時會再次執行 fn
+type processedMessageStoreMemory struct {
+	store *MemoryStore
+}
+
+func processedKey(group, msgID string) string {
+	return group + "\x00" + msgID
+}
+
+// Process 標記訊息處理中後執行 fn，成功才寫入處理紀錄
+func (r *processedMessageStoreMemory) Process(ctx context.Context, group, msgID string, fn func(ctx context.Context) error) error {
+	s := r.store
+	key := processedKey(group, msgID)
+
+	s.mu.Lock()
+	if _, ok := s.processed[key]; ok {
+		s.mu.Unlock()
+		return ErrAlreadyProcessed
+	}
+	if _, ok := s.processing[key]; ok {
+		s.mu.Unlock()
+		return ErrProcessingInProgress
+	}
+	s.processing[key] = struct{}{}
+	s.mu.Unlock()
+
+	// fn 回傳錯誤或 panic 時移除處理中標記
+	defer func() {
+		s.mu.Lock()
+		delete(s.processing, key)
+		s.mu.Unlock()
+	}()
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := model.ProcessedMessage{ConsumerGroup: group, MsgID: msgID, ProcessedAt: time.Now()}
+	if s.journal != nil {
+		rec := journalRecord{Op: opPutProcessed, Processed: []model.ProcessedMessage{record}, NextID: s.nextID, NextOutboxID: s.nextOutboxID}
+		if err := s.journal.append(rec); err != nil {
+			return fmt.Errorf("寫入處理紀錄失敗: %w", err)
+		}
+	}
+	s.processed[key] = record
+	return nil
+}
+
+// PurgeProcessedBefore 刪除過期的處理紀錄
+func (r *processedMessageStoreMemory) PurgeProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []model.ProcessedMessage
+	for _, p := range s.processed {
+		if p.ProcessedAt.Before(before) {
+			expired = append(expired, p)
+		}
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+	if s.journal != nil {
+		rec := journalRecord{Op: opDeleteProcessed, Processed: expired, NextID: s.nextID, NextOutboxID: s.nextOutboxID}
+		if err := s.journal.append(rec); err != nil {
+			return 0, fmt.Errorf("清除處理紀錄失敗: %w", err)
+		}
+	}
+	for _, p := range expired {
+		delete(s.processed, processedKey(p.ConsumerGroup, p.MsgID))
+	}
+	return int64(len(expired)), nil
+}
@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestProcessedMessageStoreMemory_Process(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	store := repository.NewMemoryStore().ProcessedMessages()
+
+	calls := 0
+	handler := func(err error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			calls++
+			return err
+		}
+	}
+
+	// 失敗時撤銷標記，訊息可再次處理
+	boom := errors.New("boom")
+	assert.ErrorIs(t, store.Process(ctx, "g1", "m1", handler(boom)), boom)
+	require.NoError(t, store.Process(ctx, "g1", "m1", handler(nil)))
+	assert.ErrorIs(t, store.Process(ctx, "g1", "m1", handler(nil)), repository.ErrAlreadyProcessed)
+	assert.Equal(t, 2, calls)
+
+	// 不同 Consumer Group 各自處理一次
+	require.NoError(t, store.Process(ctx, "g2", "m1", handler(nil)))
+	assert.Equal(t, 3, calls)
+
+	// 處理中的訊息不會被並行處理
+	require.NoError(t, store.Process(ctx, "g1", "m2", func(ctx context.Context) error {
+		assert.ErrorIs(t, store.Process(ctx, "g1", "m2", handler(nil)), repository.ErrProcessingInProgress)
+		return nil
+	}))
+	assert.Equal(t, 3, calls)
+
+	// panic 時同樣撤銷標記
+	assert.Panics(t, func() {
+		_ = store.Process(ctx, "g1", "m3", func(ctx context.Context) error { panic("boom") })
+	})
+	require.NoError(t, store.Process(ctx, "g1", "m3", handler(nil)))
+}
+
+func TestProcessedMessageStoreMemory_RetentionAndRestart(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	cfg := configs.MemoryStoreConfig{DataDir: t.TempDir(), FsyncPolicy: repository.FsyncAlways}
+	noop := func(ctx context.Context) error { return nil }
+
+	ms, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	store := ms.ProcessedMessages()
+	require.NoError(t, store.Process(ctx, "g1", "old", noop))
+	cutoff := time.Now()
+	require.NoError(t, store.Process(ctx, "g1", "new", noop))
+
+	purged, err := store.PurgeProcessedBefore(ctx, cutoff)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, purged)
+	require.NoError(t, ms.Close())
+
+	ms, err = repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	defer ms.Close()
+	store = ms.ProcessedMessages()
+
+	assert.ErrorIs(t, store.Process(ctx, "g1", "new", noop), repository.ErrAlreadyProcessed, "處理紀錄應在重啟後保留")
+	assert.NoError(t, store.Process(ctx, "g1", "old", noop), "已清除的紀錄不應還原")
+}
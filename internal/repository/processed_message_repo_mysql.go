@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/database"
+)
+
+// processedMessageStoreMySQL 使用 GORM 實作 ProcessedMessageStore
+// 處理紀錄與 fn 的寫入在同一個交易中提交，fn 內以 database.WithContext(ctx) 取得的 session 都會加入該交易
+type processedMessageStoreMySQL struct {
+	db *gorm.DB
+}
+
+// NewProcessedMessageStoreMySQL 建立一個新的 processedMessageStoreMySQL
+func NewProcessedMessageStoreMySQL(db *gorm.DB) ProcessedMessageStore {
+	return &processedMessageStoreMySQL{db: db}
+}
+
+// Process 先寫入處理紀錄 (主鍵衝突代表已處理過) 再執行 fn，兩者同時提交或回滾
+// 同一則訊息並行處理時，後到者會在主鍵上等待先到者的交易結束
+func (s *processedMessageStoreMySQL) Process(ctx context.Context, group, msgID string, fn func(ctx context.Context) error) error {
+	return database.Transaction(ctx, func(ctx context.Context) error {
+		record := &model.ProcessedMessage{ConsumerGroup: group, MsgID: msgID, ProcessedAt: time.Now()}
+		if err := database.WithContext(ctx).Create(record).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrAlreadyProcessed
+			}
+			return fmt.Errorf("寫入處理紀錄失敗: %w", err)
+		}
+		return fn(ctx)
+	})
+}
+
+// PurgeProcessedBefore 刪除過期的處理紀錄
+func (s *processedMessageStoreMySQL) PurgeProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := database.WithContext(ctx).Where("processed_at < ?", before).Delete(&model.ProcessedMessage{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("清除處理紀錄失敗: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
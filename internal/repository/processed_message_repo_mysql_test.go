@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/database"
+	"microservice-mvp/pkg/logger"
+)
+
+// TestProcessedMessageStoreMySQL_AtomicWithHandlerWrites 需要真實的 MySQL/TiDB，設定 TEST_MYSQL_DSN 後才會執行
+func TestProcessedMessageStoreMySQL_AtomicWithHandlerWrites(t *testing.T) {
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("未設定 TEST_MYSQL_DSN，略過 MySQL 去重測試")
+	}
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+
+	db, err := database.InitTiDB(configs.DatabaseConfig{DSN: dsn, MaxOpenConns: 20, MaxIdleConns: 5})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.Player{}, &model.ProcessedMessage{}))
+	require.NoError(t, db.Exec("TRUNCATE TABLE players").Error)
+	require.NoError(t, db.Exec("TRUNCATE TABLE processed_messages").Error)
+
+	store := repository.NewProcessedMessageStoreMySQL(db)
+	players := repository.NewPlayerRepositoryMySQL(db, nil)
+	createPlayer := func(fail error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if err := players.CreatePlayer(ctx, &model.Player{Username: "alice", Password: "secret"}); err != nil {
+				return err
+			}
+			return fail
+		}
+	}
+
+	// handler 失敗時，handler 的寫入與處理紀錄一併回滾
+	boom := errors.New("boom")
+	assert.ErrorIs(t, store.Process(ctx, "g1", "m1", createPlayer(boom)), boom)
+	player, err := players.GetPlayerByUsername(ctx, "alice")
+	require.NoError(t, err)
+	assert.Nil(t, player)
+
+	require.NoError(t, store.Process(ctx, "g1", "m1", createPlayer(nil)))
+	assert.ErrorIs(t, store.Process(ctx, "g1", "m1", createPlayer(nil)), repository.ErrAlreadyProcessed)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	goRedis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/logger"
+)
+
+const (
+	processedMessageStateProcessing = "processing"
+	processedMessageStateDone       = "done"
+
+	// processedMessageLockTTL 是處理中標記的存活時間，Consumer 當機時標記過期後訊息可再次處理
+	processedMessageLockTTL = 5 * time.Minute
+)
+
+// processedMessageStoreRedis 使用 Redis 實作 ProcessedMessageStore
+// 處理紀錄與 fn 的 DB 寫入無法在同一個交易中提交：fn 成功但寫入完成標記失敗時，
+// 處理中標記過期後重複投遞的訊息會再次執行 fn，需要嚴格一次性效果時請改用 DB 實作
+type processedMessageStoreRedis struct {
+	rdb       *goRedis.Client
+	retention time.Duration
+}
+
+// NewProcessedMessageStoreRedis 建立一個新的 processedMessageStoreRedis，處理紀錄在 retention 後由 Redis 自動過期
+func NewProcessedMessageStoreRedis(rdb *goRedis.Client, retention time.Duration) ProcessedMessageStore {
+	return &processedMessageStoreRedis{rdb: rdb, retention: retention}
+}
+
+// Process 以 SETNX 取得處理中標記後執行 fn，成功後改為完成標記，失敗則刪除標記
+func (s *processedMessageStoreRedis) Process(ctx context.Context, group, msgID string, fn func(ctx context.Context) error) error {
+	key := fmt.Sprintf("processed:%s:%s", group, msgID)
+
+	acquired, err := s.rdb.SetNX(ctx, key, processedMessageStateProcessing, processedMessageLockTTL).Result()
+	if err != nil {
+		return fmt.Errorf("寫入處理紀錄失敗: %w", err)
+	}
+	if !acquired {
+		state, err := s.rdb.Get(ctx, key).Result()
+		switch {
+		case errors.Is(err, goRedis.Nil):
+			return ErrProcessingInProgress // 標記剛好過期或被刪除，交由重試再次判斷
+		case err != nil:
+			return fmt.Errorf("讀取處理紀錄失敗: %w", err)
+		case state == processedMessageStateDone:
+			return ErrAlreadyProcessed
+		default:
+			return ErrProcessingInProgress
+		}
+	}
+
+	if err := s.runOrRelease(ctx, key, fn); err != nil {
+		return err
+	}
+
+	if err := s.rdb.Set(ctx, key, processedMessageStateDone, s.retention).Err(); err != nil {
+		logger.FromContext(ctx).Error("訊息已處理但寫入完成標記失敗，重複投遞時可能再次處理",
+			zap.Error(err), zap.String("group", group), zap.String("msgID", msgID))
+	}
+	return nil
+}
+
+// runOrRelease 執行 fn，回傳錯誤或 panic 時刪除處理中標記
+func (s *processedMessageStoreRedis) runOrRelease(ctx context.Context, key string, fn func(ctx context.Context) error) (err error) {
+	release := true
+	defer func() {
+		if release {
+			// 使用獨立的上下文，避免 ctx 已取消時標記殘留到過期
+			if delErr := s.rdb.Del(context.WithoutCancel(ctx), key).Err(); delErr != nil {
+				logger.FromContext(ctx).Warn("刪除處理中標記失敗", zap.Error(delErr), zap.String("key", key))
+			}
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	release = false
+	return nil
+}
+
+// PurgeProcessedBefore 不需要執行任何動作，處理紀錄由 Redis 依 TTL 自動過期
+func (s *processedMessageStoreRedis) PurgeProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
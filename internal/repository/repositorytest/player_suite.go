@@ -0,0 +1,220 @@
+// Package repositorytest 提供可重用的 Repository 一致性測試套件
+// 任何 PlayerRepository 實作 (In-Memory、MySQL 或未來新增的儲存) 都應通過此套件，
+// 以確保切換持久化模式時業務邏輯觀察到的行為一致。
+//
+// mocks.MockPlayerRepository 不在此套件的範圍內：它的每個回傳值都由測試以 On(...).Return(...) 預先指定，
+// 沒有自己的狀態或唯一性約束，對它執行套件只會驗證測試腳本本身。Service 層以 mock 測試時，
+// 應讓預期的回傳值 (例如重複名稱時回傳 repository.ErrDuplicateUsername) 與此套件規範的行為一致。
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+)
+
+// Factory 為每個子測試建立一個全新且空白的 PlayerRepository
+type Factory func(t *testing.T) repository.PlayerRepository
+
+// RunPlayerRepositorySuite 對指定的 PlayerRepository 實作執行完整的一致性測試
+func RunPlayerRepositorySuite(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.PlayerRepository)
+	}{
+		{name: "CreateAndGet", run: testCreateAndGet},
+		{name: "NotFound", run: testNotFound},
+		{name: "DuplicateUsername", run: testDuplicateUsername},
+		{name: "ReturnsCopies", run: testReturnsCopies},
+		{name: "IDMonotonicity", run: testIDMonotonicity},
+		{name: "ConcurrentCreates", run: testConcurrentCreates},
+		{name: "ConcurrentDuplicateCreates", run: testConcurrentDuplicateCreates},
+		{name: "CreateWithEvents", run: testCreateWithEvents},
+		{name: "CreateWithEventsRollback", run: testCreateWithEventsRollback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func testCreateAndGet(t *testing.T, repo repository.PlayerRepository) {
+	ctx := context.Background()
+	player := &model.Player{Username: "alice", Password: "secret", Balance: 12.5}
+	require.NoError(t, repo.CreatePlayer(ctx, player))
+	assert.NotZero(t, player.ID, "CreatePlayer 應回填 ID")
+	assert.False(t, player.CreatedAt.IsZero(), "CreatePlayer 應回填 CreatedAt")
+
+	byID, err := repo.GetPlayerByID(ctx, player.ID)
+	require.NoError(t, err)
+	require.NotNil(t, byID)
+	assert.Equal(t, "alice", byID.Username)
+	assert.Equal(t, "secret", byID.Password)
+	assert.Equal(t, 12.5, byID.Balance)
+
+	byName, err := repo.GetPlayerByUsername(ctx, "alice")
+	require.NoError(t, err)
+	require.NotNil(t, byName)
+	assert.Equal(t, player.ID, byName.ID)
+}
+
+func testCreateWithEvents(t *testing.T, repo repository.PlayerRepository) {
+	ctx := context.Background()
+	var seenID uint
+	player := &model.Player{Username: "alice", Password: "secret"}
+	err := repo.CreatePlayerWithEvents(ctx, player, func(p *model.Player) ([]*model.OutboxEvent, error) {
+		seenID = p.ID
+		ev, err := repository.NewOutboxEvent(ctx, "player_events", "registered", fmt.Sprint(p.ID), map[string]uint{"player_id": p.ID})
+		return []*model.OutboxEvent{ev}, err
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, seenID, "事件產生時應已取得玩家 ID")
+	assert.Equal(t, player.ID, seenID)
+}
+
+func testCreateWithEventsRollback(t *testing.T, repo repository.PlayerRepository) {
+	ctx := context.Background()
+	failure := errors.New("事件產生失敗")
+	err := repo.CreatePlayerWithEvents(ctx, &model.Player{Username: "alice", Password: "secret"},
+		func(p *model.Player) ([]*model.OutboxEvent, error) { return nil, failure })
+	require.ErrorIs(t, err, failure)
+
+	got, err := repo.GetPlayerByUsername(ctx, "alice")
+	assertNotFound(t, got, err)
+
+	// 取消的寫入不應佔用使用者名稱
+	require.NoError(t, repo.CreatePlayer(ctx, &model.Player{Username: "alice", Password: "secret"}))
+}
+
+func testNotFound(t *testing.T, repo repository.PlayerRepository) {
+	ctx := context.Background()
+
+	byID, err := repo.GetPlayerByID(ctx, 999999)
+	assertNotFound(t, byID, err)
+
+	byName, err := repo.GetPlayerByUsername(ctx, "nobody")
+	assertNotFound(t, byName, err)
+}
+
+func testDuplicateUsername(t *testing.T, repo repository.PlayerRepository) {
+	ctx := context.Background()
+	original := &model.Player{Username: "alice", Password: "first"}
+	require.NoError(t, repo.CreatePlayer(ctx, original))
+
+	err := repo.CreatePlayer(ctx, &model.Player{Username: "alice", Password: "second"})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, repository.ErrDuplicateUsername), "重複的使用者名稱應回傳 ErrDuplicateUsername，實際為: %v", err)
+
+	// 原本的玩家不應被覆蓋
+	got, err := repo.GetPlayerByUsername(ctx, "alice")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, original.ID, got.ID)
+	assert.Equal(t, "first", got.Password)
+}
+
+func testReturnsCopies(t *testing.T, repo repository.PlayerRepository) {
+	ctx := context.Background()
+	player := &model.Player{Username: "alice", Password: "secret"}
+	require.NoError(t, repo.CreatePlayer(ctx, player))
+
+	// 修改呼叫端持有的物件不應影響已儲存的資料
+	player.Balance = 100
+	got, err := repo.GetPlayerByID(ctx, player.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Zero(t, got.Balance)
+
+	got.Balance = 200
+	again, err := repo.GetPlayerByID(ctx, player.ID)
+	require.NoError(t, err)
+	require.NotNil(t, again)
+	assert.Zero(t, again.Balance)
+}
+
+func testIDMonotonicity(t *testing.T, repo repository.PlayerRepository) {
+	ctx := context.Background()
+	var lastID uint
+	for i := 0; i < 20; i++ {
+		player := &model.Player{Username: fmt.Sprintf("player-%d", i), Password: "secret"}
+		require.NoError(t, repo.CreatePlayer(ctx, player))
+		assert.Greater(t, player.ID, lastID, "ID 應嚴格遞增")
+		lastID = player.ID
+	}
+}
+
+func testConcurrentCreates(t *testing.T, repo repository.PlayerRepository) {
+	ctx := context.Background()
+	const workers = 50
+
+	ids := make([]uint, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			player := &model.Player{Username: fmt.Sprintf("concurrent-%d", i), Password: "secret"}
+			errs[i] = repo.CreatePlayer(ctx, player)
+			ids[i] = player.ID
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[uint]bool, workers)
+	for i := 0; i < workers; i++ {
+		require.NoError(t, errs[i])
+		assert.False(t, seen[ids[i]], "ID %d 被重複分配", ids[i])
+		seen[ids[i]] = true
+
+		got, err := repo.GetPlayerByID(ctx, ids[i])
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, fmt.Sprintf("concurrent-%d", i), got.Username)
+	}
+}
+
+func testConcurrentDuplicateCreates(t *testing.T, repo repository.PlayerRepository) {
+	ctx := context.Background()
+	const workers = 20
+
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = repo.CreatePlayer(ctx, &model.Player{Username: "contended", Password: "secret"})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.True(t, errors.Is(err, repository.ErrDuplicateUsername), "非預期的錯誤: %v", err)
+	}
+	assert.Equal(t, 1, succeeded, "同一使用者名稱只能建立一次")
+}
+
+// assertNotFound 接受兩種找不到的表示方式：(nil, nil) 或 ErrPlayerNotFound
+func assertNotFound(t *testing.T, player *model.Player, err error) {
+	t.Helper()
+	assert.Nil(t, player)
+	if err != nil {
+		assert.True(t, errors.Is(err, repository.ErrPlayerNotFound), "找不到玩家時應回傳 (nil, nil) 或 ErrPlayerNotFound，實際為: %v", err)
+	}
+}
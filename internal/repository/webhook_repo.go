@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/apperrors"
+)
+
+var (
+	// ErrWebhookEndpointNotFound 代表找不到 webhook 端點，屬於 apperrors.ErrNotFound 分類
+	ErrWebhookEndpointNotFound = apperrors.NotFound("WEBHOOK_ENDPOINT_NOT_FOUND", "webhook 端點不存在")
+	// ErrWebhookDeliveryNotFound 代表找不到 webhook 投遞紀錄，屬於 apperrors.ErrNotFound 分類
+	ErrWebhookDeliveryNotFound = apperrors.NotFound("WEBHOOK_DELIVERY_NOT_FOUND", "webhook 投遞紀錄不存在")
+	// ErrWebhookClaimLost 代表投遞紀錄的認領已逾期並由其他實例接手 (或已被重設)，此次投遞結果不予記錄
+	ErrWebhookClaimLost = apperrors.Conflict("WEBHOOK_DELIVERY_CLAIM_LOST", "webhook 投遞紀錄的認領已失效")
+)
+
+// WebhookDeliveryFilter 是查詢投遞紀錄的條件
+type WebhookDeliveryFilter struct {
+	EndpointID uint64 // 0 代表全部端點
+	Status     string // 空字串代表全部狀態
+	AfterID    uint64 // 分頁游標：只回傳 ID 大於此值的紀錄
+	Limit      int
+}
+
+// WebhookAttempt 是一次投遞嘗試的結果
+type WebhookAttempt struct {
+	AttemptedAt   time.Time
+	Delivered     bool // 對方回應 2xx
+	StatusCode    int  // 0 代表沒有收到回應 (例如逾時或連線失敗)
+	Error         string
+	Duration      time.Duration
+	NextAttemptAt time.Time // 失敗且尚未放棄時的下次嘗試時間
+	GiveUp        bool      // 失敗且重試排程已用盡
+}
+
+// WebhookRepository 定義 webhook 端點與投遞紀錄的儲存操作
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, e *model.WebhookEndpoint) error
+	ListEndpoints(ctx context.Context) ([]*model.WebhookEndpoint, error)
+	// GetEndpoint 找不到時回傳 ErrWebhookEndpointNotFound
+	GetEndpoint(ctx context.Context, id uint64) (*model.WebhookEndpoint, error)
+	// UpdateEndpoint 以 e 覆蓋端點設定，找不到時回傳 ErrWebhookEndpointNotFound
+	UpdateEndpoint(ctx context.Context, e *model.WebhookEndpoint) error
+	// DeleteEndpoint 刪除端點及其投遞紀錄，找不到時回傳 ErrWebhookEndpointNotFound
+	DeleteEndpoint(ctx context.Context, id uint64) error
+
+	// EnqueueDeliveries 新增待投遞的紀錄
+	EnqueueDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error
+	// ClaimDueDeliveries 依 ID 順序認領已到期且未被認領 (或認領已逾期) 的待投遞紀錄，略過已停用的端點
+	// 回傳的紀錄 ClaimedBy 為本次認領的識別碼，認領至 now+lease 為止，期間其他實例不會取得
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WebhookDelivery, error)
+	// ReleaseDeliveries 釋放未投遞的認領，讓紀錄可在下次輪詢時再被認領
+	ReleaseDeliveries(ctx context.Context, ids []uint64) error
+	// RecordAttempt 記錄一次投遞嘗試、釋放認領並更新端點的連續失敗次數 (成功時歸零)
+	// 紀錄已不屬於 claimedBy 的認領時回傳 ErrWebhookClaimLost，不更新紀錄與端點，避免重複計入失敗次數
+	// 連續失敗達 disableAfter (> 0) 次時停用端點，回傳端點是否因此次嘗試被停用
+	RecordAttempt(ctx context.Context, deliveryID uint64, claimedBy string, attempt WebhookAttempt, disableAfter int) (bool, error)
+	// ListDeliveries 依 ID 遞增順序回傳符合條件的投遞紀錄
+	ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]*model.WebhookDelivery, error)
+	// GetDelivery 找不到時回傳 ErrWebhookDeliveryNotFound
+	GetDelivery(ctx context.Context, id uint64) (*model.WebhookDelivery, error)
+	// RetryDelivery 將投遞紀錄重設為待投遞並重新開始重試排程，於 now 重新嘗試
+	RetryDelivery(ctx context.Context, id uint64, now time.Time) error
+}
+
+// applyWebhookAttempt 將投遞結果套用到投遞紀錄
+func applyWebhookAttempt(d *model.WebhookDelivery, attempt WebhookAttempt) {
+	at := attempt.AttemptedAt
+	d.ClaimedBy = ""
+	d.ClaimedUntil = nil
+	d.Attempts++
+	d.LastAttemptAt = &at
+	d.LastStatusCode = attempt.StatusCode
+	d.LastError = truncateError(attempt.Error)
+	d.LastDurationMs = attempt.Duration.Milliseconds()
+	switch {
+	case attempt.Delivered:
+		d.Status = model.WebhookDeliverySucceeded
+		d.DeliveredAt = &at
+	case attempt.GiveUp:
+		d.Status = model.WebhookDeliveryFailed
+	default:
+		d.Status = model.WebhookDeliveryPending
+		d.NextAttemptAt = attempt.NextAttemptAt
+	}
+}
+
+// applyEndpointAttempt 依投遞結果更新端點的連續失敗次數，回傳端點是否因此被停用
+func applyEndpointAttempt(e *model.WebhookEndpoint, attempt WebhookAttempt, disableAfter int) bool {
+	if attempt.Delivered {
+		e.ConsecutiveFailures = 0
+		return false
+	}
+	e.ConsecutiveFailures++
+	if !e.Enabled || disableAfter <= 0 || e.ConsecutiveFailures < disableAfter {
+		return false
+	}
+	at := attempt.AttemptedAt
+	e.Enabled = false
+	e.DisabledAt = &at
+	e.DisabledReason = "連續投遞失敗次數達上限"
+	return true
+}
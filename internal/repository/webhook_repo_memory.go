@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"microservice-mvp/internal/model"
+)
+
+// webhookRepositoryMemory 使用 MemoryStore 實作 WebhookRepository
+type webhookRepositoryMemory struct {
+	store *MemoryStore
+}
+
+// CreateEndpoint 新增 webhook 端點並回填 ID
+func (r *webhookRepositoryMemory) CreateEndpoint(ctx context.Context, e *model.WebhookEndpoint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	cp := *e
+	cp.ID = s.nextWebhookEndpointID
+	cp.CreatedAt = now
+	cp.UpdatedAt = now
+
+	if err := s.persistWebhook(&cp, nil, cp.ID+1, s.nextWebhookDeliveryID); err != nil {
+		return fmt.Errorf("新增 webhook 端點失敗: %w", err)
+	}
+	s.applyPutWebhookEndpoint(&cp)
+	e.ID = cp.ID
+	e.CreatedAt = cp.CreatedAt
+	e.UpdatedAt = cp.UpdatedAt
+	return nil
+}
+
+// ListEndpoints 依 ID 順序回傳所有 webhook 端點副本
+func (r *webhookRepositoryMemory) ListEndpoints(ctx context.Context) ([]*model.WebhookEndpoint, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	endpoints := make([]*model.WebhookEndpoint, 0, len(s.webhookEndpoints))
+	for _, e := range s.webhookEndpoints {
+		cp := *e
+		endpoints = append(endpoints, &cp)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].ID < endpoints[j].ID })
+	return endpoints, nil
+}
+
+// GetEndpoint 根據 ID 取得 webhook 端點副本
+func (r *webhookRepositoryMemory) GetEndpoint(ctx context.Context, id uint64) (*model.WebhookEndpoint, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.webhookEndpoints[id]
+	if !ok {
+		return nil, ErrWebhookEndpointNotFound
+	}
+	cp := *e
+	return &cp, nil
+}
+
+// UpdateEndpoint 以 e 覆蓋 webhook 端點，保留建立時間
+func (r *webhookRepositoryMemory) UpdateEndpoint(ctx context.Context, e *model.WebhookEndpoint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.webhookEndpoints[e.ID]
+	if !ok {
+		return ErrWebhookEndpointNotFound
+	}
+	cp := *e
+	cp.CreatedAt = old.CreatedAt
+	cp.UpdatedAt = time.Now()
+
+	if err := s.persistWebhook(&cp, nil, s.nextWebhookEndpointID, s.nextWebhookDeliveryID); err != nil {
+		return fmt.Errorf("更新 webhook 端點失敗: %w", err)
+	}
+	s.applyPutWebhookEndpoint(&cp)
+	e.UpdatedAt = cp.UpdatedAt
+	return nil
+}
+
+// DeleteEndpoint 刪除 webhook 端點及其投遞紀錄
+func (r *webhookRepositoryMemory) DeleteEndpoint(ctx context.Context, id uint64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhookEndpoints[id]; !ok {
+		return ErrWebhookEndpointNotFound
+	}
+	if s.journal != nil {
+		rec := s.webhookRecord(opDeleteWebhookEndpoint, s.nextWebhookEndpointID, s.nextWebhookDeliveryID)
+		rec.WebhookEndpointID = id
+		if err := s.journal.append(rec); err != nil {
+			return fmt.Errorf("刪除 webhook 端點失敗: %w", err)
+		}
+	}
+	s.applyDeleteWebhookEndpoint(id)
+	return nil
+}
+
+// EnqueueDeliveries 新增待投遞的紀錄並回填 ID
+func (r *webhookRepositoryMemory) EnqueueDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	copies := make([]*model.WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		cp := *d
+		cp.ID = s.nextWebhookDeliveryID + uint64(i)
+		if cp.CreatedAt.IsZero() {
+			cp.CreatedAt = now
+		}
+		copies[i] = &cp
+	}
+
+	if err := s.persistWebhook(nil, copies, s.nextWebhookEndpointID, s.nextWebhookDeliveryID+uint64(len(copies))); err != nil {
+		return fmt.Errorf("新增 webhook 投遞紀錄失敗: %w", err)
+	}
+	for i, cp := range copies {
+		s.applyPutWebhookDelivery(cp)
+		deliveries[i].ID = cp.ID
+		deliveries[i].CreatedAt = cp.CreatedAt
+	}
+	return nil
+}
+
+// ClaimDueDeliveries 依 ID 順序認領到期的待投遞紀錄並回傳副本，略過已停用的端點
+// 認領只存在於記憶體，不寫入 append log，重新啟動後全部視為未認領
+func (r *webhookRepositoryMemory) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WebhookDelivery, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*model.WebhookDelivery
+	for _, d := range s.webhookDeliveries {
+		if d.Status != model.WebhookDeliveryPending || d.NextAttemptAt.After(now) ||
+			(d.ClaimedUntil != nil && d.ClaimedUntil.After(now)) {
+			continue
+		}
+		if e, ok := s.webhookEndpoints[d.EndpointID]; !ok || !e.Enabled {
+			continue
+		}
+		due = append(due, d)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	token := uuid.NewString()
+	until := now.Add(lease)
+	claimed := make([]*model.WebhookDelivery, 0, len(due))
+	for _, d := range due {
+		updated := *d
+		updated.ClaimedBy = token
+		updated.ClaimedUntil = &until
+		s.webhookDeliveries[d.ID] = &updated
+		cp := updated
+		claimed = append(claimed, &cp)
+	}
+	return claimed, nil
+}
+
+// ReleaseDeliveries 釋放未投遞的認領
+func (r *webhookRepositoryMemory) ReleaseDeliveries(ctx context.Context, ids []uint64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		if d, ok := s.webhookDeliveries[id]; ok {
+			updated := *d
+			updated.ClaimedBy = ""
+			updated.ClaimedUntil = nil
+			s.webhookDeliveries[id] = &updated
+		}
+	}
+	return nil
+}
+
+// RecordAttempt 記錄一次投遞嘗試，投遞紀錄與端點寫入同一筆 append log 記錄
+func (r *webhookRepositoryMemory) RecordAttempt(ctx context.Context, deliveryID uint64, claimedBy string, attempt WebhookAttempt, disableAfter int) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.webhookDeliveries[deliveryID]
+	if !ok {
+		return false, ErrWebhookDeliveryNotFound
+	}
+	if d.ClaimedBy != claimedBy || d.Status != model.WebhookDeliveryPending {
+		return false, ErrWebhookClaimLost
+	}
+	e, ok := s.webhookEndpoints[d.EndpointID]
+	if !ok {
+		return false, ErrWebhookEndpointNotFound
+	}
+	delivery := *d
+	endpoint := *e
+	applyWebhookAttempt(&delivery, attempt)
+	disabled := applyEndpointAttempt(&endpoint, attempt, disableAfter)
+
+	if err := s.persistWebhook(&endpoint, []*model.WebhookDelivery{&delivery}, s.nextWebhookEndpointID, s.nextWebhookDeliveryID); err != nil {
+		return false, fmt.Errorf("記錄 webhook 投遞結果失敗: %w", err)
+	}
+	s.applyPutWebhookEndpoint(&endpoint)
+	s.applyPutWebhookDelivery(&delivery)
+	return disabled, nil
+}
+
+// ListDeliveries 依 ID 遞增順序查詢投遞紀錄副本
+func (r *webhookRepositoryMemory) ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]*model.WebhookDelivery, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var deliveries []*model.WebhookDelivery
+	for _, d := range s.webhookDeliveries {
+		if d.ID <= filter.AfterID ||
+			(filter.EndpointID != 0 && d.EndpointID != filter.EndpointID) ||
+			(filter.Status != "" && d.Status != filter.Status) {
+			continue
+		}
+		cp := *d
+		deliveries = append(deliveries, &cp)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	if filter.Limit > 0 && len(deliveries) > filter.Limit {
+		deliveries = deliveries[:filter.Limit]
+	}
+	return deliveries, nil
+}
+
+// GetDelivery 根據 ID 取得投遞紀錄副本
+func (r *webhookRepositoryMemory) GetDelivery(ctx context.Context, id uint64) (*model.WebhookDelivery, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	d, ok := s.webhookDeliveries[id]
+	if !ok {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	cp := *d
+	return &cp, nil
+}
+
+// RetryDelivery 將投遞紀錄重設為待投遞並重新開始重試排程
+func (r *webhookRepositoryMemory) RetryDelivery(ctx context.Context, id uint64, now time.Time) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.webhookDeliveries[id]
+	if !ok {
+		return ErrWebhookDeliveryNotFound
+	}
+	cp := *d
+	cp.Status = model.WebhookDeliveryPending
+	cp.Attempts = 0
+	cp.NextAttemptAt = now
+	cp.ClaimedBy = ""
+	cp.ClaimedUntil = nil
+
+	if err := s.persistWebhook(nil, []*model.WebhookDelivery{&cp}, s.nextWebhookEndpointID, s.nextWebhookDeliveryID); err != nil {
+		return fmt.Errorf("重設 webhook 投遞紀錄失敗: %w", err)
+	}
+	s.applyPutWebhookDelivery(&cp)
+	return nil
+}
+
+// applyPutWebhookEndpoint 寫入或覆蓋一個 webhook 端點，呼叫端須持有寫鎖 (或處於初始化階段)
+func (s *MemoryStore) applyPutWebhookEndpoint(e *model.WebhookEndpoint) {
+	cp := *e
+	s.webhookEndpoints[cp.ID] = &cp
+	if cp.ID >= s.nextWebhookEndpointID {
+		s.nextWebhookEndpointID = cp.ID + 1
+	}
+}
+
+// applyPutWebhookDelivery 寫入或覆蓋一筆投遞紀錄，呼叫端須持有寫鎖 (或處於初始化階段)
+func (s *MemoryStore) applyPutWebhookDelivery(d *model.WebhookDelivery) {
+	cp := *d
+	s.webhookDeliveries[cp.ID] = &cp
+	if cp.ID >= s.nextWebhookDeliveryID {
+		s.nextWebhookDeliveryID = cp.ID + 1
+	}
+}
+
+// applyDeleteWebhookEndpoint 刪除 webhook 端點及其投遞紀錄，呼叫端須持有寫鎖 (或處於初始化階段)
+func (s *MemoryStore) applyDeleteWebhookEndpoint(id uint64) {
+	delete(s.webhookEndpoints, id)
+	for did, d := range s.webhookDeliveries {
+		if d.EndpointID == id {
+			delete(s.webhookDeliveries, did)
+		}
+	}
+}
+
+// webhookRecord 建立帶有目前所有 ID 計數器的 webhook 記錄
+func (s *MemoryStore) webhookRecord(op string, nextEndpointID, nextDeliveryID uint64) journalRecord {
+	return journalRecord{
+		Op:                    op,
+		NextID:                s.nextID,
+		NextOutboxID:          s.nextOutboxID,
+		NextDeadLetterID:      s.nextDeadLetterID,
+		NextWebhookEndpointID: nextEndpointID,
+		NextWebhookDeliveryID: nextDeliveryID,
+	}
+}
+
+// persistWebhook 將端點與投遞紀錄寫入同一筆 append log 記錄，呼叫端須持有寫鎖
+func (s *MemoryStore) persistWebhook(e *model.WebhookEndpoint, deliveries []*model.WebhookDelivery, nextEndpointID, nextDeliveryID uint64) error {
+	if s.journal == nil {
+		return nil
+	}
+	rec := s.webhookRecord(opPutWebhook, nextEndpointID, nextDeliveryID)
+	if e != nil {
+		pe := toPersistedWebhookEndpoint(e)
+		rec.WebhookEndpoint = &pe
+	}
+	for _, d := range deliveries {
+		rec.WebhookDeliveries = append(rec.WebhookDeliveries, toPersistedWebhookDelivery(d))
+	}
+	return s.journal.append(rec)
+}
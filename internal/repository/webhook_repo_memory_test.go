@@ -0,0 +1,141 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestWebhookRepositoryMemory_PersistsAcrossRestart(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	cfg := configs.MemoryStoreConfig{DataDir: t.TempDir(), FsyncPolicy: repository.FsyncAlways}
+
+	store, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	repo := store.Webhooks()
+
+	kept := &model.WebhookEndpoint{URL: "https://partner.example.com/hooks", Secret: "kept-secret-0123456789", EventTypes: "*", Enabled: true}
+	dropped := &model.WebhookEndpoint{URL: "https://old.example.com/hooks", Secret: "dropped-secret-012345", EventTypes: "*", Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, kept))
+	require.NoError(t, repo.CreateEndpoint(ctx, dropped))
+
+	now := time.Now()
+	deliveries := []*model.WebhookDelivery{
+		{EndpointID: kept.ID, EventID: "evt-1", EventType: "wallet.debited", Payload: []byte(`{"n":1}`), Status: model.WebhookDeliveryPending, NextAttemptAt: now},
+		{EndpointID: dropped.ID, EventID: "evt-1", EventType: "wallet.debited", Payload: []byte(`{"n":1}`), Status: model.WebhookDeliveryPending, NextAttemptAt: now},
+	}
+	require.NoError(t, repo.EnqueueDeliveries(ctx, deliveries))
+	assert.EqualValues(t, []uint64{1, 2}, []uint64{deliveries[0].ID, deliveries[1].ID})
+
+	claimed, err := repo.ClaimDueDeliveries(ctx, now, time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	disabled, err := repo.RecordAttempt(ctx, deliveries[0].ID, claimed[0].ClaimedBy, repository.WebhookAttempt{
+		AttemptedAt: now, StatusCode: 500, Error: "HTTP 500", GiveUp: true,
+	}, 1)
+	require.NoError(t, err)
+	assert.True(t, disabled)
+	require.NoError(t, repo.DeleteEndpoint(ctx, dropped.ID))
+
+	// 不經快照直接重播 append log
+	reopened, err := repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	repo = reopened.Webhooks()
+
+	endpoint, err := repo.GetEndpoint(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Equal(t, kept.Secret, endpoint.Secret)
+	assert.False(t, endpoint.Enabled)
+	assert.Equal(t, 1, endpoint.ConsecutiveFailures)
+	_, err = repo.GetEndpoint(ctx, dropped.ID)
+	assert.ErrorIs(t, err, repository.ErrWebhookEndpointNotFound)
+
+	delivery, err := repo.GetDelivery(ctx, deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, `{"n":1}`, string(delivery.Payload))
+	_, err = repo.GetDelivery(ctx, deliveries[1].ID)
+	assert.ErrorIs(t, err, repository.ErrWebhookDeliveryNotFound)
+
+	require.NoError(t, repo.RetryDelivery(ctx, delivery.ID, now))
+	require.NoError(t, reopened.Close())
+
+	// 經快照還原，ID 計數器不會倒退
+	reopened, err = repository.OpenMemoryStore(cfg)
+	require.NoError(t, err)
+	defer reopened.Close()
+	repo = reopened.Webhooks()
+
+	delivery, err = repo.GetDelivery(ctx, deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.Attempts)
+
+	e := &model.WebhookEndpoint{URL: "https://new.example.com/hooks", Secret: "new-secret-0123456789", EventTypes: "*", Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, e))
+	assert.EqualValues(t, 3, e.ID)
+	d := &model.WebhookDelivery{EndpointID: e.ID, EventID: "evt-2", Status: model.WebhookDeliveryPending, NextAttemptAt: now}
+	require.NoError(t, repo.EnqueueDeliveries(ctx, []*model.WebhookDelivery{d}))
+	assert.EqualValues(t, 3, d.ID)
+}
+
+func TestWebhookRepositoryMemory_ClaimDueDeliveries(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	repo := repository.NewMemoryStore().Webhooks()
+
+	e := &model.WebhookEndpoint{URL: "https://partner.example.com/hooks", Secret: "secret-0123456789abc", EventTypes: "*", Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, e))
+	now := time.Now()
+	var deliveries []*model.WebhookDelivery
+	for _, id := range []string{"evt-1", "evt-2"} {
+		deliveries = append(deliveries, &model.WebhookDelivery{EndpointID: e.ID, EventID: id, Status: model.WebhookDeliveryPending, NextAttemptAt: now})
+	}
+	require.NoError(t, repo.EnqueueDeliveries(ctx, deliveries))
+
+	// 兩個實例的認領不重疊
+	first, err := repo.ClaimDueDeliveries(ctx, now, time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	second, err := repo.ClaimDueDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.NotEqual(t, first[0].ID, second[0].ID)
+	assert.NotEqual(t, first[0].ClaimedBy, second[0].ClaimedBy)
+
+	// 認領逾期後由其他實例接手，原實例的投遞結果不計入端點的失敗次數
+	taken, err := repo.ClaimDueDeliveries(ctx, now.Add(time.Minute), time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, taken, 1)
+	assert.Equal(t, first[0].ID, taken[0].ID)
+
+	failed := repository.WebhookAttempt{AttemptedAt: now, StatusCode: 500, Error: "HTTP 500", NextAttemptAt: now.Add(time.Hour)}
+	_, err = repo.RecordAttempt(ctx, first[0].ID, first[0].ClaimedBy, failed, 1)
+	assert.ErrorIs(t, err, repository.ErrWebhookClaimLost)
+	endpoint, err := repo.GetEndpoint(ctx, e.ID)
+	require.NoError(t, err)
+	assert.Zero(t, endpoint.ConsecutiveFailures)
+	assert.True(t, endpoint.Enabled)
+
+	_, err = repo.RecordAttempt(ctx, taken[0].ID, taken[0].ClaimedBy, failed, 0)
+	require.NoError(t, err)
+	delivery, err := repo.GetDelivery(ctx, taken[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivery.Attempts)
+
+	// 釋放的紀錄立即可再被認領
+	require.NoError(t, repo.ReleaseDeliveries(ctx, []uint64{second[0].ID}))
+	again, err := repo.ClaimDueDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+	assert.Equal(t, second[0].ID, again[0].ID)
+}
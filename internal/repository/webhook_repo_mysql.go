@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/pkg/database"
+)
+
+// webhookRepositoryMySQL 使用 GORM 實作 WebhookRepository
+type webhookRepositoryMySQL struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepositoryMySQL 建立一個新的 webhookRepositoryMySQL
+func NewWebhookRepositoryMySQL(db *gorm.DB) WebhookRepository {
+	return &webhookRepositoryMySQL{db: db}
+}
+
+// CreateEndpoint 新增 webhook 端點
+func (r *webhookRepositoryMySQL) CreateEndpoint(ctx context.Context, e *model.WebhookEndpoint) error {
+	if err := database.WithContext(ctx).Create(e).Error; err != nil {
+		return fmt.Errorf("新增 webhook 端點失敗: %w", err)
+	}
+	return nil
+}
+
+// ListEndpoints 依 ID 順序回傳所有 webhook 端點
+// 端點設定會影響事件分送，因此一律讀取主庫
+func (r *webhookRepositoryMySQL) ListEndpoints(ctx context.Context) ([]*model.WebhookEndpoint, error) {
+	var endpoints []*model.WebhookEndpoint
+	if err := database.WithContext(ctx).Order("id").Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("查詢 webhook 端點失敗: %w", err)
+	}
+	return endpoints, nil
+}
+
+// GetEndpoint 根據 ID 取得 webhook 端點
+func (r *webhookRepositoryMySQL) GetEndpoint(ctx context.Context, id uint64) (*model.WebhookEndpoint, error) {
+	var e model.WebhookEndpoint
+	if err := database.WithContext(ctx).First(&e, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookEndpointNotFound
+		}
+		return nil, fmt.Errorf("取得 webhook 端點失敗: %w", err)
+	}
+	return &e, nil
+}
+
+// UpdateEndpoint 更新 webhook 端點
+func (r *webhookRepositoryMySQL) UpdateEndpoint(ctx context.Context, e *model.WebhookEndpoint) error {
+	result := database.WithContext(ctx).Model(e).Select("*").Omit("created_at").Updates(e)
+	if result.Error != nil {
+		return fmt.Errorf("更新 webhook 端點失敗: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookEndpointNotFound
+	}
+	return nil
+}
+
+// DeleteEndpoint 刪除 webhook 端點及其投遞紀錄
+func (r *webhookRepositoryMySQL) DeleteEndpoint(ctx context.Context, id uint64) error {
+	return database.Transaction(ctx, func(ctx context.Context) error {
+		tx := database.WithContext(ctx)
+		result := tx.Delete(&model.WebhookEndpoint{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("刪除 webhook 端點失敗: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookEndpointNotFound
+		}
+		if err := tx.Where("endpoint_id = ?", id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return fmt.Errorf("刪除 webhook 投遞紀錄失敗: %w", err)
+		}
+		return nil
+	})
+}
+
+// EnqueueDeliveries 新增待投遞的紀錄
+func (r *webhookRepositoryMySQL) EnqueueDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := database.WithContext(ctx).Create(&deliveries).Error; err != nil {
+		return fmt.Errorf("新增 webhook 投遞紀錄失敗: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveries 以單一 UPDATE 認領到期的待投遞紀錄，再讀取本次認領的紀錄
+// UPDATE 會鎖定並重新檢查符合條件的資料列，兩個實例同時認領時不會取得相同的紀錄
+func (r *webhookRepositoryMySQL) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WebhookDelivery, error) {
+	token := uuid.NewString()
+	enabled := database.WithContext(ctx).Model(&model.WebhookEndpoint{}).Select("id").Where("enabled = ?", true)
+	err := database.WithContext(ctx).Model(&model.WebhookDelivery{}).
+		Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+		Where("claimed_until IS NULL OR claimed_until <= ?", now).
+		Where("endpoint_id IN (?)", enabled).
+		Order("id").
+		Limit(limit).
+		Updates(map[string]any{"claimed_by": token, "claimed_until": now.Add(lease)}).Error
+	if err != nil {
+		return nil, fmt.Errorf("認領到期的 webhook 投遞紀錄失敗: %w", err)
+	}
+
+	var deliveries []*model.WebhookDelivery
+	if err := database.WithContext(ctx).Where("claimed_by = ?", token).Order("id").Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("查詢認領的 webhook 投遞紀錄失敗: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ReleaseDeliveries 釋放未投遞的認領
+func (r *webhookRepositoryMySQL) ReleaseDeliveries(ctx context.Context, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := database.WithContext(ctx).Model(&model.WebhookDelivery{}).
+		Where("id IN ?", ids).
+		Updates(map[string]any{"claimed_by": "", "claimed_until": nil}).Error
+	if err != nil {
+		return fmt.Errorf("釋放 webhook 投遞紀錄的認領失敗: %w", err)
+	}
+	return nil
+}
+
+// RecordAttempt 在同一個交易中更新投遞紀錄與端點的連續失敗次數
+func (r *webhookRepositoryMySQL) RecordAttempt(ctx context.Context, deliveryID uint64, claimedBy string, attempt WebhookAttempt, disableAfter int) (bool, error) {
+	var disabled bool
+	err := database.Transaction(ctx, func(ctx context.Context) error {
+		tx := database.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"})
+
+		var d model.WebhookDelivery
+		if err := tx.First(&d, deliveryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWebhookDeliveryNotFound
+			}
+			return err
+		}
+		if d.ClaimedBy != claimedBy || d.Status != model.WebhookDeliveryPending {
+			return ErrWebhookClaimLost
+		}
+		var e model.WebhookEndpoint
+		if err := tx.First(&e, d.EndpointID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWebhookEndpointNotFound
+			}
+			return err
+		}
+
+		applyWebhookAttempt(&d, attempt)
+		disabled = applyEndpointAttempt(&e, attempt, disableAfter)
+		if err := database.WithContext(ctx).Save(&d).Error; err != nil {
+			return err
+		}
+		return database.WithContext(ctx).Save(&e).Error
+	})
+	if errors.Is(err, ErrWebhookClaimLost) {
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("記錄 webhook 投遞結果失敗: %w", err)
+	}
+	return disabled, nil
+}
+
+// ListDeliveries 查詢投遞紀錄
+func (r *webhookRepositoryMySQL) ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]*model.WebhookDelivery, error) {
+	q := database.ReadContext(ctx).Where("id > ?", filter.AfterID)
+	if filter.EndpointID != 0 {
+		q = q.Where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+
+	var deliveries []*model.WebhookDelivery
+	if err := q.Order("id").Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("查詢 webhook 投遞紀錄失敗: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetDelivery 根據 ID 取得投遞紀錄
+func (r *webhookRepositoryMySQL) GetDelivery(ctx context.Context, id uint64) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	if err := database.ReadContext(ctx).First(&d, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("取得 webhook 投遞紀錄失敗: %w", err)
+	}
+	return &d, nil
+}
+
+// RetryDelivery 將投遞紀錄重設為待投遞並重新開始重試排程
+func (r *webhookRepositoryMySQL) RetryDelivery(ctx context.Context, id uint64, now time.Time) error {
+	result := database.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("id = ?", id).
+		Updates(map[string]any{"status": model.WebhookDeliveryPending, "attempts": 0, "next_attempt_at": now, "claimed_by": "", "claimed_until": nil})
+	if result.Error != nil {
+		return fmt.Errorf("重設 webhook 投遞紀錄失敗: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/logger"
+)
+
+// auditVerifyPageSize 是驗證雜湊鏈時每次讀取的紀錄筆數
+const auditVerifyPageSize = 500
+
+// AuditService 定義稽核紀錄的查詢與驗證操作
+type AuditService interface {
+	List(ctx context.Context, filter repository.AuditLogFilter) ([]model.AuditLog, error)
+	// Verify 從第一筆開始驗證雜湊鏈，回報第一筆 ID 不連續、PrevHash 不符或內容雜湊不符的紀錄
+	Verify(ctx context.Context) (*model.AuditVerifyResponse, error)
+}
+
+// auditService 實作 AuditService
+type auditService struct {
+	repo repository.AuditLogRepository
+}
+
+// NewAuditService 建立一個新的 AuditService
+func NewAuditService(repo repository.AuditLogRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// List 查詢稽核紀錄
+func (s *auditService) List(ctx context.Context, filter repository.AuditLogFilter) ([]model.AuditLog, error) {
+	logs, err := s.repo.List(ctx, filter)
+	if err != nil {
+		logger.FromContext(ctx).Error("查詢稽核紀錄失敗", zap.Error(err))
+		return nil, apperrors.Internal("AUDIT_LIST_FAILED", "查詢稽核紀錄失敗", err)
+	}
+
+	resp := make([]model.AuditLog, 0, len(logs))
+	for _, e := range logs {
+		resp = append(resp, *e)
+	}
+	return resp, nil
+}
+
+// Verify 驗證雜湊鏈
+func (s *auditService) Verify(ctx context.Context) (*model.AuditVerifyResponse, error) {
+	resp := &model.AuditVerifyResponse{Valid: true}
+	for {
+		page, err := s.repo.List(ctx, repository.AuditLogFilter{AfterID: resp.LastID, Limit: auditVerifyPageSize})
+		if err != nil {
+			logger.FromContext(ctx).Error("讀取稽核紀錄失敗", zap.Error(err))
+			return nil, apperrors.Internal("AUDIT_VERIFY_FAILED", "驗證稽核紀錄失敗", err)
+		}
+		for _, e := range page {
+			if reason := verifyLink(resp, e); reason != "" {
+				resp.Valid, resp.BrokenAtID, resp.Reason = false, e.ID, reason
+				logger.FromContext(ctx).Error("稽核紀錄雜湊鏈驗證失敗",
+					zap.Uint64("id", e.ID), zap.String("reason", reason))
+				return resp, nil
+			}
+			resp.Checked++
+			resp.LastID, resp.LastHash = e.ID, e.Hash
+		}
+		if len(page) < auditVerifyPageSize {
+			return resp, nil
+		}
+	}
+}
+
+// verifyLink 檢查 e 是否正確接在已驗證的最後一筆之後，回傳失敗原因，通過時回傳空字串
+func verifyLink(verified *model.AuditVerifyResponse, e *model.AuditLog) string {
+	switch {
+	case e.ID != verified.LastID+1:
+		return fmt.Sprintf("紀錄 ID 不連續，預期 %d", verified.LastID+1)
+	case e.PrevHash != verified.LastHash:
+		return "前一筆雜湊不符，紀錄可能遭刪除或插入"
+	case e.Hash != e.ComputeHash():
+		return "內容雜湊不符，紀錄可能遭修改"
+	}
+	return ""
+}
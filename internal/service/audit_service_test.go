@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/internal/service"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestAuditService_Verify(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		tamper     func(lines []string) []string
+		wantValid  bool
+		wantBroken uint64
+		wantReason string
+	}{
+		{name: "Intact", tamper: func(lines []string) []string { return lines }, wantValid: true},
+		{
+			name: "Modified",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `:100}`, `:1000000}`, 1)
+				return lines
+			},
+			wantBroken: 2, wantReason: "內容雜湊不符",
+		},
+		{
+			name:       "Deleted",
+			tamper:     func(lines []string) []string { return append(lines[:1], lines[2:]...) },
+			wantBroken: 3, wantReason: "ID 不連續",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			repo, err := repository.OpenAuditLogJSONL(path)
+			require.NoError(t, err)
+			for _, after := range []string{`{"balance":0}`, `{"balance":100}`, `{"balance":50}`} {
+				require.NoError(t, repo.Append(ctx, &model.AuditLog{
+					Action: model.AuditActionBalanceChange, ActorType: model.AuditActorSystem, TargetID: "7", After: after,
+				}))
+			}
+			require.NoError(t, repo.Close())
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			lines := tt.tamper(strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"))
+			require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+
+			reopened, err := repository.OpenAuditLogJSONL(path)
+			require.NoError(t, err)
+			defer reopened.Close()
+
+			resp, err := service.NewAuditService(reopened).Verify(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantValid, resp.Valid)
+			assert.Equal(t, tt.wantBroken, resp.BrokenAtID)
+			assert.Contains(t, resp.Reason, tt.wantReason)
+			if tt.wantValid {
+				assert.Equal(t, 3, resp.Checked)
+				assert.EqualValues(t, 3, resp.LastID)
+			}
+		})
+	}
+}
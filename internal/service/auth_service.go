@@ -2,30 +2,40 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"go.uber.org/zap"
 
+	"microservice-mvp/internal/audit"
 	"microservice-mvp/internal/model"
 	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/events"
 	"microservice-mvp/pkg/logger"
 )
 
+// ErrInvalidCredentials 代表使用者名稱或密碼錯誤
+// 兩種情況刻意回傳相同錯誤，避免洩漏使用者名稱是否存在
+var ErrInvalidCredentials = apperrors.Unauthorized("INVALID_CREDENTIALS", "憑證無效")
+
 // AuthService 定義認證操作的介面
 type AuthService interface {
+	// Login 驗證玩家，成功時透過 outbox 可靠地發送玩家登入事件
 	Login(ctx context.Context, req *model.LoginRequest) (*model.LoginResponse, error)
-	// 在 MVP 中，我們保持密碼處理簡單。在真實應用中，應使用 bcrypt。
-	// 我們可能也需要 Register 方法，但在 MVP 中，假設玩家已存在。
 }
 
 // authService 實作 AuthService
 type authService struct {
 	playerRepo repository.PlayerRepository
+	outboxRepo repository.OutboxRepository
 }
 
 // NewAuthService 建立一個新的 authService
-func NewAuthService(playerRepo repository.PlayerRepository) AuthService {
-	return &authService{playerRepo: playerRepo}
+func NewAuthService(playerRepo repository.PlayerRepository, outboxRepo repository.OutboxRepository) AuthService {
+	return &authService{playerRepo: playerRepo, outboxRepo: outboxRepo}
 }
 
 // Login 驗證玩家
@@ -33,25 +43,67 @@ func (s *authService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 	log := logger.FromContext(ctx)
 
 	player, err := s.playerRepo.GetPlayerByUsername(ctx, req.Username)
-	if err != nil {
+	if err != nil && !errors.Is(err, repository.ErrPlayerNotFound) {
 		log.Error("取得玩家進行登入失敗", zap.Error(err), zap.String("username", req.Username))
-		return nil, fmt.Errorf("認證失敗: %w", err)
+		return nil, apperrors.Internal("AUTH_FAILED", "認證失敗", err)
 	}
 	if player == nil {
 		log.Warn("嘗試使用不存在的使用者名稱登入", zap.String("username", req.Username))
-		return nil, fmt.Errorf("憑證無效")
+		recordLoginFailed(ctx, req.Username, "使用者名稱不存在")
+		return nil, ErrInvalidCredentials
 	}
 
 	// 在真實應用程式中，應使用 bcrypt 比較雜湊密碼。
 	// 對於 MVP，僅進行簡單字串比較作為演示。
 	if player.Password != req.Password {
 		log.Warn("嘗試使用錯誤密碼登入", zap.String("username", req.Username))
-		return nil, fmt.Errorf("憑證無效")
+		recordLoginFailed(ctx, req.Username, "密碼錯誤")
+		return nil, ErrInvalidCredentials
 	}
 
 	// 對於 MVP，回傳一個虛擬 Token。在真實應用中，應生成 JWT。
 	token := fmt.Sprintf("mock-jwt-token-for-player-%d", player.ID)
 
+	playerID := strconv.FormatUint(uint64(player.ID), 10)
+	// 登入事件寫入 outbox 後才回傳 Token，確保每次成功登入都會發出事件
+	if err := s.enqueueLoggedIn(ctx, player.ID, playerID); err != nil {
+		log.Error("寫入玩家登入事件失敗", zap.Error(err), zap.Uint("playerID", player.ID))
+		return nil, apperrors.Internal("AUTH_FAILED", "認證失敗", err)
+	}
+
 	log.Info("玩家登入成功", zap.Uint("playerID", player.ID), zap.String("username", player.Username))
+	audit.Record(ctx, audit.Entry{
+		Action: model.AuditActionLogin,
+		Actor:  audit.Actor{Type: model.AuditActorPlayer, ID: playerID},
+		Target: audit.Target{Type: model.AuditTargetPlayer, ID: playerID},
+	})
 	return &model.LoginResponse{Token: token}, nil
-}
\ No newline at end of file
+}
+
+// recordLoginFailed 記錄登入失敗，稽核紀錄只供內部查閱，因此保留實際的失敗原因
+func recordLoginFailed(ctx context.Context, username, reason string) {
+	audit.Record(ctx, audit.Entry{
+		Action: model.AuditActionLoginFailed,
+		Result: model.AuditResultFailure,
+		Actor:  audit.Actor{Type: model.AuditActorAnonymous},
+		Target: audit.Target{Type: model.AuditTargetPlayer, ID: username},
+		Reason: reason,
+	})
+}
+
+// enqueueLoggedIn 將 player.logged_in 事件寫入 outbox，由 relay 發送
+func (s *authService) enqueueLoggedIn(ctx context.Context, id uint, playerID string) error {
+	payload := events.PlayerLoggedIn{PlayerID: id, LoginAt: time.Now()}
+	if c, ok := audit.ClientFromContext(ctx); ok {
+		payload.IP = c.IP
+	}
+	env, err := events.New(ctx, playerID, payload)
+	if err != nil {
+		return err
+	}
+	ev, err := repository.NewEnvelopeOutboxEvent(ctx, events.Default, env, events.Default.JSONCodec())
+	if err != nil {
+		return err
+	}
+	return s.outboxRepo.Enqueue(ctx, []*model.OutboxEvent{ev})
+}
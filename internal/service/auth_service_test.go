@@ -4,13 +4,19 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"microservice-mvp/internal/audit"
 	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
 	"microservice-mvp/internal/repository/mocks"
 	"microservice-mvp/internal/service"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/events"
 	"microservice-mvp/pkg/logger"
 )
 
@@ -24,6 +30,7 @@ func TestAuthService_Login(t *testing.T) {
 		mockBehavior  func(m *mocks.MockPlayerRepository)
 		expectedToken string
 		expectedError string
+		expectedIs    error
 	}{
 		{
 			name: "Success",
@@ -52,6 +59,7 @@ func TestAuthService_Login(t *testing.T) {
 			},
 			expectedToken: "",
 			expectedError: "憑證無效",
+			expectedIs:    apperrors.ErrUnauthorized,
 		},
 		{
 			name: "WrongPassword",
@@ -68,6 +76,7 @@ func TestAuthService_Login(t *testing.T) {
 			},
 			expectedToken: "",
 			expectedError: "憑證無效",
+			expectedIs:    apperrors.ErrUnauthorized,
 		},
 		{
 			name: "RepositoryError",
@@ -80,6 +89,7 @@ func TestAuthService_Login(t *testing.T) {
 			},
 			expectedToken: "",
 			expectedError: "認證失敗: db error",
+			expectedIs:    apperrors.ErrInternal,
 		},
 	}
 
@@ -88,12 +98,13 @@ func TestAuthService_Login(t *testing.T) {
 			mockRepo := new(mocks.MockPlayerRepository)
 			tt.mockBehavior(mockRepo)
 
-			authService := service.NewAuthService(mockRepo)
+			authService := service.NewAuthService(mockRepo, repository.NewMemoryStore().Outbox())
 			resp, err := authService.Login(context.Background(), tt.loginRequest)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.ErrorIs(t, err, tt.expectedIs)
 				assert.Nil(t, resp)
 			} else {
 				assert.NoError(t, err)
@@ -105,3 +116,62 @@ func TestAuthService_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthService_LoginEnqueuesEvent(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := logger.WithTraceID(context.Background(), "trace-login")
+	ctx = audit.WithClient(ctx, audit.Client{IP: "203.0.113.9"})
+
+	store := repository.NewMemoryStore()
+	player := &model.Player{Username: "testuser", Password: "password123"}
+	require.NoError(t, store.Players().CreatePlayer(ctx, player))
+
+	authService := service.NewAuthService(store.Players(), store.Outbox())
+
+	_, err := authService.Login(ctx, &model.LoginRequest{Username: "testuser", Password: "wrongpassword"})
+	require.Error(t, err)
+	stats, err := store.Outbox().Stats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Pending, "登入失敗不應產生事件")
+
+	_, err = authService.Login(ctx, &model.LoginRequest{Username: "testuser", Password: "password123"})
+	require.NoError(t, err)
+
+	due, err := store.Outbox().ClaimDue(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	ev := due[0]
+	assert.Equal(t, events.TopicPlayerEvents, ev.Topic)
+	assert.Equal(t, events.TypePlayerLoggedIn, ev.Tag)
+	assert.Equal(t, "1", ev.Key)
+	assert.Equal(t, "trace-login", ev.TraceID)
+
+	env, err := events.Default.JSONCodec().Unmarshal(ev.Payload)
+	require.NoError(t, err)
+	payload, ok := env.Payload.(*events.PlayerLoggedIn)
+	require.True(t, ok)
+	assert.Equal(t, player.ID, payload.PlayerID)
+	assert.Equal(t, "203.0.113.9", payload.IP)
+}
+
+func TestAuthService_LoginFailsWhenEventNotRecorded(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+
+	mockRepo := new(mocks.MockPlayerRepository)
+	mockRepo.On("GetPlayerByUsername", mock.Anything, "testuser").Return(&model.Player{ID: 1, Username: "testuser", Password: "password123"}, nil)
+
+	authService := service.NewAuthService(mockRepo, failingOutbox{OutboxRepository: repository.NewMemoryStore().Outbox()})
+	resp, err := authService.Login(ctx, &model.LoginRequest{Username: "testuser", Password: "password123"})
+	assert.ErrorIs(t, err, apperrors.ErrInternal)
+	assert.Nil(t, resp, "事件未寫入時不應發出 Token")
+}
+
+// failingOutbox 讓 Enqueue 一律失敗
+type failingOutbox struct {
+	repository.OutboxRepository
+}
+
+func (failingOutbox) Enqueue(ctx context.Context, events []*model.OutboxEvent) error {
+	return errors.New("outbox unavailable")
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/consumer"
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+// replayStrippedProperties 是重新投遞時移除的死信相關屬性
+// Broker 在投遞與重試時加上的系統屬性另以 messaging.IsReservedProperty 判斷
+var replayStrippedProperties = map[string]bool{
+	messaging.PropertyOriginTopic:     true,
+	messaging.PropertyOriginMsgID:     true,
+	consumer.PropertyDeadLetterReason: true,
+	// 重新投遞屬於新的 trace，由發送時重新寫入
+	messaging.PropertyTraceParent: true,
+	messaging.PropertyTraceState:  true,
+}
+
+// DeadLetterService 定義死信的管理操作
+type DeadLetterService interface {
+	List(ctx context.Context, filter repository.DeadLetterFilter) ([]model.DeadLetterResponse, error)
+	Get(ctx context.Context, id uint64) (*model.DeadLetterResponse, error)
+	// Replay 將死信以原本的 Topic、標籤、Keys 與屬性重新投遞，成功後刪除該死信
+	Replay(ctx context.Context, id uint64) (*model.ReplayDeadLetterResponse, error)
+	Delete(ctx context.Context, id uint64) error
+	// Purge 清除指定 Topic (空字串代表全部) 的死信
+	Purge(ctx context.Context, topic string) (*model.PurgeDeadLettersResponse, error)
+}
+
+// deadLetterService 實作 DeadLetterService
+type deadLetterService struct {
+	repo    repository.DeadLetterRepository
+	publish consumer.Publisher
+}
+
+// NewDeadLetterService 建立一個新的 DeadLetterService，publish 為 nil 時使用 messaging.Send
+func NewDeadLetterService(repo repository.DeadLetterRepository, publish consumer.Publisher) DeadLetterService {
+	if publish == nil {
+		publish = messaging.Send
+	}
+	return &deadLetterService{repo: repo, publish: publish}
+}
+
+// List 查詢死信
+func (s *deadLetterService) List(ctx context.Context, filter repository.DeadLetterFilter) ([]model.DeadLetterResponse, error) {
+	letters, err := s.repo.ListDeadLetters(ctx, filter)
+	if err != nil {
+		logger.FromContext(ctx).Error("查詢死信失敗", zap.Error(err))
+		return nil, apperrors.Internal("DEAD_LETTER_LIST_FAILED", "查詢死信失敗", err)
+	}
+
+	resp := make([]model.DeadLetterResponse, 0, len(letters))
+	for _, d := range letters {
+		resp = append(resp, d.ToDeadLetterResponse())
+	}
+	return resp, nil
+}
+
+// Get 取得單筆死信
+func (s *deadLetterService) Get(ctx context.Context, id uint64) (*model.DeadLetterResponse, error) {
+	d, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := d.ToDeadLetterResponse()
+	return &resp, nil
+}
+
+// Replay 重新投遞死信
+// 發送成功但刪除失敗時會回傳錯誤，該死信仍保留，再次重新投遞會產生重複訊息
+func (s *deadLetterService) Replay(ctx context.Context, id uint64) (*model.ReplayDeadLetterResponse, error) {
+	log := logger.FromContext(ctx)
+
+	d, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := messaging.NewMessage(d.Topic, d.Tag, d.Body, strings.Fields(d.Keys))
+	var props map[string]string
+	if err := json.Unmarshal([]byte(d.Properties), &props); err != nil {
+		log.Warn("死信屬性格式錯誤，僅保留標籤與 Keys", zap.Error(err), zap.Uint64("id", id))
+	}
+	for name, value := range props {
+		if !messaging.IsReservedProperty(name) && !replayStrippedProperties[name] {
+			msg.WithProperty(name, value)
+		}
+	}
+
+	result, err := s.publish(ctx, msg)
+	if err != nil {
+		log.Error("重新投遞死信失敗", zap.Error(err), zap.Uint64("id", id), zap.String("topic", d.Topic))
+		return nil, apperrors.Internal("DEAD_LETTER_REPLAY_FAILED", "重新投遞死信失敗", err)
+	}
+	if err := s.repo.DeleteDeadLetter(ctx, id); err != nil && !errors.Is(err, repository.ErrDeadLetterNotFound) {
+		log.Error("死信已重新投遞但刪除失敗", zap.Error(err), zap.Uint64("id", id))
+		return nil, apperrors.Internal("DEAD_LETTER_DELETE_FAILED", "死信已重新投遞但刪除失敗", err)
+	}
+
+	resp := &model.ReplayDeadLetterResponse{ID: id, Topic: d.Topic}
+	if result != nil {
+		resp.MsgID = result.MsgID
+	}
+	log.Info("死信已重新投遞", zap.Uint64("id", id), zap.String("topic", d.Topic), zap.String("msgID", resp.MsgID))
+	return resp, nil
+}
+
+// Delete 刪除單筆死信
+func (s *deadLetterService) Delete(ctx context.Context, id uint64) error {
+	err := s.repo.DeleteDeadLetter(ctx, id)
+	if errors.Is(err, repository.ErrDeadLetterNotFound) {
+		return err
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("刪除死信失敗", zap.Error(err), zap.Uint64("id", id))
+		return apperrors.Internal("DEAD_LETTER_DELETE_FAILED", "刪除死信失敗", err)
+	}
+	logger.FromContext(ctx).Info("死信已刪除", zap.Uint64("id", id))
+	return nil
+}
+
+// Purge 清除死信
+func (s *deadLetterService) Purge(ctx context.Context, topic string) (*model.PurgeDeadLettersResponse, error) {
+	n, err := s.repo.PurgeDeadLetters(ctx, topic)
+	if err != nil {
+		logger.FromContext(ctx).Error("清除死信失敗", zap.Error(err), zap.String("topic", topic))
+		return nil, apperrors.Internal("DEAD_LETTER_PURGE_FAILED", "清除死信失敗", err)
+	}
+	logger.FromContext(ctx).Info("死信已清除", zap.String("topic", topic), zap.Int64("purged", n))
+	return &model.PurgeDeadLettersResponse{Purged: n}, nil
+}
+
+func (s *deadLetterService) get(ctx context.Context, id uint64) (*model.DeadLetter, error) {
+	d, err := s.repo.GetDeadLetter(ctx, id)
+	if errors.Is(err, repository.ErrDeadLetterNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("取得死信失敗", zap.Error(err), zap.Uint64("id", id))
+		return nil, apperrors.Internal("DEAD_LETTER_LOOKUP_FAILED", "取得死信失敗", err)
+	}
+	return d, nil
+}
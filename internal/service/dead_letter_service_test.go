@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/internal/service"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+func TestDeadLetterService_Replay(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		publishErr  error
+		wantErrKind apperrors.Kind
+		wantKept    bool
+	}{
+		{name: "Success"},
+		{name: "PublishFailed", publishErr: errors.New("broker down"), wantErrKind: apperrors.KindInternal, wantKept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMemoryStore().DeadLetters()
+			d := &model.DeadLetter{
+				ConsumerGroup: "CID_Test",
+				Topic:         "player_events",
+				Tag:           "player.registered",
+				Keys:          "42",
+				MsgID:         "msg-1",
+				Body:          []byte(`{"id":"ev-1"}`),
+				Properties:    `{"TAGS":"player.registered","KEYS":"42","TRACE_ID":"trace-1","DELAY":"3","RECONSUME_TIME":"5","ORIGIN_MESSAGE_ID":"msg-0"}`,
+				Reason:        "handler failed",
+			}
+			require.NoError(t, repo.SaveDeadLetter(ctx, d))
+
+			var sent *messaging.Message
+			svc := service.NewDeadLetterService(repo, func(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error) {
+				sent = msg
+				if tt.publishErr != nil {
+					return nil, tt.publishErr
+				}
+				return &messaging.SendResult{MsgID: "msg-2"}, nil
+			})
+
+			resp, err := svc.Replay(ctx, d.ID)
+			if tt.publishErr != nil {
+				assert.Equal(t, tt.wantErrKind, apperrors.From(err).Kind)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, &model.ReplayDeadLetterResponse{ID: d.ID, Topic: "player_events", MsgID: "msg-2"}, resp)
+			}
+
+			require.NotNil(t, sent)
+			assert.Equal(t, "player_events", sent.Topic)
+			assert.Equal(t, "player.registered", sent.Tag)
+			assert.Equal(t, []string{"42"}, sent.Keys)
+			assert.Equal(t, "trace-1", sent.Property(messaging.PropertyTraceID))
+			assert.Empty(t, sent.Property("DELAY"), "重新投遞不應沿用延遲等級")
+			assert.Empty(t, sent.Property("RECONSUME_TIME"))
+			assert.Empty(t, sent.Property(messaging.PropertyOriginMsgID))
+			assert.Equal(t, map[string]string{messaging.PropertyTraceID: "trace-1"}, sent.Properties, "標籤與 Keys 不應重複放入屬性")
+
+			_, err = repo.GetDeadLetter(ctx, d.ID)
+			if tt.wantKept {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, repository.ErrDeadLetterNotFound)
+			}
+		})
+	}
+}
+
+func TestDeadLetterService_NotFound(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	svc := service.NewDeadLetterService(repository.NewMemoryStore().DeadLetters(), nil)
+
+	_, err := svc.Get(context.Background(), 1)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	_, err = svc.Replay(context.Background(), 1)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	assert.ErrorIs(t, svc.Delete(context.Background(), 1), apperrors.ErrNotFound)
+}
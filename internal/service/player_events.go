@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/events"
+	"microservice-mvp/pkg/logger"
+)
+
+// HandlePlayerLoggedIn 消費玩家登入事件
+// 目前僅記錄日誌，作為 consumer.Router 的範例 handler (例如之後可改為異常登入偵測)
+func HandlePlayerLoggedIn(ctx context.Context, env *events.Envelope) error {
+	payload, ok := env.Payload.(*events.PlayerLoggedIn)
+	if !ok {
+		return fmt.Errorf("非預期的事件內容型別: %T", env.Payload)
+	}
+
+	logger.FromContext(ctx).Info("收到玩家登入事件",
+		zap.String("eventID", env.ID),
+		zap.Int("version", env.Version),
+		zap.Uint("playerID", payload.PlayerID),
+		zap.String("ip", payload.IP),
+	)
+	return nil
+}
@@ -2,12 +2,13 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"errors"
 
 	"go.uber.org/zap"
 
 	"microservice-mvp/internal/model"
 	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/apperrors"
 	"microservice-mvp/pkg/logger"
 )
 
@@ -31,16 +32,16 @@ func (s *playerService) GetPlayerInfo(ctx context.Context, playerID uint) (*mode
 	log := logger.FromContext(ctx)
 
 	player, err := s.playerRepo.GetPlayerByID(ctx, playerID)
-	if err != nil {
+	if err != nil && !errors.Is(err, repository.ErrPlayerNotFound) {
 		log.Error("從 Repository 取得玩家資訊失敗", zap.Error(err), zap.Uint("playerID", playerID))
-		return nil, fmt.Errorf("檢索玩家資訊失敗: %w", err)
+		return nil, apperrors.Internal("PLAYER_LOOKUP_FAILED", "檢索玩家資訊失敗", err)
 	}
 	if player == nil {
 		log.Warn("找不到玩家", zap.Uint("playerID", playerID))
-		return nil, fmt.Errorf("玩家不存在")
+		return nil, repository.ErrPlayerNotFound
 	}
 
 	log.Info("成功取得玩家資訊", zap.Uint("playerID", playerID))
 	resp := player.ToPlayerInfoResponse()
 	return &resp, nil
-}
\ No newline at end of file
+}
@@ -11,6 +11,7 @@ import (
 	"microservice-mvp/internal/model"
 	"microservice-mvp/internal/repository/mocks"
 	"microservice-mvp/internal/service"
+	"microservice-mvp/pkg/apperrors"
 	"microservice-mvp/pkg/logger"
 )
 
@@ -24,6 +25,7 @@ func TestPlayerService_GetPlayerInfo(t *testing.T) {
 		mockBehavior   func(m *mocks.MockPlayerRepository)
 		expectedPlayer *model.PlayerInfoResponse
 		expectedError  string
+		expectedIs     error
 	}{
 		{
 			name:     "Success",
@@ -50,6 +52,7 @@ func TestPlayerService_GetPlayerInfo(t *testing.T) {
 			},
 			expectedPlayer: nil,
 			expectedError:  "玩家不存在",
+			expectedIs:     apperrors.ErrNotFound,
 		},
 		{
 			name:     "RepositoryError",
@@ -59,6 +62,7 @@ func TestPlayerService_GetPlayerInfo(t *testing.T) {
 			},
 			expectedPlayer: nil,
 			expectedError:  "檢索玩家資訊失敗: db error",
+			expectedIs:     apperrors.ErrInternal,
 		},
 	}
 
@@ -73,6 +77,7 @@ func TestPlayerService_GetPlayerInfo(t *testing.T) {
 			if tt.expectedError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.ErrorIs(t, err, tt.expectedIs)
 				assert.Nil(t, resp)
 			} else {
 				assert.NoError(t, err)
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/apperrors"
+	"microservice-mvp/pkg/events"
+	"microservice-mvp/pkg/logger"
+)
+
+// webhookSecretPrefix 是伺服器產生的簽章金鑰前綴，方便在設定檔或日誌中辨識
+const webhookSecretPrefix = "whsec_"
+
+// ErrInvalidWebhookURL 代表端點 URL 不是 https
+var ErrInvalidWebhookURL = apperrors.Validation("INVALID_WEBHOOK_URL", "webhook 端點須為 https URL")
+
+// WebhookService 定義 webhook 端點與投遞紀錄的管理操作
+type WebhookService interface {
+	// CreateEndpoint 註冊端點，未提供簽章金鑰時由伺服器產生，金鑰只在此時回傳
+	CreateEndpoint(ctx context.Context, req *model.CreateWebhookEndpointRequest) (*model.CreateWebhookEndpointResponse, error)
+	ListEndpoints(ctx context.Context) ([]model.WebhookEndpointResponse, error)
+	GetEndpoint(ctx context.Context, id uint64) (*model.WebhookEndpointResponse, error)
+	// UpdateEndpoint 更新端點，重新啟用時會將連續失敗次數歸零
+	UpdateEndpoint(ctx context.Context, id uint64, req *model.UpdateWebhookEndpointRequest) (*model.WebhookEndpointResponse, error)
+	// DeleteEndpoint 刪除端點及其投遞紀錄
+	DeleteEndpoint(ctx context.Context, id uint64) error
+
+	ListDeliveries(ctx context.Context, filter repository.WebhookDeliveryFilter) ([]model.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id uint64) (*model.WebhookDeliveryResponse, error)
+	// RetryDelivery 立即重新投遞，並重新開始重試排程；端點停用期間不會投遞
+	RetryDelivery(ctx context.Context, id uint64) (*model.WebhookDelivery, error)
+}
+
+// webhookService 實作 WebhookService
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+// NewWebhookService 建立一個新的 WebhookService
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo}
+}
+
+// CreateEndpoint 註冊 webhook 端點
+func (s *webhookService) CreateEndpoint(ctx context.Context, req *model.CreateWebhookEndpointRequest) (*model.CreateWebhookEndpointResponse, error) {
+	log := logger.FromContext(ctx)
+
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			log.Error("產生 webhook 簽章金鑰失敗", zap.Error(err))
+			return nil, apperrors.Internal("WEBHOOK_SECRET_FAILED", "產生簽章金鑰失敗", err)
+		}
+	}
+
+	e := &model.WebhookEndpoint{
+		URL:         req.URL,
+		Description: req.Description,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		Enabled:     true,
+	}
+	if err := s.repo.CreateEndpoint(ctx, e); err != nil {
+		log.Error("註冊 webhook 端點失敗", zap.Error(err))
+		return nil, apperrors.Internal("WEBHOOK_CREATE_FAILED", "註冊 webhook 端點失敗", err)
+	}
+
+	log.Info("webhook 端點已註冊", zap.Uint64("endpointID", e.ID), zap.String("url", e.URL), zap.String("eventTypes", e.EventTypes))
+	return &model.CreateWebhookEndpointResponse{WebhookEndpointResponse: e.ToWebhookEndpointResponse(), Secret: secret}, nil
+}
+
+// ListEndpoints 列出所有 webhook 端點
+func (s *webhookService) ListEndpoints(ctx context.Context) ([]model.WebhookEndpointResponse, error) {
+	endpoints, err := s.repo.ListEndpoints(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("查詢 webhook 端點失敗", zap.Error(err))
+		return nil, apperrors.Internal("WEBHOOK_LIST_FAILED", "查詢 webhook 端點失敗", err)
+	}
+
+	resp := make([]model.WebhookEndpointResponse, 0, len(endpoints))
+	for _, e := range endpoints {
+		resp = append(resp, e.ToWebhookEndpointResponse())
+	}
+	return resp, nil
+}
+
+// GetEndpoint 取得單一 webhook 端點
+func (s *webhookService) GetEndpoint(ctx context.Context, id uint64) (*model.WebhookEndpointResponse, error) {
+	e, err := s.getEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := e.ToWebhookEndpointResponse()
+	return &resp, nil
+}
+
+// UpdateEndpoint 更新 webhook 端點
+func (s *webhookService) UpdateEndpoint(ctx context.Context, id uint64, req *model.UpdateWebhookEndpointRequest) (*model.WebhookEndpointResponse, error) {
+	log := logger.FromContext(ctx)
+
+	e, err := s.getEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		e.URL = *req.URL
+	}
+	if req.Description != nil {
+		e.Description = *req.Description
+	}
+	if req.EventTypes != nil {
+		if e.EventTypes, err = normalizeWebhookEventTypes(req.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if req.Enabled != nil && *req.Enabled != e.Enabled {
+		e.Enabled = *req.Enabled
+		if e.Enabled {
+			e.ConsecutiveFailures = 0
+			e.DisabledAt = nil
+			e.DisabledReason = ""
+		} else {
+			now := time.Now()
+			e.DisabledAt = &now
+			e.DisabledReason = "手動停用"
+		}
+	}
+
+	if err := s.repo.UpdateEndpoint(ctx, e); err != nil {
+		if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+			return nil, err
+		}
+		log.Error("更新 webhook 端點失敗", zap.Error(err), zap.Uint64("endpointID", id))
+		return nil, apperrors.Internal("WEBHOOK_UPDATE_FAILED", "更新 webhook 端點失敗", err)
+	}
+
+	log.Info("webhook 端點已更新", zap.Uint64("endpointID", id), zap.Bool("enabled", e.Enabled))
+	resp := e.ToWebhookEndpointResponse()
+	return &resp, nil
+}
+
+// DeleteEndpoint 刪除 webhook 端點
+func (s *webhookService) DeleteEndpoint(ctx context.Context, id uint64) error {
+	err := s.repo.DeleteEndpoint(ctx, id)
+	if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+		return err
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("刪除 webhook 端點失敗", zap.Error(err), zap.Uint64("endpointID", id))
+		return apperrors.Internal("WEBHOOK_DELETE_FAILED", "刪除 webhook 端點失敗", err)
+	}
+	logger.FromContext(ctx).Info("webhook 端點已刪除", zap.Uint64("endpointID", id))
+	return nil
+}
+
+// ListDeliveries 查詢投遞紀錄
+func (s *webhookService) ListDeliveries(ctx context.Context, filter repository.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	deliveries, err := s.repo.ListDeliveries(ctx, filter)
+	if err != nil {
+		logger.FromContext(ctx).Error("查詢 webhook 投遞紀錄失敗", zap.Error(err))
+		return nil, apperrors.Internal("WEBHOOK_DELIVERY_LIST_FAILED", "查詢 webhook 投遞紀錄失敗", err)
+	}
+
+	resp := make([]model.WebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, *d)
+	}
+	return resp, nil
+}
+
+// GetDelivery 取得單筆投遞紀錄
+func (s *webhookService) GetDelivery(ctx context.Context, id uint64) (*model.WebhookDeliveryResponse, error) {
+	d, err := s.getDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := d.ToWebhookDeliveryResponse()
+	return &resp, nil
+}
+
+// RetryDelivery 重新投遞
+func (s *webhookService) RetryDelivery(ctx context.Context, id uint64) (*model.WebhookDelivery, error) {
+	log := logger.FromContext(ctx)
+
+	err := s.repo.RetryDelivery(ctx, id, time.Now())
+	if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		log.Error("重設 webhook 投遞紀錄失敗", zap.Error(err), zap.Uint64("deliveryID", id))
+		return nil, apperrors.Internal("WEBHOOK_DELIVERY_RETRY_FAILED", "重設 webhook 投遞紀錄失敗", err)
+	}
+
+	d, err := s.getDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("webhook 投遞紀錄已排入重新投遞", zap.Uint64("deliveryID", id), zap.Uint64("endpointID", d.EndpointID))
+	return d, nil
+}
+
+func (s *webhookService) getEndpoint(ctx context.Context, id uint64) (*model.WebhookEndpoint, error) {
+	e, err := s.repo.GetEndpoint(ctx, id)
+	if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("取得 webhook 端點失敗", zap.Error(err), zap.Uint64("endpointID", id))
+		return nil, apperrors.Internal("WEBHOOK_LOOKUP_FAILED", "取得 webhook 端點失敗", err)
+	}
+	return e, nil
+}
+
+func (s *webhookService) getDelivery(ctx context.Context, id uint64) (*model.WebhookDelivery, error) {
+	d, err := s.repo.GetDelivery(ctx, id)
+	if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("取得 webhook 投遞紀錄失敗", zap.Error(err), zap.Uint64("deliveryID", id))
+		return nil, apperrors.Internal("WEBHOOK_DELIVERY_LOOKUP_FAILED", "取得 webhook 投遞紀錄失敗", err)
+	}
+	return d, nil
+}
+
+// validateWebhookURL 確認端點為 https URL，連線的目的位址於投遞時另行檢查 (見 webhook.NewDispatcher)
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// normalizeWebhookEventTypes 驗證過濾條件並合併為以逗號分隔的字串
+// 條件須為 "*"、以 ".*" 結尾的前綴，或 events.Default 中已註冊的事件類型
+func normalizeWebhookEventTypes(types []string) (string, error) {
+	seen := make(map[string]bool, len(types))
+	normalized := make([]string, 0, len(types))
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if seen[t] {
+			continue
+		}
+		if t != "*" && !strings.HasSuffix(t, ".*") {
+			if _, err := events.Default.Lookup(t, 0); err != nil {
+				return "", apperrors.Validation("UNKNOWN_WEBHOOK_EVENT_TYPE", "未知的事件類型: "+t).WithCause(err)
+			}
+		}
+		seen[t] = true
+		normalized = append(normalized, t)
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+// generateWebhookSecret 產生 32 位元組的隨機簽章金鑰
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
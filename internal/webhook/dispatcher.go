@@ -0,0 +1,346 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+// metrics 透過 expvar 暴露 webhook 的投遞統計
+var (
+	metrics                = expvar.NewMap("webhook")
+	enqueuedTotal          = new(expvar.Int)
+	deliveredTotal         = new(expvar.Int)
+	attemptFailuresTotal   = new(expvar.Int)
+	givenUpTotal           = new(expvar.Int)
+	endpointsDisabledTotal = new(expvar.Int)
+	lastPollUnix           = new(expvar.Int)
+)
+
+func init() {
+	metrics.Set("enqueued_total", enqueuedTotal)
+	metrics.Set("delivered_total", deliveredTotal)
+	metrics.Set("attempt_failures_total", attemptFailuresTotal)
+	metrics.Set("given_up_total", givenUpTotal)
+	metrics.Set("endpoints_disabled_total", endpointsDisabledTotal)
+	metrics.Set("last_poll_unix", lastPollUnix)
+}
+
+// DefaultRetrySchedule 是未設定重試排程時，第 n 次失敗後的等待時間
+var DefaultRetrySchedule = []time.Duration{
+	10 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	6 * time.Hour,
+}
+
+// userAgent 是投遞請求的 User-Agent
+const userAgent = "microservice-mvp-webhook/1.0"
+
+// maxErrorBody 是記錄在投遞紀錄中的回應內容長度上限
+const maxErrorBody = 256
+
+// Dispatcher 是將待投遞紀錄 POST 到端點的背景工作
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+
+	interval     time.Duration
+	batchSize    int
+	concurrency  int
+	schedule     []time.Duration
+	disableAfter int
+	lease        time.Duration
+
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// ErrBlockedAddress 代表端點解析到 loopback、link-local、私有或其他內部位址，預設 client 拒絕連線
+var ErrBlockedAddress = errors.New("webhook 端點位址為內部位址，拒絕連線")
+
+// sharedAddressSpace 是電信業者 NAT 使用的 100.64.0.0/10，netip 的 IsPrivate 不包含此範圍
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewDispatcher 建立一個新的 Dispatcher，client 為 nil 時使用依 TimeoutMs 設定逾時的預設 client
+// 預設 client 不跟隨重導向 (3xx 視為失敗)、不使用環境變數的 proxy，並拒絕連線到內部位址 (見 dialControl)
+func NewDispatcher(repo repository.WebhookRepository, cfg configs.WebhookConfig, client *http.Client) *Dispatcher {
+	if client == nil {
+		timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{Timeout: timeout, Control: dialControl}).DialContext
+		client = &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	d := &Dispatcher{
+		repo:         repo,
+		client:       client,
+		interval:     time.Duration(cfg.PollIntervalMs) * time.Millisecond,
+		batchSize:    cfg.BatchSize,
+		concurrency:  cfg.Concurrency,
+		schedule:     DefaultRetrySchedule,
+		disableAfter: cfg.DisableAfterFailures,
+		lease:        time.Duration(cfg.LeaseSeconds) * time.Second,
+		stop:         make(chan struct{}),
+	}
+	if len(cfg.RetryScheduleSeconds) > 0 {
+		d.schedule = make([]time.Duration, len(cfg.RetryScheduleSeconds))
+		for i, s := range cfg.RetryScheduleSeconds {
+			d.schedule[i] = time.Duration(s) * time.Second
+		}
+	}
+	if d.interval <= 0 {
+		d.interval = time.Second
+	}
+	if d.batchSize <= 0 {
+		d.batchSize = 100
+	}
+	if d.concurrency <= 0 {
+		d.concurrency = 4
+	}
+	if d.lease <= 0 {
+		d.lease = 10 * time.Minute
+	}
+	return d
+}
+
+// Start 啟動背景輪詢
+func (d *Dispatcher) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := d.RunOnce(context.Background()); err != nil {
+				logger.Named("webhook").Error("webhook 投遞輪詢失敗", zap.Error(err))
+			}
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	logger.Named("webhook").Info("webhook 投遞工作已啟動",
+		zap.Duration("interval", d.interval),
+		zap.Int("batch_size", d.batchSize),
+		zap.Int("concurrency", d.concurrency),
+		zap.Int("max_attempts", len(d.schedule)+1),
+		zap.Int("disable_after_failures", d.disableAfter),
+	)
+}
+
+// Stop 停止背景輪詢並等待進行中的批次完成
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+		d.wg.Wait()
+		logger.Named("webhook").Info("webhook 投遞工作已停止")
+	})
+}
+
+// RunOnce 認領並投遞一批到期的紀錄，回傳成功投遞的筆數
+// 不同端點並行投遞；同一端點依 ID 順序逐筆投遞，端點在批次中被停用後其餘紀錄釋放認領，留待重新啟用
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	lastPollUnix.Set(now.Unix())
+
+	due, err := d.repo.ClaimDueDeliveries(ctx, now, d.lease, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var order []uint64
+	byEndpoint := make(map[uint64][]*model.WebhookDelivery)
+	for _, dl := range due {
+		if _, ok := byEndpoint[dl.EndpointID]; !ok {
+			order = append(order, dl.EndpointID)
+		}
+		byEndpoint[dl.EndpointID] = append(byEndpoint[dl.EndpointID], dl)
+	}
+
+	var delivered atomic.Int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, d.concurrency)
+	for _, id := range order {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(endpointID uint64, deliveries []*model.WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			delivered.Add(int64(d.deliverEndpoint(ctx, endpointID, deliveries)))
+		}(id, byEndpoint[id])
+	}
+	wg.Wait()
+	return int(delivered.Load()), nil
+}
+
+// deliverEndpoint 依序投遞同一端點的紀錄，回傳成功投遞的筆數
+func (d *Dispatcher) deliverEndpoint(ctx context.Context, endpointID uint64, deliveries []*model.WebhookDelivery) int {
+	endpoint, err := d.repo.GetEndpoint(ctx, endpointID)
+	if err != nil {
+		logger.Named("webhook").Error("取得 webhook 端點失敗", zap.Error(err), zap.Uint64("endpointID", endpointID))
+		d.release(ctx, deliveries)
+		return 0
+	}
+
+	delivered := 0
+	for i, dl := range deliveries {
+		ok, disabled := d.deliverOne(ctx, endpoint, dl)
+		if ok {
+			delivered++
+		}
+		if disabled {
+			d.release(ctx, deliveries[i+1:])
+			break
+		}
+	}
+	return delivered
+}
+
+// release 釋放未投遞紀錄的認領，失敗時紀錄在認領逾期後仍會被再次認領
+func (d *Dispatcher) release(ctx context.Context, deliveries []*model.WebhookDelivery) {
+	if len(deliveries) == 0 {
+		return
+	}
+	ids := make([]uint64, len(deliveries))
+	for i, dl := range deliveries {
+		ids[i] = dl.ID
+	}
+	if err := d.repo.ReleaseDeliveries(ctx, ids); err != nil {
+		logger.Named("webhook").Warn("釋放 webhook 投遞紀錄的認領失敗", zap.Error(err))
+	}
+}
+
+// deliverOne 投遞單筆紀錄並記錄結果，回傳是否投遞成功與端點是否因此被停用
+func (d *Dispatcher) deliverOne(ctx context.Context, endpoint *model.WebhookEndpoint, dl *model.WebhookDelivery) (bool, bool) {
+	log := logger.Named("webhook").With(
+		zap.Uint64("endpointID", endpoint.ID),
+		zap.Uint64("deliveryID", dl.ID),
+		zap.String("eventID", dl.EventID),
+		zap.String("eventType", dl.EventType),
+	)
+
+	attempt := d.send(ctx, endpoint, dl)
+	attempts := dl.Attempts + 1
+	switch {
+	case attempt.Delivered:
+		deliveredTotal.Add(1)
+	case attempts > len(d.schedule):
+		attempt.GiveUp = true
+		attemptFailuresTotal.Add(1)
+		givenUpTotal.Add(1)
+		log.Error("webhook 投遞超過重試次數，已放棄",
+			zap.Int("statusCode", attempt.StatusCode), zap.String("error", attempt.Error), zap.Int("attempts", attempts))
+	default:
+		attempt.NextAttemptAt = attempt.AttemptedAt.Add(d.schedule[attempts-1])
+		attemptFailuresTotal.Add(1)
+		log.Warn("webhook 投遞失敗，稍後重試",
+			zap.Int("statusCode", attempt.StatusCode), zap.String("error", attempt.Error),
+			zap.Int("attempts", attempts), zap.Time("nextAttemptAt", attempt.NextAttemptAt))
+	}
+
+	disabled, err := d.repo.RecordAttempt(ctx, dl.ID, dl.ClaimedBy, attempt, d.disableAfter)
+	if errors.Is(err, repository.ErrWebhookClaimLost) {
+		// 投遞時間超過認領期間，紀錄已由其他實例接手，結果以該實例為準
+		log.Warn("webhook 投遞紀錄的認領已失效，不記錄此次投遞結果", zap.Duration("lease", d.lease))
+		return attempt.Delivered, false
+	}
+	if err != nil {
+		// 投遞結果未保存，下次輪詢會再投遞一次 (at-least-once)
+		log.Error("記錄 webhook 投遞結果失敗", zap.Error(err))
+		return attempt.Delivered, false
+	}
+	if disabled {
+		endpointsDisabledTotal.Add(1)
+		log.Error("webhook 端點連續投遞失敗，已自動停用",
+			zap.String("url", endpoint.URL), zap.Int("disableAfter", d.disableAfter))
+	}
+	return attempt.Delivered, disabled
+}
+
+// send 以簽章後的 POST 請求投遞一次
+func (d *Dispatcher) send(ctx context.Context, endpoint *model.WebhookEndpoint, dl *model.WebhookDelivery) repository.WebhookAttempt {
+	start := time.Now()
+	attempt := repository.WebhookAttempt{AttemptedAt: start}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(dl.Payload))
+	if err != nil {
+		attempt.Error = fmt.Sprintf("建立請求失敗: %v", err)
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, dl.EventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(dl.ID, 10))
+	req.Header.Set(HeaderEvent, dl.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(start.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, start.Unix(), dl.Payload))
+
+	resp, err := d.client.Do(req)
+	attempt.Duration = time.Since(start)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	attempt.StatusCode = resp.StatusCode
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // 讀完回應讓連線可重用
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		attempt.Delivered = true
+		return attempt
+	}
+	attempt.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	return attempt
+}
+
+// dialControl 在 DNS 解析後、建立連線前檢查目的位址，避免端點 URL 被用來存取內部服務 (SSRF)
+// 檢查實際連線的位址，DNS rebinding 或解析到多個位址時同樣有效
+func dialControl(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	ip := ap.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+	}
+	return nil
+}
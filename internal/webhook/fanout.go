@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/internal/consumer"
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/pkg/events"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+// Fanout 消費領域事件，為每個訂閱該事件的啟用中端點建立一筆投遞紀錄
+type Fanout struct {
+	repo repository.WebhookRepository
+}
+
+// NewFanout 建立一個新的 Fanout
+func NewFanout(repo repository.WebhookRepository) *Fanout {
+	return &Fanout{repo: repo}
+}
+
+// Handle 實作 consumer.Handler，可註冊到事件 Topic 的所有標籤
+// 投遞內容一律為事件信封的 JSON，與訊息本身使用的編碼無關
+// 搭配 consumer.Dedupe 與 DB 儲存時，投遞紀錄與處理紀錄在同一個交易中提交，重複投遞的訊息不會產生重複的投遞紀錄
+func (f *Fanout) Handle(ctx context.Context, msg *messaging.Delivery) error {
+	env, err := events.Default.DecodeMessage(msg)
+	if err != nil {
+		return consumer.Permanent(fmt.Errorf("解碼事件失敗: %w", err))
+	}
+
+	endpoints, err := f.repo.ListEndpoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	var deliveries []*model.WebhookDelivery
+	now := time.Now()
+	for _, e := range endpoints {
+		if !e.Enabled || !MatchEventType(e.EventTypeList(), env.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = events.Default.JSONCodec().Marshal(env); err != nil {
+				return consumer.Permanent(fmt.Errorf("編碼事件失敗: %w", err))
+			}
+		}
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			EndpointID:    e.ID,
+			EventID:       env.ID,
+			EventType:     env.Type,
+			Payload:       payload,
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := f.repo.EnqueueDeliveries(ctx, deliveries); err != nil {
+		return err
+	}
+	enqueuedTotal.Add(int64(len(deliveries)))
+	logger.FromContext(ctx).Debug("已建立 webhook 投遞紀錄",
+		zap.String("eventID", env.ID), zap.String("eventType", env.Type), zap.Int("endpoints", len(deliveries)))
+	return nil
+}
+
+// MatchEventType 判斷事件類型是否符合端點的過濾條件
+// 條件可為完整的事件類型、"*" (全部) 或以 ".*" 結尾的前綴 (例如 "wallet.*")
+func MatchEventType(filters []string, eventType string) bool {
+	for _, f := range filters {
+		switch {
+		case f == "*" || f == eventType:
+			return true
+		case strings.HasSuffix(f, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(f, "*")):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+// Package webhook 將領域事件投遞到合作夥伴註冊的 HTTP 端點：
+// Fanout 消費事件並依端點的過濾條件建立投遞紀錄，Dispatcher 以 HMAC-SHA256 簽章後 POST 到端點，
+// 失敗時依重試排程重試，端點連續失敗達上限時自動停用。投遞語意為 at-least-once，
+// 接收端可用 X-Webhook-ID (事件 ID) 去除重複。
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 投遞請求的標頭
+const (
+	HeaderID        = "X-Webhook-ID" // 事件 ID，重試時不變
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp" // Unix 秒數，納入簽章以防止重放
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signatureVersion 是簽章格式的版本前綴，之後更換演算法時可並存
+const signatureVersion = "v1"
+
+var (
+	// ErrInvalidSignature 代表簽章格式錯誤或與內容不符
+	ErrInvalidSignature = errors.New("webhook 簽章無效")
+	// ErrTimestampOutOfTolerance 代表時間戳記超出容許範圍，可能是重放的請求
+	ErrTimestampOutOfTolerance = errors.New("webhook 時間戳記超出容許範圍")
+)
+
+// Sign 回傳 X-Webhook-Signature 的值：v1=<hex(HMAC-SHA256(secret, "<timestamp>.<body>"))>
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 驗證簽章與時間戳記，供接收端或測試使用
+// signature 可包含以逗號分隔的多個簽章 (例如輪替金鑰期間)，任一符合即通過；tolerance 為 0 時不檢查時間
+func Verify(secret, timestamp, signature string, body []byte, tolerance time.Duration, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+			return ErrTimestampOutOfTolerance
+		}
+	}
+
+	expected := []byte(Sign(secret, ts, body))
+	for _, sig := range strings.Split(signature, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(sig)), expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
@@ -0,0 +1,229 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/internal/model"
+	"microservice-mvp/internal/repository"
+	"microservice-mvp/internal/webhook"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/events"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+const testSecret = "whsec_test_secret_0123456789"
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"wallet.debited"}`)
+	sig := webhook.Sign(testSecret, now.Unix(), body)
+	ts := "1700000000"
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		now       time.Time
+		wantErr   error
+	}{
+		{name: "Valid", secret: testSecret, timestamp: ts, signature: sig, body: body, now: now},
+		{name: "RotatedSecretList", secret: testSecret, timestamp: ts, signature: "v1=deadbeef, " + sig, body: body, now: now},
+		{name: "WrongSecret", secret: "another-secret-value", timestamp: ts, signature: sig, body: body, now: now, wantErr: webhook.ErrInvalidSignature},
+		{name: "TamperedBody", secret: testSecret, timestamp: ts, signature: sig, body: []byte(`{}`), now: now, wantErr: webhook.ErrInvalidSignature},
+		{name: "TamperedTimestamp", secret: testSecret, timestamp: "1700000001", signature: sig, body: body, now: now, wantErr: webhook.ErrInvalidSignature},
+		{name: "Replayed", secret: testSecret, timestamp: ts, signature: sig, body: body, now: now.Add(10 * time.Minute), wantErr: webhook.ErrTimestampOutOfTolerance},
+		{name: "MalformedTimestamp", secret: testSecret, timestamp: "abc", signature: sig, body: body, now: now, wantErr: webhook.ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhook.Verify(tt.secret, tt.timestamp, tt.signature, tt.body, 5*time.Minute, tt.now)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMatchEventType(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []string
+		want    bool
+	}{
+		{name: "Exact", filters: []string{"wallet.debited"}, want: true},
+		{name: "Wildcard", filters: []string{"*"}, want: true},
+		{name: "Prefix", filters: []string{"player.*", "wallet.*"}, want: true},
+		{name: "OtherPrefix", filters: []string{"player.*"}, want: false},
+		{name: "PartialNameIsNotPrefix", filters: []string{"wallet"}, want: false},
+		{name: "Empty", filters: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, webhook.MatchEventType(tt.filters, events.TypeWalletDebited))
+		})
+	}
+}
+
+// walletDebitedMessage 建立一則 wallet.debited 事件訊息
+func walletDebitedMessage(t *testing.T, msgID string) *messaging.Delivery {
+	t.Helper()
+	env, err := events.New(context.Background(), "42", events.WalletDebited{PlayerID: 42, Amount: 10, BalanceAfter: 90})
+	require.NoError(t, err)
+	msg, err := events.Default.NewMessage(env, events.Default.ProtoCodec())
+	require.NoError(t, err)
+	return &messaging.Delivery{Message: *msg, MsgID: msgID}
+}
+
+func TestFanoutAndDispatcher_RetriesUntilDelivered(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	repo := repository.NewMemoryStore().Webhooks()
+
+	var calls atomic.Int32
+	var verified atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := webhook.Verify(testSecret, r.Header.Get(webhook.HeaderTimestamp), r.Header.Get(webhook.HeaderSignature), body, time.Minute, time.Now())
+		verified.Store(err == nil && r.Header.Get(webhook.HeaderEvent) == events.TypeWalletDebited)
+		if calls.Add(1) == 1 {
+			http.Error(w, "暫時無法處理", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	wallet := &model.WebhookEndpoint{URL: srv.URL, Secret: testSecret, EventTypes: "wallet.*", Enabled: true}
+	player := &model.WebhookEndpoint{URL: srv.URL, Secret: testSecret, EventTypes: events.TypePlayerRegistered, Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, wallet))
+	require.NoError(t, repo.CreateEndpoint(ctx, player))
+
+	// 訊息以 protobuf 編碼，投遞內容一律為 JSON
+	require.NoError(t, webhook.NewFanout(repo).Handle(ctx, walletDebitedMessage(t, "msg-1")))
+	deliveries, err := repo.ListDeliveries(ctx, repository.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, wallet.ID, deliveries[0].EndpointID)
+	assert.JSONEq(t, `{"player_id":42,"amount":10,"balance_after":90,"reason":"","reference_id":""}`,
+		string(mustPayload(t, deliveries[0].Payload)))
+
+	// 測試伺服器位於 loopback，使用其 client 略過預設 client 的內部位址檢查
+	d := webhook.NewDispatcher(repo, configs.WebhookConfig{RetryScheduleSeconds: []int{0}}, srv.Client())
+
+	n, err := d.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	failed, err := repo.GetDelivery(ctx, deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryPending, failed.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, failed.LastStatusCode)
+	assert.Contains(t, failed.LastError, "HTTP 503")
+
+	n, err = d.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.True(t, verified.Load())
+
+	delivered, err := repo.GetDelivery(ctx, deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliverySucceeded, delivered.Status)
+	assert.Equal(t, 2, delivered.Attempts)
+	assert.NotNil(t, delivered.DeliveredAt)
+
+	endpoint, err := repo.GetEndpoint(ctx, wallet.ID)
+	require.NoError(t, err)
+	assert.Zero(t, endpoint.ConsecutiveFailures)
+}
+
+func TestDispatcher_DisablesFailingEndpoint(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	repo := repository.NewMemoryStore().Webhooks()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	e := &model.WebhookEndpoint{URL: srv.URL, Secret: testSecret, EventTypes: "*", Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, e))
+	for _, id := range []string{"msg-1", "msg-2", "msg-3"} {
+		require.NoError(t, webhook.NewFanout(repo).Handle(ctx, walletDebitedMessage(t, id)))
+	}
+
+	d := webhook.NewDispatcher(repo, configs.WebhookConfig{RetryScheduleSeconds: []int{0}, DisableAfterFailures: 2}, srv.Client())
+	_, err := d.RunOnce(ctx)
+	require.NoError(t, err)
+
+	// 第二次失敗時停用端點，同批次的第三筆不再投遞
+	assert.EqualValues(t, 2, calls.Load())
+	endpoint, err := repo.GetEndpoint(ctx, e.ID)
+	require.NoError(t, err)
+	assert.False(t, endpoint.Enabled)
+	assert.Equal(t, 2, endpoint.ConsecutiveFailures)
+	assert.NotNil(t, endpoint.DisabledAt)
+
+	due, err := repo.ClaimDueDeliveries(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	pending, err := repo.ListDeliveries(ctx, repository.WebhookDeliveryFilter{Status: model.WebhookDeliveryPending})
+	require.NoError(t, err)
+	assert.Len(t, pending, 3)
+	assert.Zero(t, pending[2].Attempts)
+}
+
+func TestDispatcher_RejectsInternalAddresses(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := context.Background()
+	repo := repository.NewMemoryStore().Webhooks()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	e := &model.WebhookEndpoint{URL: srv.URL, Secret: testSecret, EventTypes: "*", Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, e))
+	require.NoError(t, webhook.NewFanout(repo).Handle(ctx, walletDebitedMessage(t, "msg-1")))
+
+	d := webhook.NewDispatcher(repo, configs.WebhookConfig{RetryScheduleSeconds: []int{0}}, nil)
+	n, err := d.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Zero(t, calls.Load(), "預設 client 不應連線到 loopback 位址")
+
+	deliveries, err := repo.ListDeliveries(ctx, repository.WebhookDeliveryFilter{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Contains(t, deliveries[0].LastError, webhook.ErrBlockedAddress.Error())
+}
+
+// mustPayload 取出投遞內容中的事件 payload
+func mustPayload(t *testing.T, body []byte) []byte {
+	t.Helper()
+	var env struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(body, &env))
+	return env.Payload
+}
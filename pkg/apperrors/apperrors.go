@@ -0,0 +1,163 @@
+// Package apperrors 定義 Repository、Service 與 Controller 共用的領域錯誤
+// 呼叫端應以 errors.Is 判斷錯誤分類 (例如 errors.Is(err, apperrors.ErrNotFound))，
+// 以 errors.As 取得 *Error 以讀取機器可讀的錯誤碼，而不是比對錯誤訊息字串。
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Kind 代表錯誤的分類，決定對應的 HTTP 狀態碼
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindUnauthorized
+	KindValidation
+	KindRateLimited
+)
+
+// 各分類的預設錯誤碼，作為 response.Response 中穩定的 error_code
+const (
+	CodeInternal     = "INTERNAL"
+	CodeNotFound     = "NOT_FOUND"
+	CodeConflict     = "CONFLICT"
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeValidation   = "VALIDATION_FAILED"
+	CodeRateLimited  = "RATE_LIMITED"
+)
+
+// 分類用的哨兵錯誤，任何相同分類的 *Error 都會被 errors.Is 判定為相符
+var (
+	ErrInternal     = &Error{Kind: KindInternal, Code: CodeInternal, Message: "內部伺服器錯誤", sentinel: true}
+	ErrNotFound     = &Error{Kind: KindNotFound, Code: CodeNotFound, Message: "找不到資源", sentinel: true}
+	ErrConflict     = &Error{Kind: KindConflict, Code: CodeConflict, Message: "資源衝突", sentinel: true}
+	ErrUnauthorized = &Error{Kind: KindUnauthorized, Code: CodeUnauthorized, Message: "未經授權", sentinel: true}
+	ErrValidation   = &Error{Kind: KindValidation, Code: CodeValidation, Message: "請求參數錯誤", sentinel: true}
+	ErrRateLimited  = &Error{Kind: KindRateLimited, Code: CodeRateLimited, Message: "請求過於頻繁", sentinel: true}
+)
+
+// Error 是帶有分類與錯誤碼的領域錯誤
+type Error struct {
+	Kind       Kind
+	Code       string        // 機器可讀的錯誤碼，例如 PLAYER_NOT_FOUND
+	Message    string        // 可回傳給客戶端的訊息
+	Err        error         // 底層原因，不會直接暴露給客戶端
+	RetryAfter time.Duration // 僅 KindRateLimited 使用
+
+	sentinel bool
+}
+
+// Error 實作 error 介面
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap 回傳底層原因，讓 errors.Is/As 可以繼續往下檢查
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is 讓同分類的錯誤與哨兵錯誤相符，例如 errors.Is(NotFound("PLAYER_NOT_FOUND", "..."), ErrNotFound)
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.sentinel && t.Kind == e.Kind
+}
+
+// WithCause 回傳帶有底層原因的副本，原本的錯誤 (通常是套件層級的變數) 不會被修改
+func (e *Error) WithCause(err error) *Error {
+	cp := *e
+	cp.Err = err
+	cp.sentinel = false
+	return &cp
+}
+
+func newError(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// NotFound 建立一個找不到資源的錯誤
+func NotFound(code, message string) *Error {
+	return newError(KindNotFound, code, message)
+}
+
+// Conflict 建立一個資源衝突 (例如唯一性約束) 的錯誤
+func Conflict(code, message string) *Error {
+	return newError(KindConflict, code, message)
+}
+
+// Unauthorized 建立一個未經授權的錯誤
+func Unauthorized(code, message string) *Error {
+	return newError(KindUnauthorized, code, message)
+}
+
+// Validation 建立一個請求參數驗證失敗的錯誤
+func Validation(code, message string) *Error {
+	return newError(KindValidation, code, message)
+}
+
+// RateLimited 建立一個超過速率限制的錯誤，retryAfter 會回傳在 Retry-After 標頭中
+func RateLimited(code, message string, retryAfter time.Duration) *Error {
+	e := newError(KindRateLimited, code, message)
+	e.RetryAfter = retryAfter
+	return e
+}
+
+// Internal 建立一個內部錯誤，cause 只會記錄在日誌中
+func Internal(code, message string, cause error) *Error {
+	e := newError(KindInternal, code, message)
+	e.Err = cause
+	return e
+}
+
+// From 從錯誤鏈中取出 *Error；若不存在則包裝為 KindInternal
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.WithCause(err)
+}
+
+// DefaultCode 回傳錯誤分類的預設錯誤碼
+func (k Kind) DefaultCode() string {
+	switch k {
+	case KindNotFound:
+		return CodeNotFound
+	case KindConflict:
+		return CodeConflict
+	case KindUnauthorized:
+		return CodeUnauthorized
+	case KindValidation:
+		return CodeValidation
+	case KindRateLimited:
+		return CodeRateLimited
+	default:
+		return CodeInternal
+	}
+}
+
+// HTTPStatus 回傳錯誤分類對應的 HTTP 狀態碼
+func HTTPStatus(kind Kind) int {
+	switch kind {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
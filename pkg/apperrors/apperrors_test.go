@@ -0,0 +1,45 @@
+package apperrors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"microservice-mvp/pkg/apperrors"
+)
+
+func TestErrorClassification(t *testing.T) {
+	cause := errors.New("db error")
+
+	tests := []struct {
+		name        string
+		err         error
+		sentinel    error
+		notSentinel error
+		code        string
+	}{
+		{name: "NotFound", err: apperrors.NotFound("PLAYER_NOT_FOUND", "玩家不存在"), sentinel: apperrors.ErrNotFound, notSentinel: apperrors.ErrConflict, code: "PLAYER_NOT_FOUND"},
+		{name: "WrappedConflict", err: fmt.Errorf("建立失敗: %w", apperrors.Conflict("USERNAME_TAKEN", "使用者名稱已存在")), sentinel: apperrors.ErrConflict, notSentinel: apperrors.ErrNotFound, code: "USERNAME_TAKEN"},
+		{name: "InternalWithCause", err: apperrors.Internal("LOOKUP_FAILED", "檢索失敗", cause), sentinel: apperrors.ErrInternal, notSentinel: apperrors.ErrValidation, code: "LOOKUP_FAILED"},
+		{name: "PlainErrorIsInternal", err: cause, sentinel: nil, notSentinel: apperrors.ErrInternal, code: apperrors.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.sentinel != nil {
+				assert.ErrorIs(t, tt.err, tt.sentinel)
+			}
+			assert.NotErrorIs(t, tt.err, tt.notSentinel)
+			assert.Equal(t, tt.code, apperrors.From(tt.err).Code)
+		})
+	}
+
+	// 底層原因仍可透過 errors.Is 取得
+	assert.ErrorIs(t, apperrors.Internal("LOOKUP_FAILED", "檢索失敗", cause), cause)
+	// WithCause 不應修改套件層級的錯誤
+	base := apperrors.NotFound("PLAYER_NOT_FOUND", "玩家不存在")
+	_ = base.WithCause(cause)
+	assert.Nil(t, base.Unwrap())
+}
@@ -0,0 +1,191 @@
+// Package breaker 實作依賴組件的斷路器 (closed、open、half-open)，
+// 並提供 Redis hook 與 GORM plugin，在依賴異常時快速失敗，避免每個請求都等到逾時。
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/metrics"
+)
+
+// ErrOpen 代表斷路器開啟，呼叫未送往依賴組件
+var ErrOpen = errors.New("斷路器開啟，暫停呼叫依賴組件")
+
+// State 是斷路器的狀態
+type State int
+
+const (
+	StateClosed   State = iota // 正常放行
+	StateHalfOpen              // 放行少量試探呼叫
+	StateOpen                  // 直接拒絕
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// Breaker 是單一依賴組件的斷路器，nil 代表停用 (一律放行)
+type Breaker struct {
+	name             string
+	failureThreshold int
+	openDuration     time.Duration
+	halfOpenRequests int
+	now              func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int       // closed 時的連續失敗次數
+	openedAt  time.Time // 最近一次開啟的時間
+	probedAt  time.Time // half-open 時本輪試探開始的時間
+	probes    int       // half-open 時已放行的試探呼叫數
+	successes int       // half-open 時成功的試探呼叫數
+}
+
+// New 依設定建立斷路器，未啟用時回傳 nil
+func New(name string, cfg configs.BreakerConfig) *Breaker {
+	if !cfg.Enabled {
+		return nil
+	}
+	b := &Breaker{
+		name:             name,
+		failureThreshold: cfg.FailureThreshold,
+		openDuration:     time.Duration(cfg.OpenSeconds) * time.Second,
+		halfOpenRequests: cfg.HalfOpenRequests,
+		now:              time.Now,
+	}
+	if b.failureThreshold <= 0 {
+		b.failureThreshold = 5
+	}
+	if b.openDuration <= 0 {
+		b.openDuration = 10 * time.Second
+	}
+	if b.halfOpenRequests <= 0 {
+		b.halfOpenRequests = 1
+	}
+	metrics.SetBreakerState(name, int(StateClosed))
+	return b
+}
+
+// Name 回傳斷路器名稱
+func (b *Breaker) Name() string {
+	if b == nil {
+		return ""
+	}
+	return b.name
+}
+
+// State 回傳目前狀態；開啟已超過 OpenSeconds 但尚未有呼叫時仍回報 open
+func (b *Breaker) State() State {
+	if b == nil {
+		return StateClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow 判斷是否放行呼叫，放行時呼叫端須以 Record 回報結果；拒絕時回傳 ErrOpen
+// half-open 的試探呼叫若超過 OpenSeconds 仍未回報，其名額會被收回，避免斷路器永久停在 half-open
+func (b *Breaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.openDuration {
+			metrics.ObserveBreakerRejected(b.name)
+			return ErrOpen
+		}
+		b.transition(StateHalfOpen)
+		fallthrough
+	case StateHalfOpen:
+		if b.probes >= b.halfOpenRequests && b.now().Sub(b.probedAt) >= b.openDuration {
+			// 試探呼叫超過 OpenSeconds 仍未回報結果 (例如呼叫端未呼叫 Record)，視為遺失並開始新一輪試探
+			logger.Named("breaker").Warn("試探呼叫未回報結果，重新放行試探",
+				zap.String("breaker", b.name), zap.Int("probes", b.probes), zap.Int("successes", b.successes))
+			b.probedAt = b.now()
+			b.probes, b.successes = 0, 0
+		}
+		if b.probes >= b.halfOpenRequests {
+			metrics.ObserveBreakerRejected(b.name)
+			return ErrOpen
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// Record 回報放行呼叫的結果，failure 代表依賴組件異常 (連線錯誤、逾時等)，業務錯誤不應視為失敗
+func (b *Breaker) Record(failure bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		if !failure {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.failureThreshold {
+			b.transition(StateOpen)
+		}
+	case StateHalfOpen:
+		if failure {
+			b.transition(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.halfOpenRequests {
+			b.transition(StateClosed)
+		}
+	}
+	// open 時的結果來自開啟前放行的呼叫，不影響狀態
+}
+
+// transition 切換狀態並重設計數，呼叫端須持有 b.mu
+func (b *Breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	b.failures, b.probes, b.successes = 0, 0, 0
+	switch to {
+	case StateOpen:
+		b.openedAt = b.now()
+	case StateHalfOpen:
+		b.probedAt = b.now()
+	}
+
+	metrics.SetBreakerState(b.name, int(to))
+	metrics.ObserveBreakerTransition(b.name, from.String(), to.String())
+
+	log := logger.Named("breaker")
+	fields := []zap.Field{zap.String("breaker", b.name), zap.String("from", from.String()), zap.String("to", to.String())}
+	switch to {
+	case StateOpen:
+		log.Error("斷路器開啟", append(fields, zap.Duration("openDuration", b.openDuration))...)
+	case StateHalfOpen:
+		log.Info("斷路器進入 half-open，放行試探呼叫", fields...)
+	case StateClosed:
+		log.Info("斷路器關閉，依賴組件已恢復", fields...)
+	}
+}
@@ -0,0 +1,161 @@
+package breaker
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func TestBreaker_Transitions(t *testing.T) {
+	_, err := logger.NewLogger("info", "console")
+	require.NoError(t, err)
+
+	now := time.Now()
+	b := New("test", configs.BreakerConfig{Enabled: true, FailureThreshold: 2, OpenSeconds: 5, HalfOpenRequests: 2})
+	b.now = func() time.Time { return now }
+
+	// call 模擬一次呼叫：放行時回報結果，回傳是否被拒絕
+	call := func(failure bool) error {
+		if err := b.Allow(); err != nil {
+			return err
+		}
+		b.Record(failure)
+		return nil
+	}
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		failure bool
+		wantErr error
+		want    State
+	}{
+		{name: "成功維持關閉", want: StateClosed},
+		{name: "第一次失敗", failure: true, want: StateClosed},
+		{name: "成功重設連續失敗次數", want: StateClosed},
+		{name: "再次失敗", failure: true, want: StateClosed},
+		{name: "連續失敗達閾值後開啟", failure: true, want: StateOpen},
+		{name: "開啟期間拒絕", advance: 4 * time.Second, wantErr: ErrOpen, want: StateOpen},
+		{name: "逾時後放行試探", advance: time.Second, want: StateHalfOpen},
+		{name: "試探失敗再次開啟", failure: true, want: StateOpen},
+		{name: "再次逾時後放行試探", advance: 5 * time.Second, want: StateHalfOpen},
+		{name: "試探全部成功後關閉", want: StateClosed},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		err := call(step.failure)
+		assert.ErrorIs(t, err, step.wantErr, step.name)
+		if step.wantErr == nil {
+			assert.NoError(t, err, step.name)
+		}
+		assert.Equal(t, step.want, b.State(), step.name)
+	}
+}
+
+func TestBreaker_HalfOpenLimitsProbes(t *testing.T) {
+	now := time.Now()
+	b := New("test", configs.BreakerConfig{Enabled: true, FailureThreshold: 1, OpenSeconds: 1})
+	b.now = func() time.Time { return now }
+
+	require.NoError(t, b.Allow())
+	b.Record(true)
+	now = now.Add(time.Second)
+
+	require.NoError(t, b.Allow(), "第一個試探呼叫放行")
+	assert.ErrorIs(t, b.Allow(), ErrOpen, "試探結果回報前拒絕其他呼叫")
+	b.Record(false)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_HalfOpenReclaimsUnreportedProbes(t *testing.T) {
+	now := time.Now()
+	b := New("test", configs.BreakerConfig{Enabled: true, FailureThreshold: 1, OpenSeconds: 1})
+	b.now = func() time.Time { return now }
+
+	require.NoError(t, b.Allow())
+	b.Record(true)
+	now = now.Add(time.Second)
+
+	// 試探呼叫放行後從未呼叫 Record
+	require.NoError(t, b.Allow())
+	now = now.Add(500 * time.Millisecond)
+	assert.ErrorIs(t, b.Allow(), ErrOpen, "未逾時前仍保留試探名額")
+
+	now = now.Add(500 * time.Millisecond)
+	require.NoError(t, b.Allow(), "逾時後收回名額並放行新的試探")
+	assert.ErrorIs(t, b.Allow(), ErrOpen)
+	b.Record(false)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := New("test", configs.BreakerConfig{Enabled: false})
+	assert.Nil(t, b)
+	assert.NoError(t, b.Allow())
+	b.Record(true)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestIsFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    func(error) bool
+		err   error
+		wantF bool
+	}{
+		{name: "Redis 鍵不存在", fn: isRedisFailure, err: redis.Nil, wantF: false},
+		{name: "Redis 回覆錯誤", fn: isRedisFailure, err: fmt.Errorf("wrap: %w", redisReplyError("WRONGTYPE")), wantF: false},
+		{name: "Redis 呼叫端取消", fn: isRedisFailure, err: context.Canceled, wantF: false},
+		{name: "Redis 逾時", fn: isRedisFailure, err: context.DeadlineExceeded, wantF: true},
+		{name: "Redis 連線錯誤", fn: isRedisFailure, err: errors.New("dial tcp: connection refused"), wantF: true},
+		{name: "DB 查無資料", fn: isDBFailure, err: gorm.ErrRecordNotFound, wantF: false},
+		{name: "DB 重複鍵", fn: isDBFailure, err: gorm.ErrDuplicatedKey, wantF: false},
+		{name: "DB 逾時", fn: isDBFailure, err: fmt.Errorf("query: %w", context.DeadlineExceeded), wantF: true},
+		{name: "DB 連線中斷", fn: isDBFailure, err: driver.ErrBadConn, wantF: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantF, tt.fn(tt.err))
+		})
+	}
+}
+
+type redisReplyError string
+
+func (e redisReplyError) Error() string { return string(e) }
+func (redisReplyError) RedisError()     {}
+
+func TestRedisHook(t *testing.T) {
+	_, err := logger.NewLogger("info", "console")
+	require.NoError(t, err)
+
+	b := New("redis-test", configs.BreakerConfig{Enabled: true, FailureThreshold: 2, OpenSeconds: 60})
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 50 * time.Millisecond, MaxRetries: -1})
+	defer rdb.Close()
+	rdb.AddHook(RedisHook{Breaker: b})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		err := rdb.Get(ctx, "k").Err()
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrOpen)
+	}
+	assert.Equal(t, StateOpen, b.State())
+
+	start := time.Now()
+	assert.ErrorIs(t, rdb.Get(ctx, "k").Err(), ErrOpen)
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "開啟時不連線 Redis")
+}
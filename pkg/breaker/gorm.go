@@ -0,0 +1,108 @@
+package breaker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GormPlugin 為每個 SQL 操作套用逾時並以斷路器保護的 gorm.Plugin，
+// 以 db.Use(breaker.NewGormPlugin("primary", b, timeout)) 註冊；b 為 nil 時只套用逾時
+type GormPlugin struct {
+	db      string
+	breaker *Breaker
+	timeout time.Duration
+}
+
+// NewGormPlugin 建立 GormPlugin，timeout 為 0 代表不另設逾時
+func NewGormPlugin(db string, b *Breaker, timeout time.Duration) *GormPlugin {
+	return &GormPlugin{db: db, breaker: b, timeout: timeout}
+}
+
+// Name 實作 gorm.Plugin
+func (p *GormPlugin) Name() string {
+	return "breaker:" + p.db
+}
+
+// Initialize 實作 gorm.Plugin，在各類操作的前後註冊 callback
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	register := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, r := range register {
+		// Row/Rows 的結果在 callback 結束後才讀取，取消上下文會中斷讀取，因此不套用逾時
+		timeout := p.timeout
+		if r.operation == "row" {
+			timeout = 0
+		}
+		if err := r.before("breaker:before_"+r.operation, p.before(timeout)); err != nil {
+			return err
+		}
+		if err := r.after("breaker:after_"+r.operation, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *GormPlugin) allowedKey() string { return "breaker:" + p.db + ":allowed" }
+func (p *GormPlugin) cancelKey() string  { return "breaker:" + p.db + ":cancel" }
+
+func (p *GormPlugin) before(timeout time.Duration) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Error != nil {
+			return
+		}
+		if err := p.breaker.Allow(); err != nil {
+			_ = tx.AddError(err)
+			return
+		}
+		tx.InstanceSet(p.allowedKey(), true)
+
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(tx.Statement.Context, timeout)
+			tx.Statement.Context = ctx
+			tx.InstanceSet(p.cancelKey(), cancel)
+		}
+	}
+}
+
+func (p *GormPlugin) after(tx *gorm.DB) {
+	if v, ok := tx.InstanceGet(p.cancelKey()); ok {
+		if cancel, ok := v.(context.CancelFunc); ok {
+			cancel()
+		}
+	}
+	if _, ok := tx.InstanceGet(p.allowedKey()); ok {
+		p.breaker.Record(isDBFailure(tx.Error))
+	}
+}
+
+// isDBFailure 判斷錯誤是否代表資料庫異常 (連線中斷、逾時)；查無資料、違反約束等業務錯誤不算
+func isDBFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
@@ -0,0 +1,59 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook 以斷路器保護 Redis 命令的 redis.Hook，以 rdb.AddHook(breaker.RedisHook{Breaker: b}) 註冊
+// 斷路器開啟時命令不送往 Redis，直接回傳 ErrOpen
+type RedisHook struct {
+	Breaker *Breaker
+}
+
+// DialHook 實作 redis.Hook
+func (h RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook 實作 redis.Hook
+func (h RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.Breaker.Allow(); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		err := next(ctx, cmd)
+		h.Breaker.Record(isRedisFailure(err))
+		return err
+	}
+}
+
+// ProcessPipelineHook 實作 redis.Hook
+func (h RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := h.Breaker.Allow(); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		err := next(ctx, cmds)
+		h.Breaker.Record(isRedisFailure(err))
+		return err
+	}
+}
+
+// isRedisFailure 判斷錯誤是否代表 Redis 異常；Redis 回覆的錯誤 (含 redis.Nil) 與呼叫端取消不算
+func isRedisFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var replyErr redis.Error
+	return !errors.As(err, &replyErr)
+}
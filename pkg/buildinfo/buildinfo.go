@@ -0,0 +1,60 @@
+// Package buildinfo 保存建置時以 -ldflags 注入的版本資訊，例如：
+//
+//	go build -ldflags "-X microservice-mvp/pkg/buildinfo.Version=v1.2.0 \
+//	  -X microservice-mvp/pkg/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X microservice-mvp/pkg/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/server
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// 由 -ldflags "-X" 注入，未注入時 Commit 與 BuildTime 改用 Go 工具鏈記錄的 VCS 資訊
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info 代表執行檔的建置資訊
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	Modified  bool   `json:"modified,omitempty"` // 建置時工作目錄有未提交的變更 (僅在未注入 Commit 時可得知)
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get 回傳建置資訊
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok && info.Commit == "" {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Commit = s.Value
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
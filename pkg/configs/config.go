@@ -2,6 +2,8 @@ package configs
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -14,64 +16,397 @@ type Config struct {
 	Persistence PersistenceConfig `mapstructure:"persistence"`
 	Database    DatabaseConfig    `mapstructure:"database"`
 	Redis       RedisConfig       `mapstructure:"redis"`
-	RocketMQ    RocketMQConfig    `mapstructure:"rocketmq"`
+	Messaging   MessagingConfig   `mapstructure:"messaging"`
+	Outbox      OutboxConfig      `mapstructure:"outbox"`
+	Consumer    ConsumerConfig    `mapstructure:"consumer"`
+	Webhook     WebhookConfig     `mapstructure:"webhook"`
+	Admin       AdminConfig       `mapstructure:"admin"`
+	Audit       AuditConfig       `mapstructure:"audit"`
+	Metrics     MetricsConfig     `mapstructure:"metrics"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
 	HealthCheck HealthCheckConfig `mapstructure:"health_check"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
 }
 
 // PersistenceConfig 代表持久化配置
 type PersistenceConfig struct {
-	Type string `mapstructure:"type"` // "memory" 或 "mysql"
+	Type   string            `mapstructure:"type" validate:"required,oneof=memory mysql"` // "memory" 或 "mysql"
+	Memory MemoryStoreConfig `mapstructure:"memory"`
+}
+
+// MemoryStoreConfig 代表 In-Memory 模式的落地設定 (append log + 定期快照)
+type MemoryStoreConfig struct {
+	DataDir                 string `mapstructure:"data_dir"`                                                      // 落地目錄，空字串代表不落地
+	FsyncPolicy             string `mapstructure:"fsync_policy" validate:"omitempty,oneof=always interval never"` // "always", "interval" 或 "never"
+	FsyncIntervalMs         int    `mapstructure:"fsync_interval_ms" validate:"min=0"`
+	SnapshotIntervalSeconds int    `mapstructure:"snapshot_interval_seconds" validate:"min=0"`
 }
 
 type ServerConfig struct {
-	Port          int    `mapstructure:"port"`
-	Mode          string `mapstructure:"mode"`
-	SlowThreshold int    `mapstructure:"slow_threshold"`
+	Port          int               `mapstructure:"port" validate:"required,min=1,max=65535"`
+	Mode          string            `mapstructure:"mode" validate:"omitempty,oneof=debug release test"`
+	SlowThreshold int               `mapstructure:"slow_threshold" validate:"min=0"`
+	AccessLog     AccessLogConfig   `mapstructure:"access_log"`
+	Admin         AdminServerConfig `mapstructure:"admin"`
+	// TrustedProxies 是可信任的反向代理 (IP 或 CIDR)，只有來自這些位址的請求才採用 X-Forwarded-For 決定用戶端 IP；
+	// 留空代表不信任任何代理，以連線的來源位址作為用戶端 IP (速率限制、稽核紀錄與存取日誌皆使用)
+	TrustedProxies []string `mapstructure:"trusted_proxies" validate:"dive,ip|cidr"`
+	// ShutdownDelaySeconds 是收到關閉訊號後，/readyz 轉為失敗到開始關閉連線之間的等待時間，讓負載平衡先移除此實例
+	ShutdownDelaySeconds int `mapstructure:"shutdown_delay_seconds" validate:"min=0"`
+}
+
+// AdminServerConfig 代表診斷用的獨立 HTTP 伺服器 (pprof、expvar、goroutine dump、建置資訊與配置檢視)
+// 與對外的 API 伺服器使用不同的埠，預設只監聽 127.0.0.1，應透過 port-forward 或內網存取
+type AdminServerConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Host    string `mapstructure:"host"` // 監聽位址，留空則監聽所有網路介面
+	Port    int    `mapstructure:"port" validate:"required_if=Enabled true,max=65535"`
+}
+
+// AccessLogConfig 決定哪些請求寫入存取日誌；路由以路由樣板 (例如 /api/v1/players/:id) 比對，未匹配路由時以原始路徑比對
+type AccessLogConfig struct {
+	Skip   []string         `mapstructure:"skip"`                   // 不記錄的路由，請求出錯 (5xx 或 handler 回報錯誤) 時仍會記錄
+	Routes []AccessLogRoute `mapstructure:"routes" validate:"dive"` // 個別路由的最低日誌級別
+}
+
+// AccessLogRoute 設定單一路由存取日誌的最低級別，例如 warn 表示只記錄慢請求與錯誤
+type AccessLogRoute struct {
+	Path  string `mapstructure:"path" validate:"required"`
+	Level string `mapstructure:"level" validate:"oneof=debug info warn error dpanic panic fatal"`
 }
 
 type LoggerConfig struct {
-	Level    string `mapstructure:"level"`
-	Encoding string `mapstructure:"encoding"`
+	Level     string            `mapstructure:"level" validate:"omitempty,oneof=debug info warn error dpanic panic fatal"`
+	Encoding  string            `mapstructure:"encoding" validate:"omitempty,oneof=json console"`
+	Stdout    bool              `mapstructure:"stdout"`     // 是否輸出到標準輸出
+	File      LogFileConfig     `mapstructure:"file"`       // 輪替日誌檔，記錄所有級別
+	ErrorFile LogFileConfig     `mapstructure:"error_file"` // 只記錄 error 以上級別的輪替日誌檔
+	Loggers   map[string]string `mapstructure:"loggers"`    // 具名 logger 的級別，例如 gorm: warn，覆寫全域級別
+	Redact    RedactConfig      `mapstructure:"redact"`
+	Capture   BodyCaptureConfig `mapstructure:"capture"`
+	Sampling  LogSamplingConfig `mapstructure:"sampling"`
+}
+
+// LogSamplingConfig 是 zap 的日誌取樣設定：每個 tick 內相同級別與訊息的日誌，前 Initial 筆全部輸出，之後每 Thereafter 筆輸出一筆
+type LogSamplingConfig struct {
+	Enabled     bool `mapstructure:"enabled"`
+	TickSeconds int  `mapstructure:"tick_seconds"` // 取樣週期 (秒)，0 為 1
+	Initial     int  `mapstructure:"initial"`
+	Thereafter  int  `mapstructure:"thereafter"`
+}
+
+// RedactConfig 是日誌敏感資料遮蔽的設定，遮蔽套用於日誌欄位、查詢字串、訊息內容與擷取的請求/回應 body
+type RedactConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`
+	Fields      []string `mapstructure:"fields"`       // 欄位名稱 (不分大小寫)，出現在日誌欄位、查詢參數或 JSON 任何層級的同名鍵皆遮蔽；為空時使用預設清單
+	JSONPaths   []string `mapstructure:"json_paths"`   // JSON 路徑，例如 $.payment.card.number、items[*].token，* 比對任意鍵或陣列元素
+	CardNumbers bool     `mapstructure:"card_numbers"` // 是否遮蔽通過 Luhn 檢查的信用卡號，只保留末四碼
+	Mask        string   `mapstructure:"mask"`         // 取代敏感值的字串，預設為 ***
+}
+
+// BodyCaptureConfig 是除錯用的請求/回應 body 擷取設定，擷取的內容經過遮蔽後寫入請求日誌
+type BodyCaptureConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	MaxBytes     int      `mapstructure:"max_bytes"`     // 每個 body 最多擷取的位元組數，0 為 4096
+	ContentTypes []string `mapstructure:"content_types"` // 擷取的 Content-Type 前綴，為空時只擷取 JSON 與表單
+}
+
+// LogFileConfig 是輪替日誌檔的設定，檔案超過 MaxSizeMB 時輪替
+type LogFileConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Path       string `mapstructure:"path" validate:"required_if=Enabled true"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb" validate:"min=0"`  // 單檔大小上限 (MB)，0 為 100
+	MaxAgeDays int    `mapstructure:"max_age_days" validate:"min=0"` // 舊檔保留天數，0 為不依天數清除
+	MaxBackups int    `mapstructure:"max_backups" validate:"min=0"`  // 舊檔保留數量，0 為全部保留
+	Compress   bool   `mapstructure:"compress"`                      // 是否以 gzip 壓縮舊檔
 }
 
 type DatabaseConfig struct {
-	DSN                    string `mapstructure:"dsn"`
-	MaxOpenConns           int    `mapstructure:"max_open_conns"`
-	MaxIdleConns           int    `mapstructure:"max_idle_conns"`
-	ConnMaxLifetimeMinutes int    `mapstructure:"conn_max_lifetime_minutes"`
+	DSN                    string         `mapstructure:"dsn"` // 主庫 (寫入) 連線字串
+	MaxOpenConns           int            `mapstructure:"max_open_conns" validate:"min=0"`
+	MaxIdleConns           int            `mapstructure:"max_idle_conns" validate:"min=0"`
+	ConnMaxLifetimeMinutes int            `mapstructure:"conn_max_lifetime_minutes" validate:"min=0"`
+	TimeoutMs              int            `mapstructure:"timeout_ms" validate:"min=0"` // 單一 SQL 操作的逾時 (毫秒)，0 代表不限 (僅受請求上下文限制)
+	Breaker                BreakerConfig  `mapstructure:"breaker"`                     // 主庫的斷路器
+	Replicas               ReplicasConfig `mapstructure:"replicas"`
+}
+
+// BreakerConfig 代表依賴組件的斷路器設定
+// 連續失敗 (連線錯誤、逾時) 達 FailureThreshold 次時開啟，開啟期間直接拒絕呼叫；
+// 經過 OpenSeconds 後進入 half-open，放行 HalfOpenRequests 個試探呼叫，全部成功則關閉，任一失敗則再次開啟
+// 試探呼叫超過 OpenSeconds 仍未回報結果時，收回名額並重新放行試探
+type BreakerConfig struct {
+	Enabled          bool `mapstructure:"enabled"`
+	FailureThreshold int  `mapstructure:"failure_threshold" validate:"min=0"`  // 0 為 5
+	OpenSeconds      int  `mapstructure:"open_seconds" validate:"min=0"`       // 0 為 10
+	HalfOpenRequests int  `mapstructure:"half_open_requests" validate:"min=0"` // 0 為 1
+}
+
+// ReplicasConfig 代表唯讀副本 (讀寫分離) 設定
+type ReplicasConfig struct {
+	DSNs                       []string `mapstructure:"dsns"`                                                        // 唯讀副本連線字串，留空則所有讀取都走主庫
+	Policy                     string   `mapstructure:"policy" validate:"omitempty,oneof=round_robin least_latency"` // "round_robin" 或 "least_latency"
+	HealthCheckIntervalSeconds int      `mapstructure:"health_check_interval_seconds" validate:"min=0"`
 }
 
 type RedisConfig struct {
-	Addr     string `mapstructure:"addr"`
-	Password string `mapstructure:"password"`
-	DB       int    `mapstructure:"db"`
+	Addr      string        `mapstructure:"addr"`
+	Password  string        `mapstructure:"password"`
+	DB        int           `mapstructure:"db" validate:"min=0"`
+	TimeoutMs int           `mapstructure:"timeout_ms" validate:"min=0"` // 單一命令 (連線、讀取、寫入與取得連線) 的逾時 (毫秒)，0 使用 go-redis 預設值
+	Breaker   BreakerConfig `mapstructure:"breaker"`
 }
 
+// MessagingConfig 代表訊息佇列設定，以 driver 選擇 Broker
+type MessagingConfig struct {
+	Driver            string               `mapstructure:"driver" validate:"required"`           // "rocketmq"、"kafka"、"nats" (JetStream)、"inproc" (行程內) 或 "stub" (僅記錄日誌)，不設預設值以免誤用
+	ProducerGroup     string               `mapstructure:"producer_group"`                       // RocketMQ 的 Producer Group，Kafka 與 NATS 作為 client ID
+	ConsumerGroup     string               `mapstructure:"consumer_group"`                       // Kafka 的 group ID，NATS 的 durable consumer 名稱
+	MaxReconsumeTimes int                  `mapstructure:"max_reconsume_times" validate:"min=0"` // 超過後由 Broker 層轉入死信 Topic
+	Subscriptions     []SubscriptionConfig `mapstructure:"subscriptions" validate:"dive"`
+	RocketMQ          RocketMQConfig       `mapstructure:"rocketmq"`
+	Kafka             KafkaConfig          `mapstructure:"kafka"`
+	NATS              NATSConfig           `mapstructure:"nats"`
+}
+
+// RocketMQConfig 代表 RocketMQ 連線設定
 type RocketMQConfig struct {
-	NameSrvAddr   string `mapstructure:"namesrv_addr"`
-	ProducerGroup string `mapstructure:"producer_group"`
-	ConsumerGroup string `mapstructure:"consumer_group"`
+	NameSrvAddr   string `mapstructure:"namesrv_addr"` // 多個位址以逗號分隔
+	Namespace     string `mapstructure:"namespace"`
+	AccessKey     string `mapstructure:"access_key"`
+	SecretKey     string `mapstructure:"secret_key"`
+	Retries       int    `mapstructure:"retries"`
+	SendTimeoutMs int    `mapstructure:"send_timeout_ms"`
+}
+
+// KafkaConfig 代表 Kafka 連線設定
+type KafkaConfig struct {
+	Brokers       []string `mapstructure:"brokers"`
+	Username      string   `mapstructure:"username"` // SASL/PLAIN 帳號，留空則不驗證
+	Password      string   `mapstructure:"password"`
+	TLS           bool     `mapstructure:"tls"`
+	RequiredAcks  int      `mapstructure:"required_acks" validate:"oneof=-1 0 1"` // -1 (所有同步副本)、1 (leader) 或 0 (不等待)
+	SendTimeoutMs int      `mapstructure:"send_timeout_ms"`
+	StartOffset   string   `mapstructure:"start_offset" validate:"omitempty,oneof=earliest latest"` // 新 Consumer Group 的起始位置："earliest" 或 "latest"
+}
+
+// NATSConfig 代表 NATS JetStream 連線設定
+type NATSConfig struct {
+	URL               string `mapstructure:"url"`        // 多個位址以逗號分隔
+	CredsFile         string `mapstructure:"creds_file"` // NATS 憑證檔 (選填)
+	Token             string `mapstructure:"token"`
+	StreamReplicas    int    `mapstructure:"stream_replicas"`
+	StreamMaxAgeHours int    `mapstructure:"stream_max_age_hours"` // 自動建立的 Stream 保留時間，0 代表不限
+	AckWaitMs         int    `mapstructure:"ack_wait_ms"`          // 未確認的訊息超過此時間後重新投遞
+	SendTimeoutMs     int    `mapstructure:"send_timeout_ms"`
+}
+
+// SubscriptionConfig 代表 Consumer 訂閱的 Topic 與標籤表達式
+type SubscriptionConfig struct {
+	Topic string `mapstructure:"topic" validate:"required"`
+	Tags  string `mapstructure:"tags"` // 標籤表達式，例如 "TagA || TagB"，留空代表全部 (*)
+}
+
+// OutboxConfig 代表 outbox relay (將 outbox 事件發送到訊息佇列的背景工作) 設定
+type OutboxConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	PollIntervalMs int  `mapstructure:"poll_interval_ms" validate:"min=0"`
+	BatchSize      int  `mapstructure:"batch_size" validate:"min=0"`
+	MaxAttempts    int  `mapstructure:"max_attempts" validate:"min=0"` // 超過後事件轉為 failed，0 代表無限重試
+	BackoffBaseMs  int  `mapstructure:"backoff_base_ms"`
+	BackoffMaxMs   int  `mapstructure:"backoff_max_ms"`
+	// LeaseSeconds 是 relay 認領事件的期間，期間內其他實例不會發送同一事件；須大於發送一批事件所需的時間，0 為 30
+	LeaseSeconds int `mapstructure:"lease_seconds" validate:"min=0"`
+}
+
+// ConsumerConfig 代表訊息消費框架 (handler 路由、重試與死信) 設定
+type ConsumerConfig struct {
+	Enabled       bool         `mapstructure:"enabled"`
+	MaxRetries    int          `mapstructure:"max_retries" validate:"min=0"` // 超過後訊息轉入死信，須小於 messaging.max_reconsume_times
+	BackoffBaseMs int          `mapstructure:"backoff_base_ms"`
+	BackoffMaxMs  int          `mapstructure:"backoff_max_ms"`
+	DLQTopic      string       `mapstructure:"dlq_topic"` // 留空則使用 %DLQ%<consumer_group>
+	Dedupe        DedupeConfig `mapstructure:"dedupe"`
+}
+
+// DedupeConfig 代表 Consumer 去重 (已處理訊息紀錄) 設定
+type DedupeConfig struct {
+	Enabled                bool   `mapstructure:"enabled"`
+	Store                  string `mapstructure:"store" validate:"omitempty,oneof=db redis"` // mysql 模式下為 "db" 或 "redis"；memory 模式固定使用記憶體
+	RetentionHours         int    `mapstructure:"retention_hours" validate:"min=0"`
+	CleanupIntervalMinutes int    `mapstructure:"cleanup_interval_minutes"`
+}
+
+// WebhookConfig 代表對外 webhook 投遞設定
+type WebhookConfig struct {
+	Enabled              bool     `mapstructure:"enabled"`
+	ConsumerGroup        string   `mapstructure:"consumer_group"` // 留空則使用 <messaging.consumer_group>_WEBHOOK
+	Topics               []string `mapstructure:"topics"`         // 要轉送給 webhook 的事件 Topic
+	PollIntervalMs       int      `mapstructure:"poll_interval_ms"`
+	BatchSize            int      `mapstructure:"batch_size"`
+	Concurrency          int      `mapstructure:"concurrency" validate:"min=0"` // 同時投遞的端點數
+	TimeoutMs            int      `mapstructure:"timeout_ms" validate:"min=0"`
+	RetryScheduleSeconds []int    `mapstructure:"retry_schedule_seconds"`                  // 第 n 次失敗後等待的秒數，用盡後放棄
+	DisableAfterFailures int      `mapstructure:"disable_after_failures" validate:"min=0"` // 端點連續失敗達此次數時自動停用，0 代表不停用
+	LeaseSeconds         int      `mapstructure:"lease_seconds" validate:"min=0"`          // 投遞紀錄的認領期間 (秒)，0 代表 600
 }
 
+// AdminConfig 代表管理 API 設定
+type AdminConfig struct {
+	Token string `mapstructure:"token"` // 管理 API 的存取權杖，留空則不註冊管理路由
+}
+
+// AuditConfig 代表稽核紀錄設定：mysql 模式寫入 audit_logs 資料表，memory 模式寫入 File 指定的 JSONL 檔
+type AuditConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	File    string `mapstructure:"file"` // memory 模式的稽核紀錄檔
+}
+
+// MetricsConfig 代表 Prometheus 指標端點設定
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path" validate:"omitempty,startswith=/"` // 指標端點路徑，預設為 /metrics
+}
+
+// TracingConfig 代表 OpenTelemetry 分散式追蹤設定
+type TracingConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	ServiceName string            `mapstructure:"service_name"`
+	Exporter    string            `mapstructure:"exporter" validate:"omitempty,oneof=otlp stdout none"` // "otlp"、"stdout" 或 "none" (僅產生 trace ID 供日誌使用，不匯出)
+	SampleRatio float64           `mapstructure:"sample_ratio" validate:"min=0,max=1"`                  // 沒有上游 trace 時的取樣比例 (0~1)，有上游時沿用其取樣決定
+	OTLP        OTLPConfig        `mapstructure:"otlp"`
+	Stdout      StdoutTraceConfig `mapstructure:"stdout"`
+}
+
+// OTLPConfig 代表 OTLP/HTTP 匯出設定
+type OTLPConfig struct {
+	Endpoint string            `mapstructure:"endpoint"` // host:port，例如 localhost:4318
+	Insecure bool              `mapstructure:"insecure"` // 使用 HTTP 而非 HTTPS
+	Headers  map[string]string `mapstructure:"headers"`  // 額外的請求標頭，例如驗證權杖
+}
+
+// StdoutTraceConfig 代表以 JSON 輸出 span 的設定
+type StdoutTraceConfig struct {
+	File   string `mapstructure:"file"` // 輸出檔案路徑，留空則寫入標準輸出
+	Pretty bool   `mapstructure:"pretty"`
+}
+
+// HealthCheckConfig 代表依賴組件健康檢查設定，檢查於背景輪詢，健康檢查端點只讀取最近一次的結果
 type HealthCheckConfig struct {
-	LatencyThreshold int `mapstructure:"latency_threshold"`
+	LatencyThreshold int             `mapstructure:"latency_threshold" validate:"min=0"` // 超過此延遲 (毫秒) 時狀態為 DEGRADED
+	TimeoutMs        int             `mapstructure:"timeout_ms" validate:"min=0"`        // 單次檢查的逾時 (毫秒)，0 為 1000
+	IntervalSeconds  int             `mapstructure:"interval_seconds" validate:"min=0"`  // 檢查間隔 (秒)，0 為 10
+	Critical         map[string]bool `mapstructure:"critical"`                           // 覆寫組件宣告的關鍵性，例如 redis: true
+}
+
+// RateLimitConfig 代表 API 速率限制，每個請求套用所有路由相符的策略
+type RateLimitConfig struct {
+	Enabled      bool              `mapstructure:"enabled"`
+	Store        string            `mapstructure:"store" validate:"omitempty,oneof=redis memory"` // "redis" (多個實例共用額度) 或 "memory" (單一行程)，留空則 mysql 模式使用 redis，memory 模式使用 memory
+	KeyPrefix    string            `mapstructure:"key_prefix"`                                    // Redis 鍵的前綴
+	APIKeyHeader string            `mapstructure:"api_key_header"`                                // 以 api_key 區分用戶端時讀取的標頭，預設為 X-API-Key
+	APIKeys      []string          `mapstructure:"api_keys"`                                      // 已核發的 API 金鑰，只有相符的金鑰才以 api_key 區分用戶端
+	Policies     []RateLimitPolicy `mapstructure:"policies" validate:"dive"`
 }
 
-// LoadConfig 從檔案載入配置
+// RateLimitPolicy 是一組路由的速率限制策略
+type RateLimitPolicy struct {
+	Name string `mapstructure:"name" validate:"required"` // 策略名稱，用於 Redis 鍵、日誌與指標
+	// Routes 是套用的路由，格式為 "[METHOD ]PATH"，PATH 以路由樣板 (例如 /api/v1/players/:id) 比對，結尾為 * 時比對前綴
+	Routes        []string `mapstructure:"routes" validate:"min=1"`
+	Key           string   `mapstructure:"key" validate:"oneof=ip api_key"`                        // 區分用戶端的方式："ip" 或 "api_key" (未帶金鑰或金鑰無效時以 IP 代替)
+	Algorithm     string   `mapstructure:"algorithm" validate:"oneof=token_bucket sliding_window"` // "token_bucket" 或 "sliding_window"
+	Limit         int      `mapstructure:"limit" validate:"min=1"`                                 // 每個時間窗允許的請求數，token_bucket 為補充速率
+	WindowSeconds int      `mapstructure:"window_seconds" validate:"min=1"`                        // 時間窗 (秒)
+	Burst         int      `mapstructure:"burst" validate:"min=0"`                                 // token_bucket 的容量，0 與 limit 相同
+}
+
+// 配置檔路徑與環境設定檔的環境變數
+const (
+	EnvConfigPath = "APP_CONFIG" // 配置檔路徑，--config 參數優先
+	EnvProfile    = "APP_ENV"    // 環境名稱，例如 production 會在基本配置之上合併 config.production.yaml
+)
+
+// DefaultPath 是未指定 --config 與 APP_CONFIG 時的配置檔路徑
+const DefaultPath = "./configs/config.yaml"
+
+// fileEnvSuffix 是從檔案讀取設定值的環境變數後綴，例如 DATABASE_DSN_FILE=/run/secrets/dsn
+const fileEnvSuffix = "_FILE"
+
+// ResolvePath 決定配置檔路徑，優先順序為 --config 參數、APP_CONFIG 環境變數、DefaultPath
+func ResolvePath(flagPath string) string {
+	if flagPath != "" {
+		return flagPath
+	}
+	if p := os.Getenv(EnvConfigPath); p != "" {
+		return p
+	}
+	return DefaultPath
+}
+
+// ProfilePath 回傳環境設定檔的路徑，與基本配置檔位於同一目錄，例如 configs/config.yaml 與 production 對應 configs/config.production.yaml
+func ProfilePath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// LoadConfig 從檔案載入配置並驗證，依序套用：
+//  1. 基本配置檔 path
+//  2. APP_ENV 指定的環境設定檔 (只需列出與基本配置不同的鍵，陣列整個取代)
+//  3. 環境變數，鍵名的 . 以 _ 取代，例如 DATABASE_DSN
+//  4. <鍵名>_FILE 環境變數指定的檔案內容，用於掛載的密鑰，例如 REDIS_PASSWORD_FILE
+//
+// 驗證失敗時回傳所有錯誤，呼叫端應在建立任何連線之前呼叫
 func LoadConfig(path string) (*Config, error) {
-	viper.SetConfigFile(path)
-	viper.SetConfigType("yaml")
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	v.AutomaticEnv()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("讀取配置檔失敗: %w", err)
 	}
 
+	if profile := os.Getenv(EnvProfile); profile != "" {
+		v.SetConfigFile(ProfilePath(path, profile))
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("讀取環境設定檔失敗 (%s=%s): %w", EnvProfile, profile, err)
+		}
+	}
+
+	if err := applyFileEnv(v); err != nil {
+		return nil, err
+	}
+
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("解析配置失敗: %w", err)
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 	return &config, nil
-}
\ No newline at end of file
+}
+
+// applyFileEnv 以 <鍵名>_FILE 環境變數指定的檔案內容覆寫設定值，結尾的換行會被移除
+// 同時設定 <鍵名> 與 <鍵名>_FILE 時視為錯誤，避免不清楚實際生效的值
+func applyFileEnv(v *viper.Viper) error {
+	for _, key := range v.AllKeys() {
+		env := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		file := os.Getenv(env + fileEnvSuffix)
+		if file == "" {
+			continue
+		}
+		if _, ok := os.LookupEnv(env); ok {
+			return fmt.Errorf("不可同時設定 %s 與 %s", env, env+fileEnvSuffix)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("讀取 %s 指定的檔案失敗: %w", env+fileEnvSuffix, err)
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return nil
+}
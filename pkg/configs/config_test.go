@@ -0,0 +1,167 @@
+package configs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/pkg/configs"
+)
+
+const baseConfig = `
+server:
+  port: 8080
+  mode: debug
+persistence:
+  type: mysql
+database:
+  dsn: "app:pass@tcp(127.0.0.1:4000)/game"
+  max_open_conns: 10
+redis:
+  addr: "127.0.0.1:6379"
+  password: ""
+messaging:
+  driver: stub
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestLoadConfig_ShippedConfigIsValid(t *testing.T) {
+	t.Setenv(configs.EnvProfile, "")
+	_, err := configs.LoadConfig("../../configs/config.yaml")
+	require.NoError(t, err)
+}
+
+func TestLoadConfig_Profile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, path, baseConfig)
+	writeFile(t, filepath.Join(dir, "config.production.yaml"), "server:\n  mode: release\ndatabase:\n  max_open_conns: 50\n")
+
+	t.Setenv(configs.EnvProfile, "production")
+	cfg, err := configs.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "release", cfg.Server.Mode)
+	assert.Equal(t, 50, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 8080, cfg.Server.Port, "未覆寫的鍵沿用基本配置")
+	assert.Equal(t, "app:pass@tcp(127.0.0.1:4000)/game", cfg.Database.DSN)
+
+	t.Setenv(configs.EnvProfile, "staging")
+	_, err = configs.LoadConfig(path)
+	assert.Error(t, err, "指定的環境設定檔不存在")
+}
+
+func TestLoadConfig_FileEnv(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, path, baseConfig)
+	dsnFile := filepath.Join(dir, "dsn")
+	writeFile(t, dsnFile, "secret:s3cret@tcp(db:4000)/game\n")
+	passwordFile := filepath.Join(dir, "redis_password")
+	writeFile(t, passwordFile, "redis-pass")
+
+	t.Setenv(configs.EnvProfile, "")
+	t.Setenv("DATABASE_DSN_FILE", dsnFile)
+	t.Setenv("REDIS_PASSWORD_FILE", passwordFile)
+	cfg, err := configs.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "secret:s3cret@tcp(db:4000)/game", cfg.Database.DSN)
+	assert.Equal(t, "redis-pass", cfg.Redis.Password)
+
+	t.Setenv("REDIS_PASSWORD", "from-env")
+	_, err = configs.LoadConfig(path)
+	assert.ErrorContains(t, err, "REDIS_PASSWORD_FILE")
+
+	t.Setenv("REDIS_PASSWORD_FILE", filepath.Join(dir, "missing"))
+	os.Unsetenv("REDIS_PASSWORD")
+	_, err = configs.LoadConfig(path)
+	assert.ErrorContains(t, err, "REDIS_PASSWORD_FILE")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := func() *configs.Config {
+		return &configs.Config{
+			Server:      configs.ServerConfig{Port: 8080},
+			Persistence: configs.PersistenceConfig{Type: "memory"},
+			Messaging:   configs.MessagingConfig{Driver: "stub"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *configs.Config)
+		errs   []string
+	}{
+		{name: "最小配置", modify: func(c *configs.Config) {}},
+		{
+			name: "回報所有欄位錯誤",
+			modify: func(c *configs.Config) {
+				c.Server.Port = 0
+				c.Server.Mode = "prod"
+				c.Tracing.SampleRatio = 1.5
+				c.Messaging.Driver = ""
+			},
+			errs: []string{"server.port: 必填", "messaging.driver: 必填", "server.mode: 須為下列其中之一: debug, release, test (目前為 prod)", "tracing.sample_ratio: 須小於或等於 1"},
+		},
+		{
+			name: "mysql 模式須設定 DSN 與 Redis",
+			modify: func(c *configs.Config) {
+				c.Persistence.Type = "mysql"
+			},
+			errs: []string{"database.dsn", "redis.addr"},
+		},
+		{
+			name: "巢狀與陣列欄位",
+			modify: func(c *configs.Config) {
+				c.Server.Admin.Enabled = true
+				c.Server.TrustedProxies = []string{"10.0.0.0/8", "proxy.local"}
+				c.RateLimit.Policies = []configs.RateLimitPolicy{{Name: "auth", Key: "player", Algorithm: "token_bucket", Limit: 1, WindowSeconds: 1}}
+			},
+			errs: []string{"server.trusted_proxies[1]: 須為 IP 或 CIDR (目前為 proxy.local)", "server.admin.port: 必填", "rate_limit.policies[0].routes: 至少需要 1 個", "rate_limit.policies[0].key: 須為下列其中之一: ip, api_key (目前為 player)"},
+		},
+		{
+			name: "相依的設定",
+			modify: func(c *configs.Config) {
+				c.Audit.Enabled = true
+				c.Webhook.Enabled = true
+				c.Consumer.Enabled = true
+				c.Consumer.MaxRetries = 16
+				c.Messaging.MaxReconsumeTimes = 16
+			},
+			errs: []string{"audit.file", "consumer.max_retries"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(c)
+			err := c.Validate()
+			if len(tt.errs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, e := range tt.errs {
+				assert.Contains(t, err.Error(), e)
+			}
+		})
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	t.Setenv(configs.EnvConfigPath, "")
+	assert.Equal(t, configs.DefaultPath, configs.ResolvePath(""))
+
+	t.Setenv(configs.EnvConfigPath, "/etc/app/config.yaml")
+	assert.Equal(t, "/etc/app/config.yaml", configs.ResolvePath(""))
+	assert.Equal(t, "custom.yaml", configs.ResolvePath("custom.yaml"), "--config 優先於環境變數")
+
+	assert.Equal(t, "configs/config.production.yaml", configs.ProfilePath("configs/config.yaml", "production"))
+}
@@ -0,0 +1,103 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validateOnce sync.Once
+	validate     *validator.Validate
+)
+
+// structValidator 回傳以 mapstructure 鍵名回報欄位的 validator，錯誤訊息的欄位與配置檔的鍵一致
+func structValidator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+		validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	})
+	return validate
+}
+
+// Validate 檢查 validate 標籤與欄位之間的相依條件，一次回傳所有錯誤
+func (c *Config) Validate() error {
+	var errs []error
+
+	var fieldErrs validator.ValidationErrors
+	if err := structValidator().Struct(c); errors.As(err, &fieldErrs) {
+		for _, fe := range fieldErrs {
+			errs = append(errs, fieldError(fe))
+		}
+	} else if err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Persistence.Type == "mysql" {
+		if c.Database.DSN == "" {
+			errs = append(errs, errors.New("database.dsn: mysql 模式必填"))
+		}
+		if c.Redis.Addr == "" {
+			errs = append(errs, errors.New("redis.addr: mysql 模式必填"))
+		}
+	}
+	if c.Persistence.Type == "memory" {
+		if c.Audit.Enabled && c.Audit.File == "" {
+			errs = append(errs, errors.New("audit.file: memory 模式啟用稽核紀錄時必填"))
+		}
+		if c.RateLimit.Enabled && c.RateLimit.Store == "redis" {
+			errs = append(errs, errors.New("rate_limit.store: memory 模式不可使用 redis"))
+		}
+	}
+	if c.Consumer.Enabled && c.Messaging.MaxReconsumeTimes > 0 && c.Consumer.MaxRetries >= c.Messaging.MaxReconsumeTimes {
+		errs = append(errs, fmt.Errorf("consumer.max_retries: 須小於 messaging.max_reconsume_times (%d)，否則 Broker 會先將訊息轉入死信 Topic",
+			c.Messaging.MaxReconsumeTimes))
+	}
+	if c.Webhook.Enabled && !c.Consumer.Enabled {
+		errs = append(errs, errors.New("webhook.enabled: 須同時啟用 consumer"))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("配置驗證失敗:\n%w", errors.Join(errs...))
+}
+
+// fieldError 將 validator 的錯誤轉為以配置鍵名表示的訊息，例如 server.port: 須小於或等於 65535 (目前為 70000)
+func fieldError(fe validator.FieldError) error {
+	// Namespace 的第一段是結構名稱 (Config)
+	_, key, _ := strings.Cut(fe.Namespace(), ".")
+
+	var msg string
+	switch fe.Tag() {
+	case "required", "required_if":
+		return fmt.Errorf("%s: 必填", key)
+	case "min":
+		if k := fe.Kind(); k == reflect.Slice || k == reflect.Map {
+			msg = "至少需要 " + fe.Param() + " 個"
+		} else {
+			msg = "須大於或等於 " + fe.Param()
+		}
+	case "max":
+		msg = "須小於或等於 " + fe.Param()
+	case "oneof":
+		msg = "須為下列其中之一: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "ip|cidr":
+		msg = "須為 IP 或 CIDR"
+	case "startswith":
+		msg = "須以 " + fe.Param() + " 開頭"
+	default:
+		msg = "未通過 " + fe.Tag() + " 驗證"
+	}
+	return fmt.Errorf("%s: %s (目前為 %v)", key, msg, fe.Value())
+}
@@ -5,17 +5,27 @@ import (
 	"fmt"
 	"time"
 
+	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"microservice-mvp/pkg/breaker"
 	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/health"
 	pkgLogger "microservice-mvp/pkg/logger" // 別名以避免與 gorm.io/gorm/logger 衝突
-	"go.uber.org/zap"
+	"microservice-mvp/pkg/metrics"
+	"microservice-mvp/pkg/tracing"
 )
 
-// DB 是全域 GORM DB 客戶端
+// DB 是全域 GORM DB 客戶端 (主庫)
 var DB *gorm.DB
 
+// replicas 是全域唯讀副本集合，未設定副本時為 nil
+var replicas *replicaSet
+
+// primaryBreaker 是主庫的斷路器，未啟用時為 nil
+var primaryBreaker *breaker.Breaker
+
 // InitTiDB 使用 GORM 初始化 TiDB 連線
 func InitTiDB(cfg configs.DatabaseConfig) (*gorm.DB, error) {
 	newLogger := logger.New(
@@ -27,26 +37,122 @@ func InitTiDB(cfg configs.DatabaseConfig) (*gorm.DB, error) {
 		},
 	)
 
+	gormCfg := &gorm.Config{
+		Logger:         newLogger,
+		TranslateError: true, // 將驅動程式錯誤轉換為 gorm.ErrDuplicatedKey 等通用錯誤
+	}
+
 	dsn := cfg.DSN
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: newLogger,
-	})
+	db, err := gorm.Open(mysql.Open(dsn), gormCfg)
 	if err != nil {
 		return nil, fmt.Errorf("連線到 TiDB 失敗: %w", err)
 	}
 
+	if err := configurePool(db, cfg); err != nil {
+		return nil, err
+	}
+	if err := db.Use(metrics.NewGormPlugin("primary")); err != nil {
+		return nil, fmt.Errorf("註冊查詢指標 plugin 失敗: %w", err)
+	}
+	if err := db.Use(tracing.NewGormPlugin("primary")); err != nil {
+		return nil, fmt.Errorf("註冊查詢追蹤 plugin 失敗: %w", err)
+	}
+	primaryBreaker = breaker.New("tidb", cfg.Breaker)
+	if err := db.Use(breaker.NewGormPlugin("primary", primaryBreaker, time.Duration(cfg.TimeoutMs)*time.Millisecond)); err != nil {
+		return nil, fmt.Errorf("註冊斷路器 plugin 失敗: %w", err)
+	}
+	if err := registerWriteTracking(db); err != nil {
+		return nil, fmt.Errorf("註冊讀寫分離 callback 失敗: %w", err)
+	}
+
+	if len(cfg.Replicas.DSNs) > 0 {
+		rs, err := openReplicas(cfg, gormCfg)
+		if err != nil {
+			return nil, err
+		}
+		rs.startHealthCheck(time.Duration(cfg.Replicas.HealthCheckIntervalSeconds) * time.Second)
+		replicas = rs
+		pkgLogger.Logger.Info("TiDB 唯讀副本初始化成功",
+			zap.Int("replicas", len(rs.replicas)), zap.String("policy", rs.policy))
+	}
+
+	DB = db // 設定全域 DB 實例
+	registerHealthChecks()
+	pkgLogger.Logger.Info("TiDB 連線初始化成功")
+	return db, nil
+}
+
+// registerHealthChecks 向 health 註冊主庫 (關鍵) 與各唯讀副本 (非關鍵，不健康時讀取回退至主庫) 的檢查
+func registerHealthChecks() {
+	health.Register(health.NewChecker("tidb", func(ctx context.Context) health.Result {
+		sqlDB, err := DB.DB()
+		if err != nil {
+			return health.Result{Status: health.StatusDown, Message: "無法獲取 DB 連線池"}
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return health.Result{Status: health.StatusDown, Message: fmt.Sprintf("Ping 失敗: %v", err)}
+		}
+		stats := sqlDB.Stats()
+		state := primaryBreaker.State()
+		result := health.Result{
+			Status:  health.StatusUp,
+			Details: fmt.Sprintf("open=%d in_use=%d idle=%d breaker=%s", stats.OpenConnections, stats.InUse, stats.Idle, state),
+		}
+		// Ping 不經過斷路器；Ping 成功但斷路器未關閉代表查詢仍在失敗或尚在恢復中
+		if state != breaker.StateClosed {
+			result.Status, result.Message = health.StatusDegraded, "斷路器 "+state.String()
+		}
+		return result
+	}), health.Options{Critical: true})
+
+	// 副本由 replicaSet 的背景檢查探測，這裡只回報其結果
+	for _, status := range replicas.statuses() {
+		name := status.Name
+		health.Register(health.NewChecker("tidb_"+name, func(ctx context.Context) health.Result {
+			for _, s := range replicas.statuses() {
+				if s.Name != name {
+					continue
+				}
+				if !s.Healthy {
+					return health.Result{Status: health.StatusDown, Message: "已暫停使用，讀取回退至主庫"}
+				}
+				return health.Result{Status: health.StatusUp, Details: "latency=" + s.Latency.String()}
+			}
+			return health.Result{Status: health.StatusDown, Message: "副本已關閉"}
+		}), health.Options{Critical: false})
+	}
+}
+
+// configurePool 套用連線池設定
+func configurePool(db *gorm.DB, cfg configs.DatabaseConfig) error {
 	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, fmt.Errorf("獲取底層 sql.DB 失敗: %w", err)
+		return fmt.Errorf("獲取底層 sql.DB 失敗: %w", err)
 	}
 
 	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
 	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetimeMinutes) * time.Minute)
+	return nil
+}
 
-	DB = db // 設定全域 DB 實例
-	pkgLogger.Logger.Info("TiDB 連線初始化成功")
-	return db, nil
+// Close 停止副本健康檢查並關閉主庫與所有副本連線
+func Close() error {
+	replicas.close()
+	replicas = nil
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// ReplicaStatuses 回傳所有唯讀副本的健康狀態
+func ReplicaStatuses() []ReplicaStatus {
+	return replicas.statuses()
 }
 
 // GetDB 回傳全域 GORM DB 客戶端
@@ -54,15 +160,59 @@ func GetDB() *gorm.DB {
 	return DB
 }
 
-// WithContext 將上下文中的日誌器傳遞給 GORM 的 session
-// 這允許 GORM 日誌包含 traceID
+// WithContext 回傳主庫的 GORM session，用於寫入與需要強一致的讀取
+// 它會將上下文中的日誌器傳遞給 GORM 的 session，這允許 GORM 日誌包含 traceID
+// 若上下文中帶有交易 (見 WithTx)，則回傳該交易，讓呼叫端的寫入加入同一個交易
 func WithContext(ctx context.Context) *gorm.DB {
+	if tx := txFromContext(ctx); tx != nil {
+		return withContext(tx, ctx)
+	}
+	return withContext(DB, ctx)
+}
+
+// ReadContext 回傳用於讀取的 GORM session
+// 有健康的唯讀副本時依策略挑選副本；沒有副本、副本全部不健康，
+// 或同一 session 內已發生寫入 (見 WithSession) 時回退至主庫；交易中的讀取一律使用該交易
+func ReadContext(ctx context.Context) *gorm.DB {
+	if PinnedToPrimary(ctx) || txFromContext(ctx) != nil {
+		return WithContext(ctx)
+	}
+	if r := replicas.pick(); r != nil {
+		return withContext(r.db, ctx)
+	}
+	return WithContext(ctx)
+}
+
+type txKey struct{}
+
+// WithTx 回傳帶有交易的上下文，之後以此上下文呼叫 WithContext / ReadContext 的 Repository 都會使用該交易
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// Transaction 在主庫的交易中執行 fn，fn 收到的上下文帶有該交易 (見 WithTx)
+// fn 回傳錯誤或 panic 時回滾；上下文已帶有交易時以 savepoint 巢狀執行
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(WithTx(ctx, tx))
+	})
+}
+
+func txFromContext(ctx context.Context) *gorm.DB {
+	if ctx == nil {
+		return nil
+	}
+	tx, _ := ctx.Value(txKey{}).(*gorm.DB)
+	return tx
+}
+
+func withContext(db *gorm.DB, ctx context.Context) *gorm.DB {
 	if ctx == nil {
-		return DB
+		return db
 	}
 	// 從上下文中獲取 logger (應包含 traceID)
-	zapLogger := pkgLogger.FromContext(ctx)
-	return DB.WithContext(ctx).Session(&gorm.Session{
+	zapLogger := pkgLogger.FromContext(ctx).Named("gorm")
+	return db.WithContext(ctx).Session(&gorm.Session{
 		Logger: logger.New(
 			&logWriter{zapLogger: zapLogger},
 			logger.Config{
@@ -81,7 +231,7 @@ type logWriter struct {
 
 func (l *logWriter) Printf(format string, v ...interface{}) {
 	if l.zapLogger == nil {
-		l.zapLogger = pkgLogger.Logger // 如果上下文 logger 未設定，則回退到全域 logger
+		l.zapLogger = pkgLogger.Named("gorm") // 如果上下文 logger 未設定，則回退到全域 logger
 	}
 	// GORM 的 Printf 通常在格式字串中包含日誌級別
 	// 我們將其解析或僅以預設級別記錄
@@ -89,4 +239,4 @@ func (l *logWriter) Printf(format string, v ...interface{}) {
 	// 並依賴 GORM 的 LogLevel 配置進行過濾
 	// 慢查詢已由 GORM 在 Warn 級別記錄
 	l.zapLogger.Debug(fmt.Sprintf(format, v...))
-}
\ No newline at end of file
+}
@@ -0,0 +1,292 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"microservice-mvp/pkg/breaker"
+	"microservice-mvp/pkg/configs"
+	pkgLogger "microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/metrics"
+	"microservice-mvp/pkg/tracing"
+)
+
+const (
+	// ReplicaPolicyRoundRobin 依序輪流使用健康的副本
+	ReplicaPolicyRoundRobin = "round_robin"
+	// ReplicaPolicyLeastLatency 使用最近一次健康檢查延遲最低的副本
+	ReplicaPolicyLeastLatency = "least_latency"
+
+	// latencyEWMAWeight 是新樣本在延遲移動平均中的權重
+	latencyEWMAWeight = 0.3
+)
+
+// ReplicaStatus 描述唯讀副本的健康狀態，供健康檢查使用
+type ReplicaStatus struct {
+	Name    string
+	Healthy bool
+	Latency time.Duration
+}
+
+// replica 代表一個唯讀副本
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+	latency atomic.Int64 // 健康檢查延遲的指數移動平均 (奈秒)
+}
+
+// replicaSet 管理唯讀副本的選擇與背景健康檢查
+type replicaSet struct {
+	replicas []*replica
+	policy   string
+	next     atomic.Uint64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// openReplicas 開啟所有唯讀副本
+// 副本在啟動時無法連線不會阻止服務啟動，而是標記為不健康，待健康檢查恢復後才開始使用
+func openReplicas(cfg configs.DatabaseConfig, gormCfg *gorm.Config) (*replicaSet, error) {
+	policy := cfg.Replicas.Policy
+	switch policy {
+	case "":
+		policy = ReplicaPolicyRoundRobin
+	case ReplicaPolicyRoundRobin, ReplicaPolicyLeastLatency:
+	default:
+		return nil, fmt.Errorf("無效的副本選擇策略: %s", policy)
+	}
+
+	rs := &replicaSet{policy: policy, stop: make(chan struct{})}
+	for i, dsn := range cfg.Replicas.DSNs {
+		replicaCfg := *gormCfg
+		replicaCfg.DisableAutomaticPing = true
+		db, err := gorm.Open(mysql.Open(dsn), &replicaCfg)
+		if err != nil {
+			rs.close()
+			return nil, fmt.Errorf("開啟唯讀副本 %d 失敗: %w", i, err)
+		}
+		if err := configurePool(db, cfg); err != nil {
+			rs.close()
+			return nil, err
+		}
+		name := replicaName(i, dsn)
+		if err := db.Use(metrics.NewGormPlugin(name)); err != nil {
+			rs.close()
+			return nil, fmt.Errorf("註冊唯讀副本 %d 查詢指標 plugin 失敗: %w", i, err)
+		}
+		if err := db.Use(tracing.NewGormPlugin(name)); err != nil {
+			rs.close()
+			return nil, fmt.Errorf("註冊唯讀副本 %d 查詢追蹤 plugin 失敗: %w", i, err)
+		}
+		// 副本不使用斷路器 (不健康的副本由健康檢查略過)，只套用操作逾時
+		if err := db.Use(breaker.NewGormPlugin(name, nil, time.Duration(cfg.TimeoutMs)*time.Millisecond)); err != nil {
+			rs.close()
+			return nil, fmt.Errorf("註冊唯讀副本 %d 逾時 plugin 失敗: %w", i, err)
+		}
+		rs.replicas = append(rs.replicas, &replica{name: name, db: db})
+	}
+
+	// 先同步檢查一次，避免啟動後第一個檢查週期內把流量導向無法連線的副本
+	rs.checkAll()
+	return rs, nil
+}
+
+// replicaName 以副本位址作為名稱，避免在日誌與健康檢查中暴露帳號密碼
+func replicaName(index int, dsn string) string {
+	if parsed, err := mysqlDriver.ParseDSN(dsn); err == nil && parsed.Addr != "" {
+		return fmt.Sprintf("replica-%d(%s)", index, parsed.Addr)
+	}
+	return fmt.Sprintf("replica-%d", index)
+}
+
+// startHealthCheck 啟動背景健康檢查
+func (rs *replicaSet) startHealthCheck(interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	rs.wg.Add(1)
+	go func() {
+		defer rs.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-rs.stop:
+				return
+			case <-ticker.C:
+				rs.checkAll()
+			}
+		}
+	}()
+}
+
+func (rs *replicaSet) checkAll() {
+	for _, r := range rs.replicas {
+		rs.check(r)
+	}
+}
+
+func (rs *replicaSet) check(r *replica) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := ping(ctx, r.db)
+	latency := time.Since(start)
+
+	wasHealthy := r.healthy.Load()
+	if err != nil {
+		r.healthy.Store(false)
+		if wasHealthy {
+			pkgLogger.Logger.Warn("唯讀副本健康檢查失敗，暫停使用", zap.String("replica", r.name), zap.Error(err))
+		}
+		return
+	}
+
+	r.recordLatency(latency)
+	r.healthy.Store(true)
+	if !wasHealthy {
+		pkgLogger.Logger.Info("唯讀副本已可使用", zap.String("replica", r.name), zap.Duration("latency", latency))
+	}
+}
+
+func (r *replica) recordLatency(latency time.Duration) {
+	prev := r.latency.Load()
+	if prev == 0 {
+		r.latency.Store(int64(latency))
+		return
+	}
+	r.latency.Store(int64(latencyEWMAWeight*float64(latency) + (1-latencyEWMAWeight)*float64(prev)))
+}
+
+// pick 依策略挑選一個健康的副本，若沒有健康的副本則回傳 nil (呼叫端應回退至主庫)
+func (rs *replicaSet) pick() *replica {
+	if rs == nil || len(rs.replicas) == 0 {
+		return nil
+	}
+
+	if rs.policy == ReplicaPolicyLeastLatency {
+		var best *replica
+		for _, r := range rs.replicas {
+			if !r.healthy.Load() {
+				continue
+			}
+			if best == nil || r.latency.Load() < best.latency.Load() {
+				best = r
+			}
+		}
+		return best
+	}
+
+	n := uint64(len(rs.replicas))
+	start := rs.next.Add(1) - 1
+	for i := uint64(0); i < n; i++ {
+		r := rs.replicas[(start+i)%n]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+func (rs *replicaSet) statuses() []ReplicaStatus {
+	if rs == nil {
+		return nil
+	}
+	statuses := make([]ReplicaStatus, 0, len(rs.replicas))
+	for _, r := range rs.replicas {
+		statuses = append(statuses, ReplicaStatus{
+			Name:    r.name,
+			Healthy: r.healthy.Load(),
+			Latency: time.Duration(r.latency.Load()),
+		})
+	}
+	return statuses
+}
+
+func (rs *replicaSet) close() {
+	if rs == nil {
+		return
+	}
+	close(rs.stop)
+	rs.wg.Wait()
+	for _, r := range rs.replicas {
+		if sqlDB, err := r.db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+}
+
+func ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// --- Read-your-writes ---
+
+type sessionKey struct{}
+
+// session 記錄同一請求中是否已發生寫入
+type session struct {
+	wrote atomic.Bool
+}
+
+// WithSession 為一次請求建立讀寫分離的 session
+// 同一 session 內一旦在主庫發生寫入，之後的 ReadContext 都會固定走主庫，確保呼叫端讀到自己的寫入
+func WithSession(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(sessionKey{}).(*session); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// PinnedToPrimary 回傳此上下文的讀取是否必須走主庫
+func PinnedToPrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	return ok && s.wrote.Load()
+}
+
+func markWrite(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+}
+
+// registerWriteTracking 在主庫的寫入 callback 之後標記 session，讓後續讀取固定走主庫
+func registerWriteTracking(db *gorm.DB) error {
+	mark := func(tx *gorm.DB) {
+		if tx.Error == nil && tx.Statement != nil {
+			markWrite(tx.Statement.Context)
+		}
+	}
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("rw_split:mark_write", mark); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("rw_split:mark_write", mark); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("rw_split:mark_write", mark); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("rw_split:mark_write", mark)
+}
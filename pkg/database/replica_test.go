@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReplicaSet(policy string, healthy []bool, latencies []time.Duration) *replicaSet {
+	rs := &replicaSet{policy: policy}
+	for i := range healthy {
+		r := &replica{name: string(rune('a' + i))}
+		r.healthy.Store(healthy[i])
+		r.latency.Store(int64(latencies[i]))
+		rs.replicas = append(rs.replicas, r)
+	}
+	return rs
+}
+
+func TestReplicaSet_Pick(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		healthy   []bool
+		latencies []time.Duration
+		expected  []string // 連續 pick 的結果，空字串代表回退至主庫
+	}{
+		{
+			name:      "RoundRobin",
+			policy:    ReplicaPolicyRoundRobin,
+			healthy:   []bool{true, true, true},
+			latencies: []time.Duration{0, 0, 0},
+			expected:  []string{"a", "b", "c", "a"},
+		},
+		{
+			name:      "RoundRobinSkipsUnhealthy",
+			policy:    ReplicaPolicyRoundRobin,
+			healthy:   []bool{true, false, true},
+			latencies: []time.Duration{0, 0, 0},
+			expected:  []string{"a", "c", "c", "a"},
+		},
+		{
+			name:      "LeastLatency",
+			policy:    ReplicaPolicyLeastLatency,
+			healthy:   []bool{true, true, false},
+			latencies: []time.Duration{5 * time.Millisecond, 2 * time.Millisecond, time.Millisecond},
+			expected:  []string{"b", "b"},
+		},
+		{
+			name:      "AllUnhealthyFallsBackToPrimary",
+			policy:    ReplicaPolicyRoundRobin,
+			healthy:   []bool{false, false},
+			latencies: []time.Duration{0, 0},
+			expected:  []string{"", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := newTestReplicaSet(tt.policy, tt.healthy, tt.latencies)
+			for i, want := range tt.expected {
+				got := rs.pick()
+				if want == "" {
+					assert.Nil(t, got, "第 %d 次挑選", i)
+					continue
+				}
+				if assert.NotNil(t, got, "第 %d 次挑選", i) {
+					assert.Equal(t, want, got.name, "第 %d 次挑選", i)
+				}
+			}
+		})
+	}
+}
+
+func TestSession_PinsReadsAfterWrite(t *testing.T) {
+	ctx := context.Background()
+	markWrite(ctx) // 沒有 session 時不應 panic
+	assert.False(t, PinnedToPrimary(ctx))
+
+	ctx = WithSession(ctx)
+	assert.False(t, PinnedToPrimary(ctx))
+
+	markWrite(ctx)
+	assert.True(t, PinnedToPrimary(ctx))
+	assert.True(t, PinnedToPrimary(WithSession(ctx)), "重複建立 session 不應重置狀態")
+}
@@ -0,0 +1,75 @@
+// Package diagnostics 提供與對外 API 分開、只在獨立埠監聽的診斷端點：
+// pprof、expvar、goroutine dump、建置資訊與已遮蔽敏感值的實際生效配置。
+package diagnostics
+
+import (
+	"encoding/json"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"strconv"
+	"time"
+
+	"microservice-mvp/pkg/buildinfo"
+	"microservice-mvp/pkg/configs"
+)
+
+// NewHandler 建立診斷端點的 http.Handler
+//
+//	/debug/pprof/      pprof 索引與各項 profile (profile、trace、heap、goroutine 等)
+//	/debug/vars        expvar
+//	/debug/goroutines  所有 goroutine 的完整堆疊，?debug=1 依堆疊彙總
+//	/debug/buildinfo   版本、commit 與建置時間
+//	/debug/config      實際生效的配置，密碼、權杖與金鑰以 *** 取代
+func NewHandler(cfg *configs.Config) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	mux.HandleFunc("GET /debug/goroutines", goroutines)
+	mux.HandleFunc("GET /debug/buildinfo", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, buildinfo.Get())
+	})
+
+	masked := cfg.Masked() // 配置於啟動後不再變動，只需遮蔽一次
+	mux.HandleFunc("GET /debug/config", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, masked)
+	})
+	return mux
+}
+
+// NewServer 依 server.admin 建立診斷伺服器，由呼叫端啟動並與主伺服器一同關閉
+func NewServer(cfg *configs.Config) *http.Server {
+	return &http.Server{
+		Addr:              net.JoinHostPort(cfg.Server.Admin.Host, strconv.Itoa(cfg.Server.Admin.Port)),
+		Handler:           NewHandler(cfg),
+		ReadHeaderTimeout: 5 * time.Second,
+		// 不設定 WriteTimeout：CPU profile 與 trace 依 ?seconds= 參數需要較長的回應時間
+	}
+}
+
+func goroutines(w http.ResponseWriter, r *http.Request) {
+	debug := 2
+	if v := r.URL.Query().Get("debug"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 2 {
+			http.Error(w, "debug 參數須為 1 或 2", http.StatusBadRequest)
+			return
+		}
+		debug = n
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, debug)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
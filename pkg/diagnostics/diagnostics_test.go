@@ -0,0 +1,63 @@
+package diagnostics_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/pkg/buildinfo"
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/diagnostics"
+)
+
+func TestNewHandler(t *testing.T) {
+	buildinfo.Version, buildinfo.Commit, buildinfo.BuildTime = "v1.2.3", "abc123", "2026-01-02T03:04:05Z"
+	t.Cleanup(func() { buildinfo.Version, buildinfo.Commit, buildinfo.BuildTime = "dev", "", "" })
+
+	handler := diagnostics.NewHandler(&configs.Config{
+		Redis: configs.RedisConfig{Addr: "localhost:6379", Password: "redis-pass"},
+	})
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		contains string
+	}{
+		{name: "pprof 索引", path: "/debug/pprof/", status: http.StatusOK, contains: "goroutine"},
+		{name: "expvar", path: "/debug/vars", status: http.StatusOK, contains: "memstats"},
+		{name: "goroutine dump", path: "/debug/goroutines", status: http.StatusOK, contains: "goroutine "},
+		{name: "goroutine dump 參數錯誤", path: "/debug/goroutines?debug=5", status: http.StatusBadRequest},
+		{name: "遮蔽敏感值的配置", path: "/debug/config", status: http.StatusOK, contains: `"password": "***"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.path)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.contains)
+			assert.NotContains(t, w.Body.String(), "redis-pass")
+		})
+	}
+
+	t.Run("建置資訊", func(t *testing.T) {
+		w := get("/debug/buildinfo")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var info buildinfo.Info
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+		assert.Equal(t, "v1.2.3", info.Version)
+		assert.Equal(t, "abc123", info.Commit)
+		assert.Equal(t, "2026-01-02T03:04:05Z", info.BuildTime)
+		assert.NotEmpty(t, info.GoVersion)
+	})
+}
@@ -0,0 +1,65 @@
+package events
+
+import "time"
+
+// 事件 Topic
+const (
+	TopicPlayerEvents = "player_events"
+	TopicWalletEvents = "wallet_events"
+	TopicBetEvents    = "bet_events"
+)
+
+// 事件類型，同時作為訊息標籤，Consumer 可用標籤表達式只訂閱需要的事件
+const (
+	TypePlayerRegistered = "player.registered"
+	TypePlayerLoggedIn   = "player.logged_in"
+	TypeWalletDebited    = "wallet.debited"
+	TypeBetSettled       = "bet.settled"
+)
+
+// PlayerRegistered 是玩家註冊成功後的事件內容
+type PlayerRegistered struct {
+	PlayerID     uint      `json:"player_id" proto:"1,required"`
+	Username     string    `json:"username" proto:"2,required"`
+	RegisteredAt time.Time `json:"registered_at" proto:"3"`
+}
+
+// PlayerLoggedIn 是玩家登入成功後的事件內容
+type PlayerLoggedIn struct {
+	PlayerID uint      `json:"player_id" proto:"1,required"`
+	LoginAt  time.Time `json:"login_at" proto:"2"`
+	IP       string    `json:"ip" proto:"3"`
+}
+
+// WalletDebited 是玩家錢包扣款後的事件內容
+type WalletDebited struct {
+	PlayerID     uint    `json:"player_id" proto:"1,required"`
+	Amount       float64 `json:"amount" proto:"2,required"`
+	BalanceAfter float64 `json:"balance_after" proto:"3"`
+	Reason       string  `json:"reason" proto:"4"`
+	ReferenceID  string  `json:"reference_id" proto:"5"` // 對應的業務單號，例如注單 ID
+}
+
+// BetSettled 是注單結算後的事件內容
+type BetSettled struct {
+	BetID     string    `json:"bet_id" proto:"1,required"`
+	PlayerID  uint      `json:"player_id" proto:"2,required"`
+	GameID    string    `json:"game_id" proto:"3"`
+	Stake     float64   `json:"stake" proto:"4"`
+	Payout    float64   `json:"payout" proto:"5"`
+	Outcome   string    `json:"outcome" proto:"6"` // win, lose 或 void
+	SettledAt time.Time `json:"settled_at" proto:"7"`
+}
+
+// Default 是服務內建的事件目錄
+// 修改既有事件時請新增版本並保持相容；TestCatalogSchemas 會以 testdata 中的快照阻擋破壞性變更
+var Default = NewRegistry()
+
+func init() {
+	Default.MustRegister(
+		Definition{Type: TypePlayerRegistered, Version: 1, Topic: TopicPlayerEvents, Payload: PlayerRegistered{}},
+		Definition{Type: TypePlayerLoggedIn, Version: 1, Topic: TopicPlayerEvents, Payload: PlayerLoggedIn{}},
+		Definition{Type: TypeWalletDebited, Version: 1, Topic: TopicWalletEvents, Payload: WalletDebited{}},
+		Definition{Type: TypeBetSettled, Version: 1, Topic: TopicBetEvents, Payload: BetSettled{}},
+	)
+}
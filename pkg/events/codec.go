@@ -0,0 +1,353 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 編解碼器的內容類型，會寫入訊息屬性讓 Consumer 選擇對應的解碼器
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Codec 將事件信封序列化為訊息內容
+type Codec interface {
+	ContentType() string
+	Marshal(env *Envelope) ([]byte, error)
+	Unmarshal(data []byte) (*Envelope, error)
+}
+
+// JSONCodec 回傳以 JSON 編碼事件的 Codec
+func (r *Registry) JSONCodec() Codec {
+	return &jsonCodec{registry: r}
+}
+
+// ProtoCodec 回傳以 protobuf wire format 編碼事件的 Codec
+// 欄位編號取自事件內容的 proto 標籤，不需要產生程式碼
+func (r *Registry) ProtoCodec() Codec {
+	return &protoCodec{registry: r}
+}
+
+// Codec 依內容類型取得 Codec，空字串視為 JSON
+func (r *Registry) Codec(contentType string) (Codec, error) {
+	switch contentType {
+	case ContentTypeJSON, "":
+		return r.JSONCodec(), nil
+	case ContentTypeProtobuf:
+		return r.ProtoCodec(), nil
+	default:
+		return nil, fmt.Errorf("不支援的事件內容類型: %s", contentType)
+	}
+}
+
+// definitionFor 取得信封對應的定義並驗證內容
+func (r *Registry) definitionFor(env *Envelope) (*Definition, error) {
+	def, err := r.definitionOf(env.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if def.Type != env.Type || def.Version != env.Version {
+		return nil, fmt.Errorf("信封標示為 %s v%d，但內容型別屬於 %s v%d", env.Type, env.Version, def.Type, def.Version)
+	}
+	return def, def.validate(env.Payload)
+}
+
+// --- JSON ---
+
+type jsonCodec struct {
+	registry *Registry
+}
+
+// jsonEnvelope 是 JSON 的傳輸格式，Payload 延後到查出事件定義後再解碼
+type jsonEnvelope struct {
+	ID          string          `json:"event_id"`
+	Type        string          `json:"type"`
+	Version     int             `json:"version"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	TraceID     string          `json:"trace_id,omitempty"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+func (c *jsonCodec) ContentType() string { return ContentTypeJSON }
+
+func (c *jsonCodec) Marshal(env *Envelope) ([]byte, error) {
+	if _, err := c.registry.definitionFor(env); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("JSON 編碼事件失敗: %w", err)
+	}
+	return data, nil
+}
+
+func (c *jsonCodec) Unmarshal(data []byte) (*Envelope, error) {
+	var wire jsonEnvelope
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, fmt.Errorf("JSON 解碼事件失敗: %w", err)
+	}
+	def, err := c.registry.Lookup(wire.Type, wire.Version)
+	if err != nil {
+		return nil, err
+	}
+	payload := def.newPayload()
+	if len(wire.Payload) > 0 {
+		if err := json.Unmarshal(wire.Payload, payload.Interface()); err != nil {
+			return nil, fmt.Errorf("JSON 解碼事件 %s 內容失敗: %w", wire.Type, err)
+		}
+	}
+	return &Envelope{
+		ID:          wire.ID,
+		Type:        wire.Type,
+		Version:     wire.Version,
+		OccurredAt:  wire.OccurredAt,
+		TraceID:     wire.TraceID,
+		AggregateID: wire.AggregateID,
+		Payload:     payload.Interface(),
+	}, nil
+}
+
+// --- Protobuf ---
+
+// 信封的 protobuf 欄位編號
+const (
+	envFieldID          protowire.Number = 1
+	envFieldType        protowire.Number = 2
+	envFieldVersion     protowire.Number = 3
+	envFieldOccurredAt  protowire.Number = 4 // Unix 奈秒
+	envFieldTraceID     protowire.Number = 5
+	envFieldAggregateID protowire.Number = 6
+	envFieldPayload     protowire.Number = 7 // 內嵌訊息
+)
+
+type protoCodec struct {
+	registry *Registry
+}
+
+func (c *protoCodec) ContentType() string { return ContentTypeProtobuf }
+
+func (c *protoCodec) Marshal(env *Envelope) ([]byte, error) {
+	def, err := c.registry.definitionFor(env)
+	if err != nil {
+		return nil, err
+	}
+	v, err := def.payloadValue(env.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var b []byte
+	b = appendString(b, envFieldID, env.ID)
+	b = appendString(b, envFieldType, env.Type)
+	b = appendVarint(b, envFieldVersion, uint64(env.Version))
+	if !env.OccurredAt.IsZero() {
+		b = appendVarint(b, envFieldOccurredAt, uint64(env.OccurredAt.UnixNano()))
+	}
+	b = appendString(b, envFieldTraceID, env.TraceID)
+	b = appendString(b, envFieldAggregateID, env.AggregateID)
+
+	payload := marshalPayload(def, v)
+	b = protowire.AppendTag(b, envFieldPayload, protowire.BytesType)
+	b = protowire.AppendBytes(b, payload)
+	return b, nil
+}
+
+func (c *protoCodec) Unmarshal(data []byte) (*Envelope, error) {
+	env := &Envelope{}
+	var payload []byte
+	err := consumeFields(data, func(num protowire.Number, typ protowire.Type, raw []byte) error {
+		switch num {
+		case envFieldID:
+			return decodeString(typ, raw, &env.ID)
+		case envFieldType:
+			return decodeString(typ, raw, &env.Type)
+		case envFieldVersion:
+			n, err := decodeVarint(typ, raw)
+			env.Version = int(n)
+			return err
+		case envFieldOccurredAt:
+			n, err := decodeVarint(typ, raw)
+			env.OccurredAt = time.Unix(0, int64(n)).UTC()
+			return err
+		case envFieldTraceID:
+			return decodeString(typ, raw, &env.TraceID)
+		case envFieldAggregateID:
+			return decodeString(typ, raw, &env.AggregateID)
+		case envFieldPayload:
+			if typ != protowire.BytesType {
+				return errWireType
+			}
+			payload, _ = protowire.ConsumeBytes(raw)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("protobuf 解碼事件失敗: %w", err)
+	}
+
+	def, err := c.registry.Lookup(env.Type, env.Version)
+	if err != nil {
+		return nil, err
+	}
+	v := def.newPayload()
+	if err := unmarshalPayload(def, payload, v.Elem()); err != nil {
+		return nil, fmt.Errorf("protobuf 解碼事件 %s 內容失敗: %w", env.Type, err)
+	}
+	env.Payload = v.Interface()
+	return env, nil
+}
+
+var errWireType = errors.New("欄位的 wire type 不符")
+
+// marshalPayload 依結構描述編碼事件內容；與 proto3 相同，零值欄位不輸出
+func marshalPayload(def *Definition, v reflect.Value) []byte {
+	var b []byte
+	for _, f := range def.schema.Fields {
+		fv := v.Field(f.index)
+		if fv.IsZero() {
+			continue
+		}
+		num := protowire.Number(f.Number)
+		switch f.Type {
+		case FieldString:
+			b = appendString(b, num, fv.String())
+		case FieldBytes:
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendBytes(b, fv.Bytes())
+		case FieldBool:
+			b = appendVarint(b, num, protowire.EncodeBool(fv.Bool()))
+		case FieldInt64:
+			b = appendVarint(b, num, uint64(fv.Int()))
+		case FieldUint64:
+			b = appendVarint(b, num, fv.Uint())
+		case FieldDouble:
+			b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+			b = protowire.AppendFixed64(b, math.Float64bits(fv.Float()))
+		case FieldTimestamp:
+			b = appendVarint(b, num, uint64(fv.Interface().(time.Time).UnixNano()))
+		}
+	}
+	return b
+}
+
+// unmarshalPayload 依結構描述解碼事件內容，未知的欄位編號會被略過以保持向前相容
+func unmarshalPayload(def *Definition, data []byte, v reflect.Value) error {
+	byNumber := make(map[protowire.Number]Field, len(def.schema.Fields))
+	for _, f := range def.schema.Fields {
+		byNumber[protowire.Number(f.Number)] = f
+	}
+
+	return consumeFields(data, func(num protowire.Number, typ protowire.Type, raw []byte) error {
+		f, ok := byNumber[num]
+		if !ok {
+			return nil
+		}
+		fv := v.Field(f.index)
+		switch f.Type {
+		case FieldString:
+			var s string
+			if err := decodeString(typ, raw, &s); err != nil {
+				return err
+			}
+			fv.SetString(s)
+		case FieldBytes:
+			if typ != protowire.BytesType {
+				return errWireType
+			}
+			bs, _ := protowire.ConsumeBytes(raw)
+			fv.SetBytes(append([]byte(nil), bs...))
+		case FieldBool:
+			n, err := decodeVarint(typ, raw)
+			if err != nil {
+				return err
+			}
+			fv.SetBool(protowire.DecodeBool(n))
+		case FieldInt64:
+			n, err := decodeVarint(typ, raw)
+			if err != nil {
+				return err
+			}
+			fv.SetInt(int64(n))
+		case FieldUint64:
+			n, err := decodeVarint(typ, raw)
+			if err != nil {
+				return err
+			}
+			fv.SetUint(n)
+		case FieldDouble:
+			if typ != protowire.Fixed64Type {
+				return errWireType
+			}
+			n, _ := protowire.ConsumeFixed64(raw)
+			fv.SetFloat(math.Float64frombits(n))
+		case FieldTimestamp:
+			n, err := decodeVarint(typ, raw)
+			if err != nil {
+				return err
+			}
+			fv.Set(reflect.ValueOf(time.Unix(0, int64(n)).UTC()))
+		}
+		return nil
+	})
+}
+
+// consumeFields 逐一讀取 protobuf 欄位，raw 為該欄位不含 tag 的原始內容
+func consumeFields(data []byte, fn func(num protowire.Number, typ protowire.Type, raw []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		m := protowire.ConsumeFieldValue(num, typ, data)
+		if m < 0 {
+			return protowire.ParseError(m)
+		}
+		if err := fn(num, typ, data[:m]); err != nil {
+			return fmt.Errorf("欄位 %d: %w", num, err)
+		}
+		data = data[m:]
+	}
+	return nil
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func decodeString(typ protowire.Type, raw []byte, dst *string) error {
+	if typ != protowire.BytesType {
+		return errWireType
+	}
+	s, _ := protowire.ConsumeString(raw)
+	*dst = s
+	return nil
+}
+
+func decodeVarint(typ protowire.Type, raw []byte) (uint64, error) {
+	if typ != protowire.VarintType {
+		return 0, errWireType
+	}
+	n, _ := protowire.ConsumeVarint(raw)
+	return n, nil
+}
@@ -0,0 +1,79 @@
+// Package events 定義 Producer 與 Consumer 共用的領域事件契約：
+// 事件信封 (Envelope)、事件類型目錄 (Registry)、JSON 與 protobuf 編解碼器，以及結構相容性檢查。
+package events
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+
+	"microservice-mvp/pkg/logger"
+)
+
+// Envelope 是所有領域事件共用的信封
+type Envelope struct {
+	ID          string    `json:"event_id"`
+	Type        string    `json:"type"`
+	Version     int       `json:"version"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	TraceID     string    `json:"trace_id,omitempty"`
+	AggregateID string    `json:"aggregate_id"` // 事件所屬聚合的 ID，同時作為訊息的 sharding key
+	Payload     any       `json:"payload"`      // 已註冊的事件內容，解碼後為指向該型別的指標
+}
+
+// New 以預設目錄建立事件信封
+func New(ctx context.Context, aggregateID string, payload any) (*Envelope, error) {
+	return Default.New(ctx, aggregateID, payload)
+}
+
+// New 建立事件信封，事件類型與版本由 payload 的 Go 型別決定，trace ID 取自上下文
+func (r *Registry) New(ctx context.Context, aggregateID string, payload any) (*Envelope, error) {
+	def, err := r.definitionOf(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := def.validate(payload); err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		ID:          uuid.New().String(),
+		Type:        def.Type,
+		Version:     def.Version,
+		OccurredAt:  time.Now().UTC(),
+		TraceID:     logger.TraceIDFromContext(ctx),
+		AggregateID: aggregateID,
+		Payload:     payload,
+	}, nil
+}
+
+// payloadValue 回傳事件內容的 struct 值，並確認其型別與定義相符
+func (d *Definition) payloadValue(payload any) (reflect.Value, error) {
+	v := reflect.ValueOf(payload)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, fmt.Errorf("事件 %s 的內容為 nil", d.Type)
+		}
+		v = v.Elem()
+	}
+	if v.Type() != d.payloadType {
+		return reflect.Value{}, fmt.Errorf("事件 %s v%d 的內容型別應為 %s，實際為 %s", d.Type, d.Version, d.payloadType, v.Type())
+	}
+	return v, nil
+}
+
+// validate 檢查必填欄位皆已設定 (非零值)
+func (d *Definition) validate(payload any) error {
+	v, err := d.payloadValue(payload)
+	if err != nil {
+		return err
+	}
+	for _, f := range d.schema.Fields {
+		if f.Required && v.Field(f.index).IsZero() {
+			return fmt.Errorf("事件 %s 缺少必填欄位 %s", d.Type, f.Name)
+		}
+	}
+	return nil
+}
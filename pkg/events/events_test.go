@@ -0,0 +1,130 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/pkg/events"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	ctx := logger.WithTraceID(context.Background(), "trace-xyz")
+	settledAt := time.Date(2024, 5, 1, 12, 0, 0, 123, time.UTC)
+
+	payloads := []struct {
+		name        string
+		aggregateID string
+		payload     any
+	}{
+		{name: "PlayerRegistered", aggregateID: "1", payload: &events.PlayerRegistered{PlayerID: 1, Username: "alice", RegisteredAt: settledAt}},
+		{name: "PlayerLoggedIn", aggregateID: "1", payload: &events.PlayerLoggedIn{PlayerID: 1, LoginAt: settledAt, IP: "10.0.0.1"}},
+		{name: "WalletDebited", aggregateID: "1", payload: &events.WalletDebited{PlayerID: 1, Amount: 12.5, BalanceAfter: -0.75, Reason: "bet", ReferenceID: "bet-9"}},
+		{name: "BetSettled", aggregateID: "bet-9", payload: &events.BetSettled{BetID: "bet-9", PlayerID: 1, GameID: "slots", Stake: 10, Payout: 25.5, Outcome: "win", SettledAt: settledAt}},
+	}
+	codecs := []events.Codec{events.Default.JSONCodec(), events.Default.ProtoCodec()}
+
+	for _, codec := range codecs {
+		for _, tt := range payloads {
+			t.Run(codec.ContentType()+"/"+tt.name, func(t *testing.T) {
+				env, err := events.New(ctx, tt.aggregateID, tt.payload)
+				require.NoError(t, err)
+				assert.NotEmpty(t, env.ID)
+				assert.Equal(t, 1, env.Version)
+				assert.Equal(t, "trace-xyz", env.TraceID)
+
+				data, err := codec.Marshal(env)
+				require.NoError(t, err)
+				got, err := codec.Unmarshal(data)
+				require.NoError(t, err)
+
+				assert.Equal(t, env.ID, got.ID)
+				assert.Equal(t, env.Type, got.Type)
+				assert.Equal(t, env.Version, got.Version)
+				assert.True(t, env.OccurredAt.Equal(got.OccurredAt))
+				assert.Equal(t, env.TraceID, got.TraceID)
+				assert.Equal(t, env.AggregateID, got.AggregateID)
+				assert.Equal(t, tt.payload, got.Payload)
+			})
+		}
+	}
+}
+
+func TestRegistry_NewValidation(t *testing.T) {
+	_, err := events.New(context.Background(), "1", events.PlayerRegistered{Username: "alice"})
+	assert.ErrorContains(t, err, "缺少必填欄位 player_id")
+
+	type unregistered struct {
+		ID int `json:"id" proto:"1"`
+	}
+	_, err = events.New(context.Background(), "1", unregistered{ID: 1})
+	assert.ErrorIs(t, err, events.ErrUnregisteredPayload)
+}
+
+// playerRegisteredV2 模擬 Producer 升級到相容的新版本：新增選填欄位
+type playerRegisteredV2 struct {
+	PlayerID     uint      `json:"player_id" proto:"1,required"`
+	Username     string    `json:"username" proto:"2,required"`
+	RegisteredAt time.Time `json:"registered_at" proto:"3"`
+	Referrer     string    `json:"referrer" proto:"4"`
+}
+
+func TestRegistry_NewerVersionDecodesWithLatestKnown(t *testing.T) {
+	producer := events.NewRegistry()
+	producer.MustRegister(
+		events.Definition{Type: events.TypePlayerRegistered, Version: 1, Topic: events.TopicPlayerEvents, Payload: events.PlayerRegistered{}},
+		events.Definition{Type: events.TypePlayerRegistered, Version: 2, Topic: events.TopicPlayerEvents, Payload: playerRegisteredV2{}},
+	)
+
+	for _, contentType := range []string{events.ContentTypeJSON, events.ContentTypeProtobuf} {
+		t.Run(contentType, func(t *testing.T) {
+			env, err := producer.New(context.Background(), "7", playerRegisteredV2{PlayerID: 7, Username: "bob", Referrer: "alice"})
+			require.NoError(t, err)
+			assert.Equal(t, 2, env.Version)
+
+			encoder, err := producer.Codec(contentType)
+			require.NoError(t, err)
+			data, err := encoder.Marshal(env)
+			require.NoError(t, err)
+
+			// 只認識 v1 的 Consumer 仍能解碼，新增的欄位被忽略
+			decoder, err := events.Default.Codec(contentType)
+			require.NoError(t, err)
+			got, err := decoder.Unmarshal(data)
+			require.NoError(t, err)
+			assert.Equal(t, 2, got.Version)
+			assert.Equal(t, &events.PlayerRegistered{PlayerID: 7, Username: "bob"}, got.Payload)
+		})
+	}
+}
+
+func TestRegistry_UnknownType(t *testing.T) {
+	_, err := events.Default.JSONCodec().Unmarshal([]byte(`{"type":"player.deleted","version":1,"payload":{}}`))
+	assert.ErrorIs(t, err, events.ErrUnknownEventType)
+}
+
+func TestRegistry_MessageRoundTrip(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	env, err := events.New(logger.WithTraceID(context.Background(), "trace-1"), "bet-1",
+		events.BetSettled{BetID: "bet-1", PlayerID: 3, Outcome: "lose"})
+	require.NoError(t, err)
+
+	msg, err := events.Default.NewMessage(env, events.Default.ProtoCodec())
+	require.NoError(t, err)
+	assert.Equal(t, events.TopicBetEvents, msg.Topic)
+	assert.Equal(t, events.TypeBetSettled, msg.Tag)
+	assert.Equal(t, "bet-1", msg.ShardingKey)
+	assert.Equal(t, "trace-1", msg.Property(messaging.PropertyTraceID))
+	assert.Equal(t, "1", msg.Property(events.PropertyEventVersion))
+
+	got, err := events.Default.DecodeMessage(&messaging.Delivery{Message: *msg})
+	require.NoError(t, err)
+	assert.Equal(t, env.ID, got.ID)
+	assert.Equal(t, "lose", got.Payload.(*events.BetSettled).Outcome)
+}
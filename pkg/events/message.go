@@ -0,0 +1,60 @@
+package events
+
+import (
+	"strconv"
+
+	"microservice-mvp/pkg/messaging"
+)
+
+// 事件訊息的自訂屬性，讓 Consumer 不必解碼內容即可路由或過濾
+const (
+	PropertyEventType    = "EVENT_TYPE"
+	PropertyEventVersion = "EVENT_VERSION"
+)
+
+// NewMessage 將事件編碼為訊息
+// Topic 取自事件定義，標籤為事件類型，AggregateID 同時作為索引鍵與 sharding key 以保證同一聚合的順序
+func (r *Registry) NewMessage(env *Envelope, codec Codec) (*messaging.Message, error) {
+	def, err := r.Lookup(env.Type, env.Version)
+	if err != nil {
+		return nil, err
+	}
+	body, err := codec.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{env.ID}
+	if env.AggregateID != "" {
+		keys = append(keys, env.AggregateID)
+	}
+	msg := messaging.NewMessage(def.Topic, env.Type, body, keys)
+	if env.AggregateID != "" {
+		msg.WithShardingKey(env.AggregateID)
+	}
+	msg.WithProperty(PropertyEventType, env.Type)
+	msg.WithProperty(PropertyEventVersion, strconv.Itoa(env.Version))
+	msg.WithProperty(messaging.PropertyContentType, codec.ContentType())
+	if env.TraceID != "" {
+		msg.WithProperty(messaging.PropertyTraceID, env.TraceID)
+	}
+	return msg, nil
+}
+
+// DecodeMessage 依訊息的 CONTENT_TYPE 屬性選擇 Codec 並解碼事件，未設定時視為 JSON
+func (r *Registry) DecodeMessage(msg *messaging.Delivery) (*Envelope, error) {
+	codec, err := r.Codec(msg.Property(messaging.PropertyContentType))
+	if err != nil {
+		return nil, err
+	}
+	return codec.Unmarshal(msg.Body)
+}
+
+// Topic 回傳事件類型 (最新版本) 發送的 Topic
+func (r *Registry) Topic(eventType string) (string, error) {
+	def, err := r.Lookup(eventType, 0)
+	if err != nil {
+		return "", err
+	}
+	return def.Topic, nil
+}
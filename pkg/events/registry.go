@@ -0,0 +1,162 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrUnknownEventType 代表事件類型未註冊
+	ErrUnknownEventType = errors.New("未註冊的事件類型")
+	// ErrUnregisteredPayload 代表事件內容的 Go 型別未註冊
+	ErrUnregisteredPayload = errors.New("未註冊的事件內容型別")
+)
+
+// Definition 定義一個事件類型的某個版本
+type Definition struct {
+	Type    string // 事件類型，例如 player.registered，同時作為訊息標籤
+	Version int    // 結構版本，從 1 開始；同一類型的新版本必須與前一版相容
+	Topic   string // 發送的 Topic
+	Payload any    // 事件內容的零值，例如 PlayerRegistered{}
+
+	payloadType reflect.Type
+	schema      Schema
+}
+
+// Schema 回傳此定義的結構描述
+func (d *Definition) Schema() Schema {
+	return d.schema
+}
+
+// newPayload 建立一個事件內容的指標，供解碼使用
+func (d *Definition) newPayload() reflect.Value {
+	return reflect.New(d.payloadType)
+}
+
+// Registry 保存所有事件類型的定義
+type Registry struct {
+	mu        sync.RWMutex
+	byType    map[string][]*Definition // 依版本遞增排序
+	byPayload map[reflect.Type]*Definition
+}
+
+// NewRegistry 建立一個空的 Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		byType:    make(map[string][]*Definition),
+		byPayload: make(map[reflect.Type]*Definition),
+	}
+}
+
+// Register 註冊一個事件定義
+// 若同一類型已有其他版本，新版本必須與相鄰版本相容，否則回傳 ErrIncompatibleSchema
+func (r *Registry) Register(def Definition) error {
+	if def.Type == "" || def.Topic == "" || def.Version <= 0 {
+		return fmt.Errorf("事件定義必須包含類型、Topic 與正整數版本: %+v", def)
+	}
+	t := reflect.TypeOf(def.Payload)
+	if t == nil {
+		return fmt.Errorf("事件 %s v%d 缺少內容型別", def.Type, def.Version)
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fields, err := schemaFields(t)
+	if err != nil {
+		return fmt.Errorf("事件 %s v%d: %w", def.Type, def.Version, err)
+	}
+	def.payloadType = t
+	def.schema = Schema{Type: def.Type, Version: def.Version, Topic: def.Topic, Fields: fields}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.byPayload[t]; ok {
+		return fmt.Errorf("內容型別 %s 已註冊為 %s v%d", t, existing.Type, existing.Version)
+	}
+
+	versions := r.byType[def.Type]
+	for _, v := range versions {
+		if v.Version == def.Version {
+			return fmt.Errorf("事件 %s v%d 已註冊", def.Type, def.Version)
+		}
+	}
+	versions = append(versions, &def)
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	for i := 1; i < len(versions); i++ {
+		if err := CheckCompatibility(versions[i-1].schema, versions[i].schema); err != nil {
+			return err
+		}
+	}
+
+	r.byType[def.Type] = versions
+	r.byPayload[t] = &def
+	return nil
+}
+
+// MustRegister 與 Register 相同，但失敗時 panic，適合在 init 中註冊事件目錄
+func (r *Registry) MustRegister(defs ...Definition) {
+	for _, def := range defs {
+		if err := r.Register(def); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Lookup 取得指定類型與版本的定義
+// 若該版本未註冊 (例如較新的 Producer 發送了新版本)，回退到已註冊的最新版本；
+// 由於相鄰版本保證相容，舊版本的定義可以安全地解碼新版本的內容 (未知欄位會被忽略)
+func (r *Registry) Lookup(eventType string, version int) (*Definition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := r.byType[eventType]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+	for _, d := range versions {
+		if d.Version == version {
+			return d, nil
+		}
+	}
+	return versions[len(versions)-1], nil
+}
+
+// definitionOf 取得事件內容 Go 型別對應的定義
+func (r *Registry) definitionOf(payload any) (*Definition, error) {
+	t := reflect.TypeOf(payload)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	def, ok := r.byPayload[t]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnregisteredPayload, t)
+	}
+	return def, nil
+}
+
+// Schemas 回傳所有已註冊的結構，依類型與版本排序
+func (r *Registry) Schemas() []Schema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var schemas []Schema
+	for _, versions := range r.byType {
+		for _, d := range versions {
+			schemas = append(schemas, d.schema)
+		}
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].Type != schemas[j].Type {
+			return schemas[i].Type < schemas[j].Type
+		}
+		return schemas[i].Version < schemas[j].Version
+	})
+	return schemas
+}
@@ -0,0 +1,175 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 事件欄位支援的型別，同時決定 protobuf 的編碼方式
+const (
+	FieldString    = "string"
+	FieldBool      = "bool"
+	FieldInt64     = "int64"
+	FieldUint64    = "uint64"
+	FieldDouble    = "double"
+	FieldBytes     = "bytes"
+	FieldTimestamp = "timestamp" // time.Time，protobuf 以 Unix 奈秒 (int64) 編碼
+)
+
+// ErrIncompatibleSchema 代表新版本的事件結構與舊版本不相容
+var ErrIncompatibleSchema = errors.New("事件結構不相容")
+
+// Field 描述事件內容中的一個欄位
+type Field struct {
+	Name     string `json:"name"`   // JSON 欄位名稱
+	Number   int    `json:"number"` // protobuf 欄位編號
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+
+	index int // 對應 Go struct 的欄位索引，僅供編解碼使用
+}
+
+// Schema 描述某個事件類型與版本的結構，用於相容性檢查與快照比對
+type Schema struct {
+	Type    string  `json:"type"`
+	Version int     `json:"version"`
+	Topic   string  `json:"topic"`
+	Fields  []Field `json:"fields"`
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// schemaFields 由事件內容的 struct 標籤推導欄位
+// 每個匯出欄位都必須有 json 名稱與 proto 欄位編號，例如 `json:"player_id" proto:"1,required"`
+func schemaFields(t reflect.Type) ([]Field, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("事件內容必須是 struct，實際為 %s", t)
+	}
+
+	var fields []Field
+	names := make(map[string]bool)
+	numbers := make(map[int]bool)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			return nil, fmt.Errorf("%s.%s 缺少 json 欄位名稱", t.Name(), sf.Name)
+		}
+		protoTag := strings.Split(sf.Tag.Get("proto"), ",")
+		number, err := strconv.Atoi(protoTag[0])
+		if err != nil || number <= 0 {
+			return nil, fmt.Errorf("%s.%s 缺少有效的 proto 欄位編號", t.Name(), sf.Name)
+		}
+		typ, err := fieldType(sf.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), sf.Name, err)
+		}
+		if names[name] || numbers[number] {
+			return nil, fmt.Errorf("%s.%s 的欄位名稱或編號重複", t.Name(), sf.Name)
+		}
+		names[name], numbers[number] = true, true
+
+		fields = append(fields, Field{
+			Name:     name,
+			Number:   number,
+			Type:     typ,
+			Required: len(protoTag) > 1 && protoTag[1] == "required",
+			index:    i,
+		})
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Number < fields[j].Number })
+	return fields, nil
+}
+
+func fieldType(t reflect.Type) (string, error) {
+	switch {
+	case t == timeType:
+		return FieldTimestamp, nil
+	case t == bytesType:
+		return FieldBytes, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return FieldString, nil
+	case reflect.Bool:
+		return FieldBool, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return FieldInt64, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return FieldUint64, nil
+	case reflect.Float32, reflect.Float64:
+		return FieldDouble, nil
+	default:
+		return "", fmt.Errorf("不支援的欄位型別 %s", t)
+	}
+}
+
+// CheckCompatibility 檢查 next 是否可以安全取代 prev (同一事件類型的較新版本)
+// 只允許新增選填欄位；移除欄位、變更型別或編號、重用編號、新增必填欄位與變更 Topic 都視為破壞性變更。
+// 所有違規會一併列出，並可被 errors.Is(err, ErrIncompatibleSchema) 判斷。
+func CheckCompatibility(prev, next Schema) error {
+	var problems []string
+	if prev.Type != next.Type {
+		problems = append(problems, fmt.Sprintf("事件類型由 %s 變更為 %s", prev.Type, next.Type))
+	}
+	if next.Version < prev.Version {
+		problems = append(problems, fmt.Sprintf("版本由 %d 倒退為 %d", prev.Version, next.Version))
+	}
+	if prev.Topic != next.Topic {
+		problems = append(problems, fmt.Sprintf("Topic 由 %s 變更為 %s", prev.Topic, next.Topic))
+	}
+
+	prevByName := make(map[string]Field, len(prev.Fields))
+	prevByNumber := make(map[int]Field, len(prev.Fields))
+	for _, f := range prev.Fields {
+		prevByName[f.Name] = f
+		prevByNumber[f.Number] = f
+	}
+	nextByName := make(map[string]Field, len(next.Fields))
+	for _, f := range next.Fields {
+		nextByName[f.Name] = f
+	}
+
+	for _, old := range prev.Fields {
+		f, ok := nextByName[old.Name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("移除了欄位 %s", old.Name))
+		case f.Number != old.Number:
+			problems = append(problems, fmt.Sprintf("欄位 %s 的編號由 %d 變更為 %d", old.Name, old.Number, f.Number))
+		case f.Type != old.Type:
+			problems = append(problems, fmt.Sprintf("欄位 %s 的型別由 %s 變更為 %s", old.Name, old.Type, f.Type))
+		case f.Required && !old.Required:
+			problems = append(problems, fmt.Sprintf("欄位 %s 由選填變更為必填", old.Name))
+		}
+	}
+	for _, f := range next.Fields {
+		if _, existed := prevByName[f.Name]; existed {
+			continue
+		}
+		if old, reused := prevByNumber[f.Number]; reused {
+			problems = append(problems, fmt.Sprintf("新欄位 %s 重用了欄位 %s 的編號 %d", f.Name, old.Name, f.Number))
+		}
+		if f.Required {
+			problems = append(problems, fmt.Sprintf("新增了必填欄位 %s", f.Name))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w (%s v%d -> v%d): %s", ErrIncompatibleSchema, next.Type, prev.Version, next.Version, strings.Join(problems, "; "))
+}
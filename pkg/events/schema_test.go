@@ -0,0 +1,108 @@
+package events_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/pkg/events"
+)
+
+var update = flag.Bool("update", false, "以目前的事件目錄覆寫 testdata/schemas.json")
+
+func TestCheckCompatibility(t *testing.T) {
+	base := events.Schema{
+		Type: "player.registered", Version: 1, Topic: "player_events",
+		Fields: []events.Field{
+			{Name: "player_id", Number: 1, Type: events.FieldUint64, Required: true},
+			{Name: "username", Number: 2, Type: events.FieldString},
+		},
+	}
+	next := func(mutate func(s *events.Schema)) events.Schema {
+		s := base
+		s.Version = 2
+		s.Fields = append([]events.Field(nil), base.Fields...)
+		mutate(&s)
+		return s
+	}
+
+	tests := []struct {
+		name          string
+		next          events.Schema
+		expectedError string
+	}{
+		{name: "Unchanged", next: next(func(s *events.Schema) {})},
+		{name: "AddOptionalField", next: next(func(s *events.Schema) {
+			s.Fields = append(s.Fields, events.Field{Name: "referrer", Number: 3, Type: events.FieldString})
+		})},
+		{name: "RelaxRequired", next: next(func(s *events.Schema) { s.Fields[0].Required = false })},
+		{name: "RemoveField", next: next(func(s *events.Schema) { s.Fields = s.Fields[:1] }), expectedError: "移除了欄位 username"},
+		{name: "ChangeType", next: next(func(s *events.Schema) { s.Fields[1].Type = events.FieldInt64 }), expectedError: "型別由 string 變更為 int64"},
+		{name: "ChangeNumber", next: next(func(s *events.Schema) { s.Fields[1].Number = 5 }), expectedError: "編號由 2 變更為 5"},
+		{name: "ReuseNumber", next: next(func(s *events.Schema) {
+			s.Fields[1] = events.Field{Name: "nickname", Number: 2, Type: events.FieldString}
+		}), expectedError: "重用了欄位 username 的編號 2"},
+		{name: "AddRequiredField", next: next(func(s *events.Schema) {
+			s.Fields = append(s.Fields, events.Field{Name: "email", Number: 3, Type: events.FieldString, Required: true})
+		}), expectedError: "新增了必填欄位 email"},
+		{name: "MakeOptionalRequired", next: next(func(s *events.Schema) { s.Fields[1].Required = true }), expectedError: "由選填變更為必填"},
+		{name: "ChangeTopic", next: next(func(s *events.Schema) { s.Topic = "users" }), expectedError: "Topic 由 player_events 變更為 users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := events.CheckCompatibility(base, tt.next)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, events.ErrIncompatibleSchema)
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestRegistry_RejectsIncompatibleVersion(t *testing.T) {
+	type v2 struct {
+		PlayerID string `json:"player_id" proto:"1,required"` // 型別由 uint 改為 string
+	}
+	reg := events.NewRegistry()
+	require.NoError(t, reg.Register(events.Definition{Type: events.TypePlayerRegistered, Version: 1, Topic: events.TopicPlayerEvents, Payload: events.PlayerRegistered{}}))
+	err := reg.Register(events.Definition{Type: events.TypePlayerRegistered, Version: 2, Topic: events.TopicPlayerEvents, Payload: v2{}})
+	assert.ErrorIs(t, err, events.ErrIncompatibleSchema)
+}
+
+// TestCatalogSchemas 以快照阻擋對已發布事件的破壞性變更
+// 相容的變更 (新增選填欄位或新版本) 請執行 go test ./pkg/events -run TestCatalogSchemas -update 更新快照
+func TestCatalogSchemas(t *testing.T) {
+	path := filepath.Join("testdata", "schemas.json")
+	current := events.Default.Schemas()
+
+	if *update {
+		data, err := json.MarshalIndent(current, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, append(data, '\n'), 0o644))
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var published []events.Schema
+	require.NoError(t, json.Unmarshal(data, &published))
+
+	latest := make(map[string]events.Schema)
+	for _, s := range current {
+		latest[s.Type] = s
+	}
+	for _, old := range published {
+		now, ok := latest[old.Type]
+		if !assert.True(t, ok, "已發布的事件 %s 不可從目錄移除", old.Type) {
+			continue
+		}
+		assert.NoError(t, events.CheckCompatibility(old, now))
+	}
+}
@@ -0,0 +1,312 @@
+// Package health 管理依賴組件的健康檢查：組件以 HealthChecker 向 Registry 註冊，
+// Registry 於背景依間隔執行檢查並快取結果，健康檢查端點只讀取快取，不會因大量請求而對依賴造成負載。
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+// Status 是健康檢查的狀態
+type Status string
+
+const (
+	StatusUp       Status = "UP"
+	StatusDegraded Status = "DEGRADED" // 可用但延遲偏高，或非關鍵組件異常
+	StatusDown     Status = "DOWN"
+	StatusUnknown  Status = "UNKNOWN" // 尚未完成第一次檢查
+)
+
+// Result 是單次檢查的結果
+type Result struct {
+	Status  Status
+	Message string
+	Details string // 額外資訊 (例如: 連線池統計)
+}
+
+// HealthChecker 由組件實作並向 Registry 註冊
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) Result
+}
+
+// Options 是檢查的註冊選項
+type Options struct {
+	// Critical 代表組件異常時服務無法運作 (整體狀態為 DOWN、/readyz 失敗)；非關鍵組件異常時整體狀態為 DEGRADED
+	Critical bool
+	// Interval 是檢查間隔，0 使用 health_check.interval_seconds
+	Interval time.Duration
+}
+
+// CheckResult 是快取的檢查結果
+type CheckResult struct {
+	Name      string
+	Critical  bool
+	Status    Status
+	Latency   time.Duration
+	Message   string
+	Details   string
+	CheckedAt time.Time
+}
+
+// Report 是所有檢查的快取結果與整體狀態
+type Report struct {
+	Status Status
+	Checks []CheckResult // 依名稱排序
+}
+
+type checkerFunc struct {
+	name string
+	fn   func(ctx context.Context) Result
+}
+
+func (c checkerFunc) Name() string                     { return c.name }
+func (c checkerFunc) Check(ctx context.Context) Result { return c.fn(ctx) }
+
+// NewChecker 以函式建立 HealthChecker
+func NewChecker(name string, fn func(ctx context.Context) Result) HealthChecker {
+	return checkerFunc{name: name, fn: fn}
+}
+
+// PingChecker 以 ping 函式建立 HealthChecker，回傳錯誤時為 DOWN
+func PingChecker(name string, ping func(ctx context.Context) error) HealthChecker {
+	return NewChecker(name, func(ctx context.Context) Result {
+		if err := ping(ctx); err != nil {
+			return Result{Status: StatusDown, Message: "Ping 失敗: " + err.Error()}
+		}
+		return Result{Status: StatusUp}
+	})
+}
+
+type entry struct {
+	checker HealthChecker
+	opts    Options
+
+	mu     sync.RWMutex
+	result CheckResult
+}
+
+func (e *entry) load() CheckResult {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.result
+}
+
+// Registry 保存已註冊的檢查並於背景輪詢
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*entry
+	cfg     configs.HealthCheckConfig
+	running bool
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewRegistry 建立一個新的 Registry
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Register 註冊檢查，同名的檢查會被取代；Registry 已啟動時立即開始輪詢
+// health_check.critical 可覆寫組件宣告的 Critical
+func (r *Registry) Register(c HealthChecker, opts Options) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if critical, ok := r.cfg.Critical[c.Name()]; ok {
+		opts.Critical = critical
+	}
+	e := &entry{checker: c, opts: opts}
+	e.result = CheckResult{Name: c.Name(), Critical: opts.Critical, Status: StatusUnknown}
+	r.entries[c.Name()] = e
+	if r.running {
+		r.startEntry(e)
+	}
+}
+
+// Start 套用設定，先同步執行一輪檢查後於背景依間隔輪詢
+func (r *Registry) Start(cfg configs.HealthCheckConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return
+	}
+	r.cfg = cfg
+	r.running = true
+	r.stop = make(chan struct{})
+
+	var initial sync.WaitGroup
+	for name, e := range r.entries {
+		if critical, ok := cfg.Critical[name]; ok {
+			e.opts.Critical = critical
+		}
+		initial.Add(1)
+		go func(e *entry) {
+			defer initial.Done()
+			r.run(e)
+		}(e)
+	}
+	initial.Wait()
+
+	for _, e := range r.entries {
+		r.startEntry(e)
+	}
+}
+
+// Stop 停止背景輪詢
+func (r *Registry) Stop() {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = false
+	close(r.stop)
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// Report 回傳所有檢查的快取結果與整體狀態
+// 任一關鍵檢查 DOWN 時為 DOWN；非關鍵檢查異常或任一檢查 DEGRADED 時為 DEGRADED
+func (r *Registry) Report() Report {
+	r.mu.RLock()
+	report := Report{Status: StatusUp, Checks: make([]CheckResult, 0, len(r.entries))}
+	for _, e := range r.entries {
+		report.Checks = append(report.Checks, e.load())
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(report.Checks, func(i, j int) bool { return report.Checks[i].Name < report.Checks[j].Name })
+	for _, c := range report.Checks {
+		report.Status = worse(report.Status, effectiveStatus(c))
+	}
+	return report
+}
+
+// effectiveStatus 回傳檢查對整體狀態的影響，非關鍵檢查最多使整體降級
+func effectiveStatus(c CheckResult) Status {
+	switch {
+	case c.Status == StatusUp:
+		return StatusUp
+	case c.Critical && c.Status != StatusDegraded:
+		return StatusDown // 關鍵檢查 DOWN 或尚未完成第一次檢查
+	default:
+		return StatusDegraded
+	}
+}
+
+func worse(a, b Status) Status {
+	rank := map[Status]int{StatusUp: 0, StatusDegraded: 1, StatusDown: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// startEntry 啟動單一檢查的輪詢，呼叫端須持有 r.mu
+func (r *Registry) startEntry(e *entry) {
+	interval := e.opts.Interval
+	if interval <= 0 {
+		interval = time.Duration(r.cfg.IntervalSeconds) * time.Second
+	}
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	stop := r.stop
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				r.run(e)
+			}
+		}
+	}()
+}
+
+// run 執行一次檢查並更新快取，狀態改變時記錄日誌
+func (r *Registry) run(e *entry) {
+	timeout := time.Duration(r.cfg.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	res := e.checker.Check(ctx)
+	latency := time.Since(start)
+
+	threshold := time.Duration(r.cfg.LatencyThreshold) * time.Millisecond
+	if res.Status == StatusUp && threshold > 0 && latency > threshold {
+		res.Status = StatusDegraded
+		res.Message = "高延遲: " + latency.String() + " > " + threshold.String()
+	}
+
+	e.mu.Lock()
+	prev := e.result
+	e.result = CheckResult{
+		Name:      e.checker.Name(),
+		Critical:  e.opts.Critical,
+		Status:    res.Status,
+		Latency:   latency,
+		Message:   res.Message,
+		Details:   res.Details,
+		CheckedAt: time.Now(),
+	}
+	e.mu.Unlock()
+
+	// 第一次檢查正常時不記錄
+	if prev.Status != res.Status && !(prev.Status == StatusUnknown && res.Status == StatusUp) {
+		logStatusChange(prev, e.result)
+	}
+}
+
+func logStatusChange(prev, cur CheckResult) {
+	log := logger.Named("health")
+	fields := []zap.Field{
+		zap.String("check", cur.Name),
+		zap.Bool("critical", cur.Critical),
+		zap.String("from", string(prev.Status)),
+		zap.String("to", string(cur.Status)),
+		zap.Duration("latency", cur.Latency),
+	}
+	if cur.Message != "" {
+		fields = append(fields, zap.String("message", cur.Message))
+	}
+
+	switch {
+	case cur.Status == StatusUp:
+		log.Info("健康檢查恢復", fields...)
+	case cur.Status == StatusDown && cur.Critical:
+		log.Error("健康檢查狀態改變", fields...)
+	default:
+		log.Warn("健康檢查狀態改變", fields...)
+	}
+}
+
+// defaultRegistry 是組件自行註冊使用的全域 Registry
+var defaultRegistry = NewRegistry()
+
+// Default 回傳全域 Registry
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register 向全域 Registry 註冊檢查
+func Register(c HealthChecker, opts Options) {
+	defaultRegistry.Register(c, opts)
+}
@@ -0,0 +1,102 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/health"
+	"microservice-mvp/pkg/logger"
+)
+
+func fixed(status health.Status) func(ctx context.Context) health.Result {
+	return func(ctx context.Context) health.Result { return health.Result{Status: status} }
+}
+
+func TestRegistry_Report(t *testing.T) {
+	_, err := logger.NewLogger("info", "console")
+	require.NoError(t, err)
+
+	type check struct {
+		name     string
+		critical bool
+		fn       func(ctx context.Context) health.Result
+	}
+	slow := func(ctx context.Context) health.Result {
+		time.Sleep(30 * time.Millisecond)
+		return health.Result{Status: health.StatusUp}
+	}
+
+	tests := []struct {
+		name   string
+		cfg    configs.HealthCheckConfig
+		checks []check
+		want   health.Status
+	}{
+		{
+			name:   "全部正常",
+			checks: []check{{"tidb", true, fixed(health.StatusUp)}, {"redis", false, fixed(health.StatusUp)}},
+			want:   health.StatusUp,
+		},
+		{
+			name:   "非關鍵組件異常時降級",
+			checks: []check{{"tidb", true, fixed(health.StatusUp)}, {"redis", false, fixed(health.StatusDown)}},
+			want:   health.StatusDegraded,
+		},
+		{
+			name:   "關鍵組件異常",
+			checks: []check{{"tidb", true, fixed(health.StatusDown)}, {"redis", false, fixed(health.StatusUp)}},
+			want:   health.StatusDown,
+		},
+		{
+			name:   "以配置覆寫關鍵性",
+			cfg:    configs.HealthCheckConfig{Critical: map[string]bool{"redis": true}},
+			checks: []check{{"tidb", true, fixed(health.StatusUp)}, {"redis", false, fixed(health.StatusDown)}},
+			want:   health.StatusDown,
+		},
+		{
+			name:   "高延遲時降級",
+			cfg:    configs.HealthCheckConfig{LatencyThreshold: 10},
+			checks: []check{{"tidb", true, slow}},
+			want:   health.StatusDegraded,
+		},
+		{
+			name:   "檢查逾時",
+			cfg:    configs.HealthCheckConfig{TimeoutMs: 10},
+			checks: []check{{"tidb", true, health.PingChecker("tidb", func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }).Check}},
+			want:   health.StatusDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := health.NewRegistry()
+			for _, c := range tt.checks {
+				r.Register(health.NewChecker(c.name, c.fn), health.Options{Critical: c.critical})
+			}
+			r.Start(tt.cfg)
+			defer r.Stop()
+
+			report := r.Report()
+			assert.Equal(t, tt.want, report.Status)
+			require.Len(t, report.Checks, len(tt.checks))
+			for _, c := range report.Checks {
+				assert.False(t, c.CheckedAt.IsZero(), c.Name)
+			}
+		})
+	}
+}
+
+func TestRegistry_NotStarted(t *testing.T) {
+	r := health.NewRegistry()
+	r.Register(health.NewChecker("tidb", fixed(health.StatusUp)), health.Options{Critical: true})
+
+	// 尚未完成第一次檢查的關鍵組件視為異常
+	report := r.Report()
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, health.StatusUnknown, report.Checks[0].Status)
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levels 保存全域級別與具名 logger 的級別覆寫，可於執行期調整而不需重啟
+var levels = newLevelRegistry()
+
+// levelRegistry 以 logger 名稱 (zap.Logger.Named 產生的名稱，以 "." 分隔) 決定日誌級別
+// 名稱未設定覆寫時，沿用最接近的上層名稱的級別，都沒有則使用全域級別
+type levelRegistry struct {
+	global zap.AtomicLevel
+
+	mu        sync.RWMutex
+	overrides map[string]zapcore.Level
+	min       zap.AtomicLevel // 全域級別與所有覆寫中最低的級別，供 Enabled 快速判斷
+}
+
+func newLevelRegistry() *levelRegistry {
+	return &levelRegistry{
+		global:    zap.NewAtomicLevelAt(zapcore.InfoLevel),
+		overrides: make(map[string]zapcore.Level),
+		min:       zap.NewAtomicLevelAt(zapcore.InfoLevel),
+	}
+}
+
+// levelFor 回傳名稱對應的級別，以最長的 "." 分隔前綴比對覆寫
+func (r *levelRegistry) levelFor(name string) zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name != "" {
+		if lvl, ok := r.overrides[name]; ok {
+			return lvl
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return r.global.Level()
+}
+
+func (r *levelRegistry) set(name string, lvl zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if name == "" {
+		r.global.SetLevel(lvl)
+	} else {
+		r.overrides[name] = lvl
+	}
+	r.recomputeMinLocked()
+}
+
+func (r *levelRegistry) reset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.overrides, name)
+	r.recomputeMinLocked()
+}
+
+func (r *levelRegistry) recomputeMinLocked() {
+	lowest := r.global.Level()
+	for _, lvl := range r.overrides {
+		if lvl < lowest {
+			lowest = lvl
+		}
+	}
+	r.min.SetLevel(lowest)
+}
+
+// levelFilterCore 依 entry 的 logger 名稱過濾日誌，包裝在所有輸出的 core 之外
+// 各輸出的 core 只負責其固定的最低級別 (例如錯誤日誌檔只收 error)，可調整的級別由此處決定
+type levelFilterCore struct {
+	zapcore.Core
+	registry *levelRegistry
+}
+
+// Enabled 實作 zapcore.LevelEnabler，只要有任一 logger 會輸出此級別即回傳 true，實際過濾於 Check 進行
+func (c *levelFilterCore) Enabled(lvl zapcore.Level) bool {
+	return c.registry.min.Enabled(lvl) && c.Core.Enabled(lvl)
+}
+
+// With 實作 zapcore.Core
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields), registry: c.registry}
+}
+
+// Check 實作 zapcore.Core
+func (c *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.registry.min.Enabled(ent.Level) || ent.Level < c.registry.levelFor(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// Level 實作 zapcore.LevelOf 使用的介面，回傳目前可能輸出的最低級別
+func (c *levelFilterCore) Level() zapcore.Level {
+	return c.registry.min.Level()
+}
+
+// SetLevel 於執行期調整日誌級別，name 為空字串時調整全域級別，否則調整該具名 logger 及其子 logger 的級別
+func SetLevel(name, level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	levels.set(name, lvl)
+	return nil
+}
+
+// ResetLevel 移除具名 logger 的級別覆寫，使其恢復沿用上層或全域級別
+func ResetLevel(name string) {
+	levels.reset(name)
+}
+
+// Levels 回傳目前的全域級別與各具名 logger 的級別覆寫
+func Levels() (string, map[string]string) {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+	overrides := make(map[string]string, len(levels.overrides))
+	for name, lvl := range levels.overrides {
+		overrides[name] = lvl.String()
+	}
+	return levels.global.Level().String(), overrides
+}
+
+// parseLevel 解析日誌級別字串，不接受空字串
+func parseLevel(level string) (zapcore.Level, error) {
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil || level == "" {
+		return lvl, fmt.Errorf("無效的日誌級別: %q", level)
+	}
+	return lvl, nil
+}
@@ -2,10 +2,11 @@ package logger
 
 import (
 	"context"
-	"os"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
+
+	"microservice-mvp/pkg/configs"
 )
 
 // Logger 是全域 logger 實例
@@ -13,40 +14,34 @@ var Logger *zap.Logger
 
 type loggerKey struct{}
 
-// NewLogger 根據提供的日誌級別和編碼初始化新的 Zap logger
-func NewLogger(level, encoding string) (*zap.Logger, error) {
-	var zapLevel zapcore.Level
-	if err := zapLevel.UnmarshalText([]byte(level)); err != nil {
-		zapLevel = zapcore.InfoLevel // 如果解析失敗，預設為 Info
-	}
-
-	encoderConfig := zap.NewProductionEncoderConfig()
-	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder // 使用 ISO8601 格式化時間
-	encoderConfig.CallerKey = "caller"                   // 添加呼叫者資訊
-	encoderConfig.TimeKey = "time"                       // 添加時間鍵
-
-	var encoder zapcore.Encoder
-	switch encoding {
-	case "json":
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	case "console":
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	default:
-		encoder = zapcore.NewJSONEncoder(encoderConfig) // 預設為 JSON
-	}
+type traceIDKey struct{}
 
-	core := zapcore.NewCore(
-		encoder,
-		zapcore.AddSync(os.Stdout), // 寫入到標準輸出
-		zapLevel,
-	)
+// NewLogger 根據提供的日誌級別和編碼初始化只輸出到標準輸出的 Zap logger
+// 需要輸出到日誌檔時請使用 New
+func NewLogger(level, encoding string) (*zap.Logger, error) {
+	return New(configs.LoggerConfig{Level: level, Encoding: encoding, Stdout: true})
+}
 
-	Logger = zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
-	return Logger, nil
+// Named 返回全域 logger 的具名子 logger，其級別可透過 SetLevel(name, level) 單獨調整
+func Named(name string) *zap.Logger {
+	return Logger.Named(name)
 }
 
 // FromContext 從上下文中返回帶有欄位的 logger，如果未找到則返回全域 logger
+// 上下文中有 OpenTelemetry span 時，日誌會帶上該 span 的 trace_id 與 span_id
 func FromContext(ctx context.Context) *zap.Logger {
+	l := loggerFromContext(ctx)
+	if ctx == nil {
+		return l
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return l.With(zap.String(SpanTraceIDKey, sc.TraceID().String()), zap.String(SpanIDKey, sc.SpanID().String()))
+	}
+	return l
+}
+
+// loggerFromContext 返回上下文中保存的 logger，不附加 span 欄位
+func loggerFromContext(ctx context.Context) *zap.Logger {
 	if ctx == nil {
 		return Logger
 	}
@@ -64,11 +59,37 @@ func WithContext(ctx context.Context, logger *zap.Logger) context.Context {
 // TraceIDKey 是用於在上下文和日誌欄位中存儲和檢索 trace ID 的鍵
 const TraceIDKey = "traceID"
 
+const (
+	// SpanTraceIDKey 是 OpenTelemetry span 的 trace ID 日誌欄位
+	SpanTraceIDKey = "trace_id"
+	// SpanIDKey 是 OpenTelemetry span 的 span ID 日誌欄位
+	SpanIDKey = "span_id"
+)
+
 // WithTraceID 將 traceID 欄位添加到 logger 並返回帶有此 logger 的新上下文
+// traceID 本身也會存入上下文，供 TraceIDFromContext 取回 (例如寫入 outbox 事件)
+// traceID 與上下文中 span 的 trace ID 相同時，日誌已帶有 trace_id，不再重複添加 traceID 欄位
 func WithTraceID(ctx context.Context, traceID string) context.Context {
 	if traceID == "" {
 		return ctx
 	}
-	l := FromContext(ctx).With(zap.String(TraceIDKey, traceID))
-	return WithContext(ctx, l)
+	ctx = context.WithValue(ctx, traceIDKey{}, traceID)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() && sc.TraceID().String() == traceID {
+		return ctx
+	}
+	return WithContext(ctx, loggerFromContext(ctx).With(zap.String(TraceIDKey, traceID)))
+}
+
+// TraceIDFromContext 返回上下文中的 trace ID，未以 WithTraceID 設定時返回 span 的 trace ID，都沒有則返回空字串
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if traceID, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return traceID
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	return ""
 }
\ No newline at end of file
@@ -0,0 +1,144 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func newFileLogger(t *testing.T, cfg configs.LoggerConfig) (appLog, errorLog string) {
+	t.Helper()
+	dir := t.TempDir()
+	appLog = filepath.Join(dir, "logs", "app.log")
+	errorLog = filepath.Join(dir, "logs", "error.log")
+	cfg.File = configs.LogFileConfig{Enabled: true, Path: appLog}
+	cfg.ErrorFile = configs.LogFileConfig{Enabled: true, Path: errorLog}
+	_, err := logger.New(cfg)
+	require.NoError(t, err)
+	return appLog, errorLog
+}
+
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	_ = logger.Logger.Sync()
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ""
+	}
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestNew_ErrorFileOnlyReceivesErrors(t *testing.T) {
+	appLog, errorLog := newFileLogger(t, configs.LoggerConfig{Level: "info"})
+
+	logger.Logger.Info("一般訊息")
+	logger.Logger.Error("錯誤訊息")
+
+	app := readLog(t, appLog)
+	assert.Contains(t, app, "一般訊息")
+	assert.Contains(t, app, "錯誤訊息")
+
+	errs := readLog(t, errorLog)
+	assert.NotContains(t, errs, "一般訊息")
+	assert.Contains(t, errs, "錯誤訊息")
+}
+
+func TestNew_InvalidNamedLevel(t *testing.T) {
+	_, err := logger.New(configs.LoggerConfig{Stdout: true, Loggers: map[string]string{"gorm": "verbose"}})
+	assert.Error(t, err)
+}
+
+func TestSetLevel(t *testing.T) {
+	appLog, _ := newFileLogger(t, configs.LoggerConfig{Level: "info", Loggers: map[string]string{"gorm": "warn"}})
+
+	tests := []struct {
+		name    string
+		logger  string
+		level   string
+		write   func()
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "設定檔中的具名級別生效",
+			write: func() { logger.Named("gorm").Info("gorm-info-before") },
+		},
+		{
+			name:   "調高具名 logger 的級別不影響全域",
+			logger: "gorm", level: "debug",
+			write: func() { logger.Named("gorm").Debug("gorm-debug"); logger.Logger.Debug("global-debug") },
+			want:  "gorm-debug",
+		},
+		{
+			name:   "子 logger 沿用上層名稱的級別",
+			logger: "gorm", level: "debug",
+			write: func() { logger.Named("gorm").Named("replica").Debug("gorm-replica-debug") },
+			want:  "gorm-replica-debug",
+		},
+		{
+			name:   "調整全域級別",
+			logger: "", level: "debug",
+			write: func() { logger.Named("outbox").Debug("outbox-debug") },
+			want:  "outbox-debug",
+		},
+		{
+			name:   "無效的級別",
+			logger: "", level: "verbose",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.level != "" {
+				err := logger.SetLevel(tt.logger, tt.level)
+				if tt.wantErr {
+					assert.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+			}
+			tt.write()
+			if tt.want != "" {
+				assert.Contains(t, readLog(t, appLog), tt.want)
+			}
+		})
+	}
+
+	out := readLog(t, appLog)
+	assert.NotContains(t, out, "gorm-info-before")
+	assert.NotContains(t, out, "global-debug")
+
+	level, loggers := logger.Levels()
+	assert.Equal(t, "debug", level)
+	assert.Equal(t, map[string]string{"gorm": "debug"}, loggers)
+
+	logger.ResetLevel("gorm")
+	_, loggers = logger.Levels()
+	assert.Empty(t, loggers)
+}
+
+func TestNew_Sampling(t *testing.T) {
+	appLog, _ := newFileLogger(t, configs.LoggerConfig{
+		Level:    "info",
+		Sampling: configs.LogSamplingConfig{Enabled: true, TickSeconds: 60, Initial: 2, Thereafter: 5},
+	})
+
+	for i := 0; i < 12; i++ {
+		logger.Logger.Info("重複訊息")
+	}
+	logger.Logger.Info("其他訊息")
+
+	out := readLog(t, appLog)
+	// 前 2 筆全部輸出，之後每 5 筆輸出一筆 (第 7、12 筆)
+	assert.Equal(t, 4, strings.Count(out, "重複訊息"))
+	assert.Contains(t, out, "其他訊息", "取樣以訊息區分，不影響其他訊息")
+}
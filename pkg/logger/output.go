@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"microservice-mvp/pkg/configs"
+)
+
+const (
+	// defaultMaxSizeMB 是未設定 max_size_mb 時的輪替大小
+	defaultMaxSizeMB = 100
+
+	defaultSamplingInitial    = 100
+	defaultSamplingThereafter = 100
+)
+
+// New 依設定建立全域 logger，可同時輸出到標準輸出、輪替日誌檔與只記錄錯誤的日誌檔
+// 啟用 cfg.Redact 時，所有輸出在寫入前遮蔽敏感資料；啟用 cfg.Sampling 時，大量重複的日誌依取樣設定輸出
+// 全域級別與 cfg.Loggers 中的具名 logger 級別可於執行期以 SetLevel 調整
+func New(cfg configs.LoggerConfig) (*zap.Logger, error) {
+	encoder := newEncoder(cfg.Encoding)
+
+	var cores []zapcore.Core
+	if cfg.Stdout {
+		cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.DebugLevel))
+	}
+	if cfg.File.Enabled {
+		w, err := newRotatingWriter(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, zapcore.NewCore(encoder.Clone(), w, zapcore.DebugLevel))
+	}
+	if cfg.ErrorFile.Enabled {
+		w, err := newRotatingWriter(cfg.ErrorFile)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, zapcore.NewCore(encoder.Clone(), w, zapcore.ErrorLevel))
+	}
+	if len(cores) == 0 {
+		// 未啟用任何輸出時仍輸出到標準輸出，避免日誌完全遺失
+		cores = append(cores, zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapcore.DebugLevel))
+	}
+
+	// 每個輸出各自包裝遮蔽層，保留各輸出原本的級別判斷
+	r := NewRedactor(cfg.Redact)
+	redactor.Store(r)
+	if r != nil {
+		for i, c := range cores {
+			cores[i] = &redactCore{Core: c, redactor: r}
+		}
+	}
+
+	global, err := parseLevel(cfg.Level)
+	if err != nil {
+		global = zapcore.InfoLevel // 如果解析失敗，預設為 Info
+	}
+	overrides := make(map[string]zapcore.Level, len(cfg.Loggers))
+	for name, level := range cfg.Loggers {
+		lvl, err := parseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("logger %s: %w", name, err)
+		}
+		overrides[name] = lvl
+	}
+	levels.mu.Lock()
+	levels.global.SetLevel(global)
+	levels.overrides = overrides
+	levels.recomputeMinLocked()
+	levels.mu.Unlock()
+
+	tee := zapcore.NewTee(cores...)
+	if cfg.Sampling.Enabled {
+		tee = newSampler(tee, cfg.Sampling)
+	}
+	core := &levelFilterCore{Core: tee, registry: levels}
+	Logger = zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	return Logger, nil
+}
+
+// newSampler 依設定包裝 zap 取樣 core，未設定的參數採用 zap production 設定的預設值
+func newSampler(core zapcore.Core, cfg configs.LogSamplingConfig) zapcore.Core {
+	tick := time.Duration(cfg.TickSeconds) * time.Second
+	if tick <= 0 {
+		tick = time.Second
+	}
+	initial, thereafter := cfg.Initial, cfg.Thereafter
+	if initial <= 0 {
+		initial = defaultSamplingInitial
+	}
+	if thereafter <= 0 {
+		thereafter = defaultSamplingThereafter
+	}
+	return zapcore.NewSamplerWithOptions(core, tick, initial, thereafter)
+}
+
+func newEncoder(encoding string) zapcore.Encoder {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder // 使用 ISO8601 格式化時間
+	encoderConfig.CallerKey = "caller"                    // 添加呼叫者資訊
+	encoderConfig.TimeKey = "time"                        // 添加時間鍵
+
+	switch encoding {
+	case "console":
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return zapcore.NewJSONEncoder(encoderConfig) // 預設為 JSON
+	}
+}
+
+// newRotatingWriter 建立依大小輪替的日誌檔，並依保留天數與數量清除舊檔
+func newRotatingWriter(cfg configs.LogFileConfig) (zapcore.WriteSyncer, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("日誌檔路徑不可為空")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("建立日誌目錄失敗: %w", err)
+	}
+	maxSize := cfg.MaxSizeMB
+	if maxSize <= 0 {
+		maxSize = defaultMaxSizeMB
+	}
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   cfg.Path,
+		MaxSize:    maxSize,
+		MaxAge:     cfg.MaxAgeDays,
+		MaxBackups: cfg.MaxBackups,
+		Compress:   cfg.Compress,
+		LocalTime:  true,
+	}), nil
+}
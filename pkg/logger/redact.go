@@ -0,0 +1,313 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"microservice-mvp/pkg/configs"
+)
+
+// defaultRedactFields 是未設定 logger.redact.fields 時遮蔽的欄位名稱
+var defaultRedactFields = []string{
+	"password", "token", "access_token", "refresh_token", "authorization", "x-admin-token", "secret", "card_number",
+}
+
+// defaultMask 是未設定 logger.redact.mask 時取代敏感值的字串
+const defaultMask = "***"
+
+// cardPattern 比對 13 到 19 位、可用空白或連字號分隔的數字，實際是否為卡號再以 Luhn 檢查
+var cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// redactor 是 New 依設定建立的全域遮蔽器，未啟用時為 nil
+var redactor atomic.Pointer[Redactor]
+
+// Redactor 依欄位名稱、JSON 路徑與信用卡號規則遮蔽敏感資料
+// nil 的 *Redactor 不遮蔽任何內容
+type Redactor struct {
+	fields      map[string]struct{}
+	paths       [][]string
+	cardNumbers bool
+	mask        string
+}
+
+// NewRedactor 依設定建立 Redactor，未啟用時回傳 nil
+func NewRedactor(cfg configs.RedactConfig) *Redactor {
+	if !cfg.Enabled {
+		return nil
+	}
+	names := cfg.Fields
+	if len(names) == 0 {
+		names = defaultRedactFields
+	}
+	r := &Redactor{
+		fields:      make(map[string]struct{}, len(names)),
+		cardNumbers: cfg.CardNumbers,
+		mask:        cfg.Mask,
+	}
+	if r.mask == "" {
+		r.mask = defaultMask
+	}
+	for _, name := range names {
+		r.fields[strings.ToLower(name)] = struct{}{}
+	}
+	for _, p := range cfg.JSONPaths {
+		if segs := parseJSONPath(p); len(segs) > 0 {
+			r.paths = append(r.paths, segs)
+		}
+	}
+	return r
+}
+
+// parseJSONPath 將 $.items[*].token 之類的路徑拆成 ["items", "*", "token"]
+func parseJSONPath(p string) []string {
+	p = strings.TrimPrefix(strings.TrimSpace(p), "$")
+	p = strings.NewReplacer("[", ".", "]", "").Replace(p)
+	var segs []string
+	for _, s := range strings.Split(p, ".") {
+		if s != "" {
+			segs = append(segs, s)
+		}
+	}
+	return segs
+}
+
+// RedactQuery 以全域遮蔽器遮蔽 URL 查詢字串中的敏感參數值
+func RedactQuery(rawQuery string) string {
+	return redactor.Load().Query(rawQuery)
+}
+
+// RedactPayload 以全域遮蔽器遮蔽訊息內容或 HTTP body，JSON 依欄位名稱與路徑遮蔽，其他內容只遮蔽信用卡號
+func RedactPayload(payload []byte) string {
+	return redactor.Load().Payload(payload)
+}
+
+// Query 遮蔽 URL 查詢字串中名稱相符的參數值，其餘參數維持原始編碼
+func (r *Redactor) Query(rawQuery string) string {
+	if r == nil || rawQuery == "" {
+		return rawQuery
+	}
+	parts := strings.Split(rawQuery, "&")
+	for i, part := range parts {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			key = k
+		}
+		if r.matchField(key) {
+			parts[i] = k + "=" + r.mask
+			continue
+		}
+		if val, err := url.QueryUnescape(v); err == nil {
+			if masked := r.maskCards(val); masked != val {
+				parts[i] = k + "=" + strings.ReplaceAll(url.QueryEscape(masked), "%2A", "*") // 保留遮蔽字元以便閱讀
+			}
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// Payload 遮蔽內容並以字串回傳，可解析為 JSON 時依欄位名稱與路徑遮蔽
+func (r *Redactor) Payload(payload []byte) string {
+	if r == nil {
+		return string(payload)
+	}
+	if out, ok := r.redactJSON(payload); ok {
+		return string(out)
+	}
+	return r.maskCards(string(payload))
+}
+
+// Fields 遮蔽日誌欄位：名稱相符的欄位整個取代為遮蔽字串，字串內容中的 JSON 與信用卡號另行遮蔽
+// 未遮蔽任何欄位時回傳原切片
+func (r *Redactor) Fields(fields []zapcore.Field) []zapcore.Field {
+	if r == nil {
+		return fields
+	}
+	out := fields
+	copied := false
+	for i, f := range fields {
+		nf, changed := r.field(f)
+		if !changed {
+			continue
+		}
+		if !copied {
+			out = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		out[i] = nf
+	}
+	return out
+}
+
+func (r *Redactor) field(f zapcore.Field) (zapcore.Field, bool) {
+	switch f.Type {
+	case zapcore.SkipType, zapcore.NamespaceType:
+		return f, false
+	}
+	if r.matchField(f.Key) {
+		return zap.String(f.Key, r.mask), true
+	}
+	switch f.Type {
+	case zapcore.StringType:
+		if v := r.text(f.String); v != f.String {
+			return zap.String(f.Key, v), true
+		}
+	case zapcore.ByteStringType:
+		if b, ok := f.Interface.([]byte); ok {
+			if v := r.text(string(b)); v != string(b) {
+				return zap.String(f.Key, v), true
+			}
+		}
+	}
+	return f, false
+}
+
+// text 遮蔽字串值：看起來是 JSON 時依欄位名稱與路徑遮蔽，否則只遮蔽信用卡號
+func (r *Redactor) text(s string) string {
+	if t := strings.TrimSpace(s); t != "" && (t[0] == '{' || t[0] == '[') {
+		if out, ok := r.redactJSON([]byte(t)); ok {
+			return string(out)
+		}
+	}
+	return r.maskCards(s)
+}
+
+func (r *Redactor) matchField(name string) bool {
+	_, ok := r.fields[strings.ToLower(name)]
+	return ok
+}
+
+func (r *Redactor) matchPath(path []string) bool {
+	for _, p := range r.paths {
+		if len(p) != len(path) {
+			continue
+		}
+		matched := true
+		for i, seg := range p {
+			if seg != "*" && seg != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSON 解析並遮蔽 JSON，無法解析時回傳 false
+func (r *Redactor) redactJSON(b []byte) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber() // 保留數字原始格式，避免大整數失去精度
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil, false
+	}
+	v = r.walk(v, nil)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, false
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), true
+}
+
+func (r *Redactor) walk(v any, path []string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			p := append(path[:len(path):len(path)], k)
+			if r.matchField(k) || r.matchPath(p) {
+				t[k] = r.mask
+				continue
+			}
+			t[k] = r.walk(child, p)
+		}
+	case []any:
+		for i, child := range t {
+			p := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if r.matchPath(p) {
+				t[i] = r.mask
+				continue
+			}
+			t[i] = r.walk(child, p)
+		}
+	case string:
+		return r.maskCards(t)
+	case json.Number:
+		if masked := r.maskCards(t.String()); masked != t.String() {
+			return masked
+		}
+	}
+	return v
+}
+
+// maskCards 將通過 Luhn 檢查的卡號遮蔽為只保留末四碼
+func (r *Redactor) maskCards(s string) string {
+	if !r.cardNumbers {
+		return s
+	}
+	return cardPattern.ReplaceAllStringFunc(s, func(m string) string {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(m)
+		if !luhnValid(digits) {
+			return m
+		}
+		return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+	})
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactCore 在寫入前遮蔽日誌訊息與欄位中的敏感資料，包裝在每個輸出的 core 之外
+type redactCore struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+// With 實作 zapcore.Core
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.Fields(fields)), redactor: c.redactor}
+}
+
+// Check 實作 zapcore.Core
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 實作 zapcore.Core
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.redactor.maskCards(ent.Message)
+	return c.Core.Write(ent, c.redactor.Fields(fields))
+}
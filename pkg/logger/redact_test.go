@@ -0,0 +1,106 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+func newTestRedactor() *logger.Redactor {
+	return logger.NewRedactor(configs.RedactConfig{
+		Enabled:     true,
+		Fields:      []string{"password", "token", "Authorization"},
+		JSONPaths:   []string{"$.payment.cvv", "items[*].secret"},
+		CardNumbers: true,
+	})
+}
+
+func TestRedactor_Payload(t *testing.T) {
+	r := newTestRedactor()
+
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{
+			name:    "任何層級的同名欄位",
+			payload: `{"username":"alice","password":"p@ss","profile":{"TOKEN":"abc"}}`,
+			want:    `{"password":"***","profile":{"TOKEN":"***"},"username":"alice"}`,
+		},
+		{
+			name:    "JSON 路徑與陣列萬用字元",
+			payload: `{"payment":{"cvv":"123","amount":100},"items":[{"secret":"s1"},{"secret":"s2","id":12345678901234567890}]}`,
+			want:    `{"items":[{"secret":"***"},{"id":12345678901234567890,"secret":"***"}],"payment":{"amount":100,"cvv":"***"}}`,
+		},
+		{
+			name:    "JSON 中的信用卡號只保留末四碼",
+			payload: `{"card":"4111 1111 1111 1111","order":"1234567890123"}`,
+			want:    `{"card":"************1111","order":"1234567890123"}`,
+		},
+		{
+			name:    "非 JSON 內容只遮蔽信用卡號",
+			payload: "卡號 4111-1111-1111-1111 付款",
+			want:    "卡號 ************1111 付款",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Payload([]byte(tt.payload)))
+		})
+	}
+}
+
+func TestRedactor_Query(t *testing.T) {
+	r := newTestRedactor()
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "遮蔽敏感參數", query: "id=1&token=abc&Password=x", want: "id=1&token=***&Password=***"},
+		{name: "保留其他參數的原始編碼", query: "q=%E7%8E%A9%E5%AE%B6&flag", want: "q=%E7%8E%A9%E5%AE%B6&flag"},
+		{name: "遮蔽參數中的信用卡號", query: "card=4111111111111111", want: "card=************1111"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Query(tt.query))
+		})
+	}
+}
+
+func TestRedactor_Disabled(t *testing.T) {
+	r := logger.NewRedactor(configs.RedactConfig{Enabled: false})
+	assert.Nil(t, r)
+	assert.Equal(t, "token=abc", r.Query("token=abc"))
+	assert.Equal(t, `{"password":"x"}`, r.Payload([]byte(`{"password":"x"}`)))
+}
+
+func TestNew_RedactsLogFields(t *testing.T) {
+	appLog, _ := newFileLogger(t, configs.LoggerConfig{
+		Level:  "info",
+		Redact: configs.RedactConfig{Enabled: true, CardNumbers: true},
+	})
+
+	logger.Logger.With(zap.String("authorization", "Bearer secret-token")).Info("付款 4111111111111111",
+		zap.String("password", "p@ss"),
+		zap.Int("token", 987654),
+		zap.String("body", `{"refresh_token":"refresh-r1","name":"bob"}`),
+	)
+
+	out := readLog(t, appLog)
+	assert.NotContains(t, out, "secret-token")
+	assert.NotContains(t, out, "p@ss")
+	assert.NotContains(t, out, "987654")
+	assert.NotContains(t, out, "refresh-r1")
+	assert.NotContains(t, out, "4111111111111111")
+	assert.Contains(t, out, "************1111")
+	assert.Contains(t, out, `"name\":\"bob\"`)
+}
@@ -0,0 +1,515 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/logger"
+)
+
+// InProcOptions 是行程內 Broker 的設定
+type InProcOptions struct {
+	QueueNums         int             // 每個 Topic 的佇列數，同一 sharding key 的訊息固定落在同一佇列
+	MaxReconsumeTimes int             // 超過此重新消費次數後轉入死信 Topic
+	DelayLevels       []time.Duration // 延遲等級對應的時間，延遲會進位到最接近的等級，測試時可縮短
+	SuspendInterval   time.Duration   // 順序訊息消費失敗時暫停該佇列的時間
+}
+
+// InProcBroker 是行程內的訊息 Broker，提供與 RocketMQ 相同語意的 Topic、標籤、
+// Consumer Group (群組內競爭消費)、依 sharding key 的順序投遞、延遲等級與失敗重投
+type InProcBroker struct {
+	opts InProcOptions
+
+	mu     sync.Mutex
+	groups map[string]*inprocGroup
+	timers map[*time.Timer]struct{}
+	closed bool
+
+	seq  atomic.Uint64
+	rr   atomic.Uint64
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewInProcBroker 建立一個行程內 Broker
+func NewInProcBroker(opts InProcOptions) *InProcBroker {
+	if opts.QueueNums <= 0 {
+		opts.QueueNums = 4
+	}
+	if opts.MaxReconsumeTimes <= 0 {
+		opts.MaxReconsumeTimes = 16
+	}
+	if len(opts.DelayLevels) == 0 {
+		opts.DelayLevels = DefaultDelayLevels
+	}
+	if opts.SuspendInterval <= 0 {
+		opts.SuspendInterval = time.Second
+	}
+	return &InProcBroker{
+		opts:   opts,
+		groups: make(map[string]*inprocGroup),
+		timers: make(map[*time.Timer]struct{}),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Producer 回傳發送到此 Broker 的 Producer
+func (b *InProcBroker) Producer() Producer {
+	return &inprocProducer{broker: b}
+}
+
+// NewConsumer 建立屬於指定 Consumer Group 的 Consumer
+// 同一群組的多個 Consumer 會競爭消費：每則訊息只會交給其中一個
+func (b *InProcBroker) NewConsumer(group string) Consumer {
+	return &inprocConsumer{broker: b, group: group}
+}
+
+// Close 停止所有投遞工作與尚未到期的延遲訊息
+func (b *InProcBroker) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for t := range b.timers {
+		t.Stop()
+	}
+	b.timers = nil
+	b.mu.Unlock()
+
+	close(b.stop)
+	b.wg.Wait()
+}
+
+// publish 接收一則訊息，依延遲立即或延後投遞到所有訂閱的群組
+func (b *InProcBroker) publish(msg *Message) (*SendResult, error) {
+	if msg.Topic == "" {
+		return nil, fmt.Errorf("訊息缺少 Topic")
+	}
+
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return nil, fmt.Errorf("行程內 Broker 已關閉")
+	}
+
+	msgID := fmt.Sprintf("INPROC%020d", b.seq.Add(1))
+	cp := msg.Clone()
+	cp.Delay = 0
+	born := time.Now()
+
+	dispatch := func() { b.dispatch(cp, msgID, born) }
+	if delay := b.roundDelay(msg.Delay); delay > 0 {
+		b.schedule(delay, dispatch)
+	} else {
+		dispatch()
+	}
+
+	return &SendResult{MsgID: msgID, Partition: b.queueFor(msg.ShardingKey), Offset: -1}, nil
+}
+
+// roundDelay 將延遲進位到最接近的延遲等級，超出範圍時使用最大等級
+func (b *InProcBroker) roundDelay(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	for _, level := range b.opts.DelayLevels {
+		if level >= d {
+			return level
+		}
+	}
+	return b.opts.DelayLevels[len(b.opts.DelayLevels)-1]
+}
+
+// defaultDelay 回傳未指定延遲時第 n 次重投的等待時間，與 RocketMQ 相同從等級 3 開始
+func (b *InProcBroker) defaultDelay(reconsumeTimes int) time.Duration {
+	level := 2 + reconsumeTimes
+	if level >= len(b.opts.DelayLevels) {
+		level = len(b.opts.DelayLevels) - 1
+	}
+	return b.opts.DelayLevels[level]
+}
+
+// schedule 在延遲後執行 fn，Broker 關閉時會取消尚未到期的工作
+func (b *InProcBroker) schedule(delay time.Duration, fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(delay, func() {
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return
+		}
+		delete(b.timers, t)
+		b.mu.Unlock()
+		fn()
+	})
+	b.timers[t] = struct{}{}
+}
+
+// dispatch 將訊息複製一份投遞到每個訂閱此 Topic 且標籤相符的群組
+func (b *InProcBroker) dispatch(msg *Message, msgID string, born time.Time) {
+	b.mu.Lock()
+	var targets []*inprocSubscription
+	for _, g := range b.groups {
+		if sub, ok := g.subs[msg.Topic]; ok && sub.matches(msg.Tag) {
+			targets = append(targets, sub)
+		}
+	}
+	b.mu.Unlock()
+
+	queueID := b.queueFor(msg.ShardingKey)
+	for _, sub := range targets {
+		d := &Delivery{Message: *msg.Clone(), MsgID: msgID, BornAt: born}
+		sub.queues[queueID].push(d)
+	}
+}
+
+// queueFor 依 sharding key 雜湊選擇佇列以保證同 key 順序；沒有 key 時輪流分配
+func (b *InProcBroker) queueFor(shardingKey string) int {
+	if shardingKey == "" {
+		return int(b.rr.Add(1) % uint64(b.opts.QueueNums))
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(shardingKey))
+	return int(h.Sum32() % uint32(b.opts.QueueNums))
+}
+
+// subscribe 將 Consumer 成員加入群組的訂閱，首次訂閱時啟動該訂閱的佇列工作
+func (b *InProcBroker) subscribe(group, topic, tags string, m *inprocMember) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return fmt.Errorf("行程內 Broker 已關閉")
+	}
+
+	g, ok := b.groups[group]
+	if !ok {
+		g = &inprocGroup{name: group, subs: make(map[string]*inprocSubscription)}
+		b.groups[group] = g
+	}
+
+	sub, ok := g.subs[topic]
+	if !ok {
+		sub = newInprocSubscription(b, group, topic, tags)
+		g.subs[topic] = sub
+		for i := range sub.queues {
+			b.wg.Add(1)
+			go sub.run(sub.queues[i])
+		}
+	} else if sub.expression != normalizeExpression(tags) {
+		return fmt.Errorf("Consumer Group %s 對 Topic %s 的標籤表達式不一致", group, topic)
+	}
+
+	m.sub = sub
+	sub.addMember(m)
+	return nil
+}
+
+// deadLetter 將超過重新消費上限的訊息轉入 %DLQ%<group>
+func (b *InProcBroker) deadLetter(group string, d *Delivery) {
+	dlq := d.Message.Clone()
+	dlq.Topic = DLQTopicPrefix + group
+	dlq.WithProperty(PropertyOriginTopic, d.Topic)
+	dlq.WithProperty(PropertyOriginMsgID, d.MsgID)
+
+	logger.Logger.Warn("訊息超過最大重新消費次數，轉入死信 Topic",
+		zap.String("group", group),
+		zap.String("topic", d.Topic),
+		zap.String("msgID", d.MsgID),
+		zap.Int("reconsumeTimes", d.ReconsumeTimes),
+	)
+	if _, err := b.publish(dlq); err != nil {
+		logger.Logger.Error("轉入死信 Topic 失敗", zap.Error(err), zap.String("msgID", d.MsgID))
+	}
+}
+
+type inprocGroup struct {
+	name string
+	subs map[string]*inprocSubscription
+}
+
+// inprocSubscription 是一個 Consumer Group 對一個 Topic 的訂閱
+// 每個佇列由單一 goroutine 依序處理，因此同一佇列 (同一 sharding key) 的訊息保持順序
+type inprocSubscription struct {
+	broker     *InProcBroker
+	group      string
+	topic      string
+	expression string
+	tags       map[string]bool // nil 代表訂閱全部標籤
+	queues     []*inprocQueue
+
+	mu      sync.Mutex
+	members []*inprocMember
+	next    int
+}
+
+func newInprocSubscription(b *InProcBroker, group, topic, expression string) *inprocSubscription {
+	sub := &inprocSubscription{
+		broker:     b,
+		group:      group,
+		topic:      topic,
+		expression: normalizeExpression(expression),
+	}
+	if tags := ParseTags(expression); tags != nil {
+		sub.tags = make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			sub.tags[tag] = true
+		}
+	}
+	for i := 0; i < b.opts.QueueNums; i++ {
+		sub.queues = append(sub.queues, newInprocQueue())
+	}
+	return sub
+}
+
+func normalizeExpression(expression string) string {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return "*"
+	}
+	return expression
+}
+
+func (s *inprocSubscription) matches(tag string) bool {
+	return s.tags == nil || s.tags[tag]
+}
+
+func (s *inprocSubscription) addMember(m *inprocMember) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members = append(s.members, m)
+}
+
+func (s *inprocSubscription) removeMember(m *inprocMember) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.members {
+		if existing == m {
+			s.members = append(s.members[:i], s.members[i+1:]...)
+			return
+		}
+	}
+}
+
+// nextHandler 以輪詢方式在已啟動的成員間選擇處理者，實現群組內的競爭消費
+func (s *inprocSubscription) nextHandler() Handler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < len(s.members); i++ {
+		m := s.members[(s.next+i)%len(s.members)]
+		if m.consumer.started.Load() {
+			s.next = (s.next + i + 1) % len(s.members)
+			return m.handler
+		}
+	}
+	return nil
+}
+
+// run 是單一佇列的投遞迴圈
+func (s *inprocSubscription) run(q *inprocQueue) {
+	b := s.broker
+	defer b.wg.Done()
+
+	for {
+		d := q.peek()
+		if d == nil {
+			select {
+			case <-b.stop:
+				return
+			case <-q.signal:
+			}
+			continue
+		}
+
+		handler := s.nextHandler()
+		if handler == nil {
+			// 群組內目前沒有啟動中的成員，訊息保留在佇列中等待
+			if !s.wait(50 * time.Millisecond) {
+				return
+			}
+			continue
+		}
+
+		err := s.deliver(handler, d)
+		if err == nil {
+			q.pop()
+			continue
+		}
+
+		d.ReconsumeTimes++
+		if d.ReconsumeTimes > b.opts.MaxReconsumeTimes {
+			q.pop()
+			b.deadLetter(s.group, d)
+			continue
+		}
+
+		if d.ShardingKey != "" {
+			// 順序訊息：暫停此佇列後原地重試，避免後續訊息越過失敗的訊息
+			if !s.wait(b.opts.SuspendInterval) {
+				return
+			}
+			continue
+		}
+
+		// 一般訊息：依延遲等級稍後重投，不阻塞佇列中的其他訊息
+		q.pop()
+		delay, ok := RetryDelay(err)
+		if ok {
+			delay = b.roundDelay(delay)
+		}
+		if delay <= 0 {
+			delay = b.defaultDelay(d.ReconsumeTimes - 1)
+		}
+		b.schedule(delay, func() { q.push(d) })
+	}
+}
+
+// deliver 呼叫處理函式，panic 視為處理失敗
+func (s *inprocSubscription) deliver(handler Handler, d *Delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Logger.Error("訊息處理函式發生 Panic", zap.Any("error", r), zap.String("msgID", d.MsgID))
+			err = fmt.Errorf("訊息處理 panic: %v", r)
+		}
+	}()
+
+	if err = handler(context.Background(), d); err != nil {
+		logger.Logger.Warn("訊息處理失敗，稍後重投",
+			zap.Error(err), zap.String("topic", d.Topic), zap.String("msgID", d.MsgID), zap.String("group", s.group))
+	}
+	return err
+}
+
+// wait 等待一段時間，Broker 關閉時回傳 false
+func (s *inprocSubscription) wait(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-s.broker.stop:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// inprocQueue 是無上限的 FIFO 佇列
+type inprocQueue struct {
+	mu     sync.Mutex
+	items  []*Delivery
+	signal chan struct{}
+}
+
+func newInprocQueue() *inprocQueue {
+	return &inprocQueue{signal: make(chan struct{}, 1)}
+}
+
+func (q *inprocQueue) push(d *Delivery) {
+	q.mu.Lock()
+	q.items = append(q.items, d)
+	q.mu.Unlock()
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (q *inprocQueue) peek() *Delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return nil
+	}
+	return q.items[0]
+}
+
+func (q *inprocQueue) pop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items[0] = nil
+	q.items = q.items[1:]
+}
+
+// inprocProducer 實作 Producer
+type inprocProducer struct {
+	broker  *InProcBroker
+	started atomic.Bool
+}
+
+func (p *inprocProducer) Start() error {
+	p.started.Store(true)
+	return nil
+}
+
+func (p *inprocProducer) Shutdown() error {
+	p.started.Store(false)
+	return nil
+}
+
+func (p *inprocProducer) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	return p.broker.publish(msg)
+}
+
+func (p *inprocProducer) Started() bool {
+	return p.started.Load()
+}
+
+// inprocMember 是 Consumer 在某個訂閱中的成員資格
+type inprocMember struct {
+	consumer *inprocConsumer
+	handler  Handler
+	sub      *inprocSubscription
+}
+
+// inprocConsumer 實作 Consumer
+type inprocConsumer struct {
+	broker  *InProcBroker
+	group   string
+	started atomic.Bool
+
+	mu      sync.Mutex
+	members []*inprocMember
+}
+
+func (c *inprocConsumer) Subscribe(topic, tags string, handler Handler) error {
+	m := &inprocMember{consumer: c, handler: handler}
+	if err := c.broker.subscribe(c.group, topic, tags, m); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.members = append(c.members, m)
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *inprocConsumer) Start() error {
+	c.started.Store(true)
+	return nil
+}
+
+// Shutdown 讓此 Consumer 離開群組，尚未處理的訊息由群組內其他成員接手
+func (c *inprocConsumer) Shutdown() error {
+	c.started.Store(false)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range c.members {
+		m.sub.removeMember(m)
+	}
+	c.members = nil
+	return nil
+}
@@ -0,0 +1,236 @@
+package messaging_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+func newTestBroker(t *testing.T) *messaging.InProcBroker {
+	t.Helper()
+	_, _ = logger.NewLogger("info", "console")
+	b := messaging.NewInProcBroker(messaging.InProcOptions{
+		MaxReconsumeTimes: 2,
+		DelayLevels:       []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond},
+		SuspendInterval:   10 * time.Millisecond,
+	})
+	t.Cleanup(b.Close)
+	return b
+}
+
+// collector 收集訊息，供測試等待並檢查
+type collector struct {
+	mu   sync.Mutex
+	msgs []*messaging.Delivery
+	ch   chan struct{}
+}
+
+func newCollector() *collector {
+	return &collector{ch: make(chan struct{}, 1024)}
+}
+
+func (c *collector) handler(ctx context.Context, d *messaging.Delivery) error {
+	c.mu.Lock()
+	c.msgs = append(c.msgs, d)
+	c.mu.Unlock()
+	c.ch <- struct{}{}
+	return nil
+}
+
+func (c *collector) wait(t *testing.T, n int) []*messaging.Delivery {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-c.ch:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("等待第 %d 則訊息逾時", i+1)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*messaging.Delivery(nil), c.msgs...)
+}
+
+func startConsumer(t *testing.T, b *messaging.InProcBroker, group, topic, tags string, handler messaging.Handler) messaging.Consumer {
+	t.Helper()
+	c := b.NewConsumer(group)
+	require.NoError(t, c.Subscribe(topic, tags, handler))
+	require.NoError(t, c.Start())
+	return c
+}
+
+func TestInProcBroker_TagsAndGroups(t *testing.T) {
+	b := newTestBroker(t)
+	all := newCollector()
+	bets := newCollector()
+	startConsumer(t, b, "audit", "player_events", "", all.handler)
+	startConsumer(t, b, "bet-service", "player_events", "bet_placed || bet_settled", bets.handler)
+
+	p := b.Producer()
+	require.NoError(t, p.Start())
+	for _, tag := range []string{"registered", "bet_placed", "bet_settled"} {
+		result, err := p.Send(context.Background(), messaging.NewMessage("player_events", tag, []byte(tag), nil))
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.MsgID)
+	}
+
+	assert.Len(t, all.wait(t, 3), 3)
+	got := bets.wait(t, 2)
+	tags := []string{got[0].Tag, got[1].Tag}
+	assert.ElementsMatch(t, []string{"bet_placed", "bet_settled"}, tags)
+}
+
+func TestInProcBroker_CompetingConsumers(t *testing.T) {
+	b := newTestBroker(t)
+	first, second := newCollector(), newCollector()
+	startConsumer(t, b, "workers", "jobs", "*", first.handler)
+	startConsumer(t, b, "workers", "jobs", "*", second.handler)
+
+	p := b.Producer()
+	const total = 20
+	for i := 0; i < total; i++ {
+		_, err := p.Send(context.Background(), messaging.NewMessage("jobs", "", []byte(fmt.Sprint(i)), nil))
+		require.NoError(t, err)
+	}
+
+	merged := make(chan struct{}, total)
+	go func() {
+		for {
+			select {
+			case <-first.ch:
+			case <-second.ch:
+			case <-time.After(2 * time.Second):
+				return
+			}
+			merged <- struct{}{}
+		}
+	}()
+	for i := 0; i < total; i++ {
+		select {
+		case <-merged:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("只收到 %d 則訊息", i)
+		}
+	}
+
+	first.mu.Lock()
+	second.mu.Lock()
+	defer first.mu.Unlock()
+	defer second.mu.Unlock()
+	assert.Equal(t, total, len(first.msgs)+len(second.msgs), "每則訊息在群組內只應被消費一次")
+	assert.NotEmpty(t, first.msgs)
+	assert.NotEmpty(t, second.msgs)
+}
+
+func TestInProcBroker_OrderedByShardingKey(t *testing.T) {
+	b := newTestBroker(t)
+	var mu sync.Mutex
+	seen := map[string][]int{}
+	failedOnce := false
+	done := make(chan struct{}, 100)
+	startConsumer(t, b, "ordered", "wallet", "*", func(ctx context.Context, d *messaging.Delivery) error {
+		mu.Lock()
+		defer mu.Unlock()
+		var seq int
+		_, _ = fmt.Sscan(string(d.Body), &seq)
+		// 第一則訊息失敗一次，後續同 key 的訊息不可越過它
+		if seq == 0 && !failedOnce {
+			failedOnce = true
+			return errors.New("稍後重試")
+		}
+		seen[d.ShardingKey] = append(seen[d.ShardingKey], seq)
+		done <- struct{}{}
+		return nil
+	})
+
+	p := b.Producer()
+	for i := 0; i < 10; i++ {
+		for _, key := range []string{"player-1", "player-2"} {
+			msg := messaging.NewMessage("wallet", "", []byte(fmt.Sprint(i)), nil)
+			msg.WithShardingKey(key)
+			_, err := p.Send(context.Background(), msg)
+			require.NoError(t, err)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("等待順序訊息逾時")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	assert.Equal(t, expected, seen["player-1"])
+	assert.Equal(t, expected, seen["player-2"])
+}
+
+func TestInProcBroker_DelayLevel(t *testing.T) {
+	b := newTestBroker(t)
+	c := newCollector()
+	startConsumer(t, b, "delayed", "reminders", "*", c.handler)
+
+	msg := messaging.NewMessage("reminders", "", []byte("later"), nil)
+	msg.Delay = 45 * time.Millisecond // 進位到等級 5 (50ms)
+	start := time.Now()
+	_, err := b.Producer().Send(context.Background(), msg)
+	require.NoError(t, err)
+
+	c.wait(t, 1)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestInProcBroker_RedeliveryAndDeadLetter(t *testing.T) {
+	b := newTestBroker(t)
+
+	var mu sync.Mutex
+	attempts := map[string][]int{}
+	startConsumer(t, b, "settlement", "bets", "*", func(ctx context.Context, d *messaging.Delivery) error {
+		mu.Lock()
+		defer mu.Unlock()
+		body := string(d.Body)
+		attempts[body] = append(attempts[body], d.ReconsumeTimes)
+		switch {
+		case body == "flaky" && d.ReconsumeTimes == 0:
+			return messaging.RetryAfter(time.Millisecond, errors.New("暫時性錯誤"))
+		case body == "poison":
+			panic("無法處理")
+		}
+		return nil
+	})
+	dlq := newCollector()
+	startConsumer(t, b, "dlq-watcher", messaging.DLQTopicPrefix+"settlement", "*", dlq.handler)
+
+	p := b.Producer()
+	_, err := p.Send(context.Background(), messaging.NewMessage("bets", "", []byte("flaky"), nil))
+	require.NoError(t, err)
+	poison, err := p.Send(context.Background(), messaging.NewMessage("bets", "", []byte("poison"), nil))
+	require.NoError(t, err)
+
+	dead := dlq.wait(t, 1)
+	assert.Equal(t, "poison", string(dead[0].Body))
+	assert.Equal(t, "bets", dead[0].Property(messaging.PropertyOriginTopic))
+	assert.Equal(t, poison.MsgID, dead[0].Property(messaging.PropertyOriginMsgID))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(attempts["flaky"]) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{0, 1}, attempts["flaky"])
+	assert.Equal(t, []int{0, 1, 2}, attempts["poison"])
+}
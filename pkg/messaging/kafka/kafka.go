@@ -0,0 +1,380 @@
+// Package kafka 是 pkg/messaging 的 Kafka 轉接層，匯入後以 messaging.driver: kafka 啟用
+//
+// 標籤、索引鍵與自訂屬性以標頭傳遞，sharding key 作為分區鍵以保證同一聚合的順序。
+// Kafka 沒有重新投遞的機制，Consumer 在行程內依退避時間原地重試 (會阻塞該 Reader 的後續訊息)，
+// 超過 max_reconsume_times 後轉送死信 Topic 再提交 offset；重試期間若行程重啟，重試次數會重新計算。
+// Kafka 的 Topic 名稱只允許英數字、'.'、'_' 與 '-'，其他字元 (例如死信 Topic 的 '%') 會以 '_' 取代。
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+func init() {
+	messaging.Register(messaging.DriverKafka, driver{})
+}
+
+// driver 實作 messaging.Driver
+type driver struct{}
+
+func (driver) NewProducer(cfg configs.MessagingConfig) (messaging.Producer, error) {
+	if len(cfg.Kafka.Brokers) == 0 {
+		return nil, errors.New("未設定 messaging.kafka.brokers")
+	}
+	return &producer{w: newWriter(cfg)}, nil
+}
+
+func (driver) NewConsumer(cfg configs.MessagingConfig) (messaging.Consumer, error) {
+	if len(cfg.Kafka.Brokers) == 0 {
+		return nil, errors.New("未設定 messaging.kafka.brokers")
+	}
+	if cfg.ConsumerGroup == "" {
+		return nil, errors.New("未設定 messaging.consumer_group")
+	}
+	c := &consumer{cfg: cfg, maxReconsume: cfg.MaxReconsumeTimes}
+	if c.maxReconsume <= 0 {
+		c.maxReconsume = 16
+	}
+	return c, nil
+}
+
+// TopicName 將 Topic 轉為 Kafka 合法的名稱，不合法的字元以 '_' 取代
+func TopicName(topic string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, topic)
+}
+
+// tlsConfig 依設定回傳 TLS 設定，未啟用時為 nil
+func tlsConfig(cfg configs.KafkaConfig) *tls.Config {
+	if !cfg.TLS {
+		return nil
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+// newWriter 建立發送用的 Writer，有 sharding key 的訊息依雜湊選擇分區，沒有時輪流分配
+func newWriter(cfg configs.MessagingConfig) *kafkago.Writer {
+	kc := cfg.Kafka
+	transport := &kafkago.Transport{ClientID: cfg.ProducerGroup, TLS: tlsConfig(kc)}
+	if kc.Username != "" {
+		transport.SASL = plain.Mechanism{Username: kc.Username, Password: kc.Password}
+	}
+	w := &kafkago.Writer{
+		Addr:                   kafkago.TCP(kc.Brokers...),
+		Balancer:               &kafkago.Hash{},
+		RequiredAcks:           kafkago.RequiredAcks(kc.RequiredAcks),
+		BatchTimeout:           10 * time.Millisecond,
+		AllowAutoTopicCreation: true,
+		Transport:              transport,
+	}
+	if kc.SendTimeoutMs > 0 {
+		w.WriteTimeout = time.Duration(kc.SendTimeoutMs) * time.Millisecond
+	}
+	return w
+}
+
+// toKafka 將 messaging.Message 轉為 Kafka 訊息，msgID 寫入 UNIQ_KEY 標頭供 Consumer 去重
+func toKafka(msg *messaging.Message, msgID string) kafkago.Message {
+	km := kafkago.Message{Topic: TopicName(msg.Topic), Value: msg.Body}
+	if msg.ShardingKey != "" {
+		km.Key = []byte(msg.ShardingKey)
+	}
+	header := func(k, v string) {
+		km.Headers = append(km.Headers, kafkago.Header{Key: k, Value: []byte(v)})
+	}
+	header(messaging.HeaderMsgID, msgID)
+	if msg.Tag != "" {
+		header(messaging.HeaderTag, msg.Tag)
+	}
+	if len(msg.Keys) > 0 {
+		header(messaging.HeaderKeys, msg.KeysString())
+	}
+	if msg.ShardingKey != "" {
+		header(messaging.HeaderShardingKey, msg.ShardingKey)
+	}
+	for k, v := range msg.Properties {
+		if !messaging.IsReservedProperty(k) {
+			header(k, v)
+		}
+	}
+	return km
+}
+
+// fromKafka 將 Kafka 訊息轉為 messaging.Delivery，topic 為訂閱時的原始名稱
+func fromKafka(topic string, m kafkago.Message) *messaging.Delivery {
+	d := &messaging.Delivery{
+		Message: messaging.Message{Topic: topic, Body: m.Value},
+		BornAt:  m.Time,
+	}
+	for _, h := range m.Headers {
+		v := string(h.Value)
+		switch h.Key {
+		case messaging.HeaderMsgID:
+			d.MsgID = v
+		case messaging.HeaderTag:
+			d.Tag = v
+		case messaging.HeaderKeys:
+			d.Keys = strings.Fields(v)
+		case messaging.HeaderShardingKey:
+			d.ShardingKey = v
+		default:
+			d.WithProperty(h.Key, v)
+		}
+	}
+	if d.MsgID == "" {
+		// 非本套件發送的訊息以分區與 offset 作為 ID，重新投遞時不變
+		d.MsgID = fmt.Sprintf("%s-%d-%d", m.Topic, m.Partition, m.Offset)
+	}
+	if d.ShardingKey == "" && len(m.Key) > 0 {
+		d.ShardingKey = string(m.Key)
+	}
+	return d
+}
+
+// producer 實作 messaging.Producer
+type producer struct {
+	w       *kafkago.Writer
+	started atomic.Bool
+}
+
+func (p *producer) Start() error {
+	p.started.Store(true)
+	return nil
+}
+
+func (p *producer) Shutdown() error {
+	p.started.Store(false)
+	return p.w.Close()
+}
+
+func (p *producer) Send(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error) {
+	if msg.Delay > 0 {
+		return nil, messaging.ErrDelayNotSupported
+	}
+	msgID := uuid.NewString()
+	if err := p.w.WriteMessages(ctx, toKafka(msg, msgID)); err != nil {
+		return nil, err
+	}
+	return &messaging.SendResult{MsgID: msgID, Partition: -1, Offset: -1}, nil
+}
+
+func (p *producer) Started() bool {
+	return p.started.Load()
+}
+
+// Ping 向 Broker 查詢叢集 metadata
+func (p *producer) Ping(ctx context.Context) error {
+	client := &kafkago.Client{Addr: p.w.Addr, Transport: p.w.Transport}
+	_, err := client.Metadata(ctx, &kafkago.MetadataRequest{Topics: []string{}})
+	return err
+}
+
+// subscription 是一個 Topic 的訂閱，由獨立的 Reader 與 goroutine 依序處理
+type subscription struct {
+	topic   string
+	tags    map[string]bool // nil 代表訂閱全部標籤
+	handler messaging.Handler
+	reader  *kafkago.Reader
+}
+
+func (s *subscription) matches(tag string) bool {
+	return s.tags == nil || s.tags[tag]
+}
+
+// consumer 實作 messaging.Consumer
+type consumer struct {
+	cfg          configs.MessagingConfig
+	maxReconsume int
+
+	mu      sync.Mutex
+	subs    []*subscription
+	dlq     *kafkago.Writer
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+func (c *consumer) Subscribe(topic, tags string, handler messaging.Handler) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		return errors.New("Consumer 已啟動，無法再訂閱")
+	}
+
+	sub := &subscription{topic: topic, handler: handler}
+	if parsed := messaging.ParseTags(tags); parsed != nil {
+		sub.tags = make(map[string]bool, len(parsed))
+		for _, tag := range parsed {
+			sub.tags[tag] = true
+		}
+	}
+	c.subs = append(c.subs, sub)
+	return nil
+}
+
+func (c *consumer) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		return nil
+	}
+
+	kc := c.cfg.Kafka
+	dialer := &kafkago.Dialer{Timeout: 10 * time.Second, DualStack: true, ClientID: c.cfg.ProducerGroup, TLS: tlsConfig(kc)}
+	if kc.Username != "" {
+		dialer.SASLMechanism = plain.Mechanism{Username: kc.Username, Password: kc.Password}
+	}
+	startOffset := kafkago.FirstOffset
+	if kc.StartOffset == "latest" {
+		startOffset = kafkago.LastOffset
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.dlq = newWriter(c.cfg)
+	for _, sub := range c.subs {
+		sub.reader = kafkago.NewReader(kafkago.ReaderConfig{
+			Brokers:     kc.Brokers,
+			GroupID:     c.cfg.ConsumerGroup,
+			Topic:       TopicName(sub.topic),
+			Dialer:      dialer,
+			StartOffset: startOffset,
+			MaxWait:     time.Second,
+		})
+		c.wg.Add(1)
+		go c.run(ctx, sub)
+	}
+	c.started = true
+	return nil
+}
+
+// Shutdown 停止消費並關閉連線，處理中的訊息若尚未提交會在重新啟動後再次投遞
+func (c *consumer) Shutdown() error {
+	c.mu.Lock()
+	if !c.started {
+		c.mu.Unlock()
+		return nil
+	}
+	c.started = false
+	c.cancel()
+	c.mu.Unlock()
+
+	c.wg.Wait()
+	var errs []error
+	for _, sub := range c.subs {
+		errs = append(errs, sub.reader.Close())
+	}
+	errs = append(errs, c.dlq.Close())
+	return errors.Join(errs...)
+}
+
+// run 是單一訂閱的消費迴圈：取得訊息、處理 (含重試)、提交 offset
+func (c *consumer) run(ctx context.Context, sub *subscription) {
+	defer c.wg.Done()
+	for {
+		m, err := sub.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Logger.Warn("取得 Kafka 訊息失敗，稍後重試", zap.Error(err), zap.String("topic", sub.topic))
+			if !sleep(ctx, time.Second) {
+				return
+			}
+			continue
+		}
+
+		d := fromKafka(sub.topic, m)
+		if sub.matches(d.Tag) && !c.handle(ctx, sub, d) {
+			return // 行程關閉中，不提交 offset
+		}
+		if err := sub.reader.CommitMessages(ctx, m); err != nil && ctx.Err() == nil {
+			logger.Logger.Error("提交 Kafka offset 失敗", zap.Error(err), zap.String("topic", sub.topic), zap.String("msgID", d.MsgID))
+		}
+	}
+}
+
+// handle 處理訊息直到成功或轉入死信，回傳 false 代表 Consumer 關閉中
+func (c *consumer) handle(ctx context.Context, sub *subscription, d *messaging.Delivery) bool {
+	for {
+		err := invoke(ctx, sub.handler, d)
+		if err == nil {
+			return true
+		}
+		if d.ReconsumeTimes >= c.maxReconsume {
+			c.deadLetter(ctx, d)
+			return true
+		}
+
+		delay, ok := messaging.RetryDelay(err)
+		if !ok || delay <= 0 {
+			delay = messaging.DefaultRetryDelay(d.ReconsumeTimes)
+		}
+		logger.Logger.Warn("訊息處理失敗，稍後重試",
+			zap.Error(err), zap.String("topic", d.Topic), zap.String("msgID", d.MsgID),
+			zap.Int("reconsumeTimes", d.ReconsumeTimes), zap.Duration("delay", delay))
+		if !sleep(ctx, delay) {
+			return false
+		}
+		d.ReconsumeTimes++
+	}
+}
+
+// deadLetter 將超過重新消費上限的訊息轉送 %DLQ%<group>，失敗時只記錄日誌
+func (c *consumer) deadLetter(ctx context.Context, d *messaging.Delivery) {
+	dlq := d.Message.Clone()
+	dlq.Topic = messaging.DLQTopicPrefix + c.cfg.ConsumerGroup
+	dlq.WithProperty(messaging.PropertyOriginTopic, d.Topic)
+	dlq.WithProperty(messaging.PropertyOriginMsgID, d.MsgID)
+
+	logger.Logger.Warn("訊息超過最大重新消費次數，轉入死信 Topic",
+		zap.String("group", c.cfg.ConsumerGroup), zap.String("topic", d.Topic),
+		zap.String("msgID", d.MsgID), zap.Int("reconsumeTimes", d.ReconsumeTimes))
+	if err := c.dlq.WriteMessages(ctx, toKafka(dlq, uuid.NewString())); err != nil {
+		logger.Logger.Error("轉入死信 Topic 失敗", zap.Error(err), zap.String("msgID", d.MsgID))
+	}
+}
+
+// invoke 呼叫處理函式，panic 視為處理失敗
+func invoke(ctx context.Context, h messaging.Handler, d *messaging.Delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Logger.Error("訊息處理函式發生 Panic", zap.Any("error", r), zap.String("msgID", d.MsgID))
+			err = fmt.Errorf("訊息處理 panic: %v", r)
+		}
+	}()
+	return h(ctx, d)
+}
+
+// sleep 等待一段時間，ctx 取消時回傳 false
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,29 @@
+package kafka_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"microservice-mvp/pkg/messaging"
+	"microservice-mvp/pkg/messaging/kafka"
+)
+
+func TestTopicName(t *testing.T) {
+	tests := []struct {
+		topic string
+		want  string
+	}{
+		{topic: "player_events", want: "player_events"},
+		{topic: "wallet.events-v2", want: "wallet.events-v2"},
+		{topic: "%DLQ%MVP_CONSUMER_GROUP", want: "_DLQ_MVP_CONSUMER_GROUP"},
+		{topic: "玩家 事件", want: "_____"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, kafka.TopicName(tt.topic), "topic=%s", tt.topic)
+	}
+}
+
+func TestRegisteredDriver(t *testing.T) {
+	assert.Contains(t, messaging.Drivers(), messaging.DriverKafka)
+}
@@ -0,0 +1,134 @@
+package messaging
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// PropertyTraceID 是訊息中記錄來源請求 trace ID 的自訂屬性
+	PropertyTraceID = "TRACE_ID"
+	// PropertyContentType 是訊息中記錄內容編碼方式的自訂屬性，例如 application/json
+	PropertyContentType = "CONTENT_TYPE"
+	// PropertyOriginTopic 記錄死信訊息原本的 Topic
+	PropertyOriginTopic = "ORIGIN_TOPIC"
+	// PropertyOriginMsgID 記錄死信訊息原本的訊息 ID
+	PropertyOriginMsgID = "ORIGIN_MESSAGE_ID"
+)
+
+// 轉接層在不支援標籤與索引鍵的 Broker (Kafka、NATS) 上，以下列標頭傳遞對應欄位
+// 名稱與 RocketMQ 的系統屬性相同，方便跨 Broker 比對
+const (
+	HeaderTag         = "TAGS"
+	HeaderKeys        = "KEYS"
+	HeaderShardingKey = "SHARDING_KEY"
+	HeaderMsgID       = "UNIQ_KEY"
+)
+
+const (
+	// DLQTopicPrefix 是死信 Topic 的前綴，與 RocketMQ 相同 (%DLQ%<consumer group>)
+	DLQTopicPrefix = "%DLQ%"
+	// KeySeparator 是多個索引鍵合併為字串時的分隔符號
+	KeySeparator = " "
+)
+
+// reservedProperties 是 Broker 或轉接層在投遞、重試時使用的系統屬性，
+// 包含已獨立為 Message 欄位的標籤與索引鍵，以及 RocketMQ 的延遲等級、重試 Topic 等
+var reservedProperties = map[string]bool{
+	HeaderTag: true, HeaderKeys: true, HeaderShardingKey: true, HeaderMsgID: true,
+	"DELAY": true, "WAIT": true, "PGROUP": true, "RETRY_TOPIC": true, "REAL_TOPIC": true, "REAL_QID": true,
+	"MIN_OFFSET": true, "MAX_OFFSET": true, "RECONSUME_TIME": true, "MAX_RECONSUME_TIMES": true,
+	"CONSUME_START_TIME": true, "MSG_REGION": true, "TRACE_ON": true, "CLUSTER": true,
+}
+
+// IsReservedProperty 判斷屬性是否為系統屬性，應用程式不應設定，重新投遞時也不應沿用
+func IsReservedProperty(name string) bool {
+	return reservedProperties[name]
+}
+
+// DefaultDelayLevels 是 RocketMQ 預設的延遲等級 (等級 1 對應索引 0)
+var DefaultDelayLevels = []time.Duration{
+	time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute, 5 * time.Minute,
+	6 * time.Minute, 7 * time.Minute, 8 * time.Minute, 9 * time.Minute, 10 * time.Minute,
+	20 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour,
+}
+
+// DefaultRetryDelay 回傳 handler 未指定延遲時，第 n 次重新投遞 (從 0 起算) 前的等待時間
+// 與 RocketMQ 相同，從等級 3 (10s) 開始逐級退避
+func DefaultRetryDelay(reconsumeTimes int) time.Duration {
+	level := 2 + reconsumeTimes
+	if level < 0 || level >= len(DefaultDelayLevels) {
+		level = len(DefaultDelayLevels) - 1
+	}
+	return DefaultDelayLevels[level]
+}
+
+// Message 是與 Broker 無關的待發送訊息
+type Message struct {
+	Topic       string
+	Tag         string   // 單一標籤，Consumer 可依標籤過濾
+	Keys        []string // 索引鍵，用於查詢與追蹤
+	ShardingKey string   // 相同 sharding key 的訊息會落在同一佇列 (分區)，以保證順序
+	Body        []byte
+	Properties  map[string]string // 自訂屬性 (Kafka 與 NATS 以標頭傳遞)
+	Delay       time.Duration     // 延遲投遞時間，僅 RocketMQ 與 inproc 支援 (進位到最接近的延遲等級)
+}
+
+// NewMessage 建立一則帶有標籤與索引鍵的訊息
+func NewMessage(topic, tag string, body []byte, keys []string) *Message {
+	return &Message{Topic: topic, Tag: tag, Keys: keys, Body: body}
+}
+
+// WithProperty 設定自訂屬性
+func (m *Message) WithProperty(name, value string) *Message {
+	if m.Properties == nil {
+		m.Properties = make(map[string]string)
+	}
+	m.Properties[name] = value
+	return m
+}
+
+// WithShardingKey 設定 sharding key
+func (m *Message) WithShardingKey(key string) *Message {
+	m.ShardingKey = key
+	return m
+}
+
+// Property 回傳自訂屬性，不存在時為空字串
+func (m *Message) Property(name string) string {
+	return m.Properties[name]
+}
+
+// KeysString 回傳以 KeySeparator 合併的索引鍵
+func (m *Message) KeysString() string {
+	return strings.Join(m.Keys, KeySeparator)
+}
+
+// Clone 複製訊息，屬性與索引鍵不與原訊息共用
+func (m *Message) Clone() *Message {
+	cp := *m
+	cp.Keys = append([]string(nil), m.Keys...)
+	if m.Properties != nil {
+		cp.Properties = make(map[string]string, len(m.Properties))
+		for k, v := range m.Properties {
+			cp.Properties[k] = v
+		}
+	}
+	return &cp
+}
+
+// SendResult 是同步發送的結果
+type SendResult struct {
+	MsgID     string // Broker 或轉接層指定的訊息 ID，Consumer 端收到的 Delivery.MsgID 與此相同
+	Partition int    // Kafka 分區或 RocketMQ 佇列，不適用時為 -1
+	Offset    int64  // Kafka offset 或 NATS stream sequence，不適用時為 -1
+}
+
+// Delivery 是 Consumer 收到的訊息
+type Delivery struct {
+	Message
+	MsgID          string    // 同一則訊息重新投遞時不變，可作為去重鍵
+	ReconsumeTimes int       // 已重新投遞的次數，首次投遞為 0
+	BornAt         time.Time // 訊息發送時間
+}
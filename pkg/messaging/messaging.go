@@ -0,0 +1,383 @@
+// Package messaging 是與 Broker 無關的訊息收發介面：Producer 發送 Message，
+// Consumer 依 Topic 與標籤訂閱並以 Handler 處理 Delivery，失敗時由 Broker 重新投遞，
+// 超過 max_reconsume_times 後轉入死信 Topic (%DLQ%<consumer group>)。
+//
+// 各 Broker 的轉接層位於子套件 (rocketmq、kafka、nats)，於 init 時以 Register 註冊，
+// 再由 messaging.driver 設定選擇；inproc 與 stub 內建於本套件，不需要外部 Broker。
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/health"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/metrics"
+)
+
+const (
+	// DriverRocketMQ 連線 RocketMQ (需匯入 pkg/messaging/rocketmq)
+	DriverRocketMQ = "rocketmq"
+	// DriverKafka 連線 Kafka (需匯入 pkg/messaging/kafka)
+	DriverKafka = "kafka"
+	// DriverNATS 連線 NATS JetStream (需匯入 pkg/messaging/nats)
+	DriverNATS = "nats"
+	// DriverInProc 使用行程內的 Broker，不需要外部依賴，適合 memory 模式與測試
+	DriverInProc = "inproc"
+	// DriverStub 不連線任何 Broker，發送的訊息僅記錄日誌
+	DriverStub = "stub"
+)
+
+// ErrDelayNotSupported 代表 Broker 不支援延遲投遞 (Message.Delay)
+var ErrDelayNotSupported = errors.New("此 Broker 不支援延遲投遞")
+
+// Handler 處理一則訊息，回傳 nil 代表已確認
+// 回傳錯誤時訊息稍後重新投遞，以 RetryAfter 包裝可指定重新投遞前的等待時間
+type Handler func(ctx context.Context, d *Delivery) error
+
+// Producer 定義訊息發送端
+type Producer interface {
+	Start() error
+	Shutdown() error
+	Send(ctx context.Context, msg *Message) (*SendResult, error)
+	Started() bool // 為健康檢查添加
+}
+
+// Pinger 由能主動探測 Broker 的 Producer 實作，Ping 會與 Broker 往返一次請求
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Consumer 定義訊息消費端
+// 同一 Consumer Group 的多個 Consumer 競爭消費：每則訊息只會交給其中一個
+type Consumer interface {
+	Start() error
+	Shutdown() error
+	// Subscribe 訂閱 Topic，tags 為標籤表達式 (例如 "TagA || TagB")，空字串或 "*" 代表全部
+	Subscribe(topic, tags string, handler Handler) error
+}
+
+// Driver 建立特定 Broker 的 Producer 與 Consumer
+type Driver interface {
+	NewProducer(cfg configs.MessagingConfig) (Producer, error)
+	// NewConsumer 建立屬於 cfg.ConsumerGroup 的 Consumer，尚未訂閱也尚未啟動
+	NewConsumer(cfg configs.MessagingConfig) (Consumer, error)
+}
+
+// retryError 要求 Broker 在指定時間後重新投遞
+type retryError struct {
+	delay time.Duration
+	err   error
+}
+
+func (e *retryError) Error() string { return e.err.Error() }
+func (e *retryError) Unwrap() error { return e.err }
+
+// RetryAfter 包裝 handler 的錯誤，要求 Broker 在 delay 後重新投遞
+// RocketMQ 與 inproc 會進位到最接近的延遲等級；err 為 nil 時仍視為需要重試
+func RetryAfter(delay time.Duration, err error) error {
+	if err == nil {
+		err = errors.New("稍後重試")
+	}
+	return &retryError{delay: delay, err: err}
+}
+
+// RetryDelay 取出 RetryAfter 指定的等待時間
+func RetryDelay(err error) (time.Duration, bool) {
+	var re *retryError
+	if errors.As(err, &re) {
+		return re.delay, true
+	}
+	return 0, false
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Driver)
+)
+
+// Register 註冊 Broker 轉接層，通常在子套件的 init 中呼叫
+func Register(name string, d Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if _, dup := drivers[name]; dup {
+		panic("messaging: 重複註冊 driver " + name)
+	}
+	drivers[name] = d
+}
+
+// Drivers 回傳已註冊 (含內建) 的 driver 名稱
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := []string{DriverInProc, DriverStub}
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// driverFor 依設定取得 driver，inproc 與 stub 為內建實作
+func driverFor(cfg configs.MessagingConfig) (Driver, error) {
+	switch cfg.Driver {
+	case "":
+		// 不以 stub 作為預設值：stub 會讓 outbox 將事件標記為已發送，漏設時應啟動失敗而非靜默丟失事件
+		return nil, fmt.Errorf("未設定訊息佇列 driver (messaging.driver，可用: %s)", strings.Join(Drivers(), ", "))
+	case DriverStub:
+		return stubDriver{}, nil
+	case DriverInProc:
+		return inprocDriver{}, nil
+	}
+
+	driversMu.RLock()
+	d, ok := drivers[cfg.Driver]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("無效或未註冊的訊息佇列 driver: %q (可用: %s)", cfg.Driver, strings.Join(Drivers(), ", "))
+	}
+	return d, nil
+}
+
+// ProducerClient 是全域 Producer 客戶端
+var ProducerClient Producer
+
+// producerDriver 是全域 Producer 使用的 driver 名稱，記錄於發送 span
+var producerDriver string
+
+// ConsumerClient 是全域 Consumer 客戶端
+var ConsumerClient Consumer
+
+// asyncSends 追蹤 SendMessageAsync 尚未完成的發送，GracefulShutdown 會等待它們完成後才關閉 Producer
+var (
+	asyncMu     sync.Mutex
+	asyncSends  sync.WaitGroup
+	asyncClosed bool // GracefulShutdown 開始後拒絕新的非同步發送
+)
+
+// inprocBroker 是 inproc 模式下 Producer 與 Consumer 共用的行程內 Broker
+var (
+	inprocMu     sync.Mutex
+	inprocBroker *InProcBroker
+)
+
+// sharedInProcBroker 回傳 inproc 模式共用的 Broker，首次呼叫時建立
+func sharedInProcBroker(cfg configs.MessagingConfig) *InProcBroker {
+	inprocMu.Lock()
+	defer inprocMu.Unlock()
+	if inprocBroker == nil {
+		inprocBroker = NewInProcBroker(InProcOptions{MaxReconsumeTimes: cfg.MaxReconsumeTimes})
+	}
+	return inprocBroker
+}
+
+// inprocDriver 以共用的行程內 Broker 建立 Producer 與 Consumer
+type inprocDriver struct{}
+
+func (inprocDriver) NewProducer(cfg configs.MessagingConfig) (Producer, error) {
+	return sharedInProcBroker(cfg).Producer(), nil
+}
+
+func (inprocDriver) NewConsumer(cfg configs.MessagingConfig) (Consumer, error) {
+	return sharedInProcBroker(cfg).NewConsumer(cfg.ConsumerGroup), nil
+}
+
+// InitProducer 依 messaging.driver 初始化並啟動全域 Producer 客戶端
+func InitProducer(cfg configs.MessagingConfig) (Producer, error) {
+	d, err := driverFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p, err := d.NewProducer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("建立 %s Producer 失敗: %w", cfg.Driver, err)
+	}
+	if err := p.Start(); err != nil {
+		return nil, fmt.Errorf("啟動 %s Producer 失敗: %w", cfg.Driver, err)
+	}
+
+	ProducerClient = p
+	producerDriver = cfg.Driver
+	asyncMu.Lock()
+	asyncClosed = false
+	asyncMu.Unlock()
+	health.Register(health.NewChecker("messaging", func(ctx context.Context) health.Result {
+		if err := Ping(ctx); err != nil {
+			return health.Result{Status: health.StatusDown, Message: err.Error(), Details: cfg.Driver}
+		}
+		return health.Result{Status: health.StatusUp, Details: cfg.Driver}
+	}), health.Options{Critical: true})
+	logger.Named("messaging").Info("訊息佇列 Producer 初始化完成",
+		zap.String("driver", cfg.Driver),
+		zap.String("group", cfg.ProducerGroup),
+	)
+	return ProducerClient, nil
+}
+
+// InitConsumer 初始化全域 Consumer 客戶端，並以 handler 訂閱配置中的所有 Topic
+func InitConsumer(cfg configs.MessagingConfig, handler Handler) (Consumer, error) {
+	c, err := NewConsumer(cfg, handler)
+	if err != nil {
+		return nil, err
+	}
+	ConsumerClient = c
+	return ConsumerClient, nil
+}
+
+// NewConsumer 建立並啟動一個以 handler 訂閱配置中所有 Topic 的 Consumer，但不設定全域客戶端
+// 用於同一行程內以不同 Consumer Group 獨立消費 (例如 webhook 投遞)，呼叫端須自行 Shutdown
+func NewConsumer(cfg configs.MessagingConfig, handler Handler) (Consumer, error) {
+	d, err := driverFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c, err := d.NewConsumer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("建立 %s Consumer 失敗: %w", cfg.Driver, err)
+	}
+
+	for _, sub := range cfg.Subscriptions {
+		if err := c.Subscribe(sub.Topic, sub.Tags, handler); err != nil {
+			return nil, fmt.Errorf("訂閱 Topic %s 失敗: %w", sub.Topic, err)
+		}
+	}
+	if err := c.Start(); err != nil {
+		return nil, fmt.Errorf("啟動 %s Consumer 失敗: %w", cfg.Driver, err)
+	}
+
+	logger.Named("messaging").Info("訊息佇列 Consumer 初始化完成",
+		zap.String("driver", cfg.Driver),
+		zap.String("group", cfg.ConsumerGroup),
+		zap.Int("subscriptions", len(cfg.Subscriptions)),
+	)
+	return c, nil
+}
+
+// SendMessage 同步發送訊息
+func SendMessage(ctx context.Context, topic string, payload []byte, keys []string) (*SendResult, error) {
+	return Send(ctx, NewMessage(topic, "", payload, keys))
+}
+
+// Send 以全域 Producer 同步發送一則已組好的訊息
+// 發送時建立 producer span，並以 traceparent/tracestate 屬性將 trace context 傳給消費端
+func Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	if ProducerClient == nil {
+		return nil, fmt.Errorf("訊息佇列 Producer 尚未初始化")
+	}
+
+	ctx, span := startPublishSpan(ctx, msg)
+	defer span.End()
+	log := logger.FromContext(ctx).Named("messaging")
+
+	result, err := ProducerClient.Send(ctx, msg)
+	metrics.ObserveMQSend(msg.Topic, err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("訊息發送失敗", zap.Error(err), zap.String("topic", msg.Topic))
+		return nil, fmt.Errorf("發送訊息失敗: %w", err)
+	}
+	span.SetAttributes(semconv.MessagingMessageID(result.MsgID))
+
+	log.Info("訊息已發送",
+		zap.String("topic", msg.Topic),
+		zap.String("tag", msg.Tag),
+		zap.String("msgID", result.MsgID),
+		zap.String("payload", logger.RedactPayload(msg.Body)), // 依 logger.redact 遮蔽敏感欄位
+	)
+	return result, nil
+}
+
+// SendCallback 是非同步發送完成時的回呼
+type SendCallback func(ctx context.Context, result *SendResult, err error)
+
+// SendMessageAsync 非同步發送訊息，結果透過 callback 通知
+// 發送使用不隨 ctx 取消的上下文 (保留 trace 等值)，呼叫端的請求結束後仍會完成；GracefulShutdown 會等待發送完成
+func SendMessageAsync(ctx context.Context, topic string, payload []byte, keys []string, callback SendCallback) error {
+	if ProducerClient == nil {
+		return fmt.Errorf("訊息佇列 Producer 尚未初始化")
+	}
+
+	asyncMu.Lock()
+	if asyncClosed {
+		asyncMu.Unlock()
+		return fmt.Errorf("訊息佇列 Producer 正在關閉")
+	}
+	asyncSends.Add(1)
+	asyncMu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer asyncSends.Done()
+		result, err := Send(ctx, NewMessage(topic, "", payload, keys))
+		if callback != nil {
+			callback(ctx, result, err)
+		}
+	}()
+	return nil
+}
+
+// Ping 探測全域 Producer 與 Broker 的連線，未實作 Pinger 的 Producer (inproc、stub) 只檢查是否已啟動
+func Ping(ctx context.Context) error {
+	if ProducerClient == nil {
+		return fmt.Errorf("訊息佇列 Producer 尚未初始化")
+	}
+	if !ProducerClient.Started() {
+		return fmt.Errorf("訊息佇列 Producer 未啟動")
+	}
+	if p, ok := ProducerClient.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// GracefulShutdown 關閉全域訊息佇列客戶端
+// Consumer 先關閉 (其 handler 可能仍在非同步發送)，等待非同步發送完成後才關閉 Producer
+func GracefulShutdown() {
+	if ConsumerClient != nil {
+		if err := ConsumerClient.Shutdown(); err != nil {
+			logger.Named("messaging").Error("關閉訊息佇列 Consumer 失敗", zap.Error(err))
+		}
+	}
+	asyncMu.Lock()
+	asyncClosed = true
+	asyncMu.Unlock()
+	asyncSends.Wait()
+	if ProducerClient != nil {
+		if err := ProducerClient.Shutdown(); err != nil {
+			logger.Named("messaging").Error("關閉訊息佇列 Producer 失敗", zap.Error(err))
+		}
+	}
+	inprocMu.Lock()
+	if inprocBroker != nil {
+		inprocBroker.Close()
+		inprocBroker = nil
+	}
+	inprocMu.Unlock()
+	logger.Named("messaging").Info("訊息佇列客戶端已關閉")
+}
+
+// ParseTags 解析標籤表達式，"*" 或空字串回傳 nil 代表全部標籤
+func ParseTags(expression string) []string {
+	expression = strings.TrimSpace(expression)
+	if expression == "" || expression == "*" {
+		return nil
+	}
+	var tags []string
+	for _, tag := range strings.Split(expression, "||") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,224 @@
+package messaging_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+	"microservice-mvp/pkg/tracing"
+)
+
+func TestInitProducer(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	t.Cleanup(messaging.GracefulShutdown)
+
+	tests := []struct {
+		name          string
+		driver        string
+		expectedError string
+	}{
+		{name: "Stub", driver: messaging.DriverStub},
+		{name: "InProc", driver: messaging.DriverInProc},
+		{name: "UnregisteredDriver", driver: messaging.DriverKafka, expectedError: "無效或未註冊的訊息佇列 driver"},
+		{name: "InvalidDriver", driver: "amqp", expectedError: "無效或未註冊的訊息佇列 driver"},
+		{name: "MissingDriver", driver: "", expectedError: "未設定訊息佇列 driver"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := messaging.InitProducer(configs.MessagingConfig{Driver: tt.driver})
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, p.Started())
+		})
+	}
+}
+
+func TestSendMessage_Stub(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	_, err := messaging.InitProducer(configs.MessagingConfig{Driver: messaging.DriverStub})
+	require.NoError(t, err)
+
+	result, err := messaging.SendMessage(context.Background(), "player_events", []byte(`{"id":1}`), []string{"1"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.MsgID)
+
+	done := make(chan error, 1)
+	err = messaging.SendMessageAsync(context.Background(), "player_events", []byte(`{"id":1}`), nil,
+		func(ctx context.Context, result *messaging.SendResult, err error) { done <- err })
+	require.NoError(t, err)
+	assert.NoError(t, <-done)
+}
+
+func TestSend_InjectsTraceContext(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	_, err := messaging.InitProducer(configs.MessagingConfig{Driver: messaging.DriverStub})
+	require.NoError(t, err)
+
+	ctx := tracing.ContextWithTraceParent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	msg := messaging.NewMessage("player_events", "player.registered", []byte(`{"id":1}`), nil)
+	_, err = messaging.Send(ctx, msg)
+	require.NoError(t, err)
+	assert.Contains(t, msg.Property(messaging.PropertyTraceParent), "4bf92f3577b34da6a3ce929d0e0e4736", "發送時應寫入 traceparent 屬性")
+}
+
+func TestInitConsumer_Stub(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+
+	handler := func(ctx context.Context, d *messaging.Delivery) error { return nil }
+	c, err := messaging.InitConsumer(configs.MessagingConfig{
+		Driver:        messaging.DriverStub,
+		Subscriptions: []configs.SubscriptionConfig{{Topic: "player_events"}},
+	}, handler)
+	require.NoError(t, err)
+	assert.NoError(t, c.Shutdown())
+}
+
+func TestInProcDriver_EndToEnd(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	t.Cleanup(messaging.GracefulShutdown)
+
+	cfg := configs.MessagingConfig{
+		Driver:        messaging.DriverInProc,
+		ConsumerGroup: "CID_Test",
+		Subscriptions: []configs.SubscriptionConfig{{Topic: "player_events", Tags: "player.registered"}},
+	}
+	_, err := messaging.InitProducer(cfg)
+	require.NoError(t, err)
+
+	got := make(chan *messaging.Delivery, 1)
+	_, err = messaging.InitConsumer(cfg, func(ctx context.Context, d *messaging.Delivery) error {
+		got <- d
+		return nil
+	})
+	require.NoError(t, err)
+
+	msg := messaging.NewMessage("player_events", "player.registered", []byte(`{"id":7}`), []string{"ev-1", "7"}).
+		WithShardingKey("7").
+		WithProperty(messaging.PropertyTraceID, "trace-1")
+	result, err := messaging.Send(context.Background(), msg)
+	require.NoError(t, err)
+
+	select {
+	case d := <-got:
+		assert.Equal(t, result.MsgID, d.MsgID)
+		assert.Equal(t, "player.registered", d.Tag)
+		assert.Equal(t, "ev-1 7", d.KeysString())
+		assert.Equal(t, "7", d.ShardingKey)
+		assert.Equal(t, "trace-1", d.Property(messaging.PropertyTraceID))
+		assert.Zero(t, d.ReconsumeTimes)
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待訊息逾時")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	cause := errors.New("下游暫時無法使用")
+	err := messaging.RetryAfter(5*time.Second, cause)
+
+	delay, ok := messaging.RetryDelay(err)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, delay)
+	assert.ErrorIs(t, err, cause)
+
+	_, ok = messaging.RetryDelay(cause)
+	assert.False(t, ok)
+	assert.Equal(t, 10*time.Second, messaging.DefaultRetryDelay(0))
+	assert.Equal(t, 2*time.Hour, messaging.DefaultRetryDelay(100))
+}
+
+// blockingDriver 建立的 Producer 在 release 關閉前不會完成發送，用於驗證非同步發送的生命週期
+type blockingDriver struct {
+	producer *blockingProducer
+}
+
+var blocking = &blockingDriver{}
+
+func init() {
+	messaging.Register("blocking-test", blocking)
+}
+
+func (d *blockingDriver) NewProducer(cfg configs.MessagingConfig) (messaging.Producer, error) {
+	return d.producer, nil
+}
+
+func (d *blockingDriver) NewConsumer(cfg configs.MessagingConfig) (messaging.Consumer, error) {
+	return nil, errors.New("不支援")
+}
+
+type blockingProducer struct {
+	release  chan struct{}
+	sendErr  chan error  // 發送時上下文的錯誤
+	traceIDs chan string // 發送時上下文的 trace ID
+	shutdown chan struct{}
+}
+
+func (p *blockingProducer) Start() error  { return nil }
+func (p *blockingProducer) Started() bool { return true }
+
+func (p *blockingProducer) Shutdown() error {
+	close(p.shutdown)
+	return nil
+}
+
+func (p *blockingProducer) Send(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error) {
+	<-p.release
+	p.sendErr <- ctx.Err()
+	p.traceIDs <- logger.TraceIDFromContext(ctx)
+	return &messaging.SendResult{MsgID: "blocking-1"}, nil
+}
+
+func TestSendMessageAsync_DrainedOnShutdown(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	producer := &blockingProducer{
+		release:  make(chan struct{}),
+		sendErr:  make(chan error, 1),
+		traceIDs: make(chan string, 1),
+		shutdown: make(chan struct{}),
+	}
+	blocking.producer = producer
+	_, err := messaging.InitProducer(configs.MessagingConfig{Driver: "blocking-test"})
+	require.NoError(t, err)
+
+	// 請求結束後上下文被取消，非同步發送仍應完成並保留 trace ID
+	ctx, cancel := context.WithCancel(logger.WithTraceID(context.Background(), "trace-async"))
+	done := make(chan error, 1)
+	require.NoError(t, messaging.SendMessageAsync(ctx, "player_events", []byte(`{"id":1}`), nil,
+		func(ctx context.Context, result *messaging.SendResult, err error) { done <- err }))
+	cancel()
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		messaging.GracefulShutdown()
+		close(shutdownDone)
+	}()
+
+	select {
+	case <-producer.shutdown:
+		t.Fatal("Producer 不應在非同步發送完成前關閉")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(producer.release)
+	assert.NoError(t, <-producer.sendErr, "非同步發送不應沿用已取消的上下文")
+	assert.Equal(t, "trace-async", <-producer.traceIDs)
+	assert.NoError(t, <-done)
+
+	select {
+	case <-shutdownDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待關閉逾時")
+	}
+	err = messaging.SendMessageAsync(context.Background(), "player_events", []byte(`{"id":2}`), nil, nil)
+	assert.ErrorContains(t, err, "正在關閉")
+}
@@ -0,0 +1,430 @@
+// Package nats 是 pkg/messaging 的 NATS JetStream 轉接層，匯入後以 messaging.driver: nats 啟用
+//
+// 每個 Topic 對應一個 Stream (名稱不合法的字元以 '_' 取代)，主題為 "<topic>.<tag>"，沒有標籤時為 "<topic>._"，
+// 因此標籤過濾由伺服器依 filter subject 完成。Stream 不存在時自動建立，已存在的 Stream 不會被修改。
+// Consumer Group 對應 durable consumer，同一 durable 的多個連線競爭消費；
+// 處理失敗以 NakWithDelay 重新投遞，超過 max_reconsume_times 後轉送死信 Topic 並終止該訊息。
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+// emptyTagToken 是沒有標籤的訊息在主題中使用的標籤
+const emptyTagToken = "_"
+
+func init() {
+	messaging.Register(messaging.DriverNATS, driver{})
+}
+
+// driver 實作 messaging.Driver
+type driver struct{}
+
+func (driver) NewProducer(cfg configs.MessagingConfig) (messaging.Producer, error) {
+	return &producer{client: newClient(cfg, cfg.ProducerGroup)}, nil
+}
+
+func (driver) NewConsumer(cfg configs.MessagingConfig) (messaging.Consumer, error) {
+	if cfg.ConsumerGroup == "" {
+		return nil, errors.New("未設定 messaging.consumer_group")
+	}
+	c := &consumer{client: newClient(cfg, cfg.ConsumerGroup), cfg: cfg, maxReconsume: cfg.MaxReconsumeTimes}
+	if c.maxReconsume <= 0 {
+		c.maxReconsume = 16
+	}
+	return c, nil
+}
+
+// StreamName 回傳 Topic 對應的 Stream 名稱
+func StreamName(topic string) string {
+	return sanitize(topic, func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-'
+	})
+}
+
+// Subject 回傳 Topic 與標籤對應的主題
+func Subject(topic, tag string) string {
+	if tag == "" {
+		tag = emptyTagToken
+	}
+	return subjectToken(topic) + "." + subjectToken(tag)
+}
+
+// subjectToken 移除主題中不允許的萬用字元與空白
+func subjectToken(s string) string {
+	return sanitize(s, func(r rune) bool {
+		return r != '*' && r != '>' && r > ' ' && r != 0x7f
+	})
+}
+
+func sanitize(s string, valid func(rune) bool) string {
+	return strings.Map(func(r rune) rune {
+		if valid(r) {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// client 管理連線並確保 Topic 對應的 Stream 存在
+type client struct {
+	cfg  configs.MessagingConfig
+	name string
+
+	nc      *natsgo.Conn
+	js      jetstream.JetStream
+	streams sync.Map // stream 名稱 -> struct{}
+}
+
+func newClient(cfg configs.MessagingConfig, name string) *client {
+	return &client{cfg: cfg, name: name}
+}
+
+func (c *client) connect() error {
+	nc := c.cfg.NATS
+	url := nc.URL
+	if url == "" {
+		url = natsgo.DefaultURL
+	}
+	opts := []natsgo.Option{natsgo.Name(c.name), natsgo.MaxReconnects(-1)}
+	if nc.CredsFile != "" {
+		opts = append(opts, natsgo.UserCredentials(nc.CredsFile))
+	}
+	if nc.Token != "" {
+		opts = append(opts, natsgo.Token(nc.Token))
+	}
+
+	conn, err := natsgo.Connect(url, opts...)
+	if err != nil {
+		return err
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	c.nc, c.js = conn, js
+	return nil
+}
+
+func (c *client) close() {
+	if c.nc != nil {
+		c.nc.Close()
+	}
+}
+
+// ensureStream 確保 Topic 對應的 Stream 存在，回傳 Stream 名稱
+func (c *client) ensureStream(ctx context.Context, topic string) (string, error) {
+	name := StreamName(topic)
+	if _, ok := c.streams.Load(name); ok {
+		return name, nil
+	}
+
+	_, err := c.js.Stream(ctx, name)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		nc := c.cfg.NATS
+		_, err = c.js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:     name,
+			Subjects: []string{subjectToken(topic) + ".>"},
+			Replicas: max(nc.StreamReplicas, 1),
+			MaxAge:   time.Duration(nc.StreamMaxAgeHours) * time.Hour,
+		})
+		if errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+			err = nil // 其他實例同時建立
+		}
+		if err == nil {
+			logger.Logger.Info("已建立 NATS Stream", zap.String("stream", name), zap.String("topic", topic))
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("確認 Stream %s 失敗: %w", name, err)
+	}
+	c.streams.Store(name, struct{}{})
+	return name, nil
+}
+
+// publish 發送訊息，msgID 同時作為 JetStream 的去重 ID
+func (c *client) publish(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error) {
+	if msg.Delay > 0 {
+		return nil, messaging.ErrDelayNotSupported
+	}
+	if _, err := c.ensureStream(ctx, msg.Topic); err != nil {
+		return nil, err
+	}
+	if timeout := c.cfg.NATS.SendTimeoutMs; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+		defer cancel()
+	}
+
+	msgID := uuid.NewString()
+	ack, err := c.js.PublishMsg(ctx, toNATS(msg), jetstream.WithMsgID(msgID))
+	if err != nil {
+		return nil, err
+	}
+	return &messaging.SendResult{MsgID: msgID, Partition: -1, Offset: int64(ack.Sequence)}, nil
+}
+
+// toNATS 將 messaging.Message 轉為 NATS 訊息，標籤、索引鍵與自訂屬性以標頭傳遞
+func toNATS(msg *messaging.Message) *natsgo.Msg {
+	m := natsgo.NewMsg(Subject(msg.Topic, msg.Tag))
+	m.Data = msg.Body
+	if msg.Tag != "" {
+		m.Header.Set(messaging.HeaderTag, msg.Tag)
+	}
+	if len(msg.Keys) > 0 {
+		m.Header.Set(messaging.HeaderKeys, msg.KeysString())
+	}
+	if msg.ShardingKey != "" {
+		m.Header.Set(messaging.HeaderShardingKey, msg.ShardingKey)
+	}
+	for k, v := range msg.Properties {
+		if !messaging.IsReservedProperty(k) {
+			m.Header.Set(k, v)
+		}
+	}
+	return m
+}
+
+// fromNATS 將 JetStream 訊息轉為 messaging.Delivery
+func fromNATS(topic, stream string, m jetstream.Msg) *messaging.Delivery {
+	d := &messaging.Delivery{Message: messaging.Message{Topic: topic, Body: m.Data()}}
+	for k, values := range m.Headers() {
+		if len(values) == 0 {
+			continue
+		}
+		v := values[0]
+		switch k {
+		case natsgo.MsgIdHdr:
+			d.MsgID = v
+		case messaging.HeaderTag:
+			d.Tag = v
+		case messaging.HeaderKeys:
+			d.Keys = strings.Fields(v)
+		case messaging.HeaderShardingKey:
+			d.ShardingKey = v
+		default:
+			if !strings.HasPrefix(k, "Nats-") {
+				d.WithProperty(k, v)
+			}
+		}
+	}
+	if meta, err := m.Metadata(); err == nil {
+		if meta.NumDelivered > 0 {
+			d.ReconsumeTimes = int(meta.NumDelivered - 1)
+		}
+		d.BornAt = meta.Timestamp
+		if d.MsgID == "" {
+			// 非本套件發送的訊息以 Stream 序號作為 ID，重新投遞時不變
+			d.MsgID = fmt.Sprintf("%s:%d", stream, meta.Sequence.Stream)
+		}
+	}
+	return d
+}
+
+// producer 實作 messaging.Producer
+type producer struct {
+	client  *client
+	started atomic.Bool
+}
+
+func (p *producer) Start() error {
+	if err := p.client.connect(); err != nil {
+		return err
+	}
+	p.started.Store(true)
+	return nil
+}
+
+func (p *producer) Shutdown() error {
+	p.started.Store(false)
+	p.client.close()
+	return nil
+}
+
+func (p *producer) Send(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error) {
+	return p.client.publish(ctx, msg)
+}
+
+func (p *producer) Started() bool {
+	return p.started.Load() && p.client.nc.IsConnected()
+}
+
+// Ping 查詢 JetStream 帳戶資訊，確認連線與 JetStream 皆可用
+func (p *producer) Ping(ctx context.Context) error {
+	_, err := p.client.js.AccountInfo(ctx)
+	return err
+}
+
+// subscription 是一個 Topic 的訂閱
+type subscription struct {
+	topic   string
+	tags    []string // nil 代表訂閱全部標籤
+	handler messaging.Handler
+}
+
+// consumer 實作 messaging.Consumer
+type consumer struct {
+	client       *client
+	cfg          configs.MessagingConfig
+	maxReconsume int
+
+	mu       sync.Mutex
+	subs     []*subscription
+	contexts []jetstream.ConsumeContext
+	started  bool
+}
+
+func (c *consumer) Subscribe(topic, tags string, handler messaging.Handler) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		return errors.New("Consumer 已啟動，無法再訂閱")
+	}
+	c.subs = append(c.subs, &subscription{topic: topic, tags: messaging.ParseTags(tags), handler: handler})
+	return nil
+}
+
+func (c *consumer) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started {
+		return nil
+	}
+	if err := c.client.connect(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, sub := range c.subs {
+		stream, err := c.client.ensureStream(ctx, sub.topic)
+		if err != nil {
+			c.stop()
+			return err
+		}
+
+		cc := jetstream.ConsumerConfig{
+			Durable:    StreamName(c.cfg.ConsumerGroup),
+			AckPolicy:  jetstream.AckExplicitPolicy,
+			MaxDeliver: -1, // 由轉接層依 max_reconsume_times 轉送死信
+		}
+		if c.cfg.NATS.AckWaitMs > 0 {
+			cc.AckWait = time.Duration(c.cfg.NATS.AckWaitMs) * time.Millisecond
+		}
+		switch {
+		case len(sub.tags) == 0:
+			cc.FilterSubject = subjectToken(sub.topic) + ".>"
+		case len(sub.tags) == 1:
+			cc.FilterSubject = Subject(sub.topic, sub.tags[0])
+		default:
+			for _, tag := range sub.tags {
+				cc.FilterSubjects = append(cc.FilterSubjects, Subject(sub.topic, tag))
+			}
+		}
+
+		cons, err := c.client.js.CreateOrUpdateConsumer(ctx, stream, cc)
+		if err != nil {
+			c.stop()
+			return fmt.Errorf("建立 durable consumer 失敗 (stream=%s): %w", stream, err)
+		}
+		sub, stream := sub, stream
+		consumeCtx, err := cons.Consume(func(m jetstream.Msg) { c.handle(sub, stream, m) })
+		if err != nil {
+			c.stop()
+			return err
+		}
+		c.contexts = append(c.contexts, consumeCtx)
+	}
+	c.started = true
+	return nil
+}
+
+// Shutdown 停止消費並關閉連線，未確認的訊息在 ack_wait 後由其他成員接手
+func (c *consumer) Shutdown() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stop()
+	c.started = false
+	return nil
+}
+
+func (c *consumer) stop() {
+	for _, cc := range c.contexts {
+		cc.Stop()
+	}
+	c.contexts = nil
+	c.client.close()
+}
+
+// handle 處理一則訊息並確認、延遲重投或轉入死信
+func (c *consumer) handle(sub *subscription, stream string, m jetstream.Msg) {
+	d := fromNATS(sub.topic, stream, m)
+	err := invoke(sub.handler, d)
+	if err == nil {
+		if err := m.Ack(); err != nil {
+			logger.Logger.Warn("確認 NATS 訊息失敗", zap.Error(err), zap.String("msgID", d.MsgID))
+		}
+		return
+	}
+
+	if d.ReconsumeTimes >= c.maxReconsume {
+		if c.deadLetter(d) {
+			_ = m.Term()
+			return
+		}
+	}
+
+	delay, ok := messaging.RetryDelay(err)
+	if !ok || delay <= 0 {
+		delay = messaging.DefaultRetryDelay(d.ReconsumeTimes)
+	}
+	logger.Logger.Warn("訊息處理失敗，稍後重投",
+		zap.Error(err), zap.String("topic", d.Topic), zap.String("msgID", d.MsgID),
+		zap.Int("reconsumeTimes", d.ReconsumeTimes), zap.Duration("delay", delay))
+	if err := m.NakWithDelay(delay); err != nil {
+		logger.Logger.Warn("NATS 訊息重投失敗", zap.Error(err), zap.String("msgID", d.MsgID))
+	}
+}
+
+// deadLetter 將超過重新消費上限的訊息轉送 %DLQ%<group>，回傳是否成功
+func (c *consumer) deadLetter(d *messaging.Delivery) bool {
+	dlq := d.Message.Clone()
+	dlq.Topic = messaging.DLQTopicPrefix + c.cfg.ConsumerGroup
+	dlq.WithProperty(messaging.PropertyOriginTopic, d.Topic)
+	dlq.WithProperty(messaging.PropertyOriginMsgID, d.MsgID)
+
+	logger.Logger.Warn("訊息超過最大重新消費次數，轉入死信 Topic",
+		zap.String("group", c.cfg.ConsumerGroup), zap.String("topic", d.Topic),
+		zap.String("msgID", d.MsgID), zap.Int("reconsumeTimes", d.ReconsumeTimes))
+	if _, err := c.client.publish(context.Background(), dlq); err != nil {
+		logger.Logger.Error("轉入死信 Topic 失敗，稍後重投", zap.Error(err), zap.String("msgID", d.MsgID))
+		return false
+	}
+	return true
+}
+
+// invoke 呼叫處理函式，panic 視為處理失敗
+func invoke(h messaging.Handler, d *messaging.Delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Logger.Error("訊息處理函式發生 Panic", zap.Any("error", r), zap.String("msgID", d.MsgID))
+			err = fmt.Errorf("訊息處理 panic: %v", r)
+		}
+	}()
+	return h(context.Background(), d)
+}
@@ -0,0 +1,46 @@
+package nats_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"microservice-mvp/pkg/messaging"
+	"microservice-mvp/pkg/messaging/nats"
+)
+
+func TestStreamName(t *testing.T) {
+	tests := []struct {
+		topic string
+		want  string
+	}{
+		{topic: "player_events", want: "player_events"},
+		{topic: "wallet.events", want: "wallet_events"}, // Stream 名稱不可含 '.'
+		{topic: "%DLQ%MVP_CONSUMER_GROUP", want: "_DLQ_MVP_CONSUMER_GROUP"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, nats.StreamName(tt.topic), "topic=%s", tt.topic)
+	}
+}
+
+func TestSubject(t *testing.T) {
+	tests := []struct {
+		name  string
+		topic string
+		tag   string
+		want  string
+	}{
+		{name: "WithTag", topic: "player_events", tag: "player.registered", want: "player_events.player.registered"},
+		{name: "EmptyTag", topic: "player_events", tag: "", want: "player_events._"},
+		{name: "Wildcards", topic: "events>", tag: "a *b", want: "events_.a__b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nats.Subject(tt.topic, tt.tag))
+		})
+	}
+}
+
+func TestRegisteredDriver(t *testing.T) {
+	assert.Contains(t, messaging.Drivers(), messaging.DriverNATS)
+}
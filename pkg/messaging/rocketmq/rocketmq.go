@@ -0,0 +1,259 @@
+// Package rocketmq 是 pkg/messaging 的 RocketMQ 轉接層，匯入後以 messaging.driver: rocketmq 啟用
+// 標籤、索引鍵、sharding key 與延遲等級直接對應 RocketMQ 的原生功能，重試與死信由 Broker 處理
+package rocketmq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	rmq "github.com/apache/rocketmq-client-go/v2"
+	"github.com/apache/rocketmq-client-go/v2/admin"
+	"github.com/apache/rocketmq-client-go/v2/consumer"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/apache/rocketmq-client-go/v2/producer"
+	"github.com/apache/rocketmq-client-go/v2/rlog"
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/messaging"
+)
+
+func init() {
+	// rocketmq-client-go 預設輸出大量 info 日誌，僅保留警告以上
+	rlog.SetLogLevel("warn")
+	messaging.Register(messaging.DriverRocketMQ, driver{})
+}
+
+// driver 實作 messaging.Driver
+type driver struct{}
+
+func (driver) NewProducer(cfg configs.MessagingConfig) (messaging.Producer, error) {
+	rc := cfg.RocketMQ
+	opts := []producer.Option{
+		producer.WithNameServer(nameServers(rc.NameSrvAddr)),
+		producer.WithGroupName(cfg.ProducerGroup),
+		producer.WithRetry(rc.Retries),
+	}
+	if rc.SendTimeoutMs > 0 {
+		opts = append(opts, producer.WithSendMsgTimeout(time.Duration(rc.SendTimeoutMs)*time.Millisecond))
+	}
+	if rc.Namespace != "" {
+		opts = append(opts, producer.WithNamespace(rc.Namespace))
+	}
+	if rc.AccessKey != "" {
+		opts = append(opts, producer.WithCredentials(primitive.Credentials{AccessKey: rc.AccessKey, SecretKey: rc.SecretKey}))
+	}
+
+	p, err := rmq.NewProducer(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// admin 客戶端只用於健康檢查時向 NameServer 查詢
+	adminOpts := []admin.AdminOption{admin.WithResolver(primitive.NewPassthroughResolver(nameServers(rc.NameSrvAddr)))}
+	if rc.Namespace != "" {
+		adminOpts = append(adminOpts, admin.WithNamespace(rc.Namespace))
+	}
+	if rc.AccessKey != "" {
+		adminOpts = append(adminOpts, admin.WithCredentials(primitive.Credentials{AccessKey: rc.AccessKey, SecretKey: rc.SecretKey}))
+	}
+	a, err := admin.NewAdmin(adminOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("建立 RocketMQ admin 客戶端失敗: %w", err)
+	}
+	logger.Logger.Info("RocketMQ Producer 已建立",
+		zap.String("namesrv", rc.NameSrvAddr),
+		zap.Int("retries", rc.Retries),
+	)
+	return &rocketProducer{p: p, admin: a}, nil
+}
+
+func (driver) NewConsumer(cfg configs.MessagingConfig) (messaging.Consumer, error) {
+	rc := cfg.RocketMQ
+	opts := []consumer.Option{
+		consumer.WithNameServer(nameServers(rc.NameSrvAddr)),
+		consumer.WithGroupName(cfg.ConsumerGroup),
+		consumer.WithConsumerModel(consumer.Clustering),
+		consumer.WithRetry(rc.Retries),
+	}
+	if cfg.MaxReconsumeTimes > 0 {
+		opts = append(opts, consumer.WithMaxReconsumeTimes(int32(cfg.MaxReconsumeTimes)))
+	}
+	if rc.Namespace != "" {
+		opts = append(opts, consumer.WithNamespace(rc.Namespace))
+	}
+	if rc.AccessKey != "" {
+		opts = append(opts, consumer.WithCredentials(primitive.Credentials{AccessKey: rc.AccessKey, SecretKey: rc.SecretKey}))
+	}
+
+	pc, err := rmq.NewPushConsumer(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &rocketConsumer{c: pc}, nil
+}
+
+// rocketProducer 將 rocketmq-client-go 的 Producer 轉接為 messaging.Producer
+type rocketProducer struct {
+	p       rmq.Producer
+	admin   admin.Admin
+	started atomic.Bool
+}
+
+func (r *rocketProducer) Start() error {
+	if err := r.p.Start(); err != nil {
+		return err
+	}
+	r.started.Store(true)
+	return nil
+}
+
+func (r *rocketProducer) Shutdown() error {
+	r.started.Store(false)
+	err := r.p.Shutdown()
+	_ = r.admin.Close()
+	return err
+}
+
+func (r *rocketProducer) Send(ctx context.Context, msg *messaging.Message) (*messaging.SendResult, error) {
+	result, err := r.p.SendSync(ctx, ToPrimitive(msg))
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != primitive.SendOK {
+		return nil, fmt.Errorf("RocketMQ 發送狀態異常: %d", result.Status)
+	}
+	sr := &messaging.SendResult{MsgID: result.MsgID, Partition: -1, Offset: result.QueueOffset}
+	if result.MessageQueue != nil {
+		sr.Partition = result.MessageQueue.QueueId
+	}
+	return sr, nil
+}
+
+func (r *rocketProducer) Started() bool {
+	return r.started.Load()
+}
+
+// Ping 向 NameServer 查詢 Topic 清單，NameServer 無法連線時失敗
+func (r *rocketProducer) Ping(ctx context.Context) error {
+	_, err := r.admin.FetchAllTopicList(ctx)
+	return err
+}
+
+// rocketConsumer 將 rocketmq-client-go 的 PushConsumer 轉接為 messaging.Consumer
+type rocketConsumer struct {
+	c rmq.PushConsumer
+}
+
+func (r *rocketConsumer) Start() error    { return r.c.Start() }
+func (r *rocketConsumer) Shutdown() error { return r.c.Shutdown() }
+
+func (r *rocketConsumer) Subscribe(topic, tags string, handler messaging.Handler) error {
+	expression := strings.TrimSpace(tags)
+	if expression == "" {
+		expression = "*"
+	}
+	selector := consumer.MessageSelector{Type: consumer.TAG, Expression: expression}
+	return r.c.Subscribe(topic, selector, adaptHandler(handler))
+}
+
+// adaptHandler 將 messaging.Handler 轉為 rocketmq-client-go 的批次處理函式
+// 批次中任一則失敗時整批重投，並採用批次中最長的指定延遲；預設為單筆消費
+func adaptHandler(handler messaging.Handler) func(context.Context, ...*primitive.MessageExt) (consumer.ConsumeResult, error) {
+	return func(ctx context.Context, msgs ...*primitive.MessageExt) (consumer.ConsumeResult, error) {
+		var delay time.Duration
+		for _, msg := range msgs {
+			err := handler(ctx, FromPrimitive(msg))
+			if err == nil {
+				continue
+			}
+			d, ok := messaging.RetryDelay(err)
+			if !ok {
+				return consumer.ConsumeRetryLater, err
+			}
+			if d > delay {
+				delay = d
+			}
+		}
+		if delay == 0 {
+			return consumer.ConsumeSuccess, nil
+		}
+		if cctx, ok := primitive.GetConcurrentlyCtx(ctx); ok {
+			cctx.DelayLevelWhenNextConsume = DelayLevel(delay)
+		}
+		return consumer.ConsumeRetryLater, nil
+	}
+}
+
+// DelayLevel 將延遲時間進位到最接近的 RocketMQ 延遲等級 (1 起算)，超過最大等級時使用最大等級
+func DelayLevel(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	for i, level := range messaging.DefaultDelayLevels {
+		if level >= d {
+			return i + 1
+		}
+	}
+	return len(messaging.DefaultDelayLevels)
+}
+
+// ToPrimitive 將 messaging.Message 轉為 RocketMQ 訊息，系統屬性不會沿用
+func ToPrimitive(msg *messaging.Message) *primitive.Message {
+	m := primitive.NewMessage(msg.Topic, msg.Body)
+	for k, v := range msg.Properties {
+		if !messaging.IsReservedProperty(k) {
+			m.WithProperty(k, v)
+		}
+	}
+	if msg.Tag != "" {
+		m.WithTag(msg.Tag)
+	}
+	if len(msg.Keys) > 0 {
+		m.WithKeys(msg.Keys)
+	}
+	if msg.ShardingKey != "" {
+		m.WithShardingKey(msg.ShardingKey)
+	}
+	if level := DelayLevel(msg.Delay); level > 0 {
+		m.WithDelayTimeLevel(level)
+	}
+	return m
+}
+
+// FromPrimitive 將 RocketMQ 訊息轉為 messaging.Delivery，系統屬性不放入 Properties
+func FromPrimitive(msg *primitive.MessageExt) *messaging.Delivery {
+	d := &messaging.Delivery{
+		Message: messaging.Message{
+			Topic:       msg.Topic,
+			Tag:         msg.GetTags(),
+			Keys:        strings.Fields(msg.GetKeys()),
+			ShardingKey: msg.GetShardingKey(),
+			Body:        msg.Body,
+		},
+		MsgID:          msg.MsgId,
+		ReconsumeTimes: int(msg.ReconsumeTimes),
+	}
+	if msg.BornTimestamp > 0 {
+		d.BornAt = time.UnixMilli(msg.BornTimestamp)
+	}
+	for k, v := range msg.GetProperties() {
+		if !messaging.IsReservedProperty(k) {
+			d.WithProperty(k, v)
+		}
+	}
+	return d
+}
+
+// nameServers 解析以逗號或分號分隔的 NameServer 位址
+func nameServers(addr string) primitive.NamesrvAddr {
+	var addrs primitive.NamesrvAddr
+	for _, a := range strings.FieldsFunc(addr, func(r rune) bool { return r == ',' || r == ';' }) {
+		addrs = append(addrs, strings.TrimSpace(a))
+	}
+	return addrs
+}
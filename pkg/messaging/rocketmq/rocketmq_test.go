@@ -0,0 +1,61 @@
+package rocketmq_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/stretchr/testify/assert"
+
+	"microservice-mvp/pkg/messaging"
+	"microservice-mvp/pkg/messaging/rocketmq"
+)
+
+func TestDelayLevel(t *testing.T) {
+	tests := []struct {
+		delay     time.Duration
+		wantLevel int
+	}{
+		{delay: 0, wantLevel: 0},
+		{delay: time.Second, wantLevel: 1},
+		{delay: 2 * time.Second, wantLevel: 2},  // 進位到 5s
+		{delay: 8 * time.Second, wantLevel: 3},  // 進位到 10s
+		{delay: 32 * time.Second, wantLevel: 5}, // 進位到 1m
+		{delay: 10 * time.Minute, wantLevel: 14},
+		{delay: 24 * time.Hour, wantLevel: 18}, // 最大等級 2h
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.wantLevel, rocketmq.DelayLevel(tt.delay), "delay=%s", tt.delay)
+	}
+}
+
+func TestMessageConversion(t *testing.T) {
+	msg := messaging.NewMessage("player_events", "player.registered", []byte(`{"id":7}`), []string{"ev-1", "7"}).
+		WithShardingKey("7").
+		WithProperty(messaging.PropertyTraceID, "trace-1").
+		WithProperty("DELAY", "3") // 系統屬性不應沿用
+	msg.Delay = 8 * time.Second
+
+	p := rocketmq.ToPrimitive(msg)
+	assert.Equal(t, "player.registered", p.GetTags())
+	assert.Equal(t, "ev-1 7", p.GetKeys())
+	assert.Equal(t, "7", p.GetShardingKey())
+	assert.Equal(t, "3", p.GetProperty(primitive.PropertyDelayTimeLevel), "延遲 8s 進位到等級 3")
+	assert.Equal(t, "trace-1", p.GetProperty(messaging.PropertyTraceID))
+
+	ext := &primitive.MessageExt{Message: primitive.Message{Topic: p.Topic, Body: p.Body}, MsgId: "msg-1", ReconsumeTimes: 2}
+	ext.WithProperties(p.GetProperties())
+	ext.WithProperty(primitive.PropertyReconsumeTime, "2")
+
+	d := rocketmq.FromPrimitive(ext)
+	assert.Equal(t, "msg-1", d.MsgID)
+	assert.Equal(t, 2, d.ReconsumeTimes)
+	assert.Equal(t, "player.registered", d.Tag)
+	assert.Equal(t, []string{"ev-1", "7"}, d.Keys)
+	assert.Equal(t, "7", d.ShardingKey)
+	assert.Equal(t, map[string]string{messaging.PropertyTraceID: "trace-1"}, d.Properties, "系統屬性不放入 Properties")
+}
+
+func TestRegisteredDriver(t *testing.T) {
+	assert.Contains(t, messaging.Drivers(), messaging.DriverRocketMQ)
+}
@@ -0,0 +1,65 @@
+package messaging
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"microservice-mvp/pkg/tracing"
+)
+
+const (
+	// PropertyTraceParent 是以 W3C traceparent 格式保存發送端 span 的屬性
+	PropertyTraceParent = "traceparent"
+	// PropertyTraceState 是 W3C tracestate 屬性
+	PropertyTraceState = "tracestate"
+)
+
+// PropertyCarrier 以訊息屬性承載 trace context，實作 propagation.TextMapCarrier
+type PropertyCarrier struct {
+	Msg *Message
+}
+
+var _ propagation.TextMapCarrier = PropertyCarrier{}
+
+// Get 實作 propagation.TextMapCarrier
+func (c PropertyCarrier) Get(key string) string {
+	return c.Msg.Property(key)
+}
+
+// Set 實作 propagation.TextMapCarrier
+func (c PropertyCarrier) Set(key, value string) {
+	c.Msg.WithProperty(key, value)
+}
+
+// Keys 實作 propagation.TextMapCarrier
+func (c PropertyCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Msg.Properties))
+	for k := range c.Msg.Properties {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// ExtractTraceContext 從訊息屬性取出發送端的 trace context，作為消費 span 的父 span
+func ExtractTraceContext(ctx context.Context, msg *Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, PropertyCarrier{Msg: msg})
+}
+
+// startPublishSpan 建立發送訊息的 producer span，並將其 trace context 寫入訊息屬性
+func startPublishSpan(ctx context.Context, msg *Message) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ctx, msg.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(producerDriver),
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingMessageBodySize(len(msg.Body)),
+		),
+	)
+	otel.GetTextMapPropagator().Inject(ctx, PropertyCarrier{Msg: msg})
+	return ctx, span
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// gormStartKey 是查詢開始時間在 gorm.DB 實例設定中的鍵
+const gormStartKey = "metrics:start_time"
+
+// GormPlugin 是記錄 GORM 查詢時間的 gorm.Plugin，以 db.Use(metrics.NewGormPlugin("primary")) 註冊
+type GormPlugin struct {
+	db string
+}
+
+// NewGormPlugin 建立 GormPlugin，db 作為指標的 db 標籤，用於區分主庫與各唯讀副本
+func NewGormPlugin(db string) *GormPlugin {
+	return &GormPlugin{db: db}
+}
+
+// Name 實作 gorm.Plugin
+func (p *GormPlugin) Name() string {
+	return "metrics:" + p.db
+}
+
+// Initialize 實作 gorm.Plugin，在各類操作的前後註冊 callback
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	register := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, r := range register {
+		if err := r.before("metrics:before_"+r.operation, before); err != nil {
+			return err
+		}
+		if err := r.after("metrics:after_"+r.operation, p.after(r.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func before(tx *gorm.DB) {
+	tx.InstanceSet(gormStartKey, time.Now())
+}
+
+func (p *GormPlugin) after(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		v, ok := tx.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		table := tx.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		// 查無資料屬於正常結果，不計為錯誤
+		result := "success"
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			result = "error"
+		}
+		dbQueryDuration.WithLabelValues(p.db, operation, table, result).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,232 @@
+// Package metrics 以 Prometheus 格式暴露服務指標：HTTP 請求、GORM 查詢、Redis 命令與快取命中、
+// 訊息佇列收發、outbox 積壓、斷路器狀態，以及 Go runtime 與行程指標。所有指標註冊在 Registry，由 Handler 輸出於 /metrics。
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace 是所有自訂指標的前綴
+const namespace = "mvp"
+
+// UnmatchedRoute 是未匹配任何路由的請求使用的 route 標籤，避免以原始路徑產生無限多的時間序列
+const UnmatchedRoute = "unmatched"
+
+// Registry 是服務專用的指標註冊表，不使用全域預設註冊表，避免第三方套件的指標混入
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP 請求數，依方法、路由樣板與狀態碼分組",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 請求處理時間 (秒)",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "GORM 查詢時間 (秒)，依資料庫、操作、資料表與結果分組",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"db", "operation", "table", "status"})
+
+	redisCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Redis 命令執行時間 (秒)，pipeline 以 pipeline 作為命令名稱",
+		Buckets:   []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25},
+	}, []string{"command", "status"})
+
+	cacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "快取查詢次數，result 為 hit 或 miss",
+	}, []string{"cache", "result"})
+
+	cacheHitRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_hit_ratio",
+		Help:      "啟動以來的快取命中率 (0~1)",
+	}, []string{"cache"})
+
+	mqSentTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mq_messages_sent_total",
+		Help:      "發送的訊息數，status 為 success 或 error",
+	}, []string{"topic", "status"})
+
+	mqConsumedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mq_messages_consumed_total",
+		Help:      "消費的訊息數，status 為 success 或 error (將重新投遞或轉入死信)",
+	}, []string{"topic", "tag", "status"})
+
+	mqConsumeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mq_consume_duration_seconds",
+		Help:      "訊息處理時間 (秒)",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"topic", "tag"})
+
+	outboxEvents = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbox_events",
+		Help:      "outbox 中尚未發送的事件數，status 為 pending 或 failed (已放棄重試)",
+	}, []string{"status"})
+
+	outboxOldestPendingAge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbox_oldest_pending_age_seconds",
+		Help:      "最舊的待發送事件已等待的時間 (秒)，沒有待發送事件時為 0",
+	})
+
+	breakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_state",
+		Help:      "斷路器狀態：0 為 closed，1 為 half-open，2 為 open",
+	}, []string{"name"})
+
+	breakerTransitionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_transitions_total",
+		Help:      "斷路器狀態轉換次數",
+	}, []string{"name", "from", "to"})
+
+	breakerRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_rejected_total",
+		Help:      "斷路器開啟期間被直接拒絕的呼叫數",
+	}, []string{"name"})
+
+	rateLimitRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_requests_total",
+		Help:      "套用速率限制的請求數，依策略與結果 (allowed、limited、error) 分組",
+	}, []string{"policy", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		dbQueryDuration,
+		redisCommandDuration,
+		cacheRequestsTotal,
+		cacheHitRatio,
+		mqSentTotal,
+		mqConsumedTotal,
+		mqConsumeDuration,
+		outboxEvents,
+		outboxOldestPendingAge,
+		breakerState,
+		breakerTransitionsTotal,
+		breakerRejectedTotal,
+		rateLimitRequestsTotal,
+	)
+}
+
+// Handler 回傳以 Prometheus 文字格式輸出 Registry 的 HTTP handler
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHTTPRequest 記錄一次 HTTP 請求，route 為路由樣板 (例如 /api/v1/players/:id)
+func ObserveHTTPRequest(method, route string, status int, latency time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	code := strconv.Itoa(status)
+	httpRequestsTotal.WithLabelValues(method, route, code).Inc()
+	httpRequestDuration.WithLabelValues(method, route, code).Observe(latency.Seconds())
+}
+
+// cacheCounts 保存各快取的命中與未命中次數，用於計算命中率
+var (
+	cacheMu     sync.Mutex
+	cacheCounts = make(map[string]*[2]uint64)
+)
+
+// ObserveCache 記錄一次快取查詢結果並更新命中率
+func ObserveCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequestsTotal.WithLabelValues(cache, result).Inc()
+
+	cacheMu.Lock()
+	counts, ok := cacheCounts[cache]
+	if !ok {
+		counts = new([2]uint64)
+		cacheCounts[cache] = counts
+	}
+	if hit {
+		counts[0]++
+	} else {
+		counts[1]++
+	}
+	ratio := float64(counts[0]) / float64(counts[0]+counts[1])
+	cacheMu.Unlock()
+
+	cacheHitRatio.WithLabelValues(cache).Set(ratio)
+}
+
+// ObserveMQSend 記錄一次訊息發送結果
+func ObserveMQSend(topic string, err error) {
+	mqSentTotal.WithLabelValues(topic, status(err)).Inc()
+}
+
+// ObserveMQConsume 記錄一次訊息處理結果與耗時
+func ObserveMQConsume(topic, tag string, latency time.Duration, err error) {
+	mqConsumedTotal.WithLabelValues(topic, tag, status(err)).Inc()
+	mqConsumeDuration.WithLabelValues(topic, tag).Observe(latency.Seconds())
+}
+
+// SetOutboxLag 設定 outbox 的積壓指標
+func SetOutboxLag(pending, failed int64, oldestPendingAge time.Duration) {
+	outboxEvents.WithLabelValues("pending").Set(float64(pending))
+	outboxEvents.WithLabelValues("failed").Set(float64(failed))
+	outboxOldestPendingAge.Set(oldestPendingAge.Seconds())
+}
+
+// SetBreakerState 設定斷路器目前的狀態 (0 closed、1 half-open、2 open)
+func SetBreakerState(name string, state int) {
+	breakerState.WithLabelValues(name).Set(float64(state))
+}
+
+// ObserveBreakerTransition 記錄一次斷路器狀態轉換
+func ObserveBreakerTransition(name, from, to string) {
+	breakerTransitionsTotal.WithLabelValues(name, from, to).Inc()
+}
+
+// ObserveBreakerRejected 記錄一次被斷路器拒絕的呼叫
+func ObserveBreakerRejected(name string) {
+	breakerRejectedTotal.WithLabelValues(name).Inc()
+}
+
+// ObserveRateLimit 記錄一次速率限制的結果，result 為 allowed、limited 或 error (儲存異常時放行)
+func ObserveRateLimit(policy, result string) {
+	rateLimitRequestsTotal.WithLabelValues(policy, result).Inc()
+}
+
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
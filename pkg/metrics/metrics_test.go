@@ -0,0 +1,82 @@
+package metrics_test
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"microservice-mvp/pkg/metrics"
+)
+
+// scrape 以 Handler 取得目前的指標輸出
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, 200, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestHandler_ExposesSeries(t *testing.T) {
+	metrics.ObserveHTTPRequest("GET", "/api/v1/players/:id", 200, 5*time.Millisecond)
+	metrics.ObserveHTTPRequest("GET", "", 404, time.Millisecond)
+	metrics.ObserveMQSend("player_events", nil)
+	metrics.ObserveMQSend("player_events", errors.New("broker down"))
+	metrics.ObserveMQConsume("player_events", "player.registered", time.Millisecond, nil)
+
+	out := scrape(t)
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "HTTPByRouteTemplate", want: `mvp_http_requests_total{method="GET",route="/api/v1/players/:id",status="200"} 1`},
+		{name: "HTTPUnmatched", want: `mvp_http_requests_total{method="GET",route="unmatched",status="404"} 1`},
+		{name: "HTTPHistogram", want: `mvp_http_request_duration_seconds_count{method="GET",route="/api/v1/players/:id",status="200"} 1`},
+		{name: "MQSendSuccess", want: `mvp_mq_messages_sent_total{status="success",topic="player_events"} 1`},
+		{name: "MQSendError", want: `mvp_mq_messages_sent_total{status="error",topic="player_events"} 1`},
+		{name: "MQConsume", want: `mvp_mq_messages_consumed_total{status="success",tag="player.registered",topic="player_events"} 1`},
+		{name: "GoRuntime", want: "go_goroutines"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, out, tt.want)
+		})
+	}
+}
+
+func TestObserveCache_HitRatio(t *testing.T) {
+	metrics.ObserveCache("test", true)
+	metrics.ObserveCache("test", true)
+	metrics.ObserveCache("test", true)
+	metrics.ObserveCache("test", false)
+
+	out := scrape(t)
+	assert.Contains(t, out, `mvp_cache_requests_total{cache="test",result="hit"} 3`)
+	assert.Contains(t, out, `mvp_cache_requests_total{cache="test",result="miss"} 1`)
+	assert.Contains(t, out, `mvp_cache_hit_ratio{cache="test"} 0.75`)
+}
+
+func TestGormPlugin_ObservesQueries(t *testing.T) {
+	// DryRun 只產生 SQL 不連線資料庫，callback 仍會依序執行
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:1)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(metrics.NewGormPlugin("dryrun")))
+
+	type widget struct{ ID uint }
+	var w widget
+	db.First(&w, 1)
+	db.Where("id = ?", 2).Find(&[]widget{})
+
+	out := scrape(t)
+	assert.Contains(t, out, `mvp_db_query_duration_seconds_count{db="dryrun",operation="query",status="success",table="widgets"} 2`)
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval 是清除已恢復完整額度的鍵的間隔
+const memorySweepInterval = time.Minute
+
+type memoryEntry struct {
+	tokens    float64     // TokenBucket 的權杖數
+	last      time.Time   // TokenBucket 上次計算的時間
+	hits      []time.Time // SlidingWindow 時間窗內的請求時間
+	expiresAt time.Time   // 額度完全恢復的時間，之後可刪除
+}
+
+// MemoryStore 以行程內的 map 保存狀態，只適用於單一實例 (memory 模式)
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore 建立一個新的 MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*memoryEntry), now: time.Now}
+}
+
+// Take 為 key 取用一次額度
+func (s *MemoryStore) Take(ctx context.Context, key string, rule Rule) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &memoryEntry{tokens: float64(rule.capacity()), last: now}
+		s.entries[key] = e
+	}
+
+	var res Result
+	switch rule.Algorithm {
+	case TokenBucket:
+		res, e.tokens = tokenBucket(rule, e.tokens, e.last, now)
+		e.last = now
+	default:
+		res, e.hits = slidingWindow(rule, e.hits, now)
+	}
+	e.expiresAt = now.Add(res.Reset)
+	return res, nil
+}
+
+// sweep 定期刪除已恢復完整額度的鍵，避免大量不同的用戶端使 map 無限成長，呼叫端須持有 s.mu
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package ratelimit 提供 token bucket 與 sliding window 兩種速率限制演算法，
+// 狀態存放於 Store：Redis 讓多個實例共用額度，記憶體則只在單一行程內生效。
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Algorithm 是速率限制演算法
+type Algorithm string
+
+const (
+	// TokenBucket 以固定速率補充權杖，容量內允許突發請求
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow 記錄時間窗內每個請求的時間，任意時間窗內最多 Limit 次
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// Rule 是單一策略的限制參數
+type Rule struct {
+	Algorithm Algorithm
+	Limit     int           // 每個 Window 允許的請求數，TokenBucket 為補充速率
+	Window    time.Duration // 時間窗
+	Burst     int           // TokenBucket 的容量，0 與 Limit 相同
+}
+
+// capacity 回傳 TokenBucket 的容量
+func (r Rule) capacity() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+// Validate 檢查限制參數是否有效
+func (r Rule) Validate() error {
+	switch r.Algorithm {
+	case TokenBucket, SlidingWindow:
+	default:
+		return fmt.Errorf("未知的速率限制演算法: %q", r.Algorithm)
+	}
+	if r.Limit <= 0 || r.Window <= 0 {
+		return fmt.Errorf("limit 與 window 須大於 0")
+	}
+	return nil
+}
+
+// Result 是一次請求的限制結果
+type Result struct {
+	Allowed    bool
+	Limit      int           // 額度上限 (TokenBucket 為容量)
+	Remaining  int           // 此請求後剩餘的額度
+	Reset      time.Duration // 額度完全恢復所需的時間
+	RetryAfter time.Duration // 被拒絕時，下一次請求可被允許前須等待的時間
+}
+
+// Store 保存速率限制的狀態，Take 嘗試為 key 取用一次額度
+type Store interface {
+	Take(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// tokenBucket 計算 token bucket 取用一次後的狀態，tokens 與 last 是上次的權杖數與時間
+// Redis 的 Lua 腳本實作相同的計算
+func tokenBucket(rule Rule, tokens float64, last, now time.Time) (Result, float64) {
+	capacity := float64(rule.capacity())
+	rate := float64(rule.Limit) / float64(rule.Window) // 每 ns 補充的權杖數
+	if elapsed := now.Sub(last); elapsed > 0 {
+		tokens = math.Min(capacity, tokens+float64(elapsed)*rate)
+	}
+
+	res := Result{Limit: int(capacity)}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - tokens) / rate))
+	}
+	res.Remaining = int(tokens)
+	res.Reset = time.Duration(math.Ceil((capacity - tokens) / rate))
+	return res, tokens
+}
+
+// slidingWindow 計算 sliding window 取用一次後的狀態，hits 是時間窗內的請求時間 (由舊到新)
+// Redis 的 Lua 腳本實作相同的計算
+func slidingWindow(rule Rule, hits []time.Time, now time.Time) (Result, []time.Time) {
+	start := now.Add(-rule.Window)
+	i := 0
+	for i < len(hits) && !hits[i].After(start) {
+		i++
+	}
+	hits = hits[i:]
+
+	res := Result{Limit: rule.Limit}
+	if len(hits) < rule.Limit {
+		hits = append(hits, now)
+		res.Allowed = true
+	}
+	res.Remaining = rule.Limit - len(hits)
+	if len(hits) > 0 {
+		// 最新的請求離開時間窗時額度完全恢復，最舊的請求離開時釋出一次額度
+		res.Reset = hits[len(hits)-1].Add(rule.Window).Sub(now)
+		if !res.Allowed {
+			res.RetryAfter = hits[0].Add(rule.Window).Sub(now)
+		}
+	}
+	return res, hits
+}
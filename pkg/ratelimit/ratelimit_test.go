@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	type step struct {
+		advance    time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		rule  Rule
+		steps []step
+	}{
+		{
+			name: "TokenBucket 容量內突發後依速率補充",
+			rule: Rule{Algorithm: TokenBucket, Limit: 1, Window: time.Second, Burst: 2},
+			steps: []step{
+				{allowed: true, remaining: 1},
+				{allowed: true, remaining: 0},
+				{allowed: false, remaining: 0, retryAfter: time.Second},
+				{advance: 500 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond},
+				{advance: 500 * time.Millisecond, allowed: true, remaining: 0},
+				{advance: 10 * time.Second, allowed: true, remaining: 1}, // 補充不超過容量
+			},
+		},
+		{
+			name: "SlidingWindow 任意時間窗內最多 Limit 次",
+			rule: Rule{Algorithm: SlidingWindow, Limit: 2, Window: 10 * time.Second},
+			steps: []step{
+				{allowed: true, remaining: 1},
+				{advance: 4 * time.Second, allowed: true, remaining: 0},
+				{advance: 4 * time.Second, allowed: false, remaining: 0, retryAfter: 2 * time.Second},
+				{advance: 2 * time.Second, allowed: true, remaining: 0}, // 第一個請求離開時間窗
+				{advance: time.Second, allowed: false, remaining: 0, retryAfter: 3 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.rule.Validate())
+			now := time.Now()
+			s := NewMemoryStore()
+			s.now = func() time.Time { return now }
+
+			for i, st := range tt.steps {
+				now = now.Add(st.advance)
+				res, err := s.Take(context.Background(), "k", tt.rule)
+				require.NoError(t, err)
+				assert.Equal(t, st.allowed, res.Allowed, "step %d", i)
+				assert.Equal(t, st.remaining, res.Remaining, "step %d", i)
+				assert.Equal(t, st.retryAfter, res.RetryAfter, "step %d", i)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_KeysAreIndependent(t *testing.T) {
+	s := NewMemoryStore()
+	rule := Rule{Algorithm: SlidingWindow, Limit: 1, Window: time.Minute}
+
+	res, err := s.Take(context.Background(), "a", rule)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = s.Take(context.Background(), "a", rule)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+
+	res, err = s.Take(context.Background(), "b", rule)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestMemoryStore_SweepsRecoveredKeys(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	rule := Rule{Algorithm: TokenBucket, Limit: 10, Window: time.Second}
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := s.Take(context.Background(), key, rule)
+		require.NoError(t, err)
+	}
+	assert.Len(t, s.entries, 3)
+
+	now = now.Add(2 * memorySweepInterval)
+	_, err := s.Take(context.Background(), "d", rule)
+	require.NoError(t, err)
+	assert.Len(t, s.entries, 1)
+}
+
+func TestRule_Validate(t *testing.T) {
+	assert.Error(t, Rule{Algorithm: "leaky_bucket", Limit: 1, Window: time.Second}.Validate())
+	assert.Error(t, Rule{Algorithm: TokenBucket, Limit: 0, Window: time.Second}.Validate())
+	assert.Error(t, Rule{Algorithm: SlidingWindow, Limit: 1}.Validate())
+}
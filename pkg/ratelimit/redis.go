@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript 與 tokenBucket 的計算相同，權杖數與時間存於 hash
+// KEYS[1]: 鍵；ARGV: 現在時間 (ms)、容量、每 ms 補充的權杖數
+// 回傳 {是否允許, 剩餘額度, 完全恢復的 ms, 須等待的 ms}
+var tokenBucketScript = goRedis.NewScript(`
+local now = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local rate = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+if now > ts then
+  tokens = math.min(capacity, tokens + (now - ts) * rate)
+  ts = now
+end
+local allowed, retry = 0, 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate)
+end
+local reset = math.ceil((capacity - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', ts)
+redis.call('PEXPIRE', KEYS[1], reset + 1000)
+return {allowed, math.floor(tokens), reset, retry}
+`)
+
+// slidingWindowScript 與 slidingWindow 的計算相同，請求時間存於 sorted set
+// KEYS[1]: 鍵；ARGV: 現在時間 (ms)、時間窗 (ms)、額度、本次請求的成員 (須唯一)
+// 回傳 {是否允許, 剩餘額度, 完全恢復的 ms, 須等待的 ms}
+var slidingWindowScript = goRedis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed, reset, retry = 0, 0, 0
+if count < limit then
+  redis.call('ZADD', KEYS[1], now, ARGV[4])
+  count = count + 1
+  allowed = 1
+end
+if count > 0 then
+  local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+  reset = tonumber(newest[2]) + window - now
+  if allowed == 0 then
+    local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+    retry = tonumber(oldest[2]) + window - now
+  end
+end
+redis.call('PEXPIRE', KEYS[1], window)
+return {allowed, limit - count, reset, retry}
+`)
+
+// RedisStore 以 Redis 保存狀態，多個實例共用同一份額度；計算以 Lua 腳本在 Redis 端原子執行
+// 時間以各實例的時鐘為準，實例間的時鐘誤差會反映在額度恢復的時間上
+type RedisStore struct {
+	rdb    *goRedis.Client
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisStore 建立一個新的 RedisStore，prefix 為所有鍵的前綴
+func NewRedisStore(rdb *goRedis.Client, prefix string) *RedisStore {
+	return &RedisStore{rdb: rdb, prefix: prefix, now: time.Now}
+}
+
+// Take 為 key 取用一次額度
+func (s *RedisStore) Take(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := s.now().UnixMilli()
+	window := rule.Window.Milliseconds()
+	key = s.prefix + key
+
+	var (
+		values []int64
+		err    error
+	)
+	switch rule.Algorithm {
+	case TokenBucket:
+		rate := float64(rule.Limit) / float64(window)
+		values, err = tokenBucketScript.Run(ctx, s.rdb, []string{key},
+			now, rule.capacity(), strconv.FormatFloat(rate, 'g', -1, 64)).Int64Slice()
+	default:
+		member := strconv.FormatInt(now, 10) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+		values, err = slidingWindowScript.Run(ctx, s.rdb, []string{key},
+			now, window, rule.Limit, member).Int64Slice()
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("執行速率限制腳本失敗: %w", err)
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("速率限制腳本回傳格式錯誤: %v", values)
+	}
+
+	limit := rule.Limit
+	if rule.Algorithm == TokenBucket {
+		limit = rule.capacity()
+	}
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  int(values[1]),
+		Reset:      time.Duration(values[2]) * time.Millisecond,
+		RetryAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
@@ -6,21 +6,38 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"microservice-mvp/pkg/breaker"
 	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/health"
 	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/metrics"
+	"microservice-mvp/pkg/tracing"
 )
 
 // Client 是全域 Redis 客戶端實例
 var Client *redis.Client
 
+// cb 是 Redis 的斷路器，未啟用時為 nil
+var cb *breaker.Breaker
+
 // InitRedis 初始化 Redis 客戶端
 func InitRedis(cfg configs.RedisConfig) (*redis.Client, error) {
-	rdb := redis.NewClient(&redis.Options{
+	opts := &redis.Options{
 		Addr:     cfg.Addr,
 		Password: cfg.Password,
 		DB:       cfg.DB,
 		PoolSize: 10, // 連線池大小
-	})
+	}
+	if cfg.TimeoutMs > 0 {
+		// Redis 卡住時每個命令最多等待 timeout，之後由呼叫端回退 (例如快取改讀資料庫)
+		timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+		opts.DialTimeout, opts.ReadTimeout, opts.WriteTimeout, opts.PoolTimeout = timeout, timeout, timeout, timeout
+	}
+	rdb := redis.NewClient(opts)
+	cb = breaker.New("redis", cfg.Breaker)
+	rdb.AddHook(metrics.RedisHook{})            // 記錄命令執行時間
+	rdb.AddHook(tracing.RedisHook{})            // 為每個命令建立子 span
+	rdb.AddHook(breaker.RedisHook{Breaker: cb}) // 斷路器開啟時直接回傳 breaker.ErrOpen
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -32,10 +49,24 @@ func InitRedis(cfg configs.RedisConfig) (*redis.Client, error) {
 	}
 
 	Client = rdb // 設定全域 Redis 客戶端實例
+	// Redis 作為快取，異常時讀取回退至資料庫，預設為非關鍵組件 (可於 health_check.critical 覆寫)
+	// 檢查的 Ping 經過斷路器，開啟時回報 DOWN，half-open 時作為試探呼叫
+	health.Register(health.NewChecker("redis", func(ctx context.Context) health.Result {
+		details := "breaker=" + cb.State().String()
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			return health.Result{Status: health.StatusDown, Message: "Ping 失敗: " + err.Error(), Details: details}
+		}
+		return health.Result{Status: health.StatusUp, Details: details}
+	}), health.Options{Critical: false})
 	logger.Logger.Info("Redis 客戶端初始化成功")
 	return rdb, nil
 }
 
+// CircuitOpen 回傳 Redis 斷路器是否開啟，開啟期間快取應直接略過
+func CircuitOpen() bool {
+	return cb.State() == breaker.StateOpen
+}
+
 // GetClient 返回全域 Redis 客戶端實例
 func GetClient() *redis.Client {
 	return Client
@@ -8,9 +8,10 @@ import (
 
 // Response 代表統一的 API 回應結構
 type Response struct {
-	Code    int         `json:"code" example:"200"`
-	Message string      `json:"message" example:"success"`
-	Data    interface{} `json:"data,omitempty"`
+	Code      int         `json:"code" example:"200"`
+	ErrorCode string      `json:"error_code,omitempty" example:""` // 機器可讀的錯誤碼，僅錯誤回應時出現
+	Message   string      `json:"message" example:"success"`
+	Data      interface{} `json:"data,omitempty"`
 }
 
 // OK 回應成功訊息和可選資料
@@ -38,6 +39,15 @@ func FailWithMessage(c *gin.Context, httpCode int, message string) {
 	})
 }
 
+// FailWithCode 回應錯誤訊息、狀態碼與機器可讀的錯誤碼
+func FailWithCode(c *gin.Context, httpCode int, errorCode, message string) {
+	c.JSON(httpCode, Response{
+		Code:      httpCode,
+		ErrorCode: errorCode,
+		Message:   message,
+	})
+}
+
 // NewError 建立一個新的錯誤回應
 func NewError(code int, message string) *Response {
 	return &Response{
@@ -50,24 +60,42 @@ func NewError(code int, message string) *Response {
 
 // HTTPError400 代表 Swagger 的 400 Bad Request 回應
 type HTTPError400 struct {
-	Code    int    `json:"code" example:"400"`
-	Message string `json:"message" example:"請求參數錯誤"`
+	Code      int    `json:"code" example:"400"`
+	ErrorCode string `json:"error_code" example:"VALIDATION_FAILED"`
+	Message   string `json:"message" example:"請求參數錯誤"`
 }
 
 // HTTPError401 代表 Swagger 的 401 Unauthorized 回應
 type HTTPError401 struct {
-	Code    int    `json:"code" example:"401"`
-	Message string `json:"message" example:"未經授權"`
+	Code      int    `json:"code" example:"401"`
+	ErrorCode string `json:"error_code" example:"INVALID_CREDENTIALS"`
+	Message   string `json:"message" example:"憑證無效"`
 }
 
 // HTTPError404 代表 Swagger 的 404 Not Found 回應
 type HTTPError404 struct {
-	Code    int    `json:"code" example:"404"`
-	Message string `json:"message" example:"找不到資源"`
+	Code      int    `json:"code" example:"404"`
+	ErrorCode string `json:"error_code" example:"PLAYER_NOT_FOUND"`
+	Message   string `json:"message" example:"玩家不存在"`
+}
+
+// HTTPError409 代表 Swagger 的 409 Conflict 回應
+type HTTPError409 struct {
+	Code      int    `json:"code" example:"409"`
+	ErrorCode string `json:"error_code" example:"USERNAME_TAKEN"`
+	Message   string `json:"message" example:"使用者名稱已存在"`
+}
+
+// HTTPError429 代表 Swagger 的 429 Too Many Requests 回應
+type HTTPError429 struct {
+	Code      int    `json:"code" example:"429"`
+	ErrorCode string `json:"error_code" example:"RATE_LIMITED"`
+	Message   string `json:"message" example:"請求過於頻繁，請稍後再試"`
 }
 
 // HTTPError500 代表 Swagger 的 500 Internal Server Error 回應
 type HTTPError500 struct {
-	Code    int    `json:"code" example:"500"`
-	Message string `json:"message" example:"內部伺服器錯誤"`
-}
\ No newline at end of file
+	Code      int    `json:"code" example:"500"`
+	ErrorCode string `json:"error_code" example:"INTERNAL"`
+	Message   string `json:"message" example:"內部伺服器錯誤"`
+}
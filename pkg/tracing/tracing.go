@@ -0,0 +1,154 @@
+// Package tracing 初始化 OpenTelemetry 分散式追蹤：以 W3C traceparent/tracestate 在 HTTP 與訊息之間傳遞 trace，
+// 並提供 GORM 與 Redis 的 span 埋點。span 依 tracing.exporter 以 OTLP/HTTP 匯出或以 JSON 輸出到標準輸出/檔案。
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+)
+
+const (
+	// ExporterOTLP 以 OTLP/HTTP 匯出到 collector
+	ExporterOTLP = "otlp"
+	// ExporterStdout 以 JSON 輸出到標準輸出或檔案
+	ExporterStdout = "stdout"
+	// ExporterNone 只產生 trace ID 與 span ID 供日誌與下游使用，不匯出 span
+	ExporterNone = "none"
+)
+
+// instrumentationName 是本服務埋點使用的 tracer 名稱
+const instrumentationName = "microservice-mvp"
+
+func init() {
+	// 即使未啟用追蹤也設定 propagator，讓上游的 traceparent 能原樣傳遞到下游
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Tracer 回傳本服務使用的 tracer，未呼叫 Init 時為不記錄的 noop tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Init 依設定建立並設定全域 TracerProvider，回傳的函式於關閉時送出尚未匯出的 span
+func Init(cfg configs.TracingConfig) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !cfg.Enabled {
+		return noop, nil
+	}
+
+	var opts []sdktrace.TracerProviderOption
+	var closer io.Closer
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		exp, err := newOTLPExporter(cfg.OTLP)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case ExporterStdout:
+		w := io.Writer(os.Stdout)
+		if cfg.Stdout.File != "" {
+			f, err := os.OpenFile(cfg.Stdout.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, fmt.Errorf("開啟追蹤輸出檔案失敗: %w", err)
+			}
+			w, closer = f, f
+		}
+		stdoutOpts := []stdouttrace.Option{stdouttrace.WithWriter(w)}
+		if cfg.Stdout.Pretty {
+			stdoutOpts = append(stdoutOpts, stdouttrace.WithPrettyPrint())
+		}
+		exp, err := stdouttrace.New(stdoutOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("建立 stdout 追蹤匯出器失敗: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	case ExporterNone, "":
+	default:
+		return nil, fmt.Errorf("無效的追蹤匯出器: %s", cfg.Exporter)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = instrumentationName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("建立追蹤資源失敗: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	opts = append(opts,
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+
+	logger.Logger.Info("分散式追蹤初始化完成",
+		zap.String("exporter", cfg.Exporter),
+		zap.String("service", serviceName),
+		zap.Float64("sampleRatio", ratio),
+	)
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return err
+	}, nil
+}
+
+// newOTLPExporter 建立 OTLP/HTTP 匯出器，連線於首次匯出時才建立，collector 未啟動不影響服務啟動
+func newOTLPExporter(cfg configs.OTLPConfig) (sdktrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithTimeout(10 * time.Second)}
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	exp, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("建立 OTLP 追蹤匯出器失敗: %w", err)
+	}
+	return exp, nil
+}
+
+// TraceParent 以 W3C traceparent 格式回傳上下文中的 span，沒有有效的 span 時回傳空字串
+// 用於將 trace 保存到稍後才發送的資料中 (例如 outbox 事件)
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// ContextWithTraceParent 將 TraceParent 保存的 span 設為上下文的遠端父 span，格式錯誤或為空時回傳原上下文
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
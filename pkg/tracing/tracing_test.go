@@ -0,0 +1,103 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"microservice-mvp/pkg/configs"
+	"microservice-mvp/pkg/logger"
+	"microservice-mvp/pkg/tracing"
+)
+
+// useRecorder 將全域 TracerProvider 換成記錄到記憶體的版本，測試結束後還原
+func useRecorder(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return exp
+}
+
+func TestInit(t *testing.T) {
+	_, _ = logger.NewLogger("info", "console")
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	tests := []struct {
+		name          string
+		cfg           configs.TracingConfig
+		expectedError string
+	}{
+		{name: "Disabled", cfg: configs.TracingConfig{Exporter: "invalid"}},
+		{name: "None", cfg: configs.TracingConfig{Enabled: true, Exporter: tracing.ExporterNone}},
+		{name: "Stdout", cfg: configs.TracingConfig{Enabled: true, Exporter: tracing.ExporterStdout,
+			Stdout: configs.StdoutTraceConfig{File: t.TempDir() + "/spans.json"}}},
+		{name: "OTLP", cfg: configs.TracingConfig{Enabled: true, Exporter: tracing.ExporterOTLP,
+			OTLP: configs.OTLPConfig{Endpoint: "127.0.0.1:1", Insecure: true}}},
+		{name: "InvalidExporter", cfg: configs.TracingConfig{Enabled: true, Exporter: "zipkin"}, expectedError: "無效的追蹤匯出器"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := tracing.Init(tt.cfg)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestTraceParent_RoundTrip(t *testing.T) {
+	useRecorder(t)
+	ctx, span := tracing.Tracer().Start(context.Background(), "register")
+	defer span.End()
+
+	tp := tracing.TraceParent(ctx)
+	require.NotEmpty(t, tp)
+	assert.Empty(t, tracing.TraceParent(context.Background()), "沒有 span 時不產生 traceparent")
+
+	restored := trace.SpanContextFromContext(tracing.ContextWithTraceParent(context.Background(), tp))
+	assert.Equal(t, span.SpanContext().TraceID(), restored.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), restored.SpanID())
+	assert.True(t, restored.IsRemote())
+
+	assert.Equal(t, context.Background(), tracing.ContextWithTraceParent(context.Background(), ""))
+}
+
+func TestGormPlugin_CreatesChildSpans(t *testing.T) {
+	exp := useRecorder(t)
+
+	// DryRun 只產生 SQL 不連線資料庫，callback 仍會依序執行
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:1)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tracing.NewGormPlugin("primary")))
+
+	type widget struct{ ID uint }
+	db.First(&widget{}, 1) // 不在 trace 中的查詢不產生 span
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "request")
+	db.WithContext(ctx).First(&widget{}, 1)
+	parent.End()
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 2)
+	child := spans[0]
+	assert.Equal(t, "db.query", child.Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), child.Parent.SpanID())
+	assert.Contains(t, child.Attributes, attribute.String("db.sql.table", "widgets"))
+	assert.Contains(t, child.Attributes, attribute.String("db.instance", "primary"))
+}